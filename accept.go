@@ -0,0 +1,143 @@
+package zeno
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AcceptEntry is a single element of an Accept-style request header such
+// as Accept, Accept-Encoding, Accept-Charset, or Accept-Language.
+type AcceptEntry struct {
+	// Value is the range without parameters, lower-cased, e.g.
+	// "text/html", "text/*", "gzip", or "en-us".
+	Value string
+
+	// Params holds media type parameters other than q, e.g.
+	// {"level": "1"} for "text/html;level=1".
+	Params map[string]string
+
+	// Q is the quality factor between 0 and 1; it defaults to 1.
+	Q float64
+}
+
+// specificity ranks how precisely e describes a value: "*" and "*/*" are
+// least specific, "type/*" and language prefixes come next, and exact
+// values with parameters are the most specific.
+func (e AcceptEntry) specificity() int {
+	switch {
+	case e.Value == "*" || e.Value == "*/*":
+		return 0
+	case strings.HasSuffix(e.Value, "/*"):
+		return 1
+	case len(e.Params) > 0:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// matches reports whether range e covers the offer o and, if so, how
+// specifically (see specificity). Language ranges such as "en" also match
+// more specific tags like "en-us".
+func (e AcceptEntry) matches(o AcceptEntry) (int, bool) {
+	switch {
+	case e.Value == "*" || e.Value == "*/*":
+		return 0, true
+	case strings.HasSuffix(e.Value, "/*"):
+		if strings.HasPrefix(o.Value, e.Value[:len(e.Value)-1]) {
+			return 1, true
+		}
+		return 0, false
+	case e.Value == o.Value:
+		for k, v := range e.Params {
+			if o.Params[k] != v {
+				return 0, false
+			}
+		}
+		return e.specificity(), true
+	case !strings.Contains(e.Value, "/") && strings.HasPrefix(o.Value, e.Value+"-"):
+		return 1, true
+	}
+	return 0, false
+}
+
+// parseAcceptEntry parses a single range such as "text/html;level=1;q=0.5".
+func parseAcceptEntry(part string) AcceptEntry {
+	value, rest, _ := strings.Cut(part, ";")
+	e := AcceptEntry{Value: strings.ToLower(strings.TrimSpace(value)), Q: 1}
+	for rest != "" {
+		var param string
+		param, rest, _ = strings.Cut(rest, ";")
+		k, v, _ := strings.Cut(param, "=")
+		k = strings.ToLower(strings.TrimSpace(k))
+		v = strings.Trim(strings.TrimSpace(v), `"`)
+		if k == "" {
+			continue
+		}
+		if k == "q" {
+			if q, err := strconv.ParseFloat(v, 64); err == nil && q >= 0 && q <= 1 {
+				e.Q = q
+			}
+			continue
+		}
+		if e.Params == nil {
+			e.Params = make(map[string]string)
+		}
+		e.Params[k] = v
+	}
+	return e
+}
+
+// ParseAccept parses an Accept-style header into its entries, ordered by
+// preference: higher q-values first, then more specific ranges, then the
+// order in which they appear in the header.
+func ParseAccept(header string) []AcceptEntry {
+	if strings.TrimSpace(header) == "" {
+		return nil
+	}
+	parts := strings.Split(header, ",")
+	entries := make([]AcceptEntry, 0, len(parts))
+	for _, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		entries = append(entries, parseAcceptEntry(part))
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Q != entries[j].Q {
+			return entries[i].Q > entries[j].Q
+		}
+		return entries[i].specificity() > entries[j].specificity()
+	})
+	return entries
+}
+
+// matchAccept returns the offer preferred by header, or "" if none is
+// acceptable. Each offer takes the q-value of the most specific range that
+// matches it; the offer with the highest q wins, with ties broken by the
+// specificity of the matching range and then by offer order.
+func matchAccept(header string, offers []string) string {
+	if header == "" || len(offers) == 0 {
+		return ""
+	}
+	accepted := ParseAccept(header)
+
+	best, bestQ, bestSpec := "", 0.0, -1
+	for _, offer := range offers {
+		o := parseAcceptEntry(offer)
+		q, spec := 0.0, -1
+		for _, acc := range accepted {
+			if s, ok := acc.matches(o); ok && s > spec {
+				q, spec = acc.Q, s
+			}
+		}
+		if spec < 0 || q <= 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && spec > bestSpec) {
+			best, bestQ, bestSpec = offer, q, spec
+		}
+	}
+	return best
+}
@@ -0,0 +1,37 @@
+package zeno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchAccept(t *testing.T) {
+	cases := []struct {
+		header string
+		offers []string
+		want   string
+	}{
+		{"application/json, text/html;q=0.8, */*;q=0.1", []string{"text/html", "application/json"}, "application/json"},
+		{"text/*, text/html", []string{"text/plain", "text/html"}, "text/html"},
+		{"text/*;q=0.5, */*;q=0.9", []string{"text/plain", "image/png"}, "image/png"},
+		{"text/html;q=0, */*", []string{"text/html"}, ""},
+		{"text/html;level=1, text/html;q=0.2", []string{"text/html", "text/html;level=1"}, "text/html;level=1"},
+		{"en;q=0.8, fr", []string{"en-US", "de"}, "en-US"},
+		{"gzip;q=0.5, br", []string{"gzip", "br"}, "br"},
+		{"image/png", []string{"text/html"}, ""},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, matchAccept(tc.header, tc.offers), tc.header)
+	}
+}
+
+func TestParseAccept(t *testing.T) {
+	entries := ParseAccept(`*/*;q=0.8, text/*;q=0.8, text/html;charset="utf-8";q=0.8, application/json`)
+	assert.Len(t, entries, 4)
+	assert.Equal(t, "application/json", entries[0].Value)
+	assert.Equal(t, "text/html", entries[1].Value)
+	assert.Equal(t, map[string]string{"charset": "utf-8"}, entries[1].Params)
+	assert.Equal(t, "text/*", entries[2].Value)
+	assert.Equal(t, "*/*", entries[3].Value)
+}
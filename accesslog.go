@@ -0,0 +1,221 @@
+package zeno
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AccessLogEntry is one line written by an AccessLogger.
+type AccessLogEntry struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Route      string    `json:"route,omitempty"`
+	Status     int       `json:"status"`
+	Latency    float64   `json:"latency_ms"`
+	BytesIn    int       `json:"bytes_in"`
+	BytesOut   int       `json:"bytes_out"`
+	RemoteAddr string    `json:"remote_addr,omitempty"`
+	Error      string    `json:"error,omitempty"`
+
+	// Slow is set when the request took longer than the slow threshold.
+	// Such entries carry the request details below.
+	Slow   bool              `json:"slow,omitempty"`
+	Query  string            `json:"query,omitempty"`
+	Header http.Header       `json:"header,omitempty"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// AccessLogConfig configures an AccessLogger.
+type AccessLogConfig struct {
+	// Output receives the entries as JSON lines. Defaults to os.Stderr.
+	Output io.Writer
+
+	// Log, when set, is called with each entry instead of writing it to
+	// Output, e.g. to hand it to a structured logger.
+	Log func(c *Context, entry AccessLogEntry)
+
+	// SampleRate is the fraction, between 0 and 1, of successful requests
+	// (status below 400) that are logged. Errors and slow requests are
+	// always logged. Defaults to 1; set a negative value to log no
+	// successful requests.
+	SampleRate float64
+
+	// SlowThreshold is the latency above which a request is logged with
+	// its query string, headers and route parameters. Zero disables it.
+	SlowThreshold time.Duration
+
+	// Redactor masks credentials and personal data in the path, error
+	// and slow request details. Defaults to DefaultRedactor.
+	Redactor *Redactor
+
+	// Skipper, when set, excludes requests for which it returns true.
+	Skipper Skipper
+}
+
+// AccessLogger logs requests passing through its Handler. Its sample rate
+// and slow threshold can be changed while the server is running, e.g. to
+// log every request while an incident is investigated.
+type AccessLogger struct {
+	config AccessLogConfig
+	mu     sync.Mutex // serializes writes to config.Output
+
+	sampleRate    atomic.Uint64 // math.Float64bits of the rate
+	slowThreshold atomic.Int64
+}
+
+// NewAccessLogger creates an AccessLogger with the given configuration,
+// applying the defaults documented on AccessLogConfig.
+//
+// Example:
+//
+//	logger := zeno.NewAccessLogger(zeno.AccessLogConfig{
+//	    SampleRate:    0.01,
+//	    SlowThreshold: 500 * time.Millisecond,
+//	})
+//	app.Use(logger.Handler())
+//	// later, e.g. from an admin endpoint:
+//	logger.SetSampleRate(1)
+func NewAccessLogger(config AccessLogConfig) *AccessLogger {
+	if config.Output == nil {
+		config.Output = os.Stderr
+	}
+	if config.SampleRate == 0 {
+		config.SampleRate = 1
+	}
+	if config.Redactor == nil {
+		config.Redactor = DefaultRedactor()
+	}
+	l := &AccessLogger{config: config}
+	l.SetSampleRate(config.SampleRate)
+	l.SetSlowThreshold(config.SlowThreshold)
+	return l
+}
+
+// SetSampleRate changes the fraction of successful requests logged.
+// Values outside [0, 1] are clamped.
+func (l *AccessLogger) SetSampleRate(rate float64) {
+	l.sampleRate.Store(math.Float64bits(math.Min(math.Max(rate, 0), 1)))
+}
+
+// SampleRate returns the fraction of successful requests logged.
+func (l *AccessLogger) SampleRate() float64 {
+	return math.Float64frombits(l.sampleRate.Load())
+}
+
+// SetSlowThreshold changes the latency above which requests are logged in
+// full detail. Zero disables slow request logging.
+func (l *AccessLogger) SetSlowThreshold(d time.Duration) {
+	l.slowThreshold.Store(int64(d))
+}
+
+// SlowThreshold returns the latency above which requests are logged in
+// full detail.
+func (l *AccessLogger) SlowThreshold() time.Duration {
+	return time.Duration(l.slowThreshold.Load())
+}
+
+// Handler returns the middleware logging requests. Register it before
+// other middleware so their time counts towards the latency.
+func (l *AccessLogger) Handler() Handler {
+	return func(c *Context) error {
+		if l.config.Skipper != nil && l.config.Skipper(c) {
+			return c.Next()
+		}
+		start := time.Now()
+		err := c.Next()
+		latency := time.Since(start)
+
+		status := c.StatusCode()
+		if err != nil {
+			// The error handler has not run yet; log the status it is
+			// expected to send.
+			status = StatusInternalServerError
+			var he HTTPError
+			if errors.As(err, &he) {
+				status = he.StatusCode()
+			}
+		}
+		slow := l.SlowThreshold() > 0 && latency > l.SlowThreshold()
+		if status < 400 && !slow {
+			if rate := l.SampleRate(); rate < 1 && rand.Float64() >= rate {
+				return err
+			}
+		}
+
+		entry := AccessLogEntry{
+			Time:       start,
+			Method:     c.Method(),
+			Path:       l.config.Redactor.String(c.Path()),
+			Route:      c.RoutePattern(),
+			Status:     status,
+			Latency:    float64(latency) / float64(time.Millisecond),
+			BytesIn:    c.BytesReceived(),
+			BytesOut:   c.ResponseSize(),
+			RemoteAddr: c.IP(),
+			Slow:       slow,
+		}
+		if err != nil {
+			entry.Error = l.config.Redactor.String(err.Error())
+		}
+		if slow {
+			l.addDetail(c, &entry)
+		}
+		l.write(c, entry)
+		return err
+	}
+}
+
+// addDetail fills in the request details logged for slow requests.
+func (l *AccessLogger) addDetail(c *Context, entry *AccessLogEntry) {
+	redactor := l.config.Redactor
+	entry.Query = redactor.Query(string(c.ctx.URI().QueryString()))
+	entry.Params = c.Params()
+	redactor.Params(entry.Params)
+	entry.Header = http.Header{}
+	c.ctx.Request.Header.VisitAll(func(key, value []byte) {
+		entry.Header.Add(string(key), string(value))
+	})
+	redactor.Header(entry.Header)
+}
+
+// write hands entry to config.Log or writes it to config.Output.
+func (l *AccessLogger) write(c *Context, entry AccessLogEntry) {
+	if l.config.Log != nil {
+		l.config.Log(c, entry)
+		return
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.config.Output.Write(append(b, '\n'))
+}
+
+// AccessLog returns a middleware logging requests as JSON lines, with
+// optional sampling of successful requests and detailed logging of slow
+// ones. Use NewAccessLogger to change these settings at runtime.
+//
+// Example:
+//
+//	app.Use(zeno.AccessLog(zeno.AccessLogConfig{
+//	    SampleRate:    0.01,                   // 1% of successful requests
+//	    SlowThreshold: 500 * time.Millisecond, // plus every slow one
+//	}))
+func AccessLog(config ...AccessLogConfig) Handler {
+	var cfg AccessLogConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	return NewAccessLogger(cfg).Handler()
+}
@@ -0,0 +1,51 @@
+package zeno
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewAccessLogger(AccessLogConfig{Output: &buf, SampleRate: -1})
+	z := New()
+	z.Use(logger.Handler())
+	z.Get("/users/{id}", func(c *Context) error { return c.SendString("ok") })
+	z.Get("/fail", func(c *Context) error { return NewHTTPError(StatusBadRequest, "bad") })
+	z.Get("/slow/{id}", func(c *Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return c.SendString("slow")
+	})
+
+	performRequest(z, "GET", "/users/1", nil, nil)
+	assert.Empty(t, buf.String(), "successful requests are not sampled")
+
+	performRequest(z, "GET", "/fail", nil, nil)
+	var entry AccessLogEntry
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, StatusBadRequest, entry.Status)
+	assert.Equal(t, "bad", entry.Error)
+	assert.False(t, entry.Slow)
+
+	buf.Reset()
+	logger.SetSlowThreshold(10 * time.Millisecond)
+	performRequest(z, "GET", "/slow/7?x=1", map[string]string{"Authorization": "secret"}, nil)
+	entry = AccessLogEntry{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.True(t, entry.Slow)
+	assert.Equal(t, "/slow/{id}", entry.Route)
+	assert.Equal(t, "x=1", entry.Query)
+	assert.Equal(t, map[string]string{"id": "7"}, entry.Params)
+	assert.Equal(t, "[REDACTED]", entry.Header.Get("Authorization"))
+
+	buf.Reset()
+	logger.SetSampleRate(1)
+	performRequest(z, "GET", "/users/1", nil, nil)
+	performRequest(z, "GET", "/users/2", nil, nil)
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+}
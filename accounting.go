@@ -0,0 +1,114 @@
+package zeno
+
+import (
+	"bufio"
+	"sync/atomic"
+)
+
+// BytesReceived returns the size of the request as received: the request
+// line and headers plus the body. When the body is being streamed (see
+// Zeno.StreamRequestBody) and has no Content-Length, only the request line
+// and headers are counted, since the body has not been read yet.
+//
+// Example:
+//
+//	log.Printf("%s %s in=%d", c.Method(), c.Path(), c.BytesReceived())
+func (c *Context) BytesReceived() int {
+	req := &c.ctx.Request
+	n := len(req.Header.Header())
+	if !req.IsBodyStream() {
+		n += len(req.Body())
+	} else if cl := req.Header.ContentLength(); cl > 0 {
+		n += cl
+	}
+	return n
+}
+
+// ResponseSize returns the size of the response body written so far.
+//
+// For bodies set with the Send helpers or Write it is the body length. For
+// streamed bodies with a known length it is that length. For streams of
+// unknown length, such as SendJSONStream, it counts the bytes handed to
+// the connection and only reaches its final value once the stream has been
+// written, which happens after the handler chain returns; read it from an
+// AfterResponse callback in that case.
+func (c *Context) ResponseSize() int {
+	resp := &c.ctx.Response
+	if !resp.IsBodyStream() {
+		return len(resp.Body())
+	}
+	if cl := resp.Header.ContentLength(); cl >= 0 {
+		return cl
+	}
+	if c.streamed != nil {
+		return int(c.streamed.Load())
+	}
+	return 0
+}
+
+// BytesSent returns the size of the response: the status line and headers
+// plus ResponseSize.
+//
+// Example:
+//
+//	app.Use(func(c *zeno.Context) error {
+//	    err := c.Next()
+//	    log.Printf("%s %s out=%d", c.Method(), c.Path(), c.BytesSent())
+//	    return err
+//	})
+func (c *Context) BytesSent() int {
+	return len(c.ctx.Response.Header.Header()) + c.ResponseSize()
+}
+
+// setBodyStreamWriter is fasthttp's SetBodyStreamWriter with the bytes
+// written by fn counted for ResponseSize. The counter is allocated per
+// stream because fn runs after the handler chain has returned, when the
+// Context may already serve another request.
+func (c *Context) setBodyStreamWriter(fn func(w *bufio.Writer)) {
+	n := new(atomic.Int64)
+	c.streamed = n
+	c.ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		cw := bufio.NewWriter(&countingWriter{w: w, n: n})
+		fn(cw)
+		_ = cw.Flush()
+	})
+}
+
+// countingWriter forwards writes to w, adding their size to n.
+type countingWriter struct {
+	w *bufio.Writer
+	n *atomic.Int64
+}
+
+// Write implements io.Writer.
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	written, err := cw.w.Write(p)
+	cw.n.Add(int64(written))
+	return written, err
+}
+
+// StatusCode returns the response status code set so far, 200 if none
+// was set.
+//
+// Example:
+//
+//	app.Use(func(c *zeno.Context) error {
+//	    err := c.Next()
+//	    metrics.Observe(c.RoutePattern(), c.StatusCode(), c.BodyLen())
+//	    return err
+//	})
+func (c *Context) StatusCode() int {
+	return c.ctx.Response.StatusCode()
+}
+
+// ResponseHeader returns the value of the response header key, or "" if
+// it has not been set.
+func (c *Context) ResponseHeader(key string) string {
+	return string(c.ctx.Response.Header.Peek(key))
+}
+
+// BodyLen returns the length of the response body written so far. It is
+// the same as ResponseSize, including its handling of streamed bodies.
+func (c *Context) BodyLen() int {
+	return c.ResponseSize()
+}
@@ -0,0 +1,56 @@
+package zeno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_ByteAccounting(t *testing.T) {
+	z := New()
+	var received, size, sent int
+	z.Use(func(c *Context) error {
+		err := c.Next()
+		received, size, sent = c.BytesReceived(), c.ResponseSize(), c.BytesSent()
+		return err
+	})
+	z.Post("/echo", func(c *Context) error { return c.SendBytes(c.PostBody()) })
+
+	ctx := performRequest(z, "POST", "/echo", nil, []byte("hello"))
+	assert.Equal(t, len(ctx.Request.Header.Header())+5, received)
+	assert.Equal(t, 5, size)
+	assert.Equal(t, len(ctx.Response.Header.Header())+5, sent)
+}
+
+func TestContext_ResponseSize_Stream(t *testing.T) {
+	z := New()
+	var c *Context
+	z.Get("/stream", func(ctx *Context) error {
+		c = ctx
+		return ctx.SendJSONStream([]int{1, 2, 3})
+	})
+
+	ctx := performRequest(z, "GET", "/stream", nil, nil)
+	body := ctx.Response.Body()
+	assert.JSONEq(t, "[1,2,3]", string(body))
+	assert.Equal(t, len(body), c.ResponseSize())
+}
+
+func TestContext_ResponseInspection(t *testing.T) {
+	z := New()
+	var status, length int
+	var ctype, missing string
+	z.Use(func(c *Context) error {
+		err := c.Next()
+		status, length = c.StatusCode(), c.BodyLen()
+		ctype, missing = c.ResponseHeader(HeaderContentType), c.ResponseHeader("X-Missing")
+		return err
+	})
+	z.Get("/", func(c *Context) error { return c.Status(StatusAccepted).SendString("queued") })
+
+	performRequest(z, "GET", "/", nil, nil)
+	assert.Equal(t, StatusAccepted, status)
+	assert.Equal(t, 6, length)
+	assert.Equal(t, "text/plain; charset=utf-8", ctype)
+	assert.Equal(t, "", missing)
+}
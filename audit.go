@@ -0,0 +1,191 @@
+package zeno
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AuditEntry is a request and its response captured by the Audit
+// middleware. Bodies are sanitized according to AuditConfig.
+type AuditEntry struct {
+	Time       time.Time   `json:"time"`
+	Method     string      `json:"method"`
+	URI        string      `json:"uri"`
+	Route      string      `json:"route,omitempty"`
+	RemoteAddr string      `json:"remote_addr,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	Status     int         `json:"status"`
+	Latency    float64     `json:"latency_ms"`
+	Error      string      `json:"error,omitempty"`
+
+	// RequestBody and ResponseBody hold the sanitized bodies. They are
+	// empty when a body exceeds AuditConfig.MaxBodySize or is streamed;
+	// the sizes are recorded regardless, -1 when unknown.
+	RequestBody      string `json:"request_body,omitempty"`
+	RequestBodySize  int    `json:"request_body_size"`
+	ResponseBody     string `json:"response_body,omitempty"`
+	ResponseBodySize int    `json:"response_body_size"`
+}
+
+// AuditSink stores audit entries. Audit is called from request goroutines
+// concurrently.
+type AuditSink interface {
+	Audit(entry AuditEntry) error
+}
+
+// AuditSinkFunc adapts a function to an AuditSink.
+type AuditSinkFunc func(entry AuditEntry) error
+
+// Audit calls f(entry).
+func (f AuditSinkFunc) Audit(entry AuditEntry) error { return f(entry) }
+
+// jsonAuditSink writes audit entries as JSON lines.
+type jsonAuditSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONAuditSink returns an AuditSink writing each entry to w as one
+// line of JSON.
+func NewJSONAuditSink(w io.Writer) AuditSink {
+	return &jsonAuditSink{enc: json.NewEncoder(w)}
+}
+
+// Audit implements AuditSink.
+func (s *jsonAuditSink) Audit(entry AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(entry)
+}
+
+// AuditConfig configures the Audit middleware.
+type AuditConfig struct {
+	// Sink receives the audit entries. Required.
+	Sink AuditSink
+
+	// Tag, when set, limits auditing to routes tagged with it (see
+	// Route.Tags), so the middleware can be installed globally while
+	// only compliance-sensitive routes are captured. Without a tag every
+	// request passing through the middleware is audited; install it on a
+	// group to audit that group.
+	Tag string
+
+	// Skipper, when set, excludes requests for which it returns true.
+	Skipper Skipper
+
+	// MaxBodySize is the largest body, in bytes, that is captured.
+	// Defaults to 64 KiB; negative values disable body capture.
+	MaxBodySize int
+
+	// Redactor masks credentials and personal data in the captured
+	// headers and bodies. Defaults to DefaultRedactor.
+	Redactor *Redactor
+
+	// OnError is called when the sink fails. Audit errors never affect
+	// the response.
+	OnError func(c *Context, err error)
+}
+
+// Audit returns a middleware that captures the requests passing through
+// it together with their responses, including bodies, masked by
+// config.Redactor, to config.Sink.
+//
+// Example:
+//
+//	app.Use(zeno.Audit(zeno.AuditConfig{
+//	    Sink: zeno.NewJSONAuditSink(auditFile),
+//	    Tag:  "audit",
+//	}))
+//	app.Post("/payments", createPayment).Tags("audit")
+func Audit(config AuditConfig) Handler {
+	if config.Sink == nil {
+		panic("zeno: Audit requires a Sink")
+	}
+	if config.MaxBodySize == 0 {
+		config.MaxBodySize = 64 << 10
+	}
+	if config.Redactor == nil {
+		config.Redactor = DefaultRedactor()
+	}
+	return func(c *Context) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return c.Next()
+		}
+		if config.Tag != "" {
+			if r := c.Route(); r == nil || !r.HasTag(config.Tag) {
+				return c.Next()
+			}
+		}
+
+		start := time.Now()
+		entry := auditRequest(c, config)
+		err := c.Next()
+		entry.Latency = float64(time.Since(start)) / float64(time.Millisecond)
+		auditResponse(c, config, &entry, err)
+
+		if serr := config.Sink.Audit(entry); serr != nil && config.OnError != nil {
+			config.OnError(c, serr)
+		}
+		return err
+	}
+}
+
+// auditRequest captures the request part of an audit entry.
+func auditRequest(c *Context, config AuditConfig) AuditEntry {
+	req := &c.ctx.Request
+	entry := AuditEntry{
+		Time:            time.Now(),
+		Method:          c.Method(),
+		URI:             config.Redactor.URI(string(req.Header.RequestURI())),
+		Route:           c.RoutePattern(),
+		RemoteAddr:      c.IP(),
+		Header:          http.Header{},
+		RequestBodySize: -1,
+	}
+	req.Header.VisitAll(func(key, value []byte) {
+		entry.Header.Add(string(key), string(value))
+	})
+	config.Redactor.Header(entry.Header)
+	if !req.IsBodyStream() {
+		body := c.PostBody()
+		entry.RequestBodySize = len(body)
+		entry.RequestBody = auditBody(string(req.Header.ContentType()), body, config)
+	}
+	return entry
+}
+
+// auditResponse captures the response part of an audit entry. When the
+// chain failed the response has not been written yet, so the status the
+// error handler is expected to send and the error are recorded instead.
+func auditResponse(c *Context, config AuditConfig, entry *AuditEntry, err error) {
+	resp := &c.ctx.Response
+	entry.ResponseBodySize = -1
+	if err != nil {
+		entry.Status = StatusInternalServerError
+		var he HTTPError
+		if errors.As(err, &he) {
+			entry.Status = he.StatusCode()
+		}
+		entry.Error = config.Redactor.String(err.Error())
+		return
+	}
+	entry.Status = resp.StatusCode()
+	if !resp.IsBodyStream() {
+		body := resp.Body()
+		entry.ResponseBodySize = len(body)
+		entry.ResponseBody = auditBody(string(resp.Header.ContentType()), body, config)
+	}
+}
+
+// auditBody returns body sanitized for an audit entry, or "" if it is too
+// large to capture.
+func auditBody(contentType string, body []byte, config AuditConfig) string {
+	if len(body) == 0 || config.MaxBodySize < 0 || len(body) > config.MaxBodySize {
+		return ""
+	}
+	return config.Redactor.Body(contentType, body)
+}
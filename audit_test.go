@@ -0,0 +1,55 @@
+package zeno
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	var entries []AuditEntry
+	var sinkErr error
+	z := New()
+	z.Use(Audit(AuditConfig{
+		Sink: AuditSinkFunc(func(e AuditEntry) error {
+			entries = append(entries, e)
+			return errors.New("disk full")
+		}),
+		Tag:     "audit",
+		OnError: func(c *Context, err error) { sinkErr = err },
+	}))
+	z.Post("/login", func(c *Context) error {
+		return c.SendJSON(map[string]any{"user": "alice", "token": "abc"})
+	}).Tags("audit")
+	z.Post("/form", func(c *Context) error { return ErrForbidden }).Tags("audit")
+	z.Post("/public", func(c *Context) error { return c.SendString("ok") })
+
+	performRequest(z, "POST", "/login", map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer secret",
+	}, []byte(`{"user":"alice","credentials":{"Password":"hunter2"}}`))
+	if assert.Len(t, entries, 1) {
+		e := entries[0]
+		assert.Equal(t, "/login", e.Route)
+		assert.Equal(t, StatusOK, e.Status)
+		assert.Equal(t, "[REDACTED]", e.Header.Get("Authorization"))
+		assert.JSONEq(t, `{"user":"alice","credentials":{"Password":"[REDACTED]"}}`, e.RequestBody)
+		assert.JSONEq(t, `{"user":"alice","token":"[REDACTED]"}`, e.ResponseBody)
+	}
+	assert.EqualError(t, sinkErr, "disk full")
+
+	performRequest(z, "POST", "/form", map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}, []byte("user=bob&password=pw"))
+	if assert.Len(t, entries, 2) {
+		e := entries[1]
+		assert.Equal(t, "password=%5BREDACTED%5D&user=bob", e.RequestBody)
+		assert.Equal(t, StatusForbidden, e.Status)
+		assert.Equal(t, ErrForbidden.Error(), e.Error)
+		assert.Equal(t, -1, e.ResponseBodySize)
+	}
+
+	performRequest(z, "POST", "/public", nil, []byte("x"))
+	assert.Len(t, entries, 2)
+}
@@ -0,0 +1,430 @@
+// Package auth implements login with OpenID Connect and OAuth 2.0
+// providers, such as an organisation's identity provider, Google or
+// GitHub, using the authorization code flow with PKCE.
+//
+// Mount registers the login, callback and logout endpoints. After login
+// the user's identity is kept in an encrypted session cookie, which
+// RequireLogin checks on protected routes.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+)
+
+// Config configures an Authenticator.
+type Config struct {
+	// Issuer is the URL of the OpenID Connect provider, e.g.
+	// "https://accounts.google.com". Its endpoints are discovered from
+	// Issuer + "/.well-known/openid-configuration" unless Endpoint is set.
+	Issuer string
+
+	// Endpoint sets the provider endpoints explicitly, e.g. for OAuth 2.0
+	// providers without discovery.
+	Endpoint Endpoint
+
+	// ClientID and ClientSecret are the credentials registered with the
+	// provider. ClientID is required; public clients have no secret and
+	// rely on PKCE alone.
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL is the absolute URL of the callback endpoint registered
+	// with the provider. Defaults to prefix + "/callback" on the host of
+	// the login request.
+	RedirectURL string
+
+	// Scopes lists the requested scopes. Defaults to "openid", "profile"
+	// and "email".
+	Scopes []string
+
+	// SessionKey encrypts and authenticates the cookies. It must be at
+	// least 32 random bytes and is required.
+	SessionKey []byte
+
+	// CookieName names the session cookie. Defaults to "zeno_session".
+	CookieName string
+
+	// SessionTTL is how long a login lasts. Defaults to 24 hours.
+	SessionTTL time.Duration
+
+	// KeepClaims lists the claims stored in the session besides the
+	// subject, email and name. Claims are dropped by default, since the
+	// whole session must fit in a single cookie.
+	KeepClaims []string
+
+	// KeepTokens stores the access and refresh tokens in the session, for
+	// calling APIs on the user's behalf. Large tokens may exceed the size
+	// browsers allow for cookies, which makes the login fail.
+	KeepTokens bool
+
+	// OnLogin, when set, is called before the session is established, to
+	// reject users or adjust the session. An error is returned to the
+	// client instead; zeno.HTTPErrors keep their status, others become
+	// 403 Forbidden.
+	OnLogin func(c *zeno.Context, s *Session) error
+
+	// AfterLogout is where users are sent after logging out. Defaults to
+	// "/".
+	AfterLogout string
+
+	// HTTPClient sends the requests to the provider. Defaults to a client
+	// with a 10 second timeout.
+	HTTPClient *http.Client
+}
+
+// Session is a logged in user.
+type Session struct {
+	// Subject identifies the user at the provider: the sub claim, or the
+	// id returned by the userinfo endpoint of OAuth 2.0 providers.
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+	Name    string `json:"name,omitempty"`
+
+	// Claims holds the claims of the ID token and the userinfo endpoint.
+	// All of them are available to Config.OnLogin, but only those listed
+	// in Config.KeepClaims are stored in the session.
+	Claims map[string]any `json:"claims,omitempty"`
+
+	// AccessToken and RefreshToken are only kept with Config.KeepTokens.
+	AccessToken  string    `json:"at,omitempty"`
+	RefreshToken string    `json:"rt,omitempty"`
+	TokenExpiry  time.Time `json:"te,omitzero"`
+
+	Expires time.Time `json:"exp"`
+}
+
+// flow is the state of a login in progress, kept in a cookie between the
+// login request and the callback.
+type flow struct {
+	State    string    `json:"state"`
+	Nonce    string    `json:"nonce"`
+	Verifier string    `json:"verifier"`
+	Redirect string    `json:"redirect"`
+	ReturnTo string    `json:"return_to"`
+	Expires  time.Time `json:"exp"`
+}
+
+// Authenticator logs users in with a provider. Create one with New.
+type Authenticator struct {
+	config   Config
+	provider *provider
+	sealer   *sealer
+	prefix   string
+}
+
+// sessionKey is the Context key of the loaded session.
+const sessionKey = "auth.session"
+
+// New returns an Authenticator configured by config. It panics if
+// config lacks a ClientID, a provider or a SessionKey.
+//
+// Example:
+//
+//	login := auth.New(auth.Config{
+//	    Issuer:       "https://accounts.google.com",
+//	    ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+//	    ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+//	    RedirectURL:  "https://app.example.com/auth/callback",
+//	    SessionKey:   sessionKey,
+//	})
+//	login.Mount(&app.RouteGroup, "/auth")
+//
+//	app.Get("/dashboard", login.RequireLogin(), func(c *zeno.Context) error {
+//	    return c.SendString("hello " + auth.SessionOf(c).Name)
+//	})
+func New(config Config) *Authenticator {
+	if config.ClientID == "" {
+		panic("auth: Config requires a ClientID")
+	}
+	if config.Issuer == "" && (config.Endpoint.AuthURL == "" || config.Endpoint.TokenURL == "") {
+		panic("auth: Config requires an Issuer or an Endpoint")
+	}
+	if len(config.SessionKey) < 32 {
+		panic("auth: Config requires a SessionKey of at least 32 bytes")
+	}
+	if config.Scopes == nil {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+	if config.CookieName == "" {
+		config.CookieName = "zeno_session"
+	}
+	if config.SessionTTL <= 0 {
+		config.SessionTTL = 24 * time.Hour
+	}
+	if config.AfterLogout == "" {
+		config.AfterLogout = "/"
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Authenticator{
+		config:   config,
+		provider: &provider{config: config, client: client},
+		sealer:   newSealer(config.SessionKey),
+		prefix:   "/auth",
+	}
+}
+
+// Mount registers the endpoints of a under prefix on r:
+//
+//   - GET prefix/login starts a login, returning to the local path in the
+//     return_to query parameter afterwards.
+//   - GET prefix/callback completes it.
+//   - POST prefix/logout ends the session.
+func (a *Authenticator) Mount(r *zeno.RouteGroup, prefix string) {
+	a.prefix = strings.TrimSuffix(prefix, "/")
+	g := r.Group(a.prefix)
+	g.Get("/login", a.login)
+	g.Get("/callback", a.callback)
+	g.Post("/logout", a.logout)
+}
+
+// RequireLogin returns a middleware admitting only logged in users.
+// Browsers navigating to a protected page are sent to the login endpoint
+// and back; other requests are answered with 401 Unauthorized.
+func (a *Authenticator) RequireLogin() zeno.Handler {
+	return func(c *zeno.Context) error {
+		if a.Session(c) != nil {
+			return c.Next()
+		}
+		method := c.Method()
+		if (method == zeno.MethodGet || method == zeno.MethodHead) && c.Accepts("text/html", "application/json") == "text/html" {
+			c.Abort()
+			return c.Redirect(a.prefix + "/login?return_to=" + url.QueryEscape(string(c.Request().RequestURI())))
+		}
+		return zeno.NewHTTPError(zeno.StatusUnauthorized, "login required")
+	}
+}
+
+// Session returns the session of the logged in user, or nil. Routes
+// without RequireLogin may call it for optional login.
+func (a *Authenticator) Session(c *zeno.Context) *Session {
+	if s, ok := c.Get(sessionKey).(*Session); ok {
+		return s
+	}
+	value := c.Request().Header.Cookie(a.config.CookieName)
+	if len(value) == 0 {
+		return nil
+	}
+	var s Session
+	if err := a.sealer.open(a.config.CookieName, string(value), &s); err != nil || time.Now().After(s.Expires) {
+		return nil
+	}
+	c.Set(sessionKey, &s)
+	return &s
+}
+
+// SessionOf returns the session loaded by RequireLogin or
+// Authenticator.Session, or nil.
+func SessionOf(c *zeno.Context) *Session {
+	s, _ := c.Get(sessionKey).(*Session)
+	return s
+}
+
+// login redirects to the provider's authorization endpoint.
+func (a *Authenticator) login(c *zeno.Context) error {
+	e, err := a.provider.discover(context.Background())
+	if err != nil {
+		return zeno.NewHTTPError(zeno.StatusBadGateway, "login: "+err.Error())
+	}
+	f := flow{
+		State:    randomString(),
+		Nonce:    randomString(),
+		Verifier: randomString(),
+		Redirect: a.redirectURL(c),
+		ReturnTo: localPath(c.Query("return_to")),
+		Expires:  time.Now().Add(10 * time.Minute),
+	}
+	value, err := a.sealer.seal(a.flowCookie(), f)
+	if err != nil {
+		return zeno.NewHTTPError(zeno.StatusInternalServerError, "login: "+err.Error())
+	}
+	setCookie(c, a.flowCookie(), value, cookieOptions{path: a.prefix, secure: a.secure(c), maxAge: 10 * time.Minute})
+
+	challenge := sha256.Sum256([]byte(f.Verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {a.config.ClientID},
+		"redirect_uri":          {f.Redirect},
+		"scope":                 {strings.Join(a.config.Scopes, " ")},
+		"state":                 {f.State},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if e.JWKSURL != "" {
+		q.Set("nonce", f.Nonce)
+	}
+	sep := "?"
+	if strings.Contains(e.AuthURL, "?") {
+		sep = "&"
+	}
+	return c.Redirect(e.AuthURL + sep + q.Encode())
+}
+
+// callback completes a login: it checks the state, redeems the code,
+// verifies the ID token, fetches the user's claims and establishes the
+// session.
+func (a *Authenticator) callback(c *zeno.Context) error {
+	var f flow
+	value := c.Request().Header.Cookie(a.flowCookie())
+	if err := a.sealer.open(a.flowCookie(), string(value), &f); err != nil || time.Now().After(f.Expires) {
+		return zeno.NewHTTPError(zeno.StatusBadRequest, "login: no login in progress")
+	}
+	setCookie(c, a.flowCookie(), "", cookieOptions{path: a.prefix, secure: a.secure(c), maxAge: -1})
+	if state := c.Query("state"); state == "" || state != f.State {
+		return zeno.NewHTTPError(zeno.StatusBadRequest, "login: state mismatch")
+	}
+	if e := c.Query("error"); e != "" {
+		return zeno.NewHTTPError(zeno.StatusUnauthorized, strings.TrimSpace("login failed: "+e+" "+c.Query("error_description")))
+	}
+	code := c.Query("code")
+	if code == "" {
+		return zeno.NewHTTPError(zeno.StatusBadRequest, "login: missing code")
+	}
+
+	ctx := context.Background()
+	e, err := a.provider.discover(ctx)
+	if err != nil {
+		return zeno.NewHTTPError(zeno.StatusBadGateway, "login: "+err.Error())
+	}
+	tok, err := a.provider.exchange(ctx, e, code, f.Redirect, f.Verifier)
+	if err != nil {
+		return zeno.NewHTTPError(zeno.StatusBadGateway, "login: "+err.Error())
+	}
+	now := time.Now()
+	s := &Session{Claims: map[string]any{}, Expires: now.Add(a.config.SessionTTL)}
+	if e.JWKSURL != "" {
+		if tok.IDToken == "" {
+			return zeno.NewHTTPError(zeno.StatusBadGateway, "login: no id token")
+		}
+		claims, err := a.provider.verifyIDToken(ctx, e, tok.IDToken, f.Nonce, now)
+		if err != nil {
+			return zeno.NewHTTPError(zeno.StatusUnauthorized, "login: "+err.Error())
+		}
+		for _, k := range []string{"iss", "aud", "exp", "iat", "nbf", "nonce", "at_hash", "c_hash", "azp", "auth_time"} {
+			delete(claims, k)
+		}
+		s.Claims = claims
+	}
+	if e.UserInfoURL != "" {
+		info, err := a.provider.userInfo(ctx, e, tok.AccessToken)
+		if err != nil {
+			return zeno.NewHTTPError(zeno.StatusBadGateway, "login: "+err.Error())
+		}
+		if sub, ok := s.Claims["sub"]; ok && info["sub"] != sub {
+			return zeno.NewHTTPError(zeno.StatusUnauthorized, "login: userinfo subject mismatch")
+		}
+		for k, v := range info {
+			s.Claims[k] = v
+		}
+	}
+	s.Subject = claimString(s.Claims, "sub", "id")
+	s.Email = claimString(s.Claims, "email")
+	s.Name = claimString(s.Claims, "name", "preferred_username", "login")
+	if s.Subject == "" {
+		return zeno.NewHTTPError(zeno.StatusBadGateway, "login: provider did not identify the user")
+	}
+	if a.config.KeepTokens {
+		s.AccessToken, s.RefreshToken = tok.AccessToken, tok.RefreshToken
+		if tok.ExpiresIn > 0 {
+			s.TokenExpiry = now.Add(time.Duration(tok.ExpiresIn) * time.Second)
+		}
+	}
+	if a.config.OnLogin != nil {
+		if err := a.config.OnLogin(c, s); err != nil {
+			if _, ok := err.(zeno.HTTPError); ok {
+				return err
+			}
+			return zeno.NewHTTPError(zeno.StatusForbidden, err.Error())
+		}
+	}
+
+	maps.DeleteFunc(s.Claims, func(k string, _ any) bool { return !slices.Contains(a.config.KeepClaims, k) })
+	if len(s.Claims) == 0 {
+		s.Claims = nil
+	}
+
+	sealed, err := a.sealer.seal(a.config.CookieName, s)
+	if err != nil {
+		return zeno.NewHTTPError(zeno.StatusInternalServerError, "login: "+err.Error())
+	}
+	if size := len(a.config.CookieName) + 1 + len(sealed); size > maxCookieSize {
+		return zeno.NewHTTPError(zeno.StatusInternalServerError, fmt.Sprintf(
+			"login: session of %d bytes exceeds the %d bytes browsers keep in a cookie; keep fewer claims or no tokens",
+			size, maxCookieSize))
+	}
+	setCookie(c, a.config.CookieName, sealed, cookieOptions{path: "/", secure: a.secure(c), maxAge: a.config.SessionTTL})
+	return c.Redirect(f.ReturnTo)
+}
+
+// logout ends the session.
+func (a *Authenticator) logout(c *zeno.Context) error {
+	setCookie(c, a.config.CookieName, "", cookieOptions{path: "/", secure: a.secure(c), maxAge: -1})
+	return c.Redirect(a.config.AfterLogout, zeno.StatusSeeOther)
+}
+
+// flowCookie names the cookie holding the login in progress.
+func (a *Authenticator) flowCookie() string {
+	return a.config.CookieName + "_login"
+}
+
+// redirectURL returns the callback URL given to the provider.
+func (a *Authenticator) redirectURL(c *zeno.Context) string {
+	if a.config.RedirectURL != "" {
+		return a.config.RedirectURL
+	}
+	return c.Scheme() + "://" + c.Host() + a.prefix + "/callback"
+}
+
+// secure reports whether the cookies are restricted to HTTPS.
+func (a *Authenticator) secure(c *zeno.Context) bool {
+	return c.IsSecure() || strings.HasPrefix(a.config.RedirectURL, "https://")
+}
+
+// randomString returns 32 random bytes, base64url-encoded, as used for
+// the state, nonce and PKCE code verifier.
+func randomString() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("auth: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// localPath returns p if it is a path on this site, and "/" otherwise, so
+// return_to cannot redirect to other sites.
+func localPath(p string) string {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") || strings.HasPrefix(p, "/\\") {
+		return "/"
+	}
+	return p
+}
+
+// claimString returns the first of the named claims that is set, as a
+// string.
+func claimString(claims map[string]any, names ...string) string {
+	for _, name := range names {
+		switch v := claims[name].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case float64:
+			return fmt.Sprint(int64(v))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func do(z *zeno.Zeno, method, uri string, headers map[string]string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	ctx.Request.Header.SetHost("app.test")
+	for k, v := range headers {
+		ctx.Request.Header.Set(k, v)
+	}
+	z.HandleRequest(ctx)
+	return ctx
+}
+
+// cookie returns the value of the cookie name set by the response.
+func cookie(ctx *fasthttp.RequestCtx, name string) (string, bool) {
+	ck := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(ck)
+	ck.SetKey(name)
+	if !ctx.Response.Header.Cookie(ck) {
+		return "", false
+	}
+	return string(ck.Value()), true
+}
+
+// fakeIdP is an OpenID Connect provider issuing RS256 ID tokens.
+type fakeIdP struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]url.Values // authorization request by code
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	idp := &fakeIdP{key: key, codes: map[string]url.Values{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"userinfo_endpoint":      idp.URL + "/userinfo",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		idp.mu.Lock()
+		authz, ok := idp.codes[r.PostFormValue("code")]
+		delete(idp.codes, r.PostFormValue("code"))
+		idp.mu.Unlock()
+		verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if id != "app" || secret != "s3cret" || !ok ||
+			r.PostFormValue("grant_type") != "authorization_code" ||
+			r.PostFormValue("redirect_uri") != authz.Get("redirect_uri") ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != authz.Get("code_challenge") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(Token{
+			AccessToken: "at-alice",
+			TokenType:   "Bearer",
+			ExpiresIn:   3600,
+			IDToken: idp.sign(t, map[string]any{
+				"iss":   idp.URL,
+				"aud":   "app",
+				"sub":   "alice",
+				"email": "alice@example.com",
+				"nonce": authz.Get("nonce"),
+				"iat":   time.Now().Unix(),
+				"exp":   time.Now().Add(time.Hour).Unix(),
+			}),
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer at-alice" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"sub": "alice", "name": "Alice", "groups": []string{"admin"}})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func (idp *fakeIdP) sign(t *testing.T, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, sum[:])
+	assert.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// authorize plays the user approving the login at the provider and
+// returns the callback URL it redirects to.
+func (idp *fakeIdP) authorize(t *testing.T, location string) string {
+	u, err := url.Parse(location)
+	assert.NoError(t, err)
+	q := u.Query()
+	assert.Equal(t, idp.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "code", q.Get("response_type"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+	assert.Equal(t, "openid profile email", q.Get("scope"))
+	code := randomString()
+	idp.mu.Lock()
+	idp.codes[code] = q
+	idp.mu.Unlock()
+	return q.Get("redirect_uri") + "?" + url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+}
+
+func newApp(idp *fakeIdP, config Config) (*zeno.Zeno, *Authenticator) {
+	config.Issuer = idp.URL
+	config.ClientID, config.ClientSecret = "app", "s3cret"
+	config.SessionKey = []byte(strings.Repeat("k", 32))
+	a := New(config)
+	z := zeno.New()
+	a.Mount(&z.RouteGroup, "/auth")
+	z.Get("/dashboard", a.RequireLogin(), func(c *zeno.Context) error {
+		s := SessionOf(c)
+		return c.SendString(s.Subject + " " + s.Email + " " + s.Name)
+	})
+	return z, a
+}
+
+func TestLogin(t *testing.T) {
+	idp := newFakeIdP(t)
+	z, _ := newApp(idp, Config{})
+	browser := map[string]string{zeno.HeaderAccept: "text/html"}
+
+	ctx := do(z, "GET", "/dashboard?tab=1", browser)
+	assert.Equal(t, zeno.StatusFound, ctx.Response.StatusCode())
+	login := string(ctx.Response.Header.Peek(zeno.HeaderLocation))
+	assert.Equal(t, "http://app.test/auth/login?return_to=%2Fdashboard%3Ftab%3D1", login)
+
+	ctx = do(z, "GET", login, nil)
+	assert.Equal(t, zeno.StatusFound, ctx.Response.StatusCode())
+	flowCookie, ok := cookie(ctx, "zeno_session_login")
+	assert.True(t, ok)
+	callback := idp.authorize(t, string(ctx.Response.Header.Peek(zeno.HeaderLocation)))
+	assert.True(t, strings.HasPrefix(callback, "http://app.test/auth/callback?"))
+
+	ctx = do(z, "GET", callback, map[string]string{zeno.HeaderCookie: "zeno_session_login=" + flowCookie})
+	assert.Equal(t, zeno.StatusFound, ctx.Response.StatusCode(), string(ctx.Response.Body()))
+	assert.Equal(t, "http://app.test/dashboard?tab=1", string(ctx.Response.Header.Peek(zeno.HeaderLocation)))
+	session, ok := cookie(ctx, "zeno_session")
+	assert.True(t, ok)
+
+	ctx = do(z, "GET", "/dashboard", map[string]string{zeno.HeaderCookie: "zeno_session=" + session})
+	assert.Equal(t, zeno.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "alice alice@example.com Alice", string(ctx.Response.Body()))
+
+	// Codes are single use.
+	ctx = do(z, "GET", callback, map[string]string{zeno.HeaderCookie: "zeno_session_login=" + flowCookie})
+	assert.Equal(t, zeno.StatusBadGateway, ctx.Response.StatusCode())
+
+	ctx = do(z, "POST", "/auth/logout", map[string]string{zeno.HeaderCookie: "zeno_session=" + session})
+	assert.Equal(t, zeno.StatusSeeOther, ctx.Response.StatusCode())
+	cleared, _ := cookie(ctx, "zeno_session")
+	assert.Empty(t, cleared)
+}
+
+func TestRequireLogin(t *testing.T) {
+	idp := newFakeIdP(t)
+	z, a := newApp(idp, Config{})
+
+	ctx := do(z, "GET", "/dashboard", map[string]string{zeno.HeaderAccept: "application/json"})
+	assert.Equal(t, zeno.StatusUnauthorized, ctx.Response.StatusCode())
+
+	expired, _ := a.sealer.seal("zeno_session", Session{Subject: "alice", Expires: time.Now().Add(-time.Minute)})
+	forged, _ := newSealer([]byte(strings.Repeat("x", 32))).seal("zeno_session", Session{Subject: "alice", Expires: time.Now().Add(time.Hour)})
+	moved, _ := a.sealer.seal("other", Session{Subject: "alice", Expires: time.Now().Add(time.Hour)})
+	for _, value := range []string{expired, forged, moved, "garbage"} {
+		ctx = do(z, "GET", "/dashboard", map[string]string{zeno.HeaderCookie: "zeno_session=" + value})
+		assert.Equal(t, zeno.StatusUnauthorized, ctx.Response.StatusCode())
+	}
+}
+
+func TestCallback_Rejected(t *testing.T) {
+	idp := newFakeIdP(t)
+	z, _ := newApp(idp, Config{
+		OnLogin: func(c *zeno.Context, s *Session) error {
+			if s.Email != "bob@example.com" {
+				return zeno.NewHTTPError(zeno.StatusForbidden, "not invited")
+			}
+			return nil
+		},
+	})
+	start := func() (string, string) {
+		ctx := do(z, "GET", "/auth/login?return_to=//evil.test", nil)
+		flowCookie, _ := cookie(ctx, "zeno_session_login")
+		return idp.authorize(t, string(ctx.Response.Header.Peek(zeno.HeaderLocation))), "zeno_session_login=" + flowCookie
+	}
+
+	callback, flowCookie := start()
+	ctx := do(z, "GET", callback, nil)
+	assert.Equal(t, zeno.StatusBadRequest, ctx.Response.StatusCode())
+	assert.Equal(t, "login: no login in progress", string(ctx.Response.Body()))
+
+	ctx = do(z, "GET", strings.Replace(callback, "state=", "state=x", 1), map[string]string{zeno.HeaderCookie: flowCookie})
+	assert.Equal(t, zeno.StatusBadRequest, ctx.Response.StatusCode())
+	assert.Equal(t, "login: state mismatch", string(ctx.Response.Body()))
+
+	callback, flowCookie = start()
+	state, _ := url.Parse(callback)
+	ctx = do(z, "GET", "/auth/callback?error=access_denied&state="+state.Query().Get("state"), map[string]string{zeno.HeaderCookie: flowCookie})
+	assert.Equal(t, zeno.StatusUnauthorized, ctx.Response.StatusCode())
+	assert.Equal(t, "login failed: access_denied", string(ctx.Response.Body()))
+
+	callback, flowCookie = start()
+	ctx = do(z, "GET", callback, map[string]string{zeno.HeaderCookie: flowCookie})
+	assert.Equal(t, zeno.StatusForbidden, ctx.Response.StatusCode())
+	assert.Equal(t, "not invited", string(ctx.Response.Body()))
+	_, ok := cookie(ctx, "zeno_session")
+	assert.False(t, ok)
+}
+
+// login runs a login through idp and returns the response to the callback.
+func login(t *testing.T, z *zeno.Zeno, idp *fakeIdP) *fasthttp.RequestCtx {
+	ctx := do(z, "GET", "/auth/login", nil)
+	flowCookie, _ := cookie(ctx, "zeno_session_login")
+	callback := idp.authorize(t, string(ctx.Response.Header.Peek(zeno.HeaderLocation)))
+	return do(z, "GET", callback, map[string]string{zeno.HeaderCookie: "zeno_session_login=" + flowCookie})
+}
+
+func TestCallback_SessionSize(t *testing.T) {
+	idp := newFakeIdP(t)
+	var claims map[string]any
+	z, a := newApp(idp, Config{
+		KeepClaims: []string{"groups"},
+		KeepTokens: true,
+		OnLogin: func(c *zeno.Context, s *Session) error {
+			claims = maps.Clone(s.Claims)
+			return nil
+		},
+	})
+	ctx := login(t, z, idp)
+	assert.Equal(t, zeno.StatusFound, ctx.Response.StatusCode(), string(ctx.Response.Body()))
+	assert.Contains(t, claims, "name")
+	value, _ := cookie(ctx, "zeno_session")
+	var s Session
+	assert.NoError(t, a.sealer.open("zeno_session", value, &s))
+	assert.Equal(t, map[string]any{"groups": []any{"admin"}}, s.Claims)
+	assert.Equal(t, "at-alice", s.AccessToken)
+
+	z, _ = newApp(idp, Config{
+		KeepTokens: true,
+		OnLogin: func(c *zeno.Context, s *Session) error {
+			s.AccessToken = strings.Repeat("x", maxCookieSize)
+			return nil
+		},
+	})
+	ctx = login(t, z, idp)
+	assert.Equal(t, zeno.StatusInternalServerError, ctx.Response.StatusCode())
+	assert.Contains(t, string(ctx.Response.Body()), "exceeds the 4096 bytes")
+	_, ok := cookie(ctx, "zeno_session")
+	assert.False(t, ok)
+}
+
+func TestLocalPath(t *testing.T) {
+	assert.Equal(t, "/a?b=c", localPath("/a?b=c"))
+	for _, p := range []string{"", "https://evil.test", "//evil.test", "/\\evil.test", "evil"} {
+		assert.Equal(t, "/", localPath(p), p)
+	}
+}
+
+func TestNew_Panics(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	assert.Panics(t, func() { New(Config{Issuer: "https://idp.test", SessionKey: key}) })
+	assert.Panics(t, func() { New(Config{ClientID: "app", SessionKey: key}) })
+	assert.Panics(t, func() { New(Config{Issuer: "https://idp.test", ClientID: "app", SessionKey: key[:16]}) })
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/valyala/fasthttp"
+)
+
+// maxCookieSize is the size of the name and value of the largest cookie
+// all browsers keep.
+const maxCookieSize = 4096
+
+// errInvalidCookie is returned for cookies that cannot be opened.
+var errInvalidCookie = errors.New("auth: invalid cookie")
+
+// sealer encrypts and authenticates cookie values with AES-GCM, so the
+// state kept in them can neither be read nor forged by clients.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+// newSealer returns a sealer using a key derived from secret.
+func newSealer(secret []byte) *sealer {
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic(err) // unreachable: the key is always 32 bytes
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &sealer{aead: aead}
+}
+
+// seal encodes v as the value of the cookie name. The name is
+// authenticated too, so values cannot be moved between cookies.
+func (s *sealer) seal(name string, v any) (string, error) {
+	plain, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(plain)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plain, []byte(name))), nil
+}
+
+// open decodes the value of the cookie name into v.
+func (s *sealer) open(name, value string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return errInvalidCookie
+	}
+	n := s.aead.NonceSize()
+	plain, err := s.aead.Open(nil, data[:n], data[n:], []byte(name))
+	if err != nil {
+		return errInvalidCookie
+	}
+	return json.Unmarshal(plain, v)
+}
+
+// cookieOptions are the attributes of the cookies set by an Authenticator.
+type cookieOptions struct {
+	path   string
+	secure bool
+	maxAge time.Duration // deletes the cookie when negative
+}
+
+// setCookie sets the cookie name on the response.
+func setCookie(c *zeno.Context, name, value string, opts cookieOptions) {
+	ck := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(ck)
+	ck.SetKey(name)
+	ck.SetValue(value)
+	ck.SetPath(opts.path)
+	ck.SetHTTPOnly(true)
+	ck.SetSecure(opts.secure)
+	// Lax lets the cookies accompany the top-level redirect back from the
+	// provider, while keeping them off cross-site subrequests.
+	ck.SetSameSite(fasthttp.CookieSameSiteLaxMode)
+	if opts.maxAge < 0 {
+		ck.SetExpire(fasthttp.CookieExpireDelete)
+	} else {
+		ck.SetMaxAge(int(opts.maxAge / time.Second))
+	}
+	c.Response().Header.SetCookie(ck)
+}
@@ -0,0 +1,373 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // hashes of the JWS algorithms
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint holds the endpoints of an OAuth 2.0 or OpenID Connect
+// provider.
+type Endpoint struct {
+	AuthURL     string `json:"authorization_endpoint"`
+	TokenURL    string `json:"token_endpoint"`
+	UserInfoURL string `json:"userinfo_endpoint,omitempty"`
+
+	// JWKSURL publishes the keys signing ID tokens. Without it ID tokens
+	// are not requested or verified, as for plain OAuth 2.0 providers.
+	JWKSURL string `json:"jwks_uri,omitempty"`
+}
+
+// Token is the response of the token endpoint.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+}
+
+// provider talks to the authorization server.
+type provider struct {
+	config Config
+	client *http.Client
+
+	mu         sync.Mutex
+	endpoint   *Endpoint
+	keys       map[string]crypto.PublicKey // by key ID
+	keysLoaded time.Time
+}
+
+// discover returns the endpoints of the provider, fetching its OpenID
+// Connect discovery document on first use unless Config.Endpoint is set.
+func (p *provider) discover(ctx context.Context) (*Endpoint, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.endpoint != nil {
+		return p.endpoint, nil
+	}
+	if p.config.Endpoint.AuthURL != "" {
+		p.endpoint = &p.config.Endpoint
+		return p.endpoint, nil
+	}
+
+	var doc struct {
+		Issuer string `json:"issuer"`
+		Endpoint
+	}
+	u := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, u, "", &doc); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if doc.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("discovery: issuer %q does not match %q", doc.Issuer, p.config.Issuer)
+	}
+	if doc.AuthURL == "" || doc.TokenURL == "" {
+		return nil, errors.New("discovery: missing endpoints")
+	}
+	p.endpoint = &doc.Endpoint
+	return p.endpoint, nil
+}
+
+// exchange redeems an authorization code for tokens.
+func (p *provider) exchange(ctx context.Context, e *Endpoint, code, redirectURI, verifier string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	}
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+	var tok Token
+	if err := p.doJSON(req, &tok); err != nil {
+		return nil, fmt.Errorf("token exchange: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return nil, errors.New("token exchange: no access token")
+	}
+	return &tok, nil
+}
+
+// userInfo fetches the claims of the user the access token belongs to.
+func (p *provider) userInfo(ctx context.Context, e *Endpoint, accessToken string) (map[string]any, error) {
+	claims := map[string]any{}
+	if err := p.getJSON(ctx, e.UserInfoURL, accessToken, &claims); err != nil {
+		return nil, fmt.Errorf("userinfo: %w", err)
+	}
+	return claims, nil
+}
+
+// getJSON fetches u, with a bearer token if set, and decodes the JSON
+// response into out.
+func (p *provider) getJSON(ctx context.Context, u, token string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return p.doJSON(req, out)
+}
+
+// doJSON sends req and decodes the JSON response into out.
+func (p *provider) doJSON(req *http.Request, out any) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			return fmt.Errorf("%s: %s %s", resp.Status, oauthErr.Error, oauthErr.Description)
+		}
+		return errors.New(resp.Status)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// verifyIDToken verifies the signature and claims of an ID token
+// (OpenID Connect Core, section 3.1.3.7) and returns its claims.
+func (p *provider) verifyIDToken(ctx context.Context, e *Endpoint, raw, nonce string, now time.Time) (map[string]any, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("id token: malformed")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errors.New("id token: malformed header")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("id token: malformed signature")
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	if strings.HasPrefix(header.Alg, "HS") {
+		err = verifyJWS(header.Alg, []byte(p.config.ClientSecret), signed, sig)
+	} else {
+		var key crypto.PublicKey
+		if key, err = p.key(ctx, e, header.Kid); err == nil && key == nil {
+			err = fmt.Errorf("unknown key %q", header.Kid)
+		} else if err == nil {
+			err = verifyJWS(header.Alg, key, signed, sig)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("id token: %w", err)
+	}
+
+	claims := map[string]any{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errors.New("id token: malformed claims")
+	}
+	if iss, _ := claims["iss"].(string); p.config.Issuer != "" && iss != p.config.Issuer {
+		return nil, fmt.Errorf("id token: issuer %q", iss)
+	}
+	if !audienceContains(claims["aud"], p.config.ClientID) {
+		return nil, errors.New("id token: wrong audience")
+	}
+	const leeway = time.Minute
+	exp, _ := claims["exp"].(float64)
+	if now.After(time.Unix(int64(exp), 0).Add(leeway)) {
+		return nil, errors.New("id token: expired")
+	}
+	if iat, ok := claims["iat"].(float64); ok && time.Unix(int64(iat), 0).After(now.Add(leeway)) {
+		return nil, errors.New("id token: issued in the future")
+	}
+	if got, _ := claims["nonce"].(string); !hmac.Equal([]byte(got), []byte(nonce)) {
+		return nil, errors.New("id token: nonce mismatch")
+	}
+	return claims, nil
+}
+
+// key returns the provider key with the given ID, refreshing the key set
+// when the ID is unknown, at most once a minute.
+func (p *provider) key(ctx context.Context, e *Endpoint, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k := p.lookup(kid); k != nil || time.Since(p.keysLoaded) < time.Minute {
+		return k, nil
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.getJSON(ctx, e.JWKSURL, "", &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	p.keys, p.keysLoaded = map[string]crypto.PublicKey{}, time.Now()
+	for _, k := range set.Keys {
+		if pub, err := k.publicKey(); err == nil && k.Use != "enc" {
+			p.keys[k.Kid] = pub
+		}
+	}
+	return p.lookup(kid), nil
+}
+
+// lookup returns the loaded key with the given ID, or the only key when
+// the token names none. The caller holds p.mu.
+func (p *provider) lookup(kid string) crypto.PublicKey {
+	if k, ok := p.keys[kid]; ok {
+		return k
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k
+		}
+	}
+	return nil
+}
+
+// jwk is a JSON Web Key (RFC 7517).
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey returns the public key k describes.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	b := func(s string) *big.Int {
+		v, _ := base64.RawURLEncoding.DecodeString(s)
+		return new(big.Int).SetBytes(v)
+	}
+	switch k.Kty {
+	case "RSA":
+		n, e := b(k.N), b(k.E)
+		if n.Sign() == 0 || !e.IsInt64() || e.Int64() < 3 {
+			return nil, errors.New("invalid RSA key")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: b(k.X), Y: b(k.Y)}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid OKP key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// verifyJWS verifies a JSON Web Signature (RFC 7518, section 3).
+func verifyJWS(alg string, key any, signed, sig []byte) error {
+	hashes := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+	h, ok := hashes[strings.TrimLeft(alg, "HRSEP")]
+	if !ok && alg != "EdDSA" {
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	var sum []byte
+	if ok {
+		hash := h.New()
+		hash.Write(signed)
+		sum = hash.Sum(nil)
+	}
+
+	valid := false
+	switch k := key.(type) {
+	case []byte:
+		if strings.HasPrefix(alg, "HS") && len(k) > 0 {
+			mac := hmac.New(h.New, k)
+			mac.Write(signed)
+			valid = hmac.Equal(mac.Sum(nil), sig)
+		}
+	case *rsa.PublicKey:
+		switch {
+		case strings.HasPrefix(alg, "RS"):
+			valid = rsa.VerifyPKCS1v15(k, h, sum, sig) == nil
+		case strings.HasPrefix(alg, "PS"):
+			valid = rsa.VerifyPSS(k, h, sum, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		}
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if strings.HasPrefix(alg, "ES") && len(sig) == 2*size {
+			r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+			valid = ecdsa.Verify(k, sum, r, s)
+		}
+	case ed25519.PublicKey:
+		valid = alg == "EdDSA" && ed25519.Verify(k, signed, sig)
+	}
+	if !valid {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// decodeSegment decodes a base64url-encoded JSON segment of a JWT.
+func decodeSegment(s string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// audienceContains reports whether the aud claim, a string or an array,
+// contains clientID.
+func audienceContains(aud any, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []any:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
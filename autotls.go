@@ -0,0 +1,117 @@
+package zeno
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// AutoTLSConfig configures RunAutoTLS.
+type AutoTLSConfig struct {
+	// CacheDir is the directory where issued certificates and the ACME
+	// account key are stored between restarts. Defaults to "./certs".
+	CacheDir string
+
+	// Email is an optional contact address handed to the ACME provider
+	// for expiry and account notifications.
+	Email string
+
+	// Addr is the address the HTTPS server listens on. Defaults to ":443".
+	Addr string
+
+	// HTTPAddr is the address used to answer HTTP-01 challenges. All other
+	// plain HTTP requests on it are redirected to HTTPS. Defaults to ":80".
+	// Set it to "-" to disable the challenge listener, e.g. when port 80 is
+	// handled elsewhere and only TLS-ALPN-01 challenges are used.
+	HTTPAddr string
+}
+
+// RunAutoTLS starts an HTTPS server that obtains and renews certificates
+// for domains automatically from Let's Encrypt using ACME.
+//
+// Alongside the HTTPS listener a plain HTTP listener is started on
+// AutoTLS.HTTPAddr to answer HTTP-01 challenges and redirect other traffic
+// to HTTPS. RunAutoTLS returns when either server fails, after shutting
+// the other one down.
+//
+// Example:
+//
+//	app.AutoTLS.CacheDir = "/var/lib/myapp/certs"
+//	log.Fatal(app.RunAutoTLS("example.com", "www.example.com"))
+func (z *Zeno) RunAutoTLS(domains ...string) error {
+	if len(domains) == 0 {
+		return errors.New("zeno: RunAutoTLS requires at least one domain")
+	}
+	if err := z.start(); err != nil {
+		return err
+	}
+
+	cfg := z.AutoTLS
+	if cfg.CacheDir == "" {
+		cfg.CacheDir = "./certs"
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = ":443"
+	}
+	if cfg.HTTPAddr == "" {
+		cfg.HTTPAddr = ":80"
+	}
+
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      autocert.DirCache(cfg.CacheDir),
+		Email:      cfg.Email,
+	}
+
+	var httpLn net.Listener
+	if cfg.HTTPAddr != "-" {
+		ln, err := z.listen(cfg.HTTPAddr)
+		if err != nil {
+			return err
+		}
+		httpLn = ln
+	}
+	ln, err := z.listen(cfg.Addr)
+	if err != nil {
+		if httpLn != nil {
+			z.closeListeners(httpLn)
+		}
+		return err
+	}
+
+	// Only protocols with a registered handler are offered, so clients
+	// do not negotiate HTTP/2 against an HTTP/1.1 server.
+	s := z.newServer()
+	tlsConfig := &tls.Config{
+		GetCertificate: m.GetCertificate,
+		NextProtos:     append(z.protoNames(), "http/1.1", acme.ALPNProto),
+	}
+	z.configureClientAuth(tlsConfig)
+
+	servers := []*fasthttp.Server{s}
+	errc := make(chan error, 2)
+	if httpLn != nil {
+		hs := &fasthttp.Server{Handler: fasthttpadaptor.NewFastHTTPHandler(m.HTTPHandler(nil))}
+		z.trackServer(hs)
+		servers = append(servers, hs)
+		go func() { errc <- hs.Serve(httpLn) }()
+	}
+	go func() { errc <- s.Serve(tls.NewListener(ln, tlsConfig)) }()
+	err = <-errc
+	for _, srv := range servers {
+		srv.Shutdown()
+	}
+	if err != nil {
+		z.closeListeners(ln)
+		if httpLn != nil {
+			z.closeListeners(httpLn)
+		}
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package benchmarks
+
+import (
+	"testing"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/valyala/fasthttp"
+)
+
+// load registers routes on a new engine with a handler that writes
+// nothing, so benchmarks measure routing and context handling only.
+func load(routes []Route) *zeno.Zeno {
+	z := zeno.New()
+	h := func(c *zeno.Context) error { return nil }
+	for _, r := range routes {
+		z.Handle(r.Method, r.Pattern(), h)
+	}
+	return z
+}
+
+// request is a prepared request for serve.
+type request struct {
+	method string
+	uri    string
+}
+
+// requests turns routes into requests matching them.
+func requests(routes []Route) []request {
+	reqs := make([]request, len(routes))
+	for i, r := range routes {
+		reqs[i] = request{r.Method, r.RequestPath()}
+	}
+	return reqs
+}
+
+// serve runs reqs through z, reusing a single RequestCtx.
+func serve(z *zeno.Zeno, ctx *fasthttp.RequestCtx, reqs []request) {
+	for _, r := range reqs {
+		ctx.Request.Header.SetMethod(r.method)
+		ctx.Request.SetRequestURI(r.uri)
+		z.HandleRequest(ctx)
+		ctx.Response.Reset()
+	}
+}
+
+func benchmarkRoutes(b *testing.B, routes []Route, reqs []request) {
+	z := load(routes)
+	ctx := new(fasthttp.RequestCtx)
+	b.ReportAllocs()
+	for b.Loop() {
+		serve(z, ctx, reqs)
+	}
+}
+
+var (
+	gitHubStatic = []request{{"GET", "/user/repos"}}
+	gitHubParam  = []request{{"GET", "/repos/julienschmidt/httprouter/stargazers"}}
+	gplusStatic  = []request{{"GET", "/people"}}
+	gplusParam   = []request{{"GET", "/people/118051310819094153327/activities/public"}}
+	parseStatic  = []request{{"GET", "/1/users"}}
+	parseParam   = []request{{"GET", "/1/classes/go/123456789"}}
+)
+
+func BenchmarkGitHub_Static(b *testing.B) { benchmarkRoutes(b, GitHubAPI, gitHubStatic) }
+func BenchmarkGitHub_Param(b *testing.B)  { benchmarkRoutes(b, GitHubAPI, gitHubParam) }
+func BenchmarkGitHub_All(b *testing.B)    { benchmarkRoutes(b, GitHubAPI, requests(GitHubAPI)) }
+func BenchmarkGPlus_Static(b *testing.B)  { benchmarkRoutes(b, GPlusAPI, gplusStatic) }
+func BenchmarkGPlus_Param(b *testing.B)   { benchmarkRoutes(b, GPlusAPI, gplusParam) }
+func BenchmarkGPlus_All(b *testing.B)     { benchmarkRoutes(b, GPlusAPI, requests(GPlusAPI)) }
+func BenchmarkParse_Static(b *testing.B)  { benchmarkRoutes(b, ParseAPI, parseStatic) }
+func BenchmarkParse_Param(b *testing.B)   { benchmarkRoutes(b, ParseAPI, parseParam) }
+func BenchmarkParse_All(b *testing.B)     { benchmarkRoutes(b, ParseAPI, requests(ParseAPI)) }
+
+// TestRoutes checks that every route of the corpora is reachable.
+func TestRoutes(t *testing.T) {
+	for name, routes := range map[string][]Route{"GitHub": GitHubAPI, "GPlus": GPlusAPI, "Parse": ParseAPI} {
+		z := zeno.New()
+		for i, r := range routes {
+			z.Handle(r.Method, r.Pattern(), func(c *zeno.Context) error { return c.SendStatusCode(200 + i) })
+		}
+		ctx := new(fasthttp.RequestCtx)
+		for i, r := range routes {
+			ctx.Request.Header.SetMethod(r.Method)
+			ctx.Request.SetRequestURI(r.RequestPath())
+			z.HandleRequest(ctx)
+			if got := ctx.Response.StatusCode(); got != 200+i {
+				t.Errorf("%s: %s %s answered %d, want %d", name, r.Method, r.RequestPath(), got, 200+i)
+			}
+			ctx.Response.Reset()
+		}
+	}
+}
+
+// TestAllocations guards against routing regressions: serving a static
+// route must not allocate, and a parameterized one at most once for the
+// parameter values.
+func TestAllocations(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []Route
+		reqs   []request
+		max    float64
+	}{
+		{"GitHub/Static", GitHubAPI, gitHubStatic, 0},
+		{"GitHub/Param", GitHubAPI, gitHubParam, 1},
+		{"GPlus/Static", GPlusAPI, gplusStatic, 0},
+		{"GPlus/Param", GPlusAPI, gplusParam, 1},
+		{"Parse/Static", ParseAPI, parseStatic, 0},
+		{"Parse/Param", ParseAPI, parseParam, 1},
+	}
+	for _, tt := range tests {
+		z := load(tt.routes)
+		ctx := new(fasthttp.RequestCtx)
+		serve(z, ctx, tt.reqs) // warm up the context pool
+		if n := testing.AllocsPerRun(100, func() { serve(z, ctx, tt.reqs) }); n > tt.max {
+			t.Errorf("%s: %v allocations per request, want at most %v", tt.name, n, tt.max)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package zeno
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// valueTree is the intermediate representation used by struct binding.
+// Leaves are []string holding every value submitted for a key; nested
+// keys such as "filter[status]" produce nested valueTrees.
+type valueTree map[string]any
+
+// splitBracketKey splits a key in bracket notation into its path, e.g.
+// "filter[status]" → ["filter", "status"] and "ids[]" → ["ids"]. Keys
+// without brackets are returned as a single segment.
+func splitBracketKey(key string) []string {
+	i := strings.IndexByte(key, '[')
+	if i <= 0 || !strings.HasSuffix(key, "]") {
+		return []string{key}
+	}
+	path := []string{key[:i]}
+	for rest := key[i:]; rest != ""; {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return []string{key}
+		}
+		if seg := rest[1:end]; seg != "" {
+			path = append(path, seg)
+		}
+		rest = rest[end+1:]
+	}
+	return path
+}
+
+// add stores value under the bracket-notation key. When sep is not empty,
+// value is additionally split on it so "ids=1,2" yields two values.
+func (t valueTree) add(key, value, sep string) {
+	path := splitBracketKey(key)
+	node := t
+	for _, seg := range path[:len(path)-1] {
+		child, ok := node[seg].(valueTree)
+		if !ok {
+			child = valueTree{}
+			node[seg] = child
+		}
+		node = child
+	}
+	last := path[len(path)-1]
+	values, _ := node[last].([]string)
+	if sep != "" {
+		values = append(values, strings.Split(value, sep)...)
+	} else {
+		values = append(values, value)
+	}
+	node[last] = values
+}
+
+// simplify converts the tree into plain Go values: leaves with a single
+// value become a string, other leaves stay []string.
+func (t valueTree) simplify() map[string]any {
+	m := make(map[string]any, len(t))
+	for k, v := range t {
+		switch v := v.(type) {
+		case valueTree:
+			m[k] = v.simplify()
+		case []string:
+			if len(v) == 1 {
+				m[k] = v[0]
+			} else {
+				m[k] = v
+			}
+		}
+	}
+	return m
+}
+
+// fieldName returns the key used to bind struct field f, taken from the
+// given tag or defaulting to the field name. It reports false for fields
+// tagged "-".
+func fieldName(f reflect.StructField, tag string) (string, bool) {
+	name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+// bindTree decodes tree into out, which must be a non-nil pointer to a
+// struct or map. Struct fields are matched by tag (falling back to the
+// field name, case-insensitively); nested structs and maps are filled from
+// nested keys, slices from repeated values.
+func bindTree(out any, tree valueTree, tag string, conv converter) error {
+	return treeDecoder{tag: tag, conv: conv}.decode(out, tree)
+}
+
+// bindTaggedTree is bindTree restricted to struct fields carrying tag, so
+// that when a request is bound from several sources, one cannot fill the
+// untagged fields meant for another.
+func bindTaggedTree(out any, tree valueTree, tag string, conv converter) error {
+	return treeDecoder{tag: tag, conv: conv, tagged: true}.decode(out, tree)
+}
+
+// treeDecoder holds the settings shared by a single bindTree call.
+type treeDecoder struct {
+	tag  string
+	conv converter
+
+	// tagged restricts decoding to struct fields with the tag
+	tagged bool
+}
+
+// decode decodes tree into out, which must be a non-nil pointer.
+func (d treeDecoder) decode(out any, tree valueTree) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("binding destination must be a non-nil pointer")
+	}
+	return d.decodeNode(rv.Elem(), tree, "", d.conv.filters.global)
+}
+
+// decodeNode stores node (a valueTree or []string) into v. path is the
+// dotted key path used in error messages; filters are applied to every
+// value before it is converted.
+func (d treeDecoder) decodeNode(v reflect.Value, node any, path string, filters []InputFilter) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decodeNode(v.Elem(), node, path, filters)
+	}
+
+	switch n := node.(type) {
+	case valueTree:
+		switch v.Kind() {
+		case reflect.Struct:
+			return d.decodeStruct(v, n, path)
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return fmt.Errorf("%s: unsupported map key type %s", path, v.Type().Key())
+			}
+			if v.IsNil() {
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+			for k, child := range n {
+				ev := reflect.New(v.Type().Elem()).Elem()
+				if err := d.decodeNode(ev, child, joinPath(path, k), filters); err != nil {
+					return err
+				}
+				v.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), ev)
+			}
+			return nil
+		}
+		return fmt.Errorf("%s: cannot bind nested values to %s", path, v.Type())
+	case []string:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 && !d.conv.has(v.Type()) {
+			s := reflect.MakeSlice(v.Type(), len(n), len(n))
+			for i, raw := range n {
+				if err := setValue(s.Index(i), applyFilters(filters, raw), d.conv); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+			}
+			v.Set(s)
+			return nil
+		}
+		if len(n) == 0 {
+			return nil
+		}
+		if err := setValue(v, applyFilters(filters, n[0]), d.conv); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// decodeStruct fills the exported fields of struct v from tree.
+func (d treeDecoder) decodeStruct(v reflect.Value, tree valueTree, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get(d.tag) == "" {
+			if err := d.decodeStruct(v.Field(i), tree, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, ok := f.Tag.Lookup("file"); ok {
+			continue
+		}
+		if _, ok := f.Tag.Lookup(d.tag); d.tagged && !ok {
+			continue
+		}
+		name, ok := fieldName(f, d.tag)
+		if !ok {
+			continue
+		}
+		node, ok := tree[name]
+		if !ok {
+			for k, n := range tree {
+				if strings.EqualFold(k, name) {
+					node, ok = n, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		filters, err := d.conv.filters.forField(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", joinPath(path, name), err)
+		}
+		if err := d.decodeNode(v.Field(i), node, joinPath(path, name), filters); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinPath appends key to a dotted path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// QueryParserConfig configures query string parsing for
+// Context.QueryNested and Context.BindQuery.
+type QueryParserConfig struct {
+	// Separator, when set, splits every value on it, so that with ","
+	// the query "ids=1,2,3" is equivalent to "ids=1&ids=2&ids=3".
+	Separator string
+}
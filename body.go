@@ -0,0 +1,136 @@
+package zeno
+
+import (
+	"mime"
+	"reflect"
+	"strings"
+)
+
+// RawBody returns the complete request body as received. When
+// StreamRequestBody is enabled the stream is drained into memory first, so
+// later calls to PostBody, BodyReader and the Bind helpers still see the
+// full body. This makes it safe for middleware that verifies request
+// signatures (e.g. Stripe or GitHub webhooks) to read the body before the
+// handler binds it.
+//
+// The returned slice is only valid until the request completes.
+//
+// Example:
+//
+//	mac := hmac.New(sha256.New, secret)
+//	mac.Write(c.RawBody())
+//	if !hmac.Equal(mac.Sum(nil), sig) {
+//	    return zeno.ErrUnauthorized
+//	}
+//	return c.Next()
+func (c *Context) RawBody() []byte {
+	return c.PostBody()
+}
+
+// BodyParsed decodes the request body into out, choosing the decoder from
+// the Content-Type header (JSON, XML, YAML, TOML, CBOR, CSV, forms (see
+// BindForm) or a type registered with Zeno.RegisterDecoder; JSON when the
+// header is missing).
+// The decoded value is cached per destination type, so
+// middleware and handlers can bind the same body repeatedly while it is
+// decoded only once. Each call receives a shallow copy of the cached value,
+// so slices and maps inside it are shared between callers.
+//
+// It returns 415 Unsupported Media Type for other content types.
+//
+// Example:
+//
+//	var in CreateOrder
+//	if err := c.BodyParsed(&in); err != nil {
+//	    return err
+//	}
+func (c *Context) BodyParsed(out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return NewHTTPError(StatusInternalServerError, "BodyParsed: destination must be a non-nil pointer")
+	}
+	t := rv.Elem().Type()
+	if cached, ok := c.bodyCache[t]; ok {
+		rv.Elem().Set(cached)
+		return nil
+	}
+	if err := c.bindBody(out); err != nil {
+		return err
+	}
+	if c.bodyCache == nil {
+		c.bodyCache = make(map[reflect.Type]reflect.Value)
+	}
+	cached := reflect.New(t).Elem()
+	cached.Set(rv.Elem())
+	c.bodyCache[t] = cached
+	return nil
+}
+
+// bindBody dispatches to the decoder registered for the request
+// Content-Type or else to the matching Bind helper.
+func (c *Context) bindBody(out any) error {
+	ct := c.GetHeader(HeaderContentType)
+	if ct == "" {
+		return c.BindJSON(out)
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return NewHTTPError(StatusBadRequest, "Invalid Content-Type: "+err.Error())
+	}
+	if decode, ok := c.zeno.decoders[mt]; ok {
+		return c.bindWith(decode, mt, out)
+	}
+	switch {
+	case isJSONMediaType(mt):
+		return c.BindJSON(out)
+	case mt == "application/xml" || mt == "text/xml" || strings.HasSuffix(mt, "+xml"):
+		return c.BindXML(out)
+	case mt == "application/yaml" || mt == "application/x-yaml" || mt == "text/yaml" || mt == "text/x-yaml":
+		return c.BindYAML(out)
+	case mt == "application/toml" || mt == "text/x-toml":
+		return c.BindTOML(out)
+	case mt == "application/cbor":
+		return c.BindCBOR(out)
+	case mt == "text/csv":
+		return c.BindCSV(out)
+	case mt == "application/x-www-form-urlencoded" || mt == "multipart/form-data":
+		return c.BindForm(out)
+	}
+	return NewHTTPError(StatusUnsupportedMediaType, "Unsupported Content-Type: "+mt)
+}
+
+// bindWith decodes the request body, of media type mt, into out with
+// decode.
+func (c *Context) bindWith(decode DecoderFunc, mt string, out any) error {
+	body := c.PostBody()
+	if len(body) == 0 {
+		return NewHTTPError(StatusBadRequest, "Request body is empty")
+	}
+	if err := c.checkCharset(); err != nil {
+		return err
+	}
+	if err := decode(body, out); err != nil {
+		return NewHTTPError(StatusBadRequest, "Invalid "+mt+" body: "+err.Error())
+	}
+	return nil
+}
+
+// isJSONMediaType reports whether mt is application/json or a +json type.
+func isJSONMediaType(mt string) bool {
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// JSONBindingConfig controls how BindJSON validates request bodies. The
+// engine-wide settings live in Zeno.JSONBinding; a config passed to
+// BindJSON replaces them for that call.
+type JSONBindingConfig struct {
+	// RequireContentType rejects requests whose Content-Type is missing
+	// or not JSON (application/json or a +json type) with 415
+	// Unsupported Media Type.
+	RequireContentType bool
+
+	// DisallowUnknownFields rejects bodies containing object keys that
+	// match no field of the destination struct with 400 Bad Request. The
+	// body is decoded with Zeno.JsonStrictDecoder in this mode.
+	DisallowUnknownFields bool
+}
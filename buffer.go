@@ -0,0 +1,48 @@
+package zeno
+
+// ResponseBody returns the response body written so far. A body stream
+// set by SendStream, SendFile or similar is read into memory first, so the
+// result is complete but may be large.
+//
+// Together with SetResponseBody it lets middleware post-process responses
+// after calling Next, e.g. to minify, sign or cache them. Enable
+// Zeno.BufferResponses when doing so, so the body is owned by the response
+// rather than shared with the handler.
+//
+// Example:
+//
+//	func Minify(c *zeno.Context) error {
+//	    if err := c.Next(); err != nil {
+//	        return err
+//	    }
+//	    return c.SetResponseBody(minify(c.ResponseBody()))
+//	}
+func (c *Context) ResponseBody() []byte {
+	return c.ctx.Response.Body()
+}
+
+// SetResponseBody replaces the response body with a copy of b.
+func (c *Context) SetResponseBody(b []byte) error {
+	c.mustBeWritable()
+	c.ctx.Response.SetBody(b)
+	return nil
+}
+
+// saveHandlerHeader records the response header before the handler runs,
+// for discardResponse.
+func (c *Context) saveHandlerHeader() {
+	c.ctx.Response.Header.CopyTo(&c.handlerHeader)
+	c.handlerHeaderSaved = true
+}
+
+// discardResponse drops the status, headers, cookies and body written by a
+// chain that went on to fail, so the error handler starts from a clean
+// response. Headers middleware set before the handler ran are kept.
+func (c *Context) discardResponse() {
+	if c.handlerHeaderSaved {
+		c.handlerHeader.CopyTo(&c.ctx.Response.Header)
+	}
+	c.ctx.Response.ResetBody()
+	c.ctx.Response.SetStatusCode(StatusOK)
+	c.ctx.Response.Header.Del(HeaderContentType)
+}
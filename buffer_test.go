@@ -0,0 +1,50 @@
+package zeno
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestZeno_BufferResponses(t *testing.T) {
+	z := New()
+	z.BufferResponses = true
+	z.Use(func(c *Context) error {
+		c.SetHeader("X-Middleware", "1")
+		if err := c.Next(); err != nil {
+			return err
+		}
+		return c.SetResponseBody(bytes.ToUpper(c.ResponseBody()))
+	})
+	buf := []byte("hello")
+	z.Get("/ok", func(c *Context) error {
+		err := c.SendBytes(buf)
+		buf[0] = 'j'
+		return err
+	})
+	z.Get("/fail", func(c *Context) error {
+		c.SetHeader("X-Handler", "1")
+		ck := fasthttp.AcquireCookie()
+		defer fasthttp.ReleaseCookie(ck)
+		ck.SetKey("session")
+		ck.SetValue("half")
+		c.RequestCtx().Response.Header.SetCookie(ck)
+		c.Status(StatusCreated)
+		_ = c.SendJSON(Map{"partial": true})
+		return errors.New("boom")
+	})
+
+	ctx := performRequest(z, "GET", "/ok", nil, nil)
+	assert.Equal(t, "HELLO", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/fail", nil, nil)
+	assert.Equal(t, StatusInternalServerError, ctx.Response.StatusCode())
+	assert.Equal(t, "Internal Server Error", string(ctx.Response.Body()))
+	assert.NotContains(t, string(ctx.Response.Header.ContentType()), "json")
+	assert.Equal(t, "1", string(ctx.Response.Header.Peek("X-Middleware")))
+	assert.Empty(t, ctx.Response.Header.Peek("X-Handler"))
+	assert.Empty(t, ctx.Response.Header.PeekCookie("session"))
+}
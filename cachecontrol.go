@@ -0,0 +1,53 @@
+package zeno
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheControl describes the directives of a Cache-Control response
+// header. Zero values are omitted; durations are rounded down to seconds.
+type CacheControl struct {
+	Public               bool
+	Private              bool
+	NoCache              bool
+	NoStore              bool
+	NoTransform          bool
+	MustRevalidate       bool
+	ProxyRevalidate      bool
+	Immutable            bool
+	MaxAge               time.Duration
+	SMaxAge              time.Duration
+	StaleWhileRevalidate time.Duration
+	StaleIfError         time.Duration
+}
+
+// String renders the directives in header form, e.g.
+// "public, max-age=60, stale-while-revalidate=30".
+func (cc CacheControl) String() string {
+	var parts []string
+	flag := func(on bool, name string) {
+		if on {
+			parts = append(parts, name)
+		}
+	}
+	seconds := func(d time.Duration, name string) {
+		if d > 0 {
+			parts = append(parts, name+"="+strconv.FormatInt(int64(d/time.Second), 10))
+		}
+	}
+	flag(cc.Public, "public")
+	flag(cc.Private, "private")
+	flag(cc.NoCache, "no-cache")
+	flag(cc.NoStore, "no-store")
+	flag(cc.NoTransform, "no-transform")
+	flag(cc.MustRevalidate, "must-revalidate")
+	flag(cc.ProxyRevalidate, "proxy-revalidate")
+	flag(cc.Immutable, "immutable")
+	seconds(cc.MaxAge, "max-age")
+	seconds(cc.SMaxAge, "s-maxage")
+	seconds(cc.StaleWhileRevalidate, "stale-while-revalidate")
+	seconds(cc.StaleIfError, "stale-if-error")
+	return strings.Join(parts, ", ")
+}
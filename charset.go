@@ -0,0 +1,68 @@
+package zeno
+
+import (
+	"mime"
+	"strings"
+)
+
+// textualMediaType reports whether documents of media type mt are text,
+// and so carry a charset parameter when sent.
+func textualMediaType(mt string) bool {
+	switch {
+	case strings.HasPrefix(mt, "text/"),
+		mt == "application/javascript",
+		mt == "application/json", strings.HasSuffix(mt, "+json"),
+		mt == "application/xml", strings.HasSuffix(mt, "+xml"),
+		mt == "application/yaml", mt == "application/toml":
+		return true
+	}
+	return false
+}
+
+// setMediaType sets the response Content-Type to the first element of
+// ctype, if given, and otherwise to mediaType followed by a charset
+// parameter for Zeno.DefaultCharset when mediaType is textual.
+func (c *Context) setMediaType(mediaType string, ctype []string) {
+	if len(ctype) > 0 {
+		c.SetContentType(ctype[0])
+		return
+	}
+	if cs := c.zeno.DefaultCharset; cs != "" && textualMediaType(mediaType) {
+		mediaType += "; charset=" + cs
+	}
+	c.SetContentType(mediaType)
+}
+
+// ContentType returns the media type of the request body, lower-cased,
+// and its parameters, as parsed by mime.ParseMediaType. Both are empty if
+// the request has no Content-Type header; the parameters are nil if the
+// header is malformed.
+//
+// Example:
+//
+//	mt, params := c.ContentType() // "application/json", {"charset": "utf-8"}
+func (c *Context) ContentType() (string, map[string]string) {
+	ct := c.GetHeader(HeaderContentType)
+	if ct == "" {
+		return "", nil
+	}
+	mt, params, err := mime.ParseMediaType(ct)
+	if err != nil {
+		mt, _, _ = strings.Cut(ct, ";")
+		return strings.ToLower(strings.TrimSpace(mt)), nil
+	}
+	return mt, params
+}
+
+// checkCharset returns a 415 Unsupported Media Type error if the request
+// body declares a charset other than UTF-8 (or its subset US-ASCII), which
+// is all the Bind helpers decode.
+func (c *Context) checkCharset() error {
+	_, params := c.ContentType()
+	switch cs := strings.ToLower(params["charset"]); cs {
+	case "", "utf-8", "utf8", "us-ascii":
+		return nil
+	default:
+		return NewHTTPError(StatusUnsupportedMediaType, "Unsupported charset: "+cs)
+	}
+}
@@ -0,0 +1,296 @@
+package zeno
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerConfig configures a Breaker and the CircuitBreaker
+// middleware.
+type CircuitBreakerConfig struct {
+	// Threshold is the number of consecutive failures that opens a
+	// circuit. Defaults to 5.
+	Threshold int
+
+	// Timeout is how long an open circuit rejects calls before it lets
+	// probe requests through (half-open). Defaults to 30 seconds.
+	Timeout time.Duration
+
+	// HalfOpenRequests is the number of probe calls allowed at once while
+	// half-open. A successful probe closes the circuit, a failed one opens
+	// it again. Defaults to 1.
+	HalfOpenRequests int
+
+	// Key selects the circuit a request belongs to, e.g. the upstream host
+	// a handler calls. By default all requests passing through the
+	// middleware share one circuit, so attaching it to a route or group
+	// tracks failures per route.
+	Key func(c *Context) string
+
+	// IsFailure decides whether a handled request counts as a failure. By
+	// default errors that are not HTTPErrors, HTTPErrors with a 5xx status
+	// and responses with a 5xx status are failures.
+	IsFailure func(c *Context, err error) bool
+
+	// Skipper, when set, lets requests for which it returns true bypass
+	// the middleware returned by Handler. Skipped requests are neither
+	// rejected nor recorded.
+	Skipper Skipper
+}
+
+// CircuitOpenError is returned while a circuit is open. It is an HTTPError
+// with status 503 Service Unavailable.
+type CircuitOpenError struct {
+	// Key identifies the open circuit.
+	Key string
+
+	// RetryAfter is the time left until the circuit accepts a probe.
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *CircuitOpenError) Error() string {
+	if e.Key == "" {
+		return "circuit breaker is open"
+	}
+	return fmt.Sprintf("circuit breaker %q is open", e.Key)
+}
+
+// StatusCode implements HTTPError.
+func (e *CircuitOpenError) StatusCode() int { return StatusServiceUnavailable }
+
+// circuitState is the state of a single circuit.
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuit tracks the failures of one key.
+type circuit struct {
+	key      string
+	state    circuitState
+	failures int
+	openedAt time.Time
+	probes   int
+
+	// active is the number of calls allowed and not yet recorded, and
+	// lastUsed the time the last one was allowed.
+	active   int
+	lastUsed time.Time
+
+	// generation is advanced on every state change, so that outcomes of
+	// calls allowed in an earlier state are not recorded in the current one
+	generation uint64
+}
+
+// setState moves cb to state, starting a new generation.
+func (cb *circuit) setState(state circuitState) {
+	cb.state = state
+	cb.generation++
+}
+
+// Breaker is a circuit breaker that stops calling a failing dependency for
+// a while, giving it time to recover, instead of piling up slow or failing
+// requests. Circuits are tracked independently per key.
+//
+// A Breaker can be used directly, e.g. around outgoing calls in a proxy,
+// or as middleware via Handler.
+//
+// Only circuits with recent failures are kept: a closed circuit is
+// forgotten once its calls succeed, or when it has been idle for Timeout,
+// so keys such as client addresses do not accumulate.
+type Breaker struct {
+	config    CircuitBreakerConfig
+	now       func() time.Time
+	mu        sync.Mutex
+	circuits  map[string]*circuit
+	lastSweep time.Time
+}
+
+// NewBreaker creates a Breaker with the given configuration, applying the
+// defaults documented on CircuitBreakerConfig.
+//
+// Example:
+//
+//	cb := zeno.NewBreaker(zeno.CircuitBreakerConfig{Threshold: 3})
+//	err := cb.Execute("payments", func() error {
+//	    return callPayments()
+//	})
+func NewBreaker(config CircuitBreakerConfig) *Breaker {
+	if config.Threshold <= 0 {
+		config.Threshold = 5
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = defaultIsFailure
+	}
+	return &Breaker{
+		config:   config,
+		now:      time.Now,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// defaultIsFailure treats server-side errors as failures.
+func defaultIsFailure(c *Context, err error) bool {
+	if err != nil {
+		var he HTTPError
+		if errors.As(err, &he) {
+			return he.StatusCode() >= 500
+		}
+		return true
+	}
+	return c.ctx.Response.StatusCode() >= 500
+}
+
+// Allow reports whether a call for key may proceed. On success it returns
+// a done function that must be called exactly once with the outcome of the
+// call. While the circuit is open it returns a *CircuitOpenError.
+func (b *Breaker) Allow(key string) (done func(failed bool), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if now.Sub(b.lastSweep) >= b.config.Timeout {
+		b.sweep(now)
+	}
+	cb := b.circuits[key]
+	if cb == nil {
+		cb = &circuit{key: key}
+		b.circuits[key] = cb
+	}
+	if cb.state == circuitOpen {
+		if wait := cb.openedAt.Add(b.config.Timeout).Sub(now); wait > 0 {
+			return nil, &CircuitOpenError{Key: key, RetryAfter: wait}
+		}
+		cb.setState(circuitHalfOpen)
+		cb.probes = 0
+	}
+	if cb.state == circuitHalfOpen {
+		if cb.probes >= b.config.HalfOpenRequests {
+			return nil, &CircuitOpenError{Key: key, RetryAfter: time.Second}
+		}
+		cb.probes++
+	}
+	cb.active++
+	cb.lastUsed = now
+	generation := cb.generation
+	return func(failed bool) { b.record(cb, generation, failed) }, nil
+}
+
+// sweep forgets the closed circuits without calls in flight that have not
+// been used for Timeout. b.mu must be held.
+func (b *Breaker) sweep(now time.Time) {
+	b.lastSweep = now
+	for key, cb := range b.circuits {
+		if cb.state == circuitClosed && cb.active == 0 && now.Sub(cb.lastUsed) >= b.config.Timeout {
+			delete(b.circuits, key)
+		}
+	}
+}
+
+// record updates cb with the outcome of a call allowed in generation. The
+// outcomes of calls allowed before the last state change are ignored. A
+// circuit left closed without failures and calls in flight is forgotten.
+func (b *Breaker) record(cb *circuit, generation uint64, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb.active--
+	defer func() {
+		if cb.state == circuitClosed && cb.failures == 0 && cb.active == 0 && b.circuits[cb.key] == cb {
+			delete(b.circuits, cb.key)
+		}
+	}()
+	if generation != cb.generation {
+		return
+	}
+	switch {
+	case cb.state == circuitHalfOpen && failed:
+		cb.setState(circuitOpen)
+		cb.openedAt = b.now()
+	case cb.state == circuitHalfOpen:
+		cb.setState(circuitClosed)
+		cb.failures = 0
+	case failed:
+		cb.failures++
+		if cb.failures >= b.config.Threshold {
+			cb.setState(circuitOpen)
+			cb.openedAt = b.now()
+		}
+	default:
+		cb.failures = 0
+	}
+}
+
+// Execute runs fn if the circuit for key allows it, recording a non-nil
+// error or a panic as a failure. It returns a *CircuitOpenError without
+// calling fn while the circuit is open.
+func (b *Breaker) Execute(key string, fn func() error) error {
+	done, err := b.Allow(key)
+	if err != nil {
+		return err
+	}
+	failed := true
+	defer func() { done(failed) }()
+	err = fn()
+	failed = err != nil
+	return err
+}
+
+// Handler returns a middleware that guards the rest of the chain with the
+// breaker. While a circuit is open requests are rejected with 503 Service
+// Unavailable and a Retry-After header. A panic in the rest of the chain
+// is recorded as a failure before it propagates.
+func (b *Breaker) Handler() Handler {
+	return func(c *Context) error {
+		if b.config.Skipper != nil && b.config.Skipper(c) {
+			return c.Next()
+		}
+		key := ""
+		if b.config.Key != nil {
+			key = b.config.Key(c)
+		}
+		done, err := b.Allow(key)
+		if err != nil {
+			var open *CircuitOpenError
+			if errors.As(err, &open) {
+				secs := int(math.Ceil(open.RetryAfter.Seconds()))
+				c.SetHeader(HeaderRetryAfter, strconv.Itoa(secs))
+			}
+			return err
+		}
+		failed := true
+		defer func() { done(failed) }()
+		err = c.Next()
+		failed = b.config.IsFailure(c, err)
+		return err
+	}
+}
+
+// CircuitBreaker returns a middleware that stops passing requests to the
+// rest of the chain after repeated failures, answering 503 with a
+// Retry-After header until the timeout elapses and a probe succeeds. See
+// Breaker for using the same logic outside of middleware.
+//
+// Example:
+//
+//	api := app.Group("/upstream", zeno.CircuitBreaker(zeno.CircuitBreakerConfig{
+//	    Threshold: 5,
+//	    Timeout:   10 * time.Second,
+//	}))
+func CircuitBreaker(config CircuitBreakerConfig) Handler {
+	return NewBreaker(config).Handler()
+}
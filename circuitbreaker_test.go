@@ -0,0 +1,113 @@
+package zeno
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_StateTransitions(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewBreaker(CircuitBreakerConfig{Threshold: 2, Timeout: 10 * time.Second})
+	b.now = func() time.Time { return now }
+	fail := func() error { return errors.New("boom") }
+	ok := func() error { return nil }
+
+	assert.Error(t, b.Execute("up", fail))
+	assert.Error(t, b.Execute("up", fail))
+
+	var open *CircuitOpenError
+	assert.ErrorAs(t, b.Execute("up", ok), &open)
+	assert.Equal(t, 10*time.Second, open.RetryAfter)
+	assert.NoError(t, b.Execute("other", ok), "circuits are tracked per key")
+
+	// Half-open: only one probe at a time.
+	now = now.Add(10 * time.Second)
+	done, err := b.Allow("up")
+	assert.NoError(t, err)
+	_, err = b.Allow("up")
+	assert.ErrorAs(t, err, &open)
+
+	// A failed probe re-opens the circuit, a successful one closes it.
+	done(true)
+	assert.ErrorAs(t, b.Execute("up", ok), &open)
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, b.Execute("up", ok))
+	assert.NoError(t, b.Execute("up", ok))
+}
+
+func TestBreaker_PanicReleasesProbe(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewBreaker(CircuitBreakerConfig{Threshold: 1, Timeout: 10 * time.Second})
+	b.now = func() time.Time { return now }
+	boom := func() error { panic("boom") }
+
+	assert.Panics(t, func() { b.Execute("up", boom) })
+	var open *CircuitOpenError
+	assert.ErrorAs(t, b.Execute("up", nil), &open, "a panic counts as a failure")
+
+	// A panicking probe re-opens the circuit instead of holding its slot.
+	now = now.Add(10 * time.Second)
+	assert.Panics(t, func() { b.Execute("up", boom) })
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, b.Execute("up", func() error { return nil }))
+}
+
+func TestBreaker_StaleOutcomes(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewBreaker(CircuitBreakerConfig{Threshold: 1, Timeout: 10 * time.Second})
+	b.now = func() time.Time { return now }
+
+	slow, err := b.Allow("up")
+	assert.NoError(t, err)
+	assert.Error(t, b.Execute("up", func() error { return errors.New("boom") }))
+
+	// The circuit is half-open now; the slow call from the closed state
+	// neither re-opens nor closes it, and the probe slot stays taken.
+	now = now.Add(10 * time.Second)
+	probe, err := b.Allow("up")
+	assert.NoError(t, err)
+	slow(true)
+	var open *CircuitOpenError
+	_, err = b.Allow("up")
+	assert.ErrorAs(t, err, &open)
+	probe(false)
+	assert.NoError(t, b.Execute("up", func() error { return nil }))
+}
+
+func TestBreaker_ForgetsIdleCircuits(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewBreaker(CircuitBreakerConfig{Threshold: 2, Timeout: 10 * time.Second})
+	b.now = func() time.Time { return now }
+	for _, key := range []string{"a", "b", "c"} {
+		assert.NoError(t, b.Execute(key, func() error { return nil }))
+	}
+	assert.Empty(t, b.circuits, "healthy circuits are kept")
+
+	done, err := b.Allow("slow")
+	assert.NoError(t, err)
+	assert.Error(t, b.Execute("flaky", func() error { return errors.New("boom") }))
+	assert.Len(t, b.circuits, 2)
+
+	// Idle closed circuits are dropped after Timeout, in-flight ones kept.
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, b.Execute("d", func() error { return nil }))
+	assert.Len(t, b.circuits, 1)
+	done(false)
+	assert.Empty(t, b.circuits)
+}
+
+func TestCircuitBreaker_Middleware(t *testing.T) {
+	c, ctx := newTestContext("GET", "/", nil, nil)
+	mw := CircuitBreaker(CircuitBreakerConfig{Threshold: 1, Timeout: 5 * time.Second})
+	c.handlers = []Handler{mw, func(c *Context) error { return NewHTTPError(StatusBadGateway) }}
+
+	assert.Error(t, c.Next())
+
+	c.index = -1
+	err := c.Next()
+	assert.Equal(t, StatusServiceUnavailable, err.(HTTPError).StatusCode())
+	assert.Equal(t, "5", string(ctx.Response.Header.Peek(HeaderRetryAfter)))
+}
@@ -0,0 +1,449 @@
+// Package client is a fluent HTTP client for services built on zeno that
+// call other services. It wraps fasthttp.Client and adds per-request
+// timeouts, retries with exponential backoff, connection pool settings and
+// propagation of tracing headers from an incoming request, while encoding
+// and decoding bodies with the same codecs as a zeno engine:
+//
+//	var user User
+//	err := client.Get("https://users.internal/users/42").
+//	    Query("expand", "teams").
+//	    JSON(&user)
+//
+// Responses with a status of 400 or above are reported as *StatusError.
+package client
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/valyala/fasthttp"
+)
+
+// Config configures a Client. The zero value is usable; New fills in the
+// defaults documented on each field.
+type Config struct {
+	// BaseURL is prepended to request URLs that are not absolute.
+	BaseURL string
+
+	// Timeout bounds each attempt of a request, including reading the
+	// response. Defaults to 30 seconds.
+	Timeout time.Duration
+
+	// Retries is the number of additional attempts made for idempotent
+	// requests (GET, HEAD, OPTIONS, PUT and DELETE) that fail with a
+	// connection error, 429 Too Many Requests, 502 Bad Gateway, 503
+	// Service Unavailable or 504 Gateway Timeout. Defaults to 0.
+	Retries int
+
+	// RetryBackoff is the wait before the first retry; it doubles with
+	// every further retry up to MaxRetryBackoff. Defaults to 100ms.
+	RetryBackoff time.Duration
+
+	// MaxRetryBackoff caps the wait between retries. Defaults to 5s.
+	MaxRetryBackoff time.Duration
+
+	// MaxConnsPerHost limits the connections kept open to each host.
+	// Defaults to fasthttp's default of 512.
+	MaxConnsPerHost int
+
+	// MaxIdleConnDuration closes pooled connections idle for longer.
+	// Defaults to fasthttp's default of 10 seconds.
+	MaxIdleConnDuration time.Duration
+
+	// Codecs supplies the encoders and decoders used for request and
+	// response bodies, including those registered with RegisterEncoder
+	// and RegisterDecoder. Defaults to the codecs of zeno.New().
+	Codecs *zeno.Zeno
+
+	// PropagateHeaders lists the headers copied from an incoming request
+	// by Request.Propagate. Defaults to the W3C Trace Context headers
+	// (traceparent, tracestate) and X-Request-ID.
+	PropagateHeaders []string
+
+	// Dial overrides how connections are established, e.g. to dial an
+	// in-memory listener in tests.
+	Dial fasthttp.DialFunc
+}
+
+// Client sends HTTP requests. It is safe for concurrent use; create one per
+// remote service and reuse it so connections are pooled.
+type Client struct {
+	config Config
+	http   *fasthttp.Client
+}
+
+// Default is the Client used by the package level request functions.
+var Default = New()
+
+// defaultCodecs is the engine supplying the codecs of clients without
+// Config.Codecs, built on first use so that importing the package does not
+// build an engine.
+var defaultCodecs = sync.OnceValue(func() *zeno.Zeno { return zeno.New() })
+
+// codecs returns the engine supplying the client's encoders and decoders.
+func (c *Client) codecs() *zeno.Zeno {
+	if c.config.Codecs != nil {
+		return c.config.Codecs
+	}
+	return defaultCodecs()
+}
+
+// New returns a Client configured by config, if given.
+//
+// Example:
+//
+//	users := client.New(client.Config{
+//	    BaseURL: "https://users.internal",
+//	    Timeout: 2 * time.Second,
+//	    Retries: 2,
+//	})
+func New(config ...Config) *Client {
+	var cfg Config
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 100 * time.Millisecond
+	}
+	if cfg.MaxRetryBackoff <= 0 {
+		cfg.MaxRetryBackoff = 5 * time.Second
+	}
+	if cfg.PropagateHeaders == nil {
+		cfg.PropagateHeaders = []string{"traceparent", "tracestate", zeno.HeaderXRequestID}
+	}
+	return &Client{
+		config: cfg,
+		http: &fasthttp.Client{
+			MaxConnsPerHost:     cfg.MaxConnsPerHost,
+			MaxIdleConnDuration: cfg.MaxIdleConnDuration,
+			Dial:                cfg.Dial,
+		},
+	}
+}
+
+// NewRequest starts building a request with the given method and URL.
+func (c *Client) NewRequest(method, url string) *Request {
+	return &Request{client: c, method: method, url: url}
+}
+
+// Get starts building a GET request.
+func (c *Client) Get(url string) *Request { return c.NewRequest(zeno.MethodGet, url) }
+
+// Head starts building a HEAD request.
+func (c *Client) Head(url string) *Request { return c.NewRequest(zeno.MethodHead, url) }
+
+// Post starts building a POST request.
+func (c *Client) Post(url string) *Request { return c.NewRequest(zeno.MethodPost, url) }
+
+// Put starts building a PUT request.
+func (c *Client) Put(url string) *Request { return c.NewRequest(zeno.MethodPut, url) }
+
+// Patch starts building a PATCH request.
+func (c *Client) Patch(url string) *Request { return c.NewRequest(zeno.MethodPatch, url) }
+
+// Delete starts building a DELETE request.
+func (c *Client) Delete(url string) *Request { return c.NewRequest(zeno.MethodDelete, url) }
+
+// Get starts building a GET request sent by Default.
+func Get(url string) *Request { return Default.Get(url) }
+
+// Post starts building a POST request sent by Default.
+func Post(url string) *Request { return Default.Post(url) }
+
+// Put starts building a PUT request sent by Default.
+func Put(url string) *Request { return Default.Put(url) }
+
+// Patch starts building a PATCH request sent by Default.
+func Patch(url string) *Request { return Default.Patch(url) }
+
+// Delete starts building a DELETE request sent by Default.
+func Delete(url string) *Request { return Default.Delete(url) }
+
+// Request is a request being built. Its methods return the Request so
+// calls can be chained; errors, e.g. from encoding the body, are reported
+// when the request is sent.
+type Request struct {
+	client      *Client
+	method      string
+	url         string
+	query       url.Values
+	header      http.Header
+	body        []byte
+	contentType string
+	timeout     time.Duration
+	err         error
+}
+
+// Query adds a query string parameter.
+func (r *Request) Query(key, value string) *Request {
+	if r.query == nil {
+		r.query = url.Values{}
+	}
+	r.query.Add(key, value)
+	return r
+}
+
+// Header sets a request header.
+func (r *Request) Header(key, value string) *Request {
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+	r.header.Set(key, value)
+	return r
+}
+
+// Timeout overrides Config.Timeout for this request.
+func (r *Request) Timeout(d time.Duration) *Request {
+	r.timeout = d
+	return r
+}
+
+// Propagate copies the Config.PropagateHeaders present on the incoming
+// request c, so traces and request IDs follow the call to the next
+// service.
+//
+// Example:
+//
+//	app.Get("/orders/{id}", func(c *zeno.Context) error {
+//	    var user User
+//	    err := users.Get("/users/" + c.Param("id")).Propagate(c).JSON(&user)
+//	    ...
+//	})
+func (r *Request) Propagate(c *zeno.Context) *Request {
+	for _, h := range r.client.config.PropagateHeaders {
+		if v := c.GetHeader(h); v != "" {
+			r.Header(h, v)
+		}
+	}
+	return r
+}
+
+// Body encodes v with the codec for mediaType and sends it as the request
+// body.
+func (r *Request) Body(mediaType string, v any) *Request {
+	encode := r.client.codecs().Encoder(mediaType)
+	if encode == nil {
+		r.err = fmt.Errorf("client: no encoder for %s", mediaType)
+		return r
+	}
+	b, err := encode(v)
+	if err != nil {
+		r.err = fmt.Errorf("client: encoding %s body: %w", mediaType, err)
+		return r
+	}
+	return r.RawBody(mediaType, b)
+}
+
+// JSONBody encodes v as the JSON request body.
+func (r *Request) JSONBody(v any) *Request {
+	return r.Body("application/json", v)
+}
+
+// RawBody sends b as the request body with the given Content-Type.
+func (r *Request) RawBody(contentType string, b []byte) *Request {
+	r.contentType, r.body = contentType, b
+	return r
+}
+
+// Do sends the request, retrying as configured, and returns the response.
+// Unlike JSON and Decode it does not treat error statuses as errors.
+func (r *Request) Do() (*Response, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	cfg := r.client.config
+	timeout := cfg.Timeout
+	if r.timeout > 0 {
+		timeout = r.timeout
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	if err := r.build(req); err != nil {
+		return nil, err
+	}
+
+	attempts := 1
+	if idempotent(r.method) {
+		attempts += cfg.Retries
+	}
+	backoff := cfg.RetryBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		resp.Reset()
+		err = r.client.http.DoTimeout(req, resp, timeout)
+		if attempt == attempts || !retryable(resp.StatusCode(), err) {
+			break
+		}
+		time.Sleep(backoff)
+		backoff = min(2*backoff, cfg.MaxRetryBackoff)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("client: %s %s: %w", r.method, r.url, err)
+	}
+	return newResponse(resp, r.client.codecs()), nil
+}
+
+// build fills req from r.
+func (r *Request) build(req *fasthttp.Request) error {
+	u := r.url
+	if base := r.client.config.BaseURL; base != "" && !strings.Contains(u, "://") {
+		u = strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(u, "/")
+	}
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(u, "?") {
+			sep = "&"
+		}
+		u += sep + r.query.Encode()
+	}
+	if _, err := url.Parse(u); err != nil {
+		return fmt.Errorf("client: %w", err)
+	}
+	req.SetRequestURI(u)
+	req.Header.SetMethod(r.method)
+	for k, values := range r.header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	if r.body != nil {
+		req.Header.SetContentType(r.contentType)
+		req.SetBody(r.body)
+	}
+	return nil
+}
+
+// JSON sends the request and decodes the JSON response body into out. See
+// Decode.
+func (r *Request) JSON(out any) error {
+	resp, err := r.Do()
+	if err != nil {
+		return err
+	}
+	if err := resp.err(); err != nil {
+		return err
+	}
+	return resp.decode("application/json", out)
+}
+
+// Decode sends the request and decodes the response body into out with
+// the codec for its Content-Type. A response with status 400 or above is
+// returned as a *StatusError instead, and an empty body leaves out
+// untouched.
+func (r *Request) Decode(out any) error {
+	resp, err := r.Do()
+	if err != nil {
+		return err
+	}
+	return resp.Decode(out)
+}
+
+// idempotent reports whether requests with method may be retried safely.
+func idempotent(method string) bool {
+	switch method {
+	case zeno.MethodGet, zeno.MethodHead, zeno.MethodOptions, zeno.MethodPut, zeno.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryable reports whether an attempt that ended with status or err is
+// worth repeating.
+func retryable(status int, err error) bool {
+	if err != nil {
+		return !errors.Is(err, fasthttp.ErrBodyTooLarge)
+	}
+	switch status {
+	case zeno.StatusTooManyRequests, zeno.StatusBadGateway, zeno.StatusServiceUnavailable, zeno.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Response is a received response. Its fields are copies and remain valid
+// after the request completes.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	codecs *zeno.Zeno
+}
+
+// newResponse copies resp into a Response.
+func newResponse(resp *fasthttp.Response, codecs *zeno.Zeno) *Response {
+	r := &Response{
+		StatusCode: resp.StatusCode(),
+		Header:     http.Header{},
+		Body:       append([]byte(nil), resp.Body()...),
+		codecs:     codecs,
+	}
+	resp.Header.VisitAll(func(key, value []byte) {
+		r.Header.Add(string(key), string(value))
+	})
+	return r
+}
+
+// Decode decodes the body into out with the codec for the response
+// Content-Type, or returns a *StatusError if the status is 400 or above.
+// An empty body, such as that of a 204 response, leaves out untouched.
+func (r *Response) Decode(out any) error {
+	if err := r.err(); err != nil {
+		return err
+	}
+	if len(r.Body) == 0 {
+		return nil
+	}
+	mt, _, err := mime.ParseMediaType(r.Header.Get(zeno.HeaderContentType))
+	if err != nil {
+		return fmt.Errorf("client: invalid response Content-Type: %w", err)
+	}
+	return r.decode(mt, out)
+}
+
+// err returns a *StatusError for error statuses.
+func (r *Response) err() error {
+	if r.StatusCode >= 400 {
+		return &StatusError{StatusCode: r.StatusCode, Body: r.Body}
+	}
+	return nil
+}
+
+// decode decodes the body into out with the codec for mediaType.
+func (r *Response) decode(mediaType string, out any) error {
+	if len(r.Body) == 0 {
+		return nil
+	}
+	decode := r.codecs.Decoder(mediaType)
+	if decode == nil {
+		return fmt.Errorf("client: no decoder for %s", mediaType)
+	}
+	if err := decode(r.Body, out); err != nil {
+		return fmt.Errorf("client: decoding %s response: %w", mediaType, err)
+	}
+	return nil
+}
+
+// StatusError reports a response with a status code of 400 or above.
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("client: unexpected status %d %s", e.StatusCode, zeno.StatusMessage(e.StatusCode))
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+type user struct {
+	ID   int    `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+}
+
+func newTestClient(t *testing.T, z *zeno.Zeno, config Config) *Client {
+	ln := fasthttputil.NewInmemoryListener()
+	t.Cleanup(func() { ln.Close() })
+	go fasthttp.Serve(ln, z.HandleRequest)
+	config.BaseURL = "http://test"
+	config.Dial = func(string) (net.Conn, error) { return ln.Dial() }
+	return New(config)
+}
+
+func TestClient(t *testing.T) {
+	z := zeno.New()
+	z.Get("/users/{id}", func(c *zeno.Context) error {
+		return c.SendJSON(user{ID: zeno.Param[int](c, "id"), Name: c.Query("name")})
+	})
+	z.Post("/users", func(c *zeno.Context) error {
+		var u user
+		if err := c.BindJSON(&u); err != nil {
+			return err
+		}
+		c.SetHeader("X-Trace", c.GetHeader("traceparent"))
+		return c.Status(zeno.StatusCreated).SendYAML(u)
+	})
+	c := newTestClient(t, z, Config{})
+
+	var u user
+	assert.NoError(t, c.Get("/users/7").Query("name", "Ada").JSON(&u))
+	assert.Equal(t, user{ID: 7, Name: "Ada"}, u)
+
+	resp, err := c.Post("/users").JSONBody(user{ID: 1, Name: "Bob"}).Header("traceparent", "00-abc-01").Do()
+	assert.NoError(t, err)
+	assert.Equal(t, zeno.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "00-abc-01", resp.Header.Get("X-Trace"))
+	u = user{}
+	assert.NoError(t, resp.Decode(&u))
+	assert.Equal(t, user{ID: 1, Name: "Bob"}, u)
+
+	err = c.Get("/missing").JSON(&u)
+	var se *StatusError
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, zeno.StatusNotFound, se.StatusCode)
+}
+
+func TestResponse_DecodeEmptyBody(t *testing.T) {
+	z := zeno.New()
+	z.Delete("/users/{id}", func(c *zeno.Context) error {
+		return c.SendStatus(zeno.StatusNoContent)
+	})
+	c := newTestClient(t, z, Config{})
+
+	u := user{ID: 7}
+	assert.NoError(t, c.Delete("/users/7").JSON(&u))
+	assert.Equal(t, user{ID: 7}, u)
+
+	r := &Response{StatusCode: zeno.StatusOK, Header: http.Header{}}
+	assert.NoError(t, r.Decode(&u))
+	assert.Equal(t, user{ID: 7}, u)
+}
+
+func TestClient_Retries(t *testing.T) {
+	z := zeno.New()
+	var calls atomic.Int32
+	z.Get("/flaky", func(c *zeno.Context) error {
+		if calls.Add(1) < 3 {
+			return c.SendStatus(zeno.StatusServiceUnavailable)
+		}
+		return c.SendString("ok")
+	})
+	z.Post("/flaky", func(c *zeno.Context) error {
+		calls.Add(1)
+		return c.SendStatus(zeno.StatusServiceUnavailable)
+	})
+	c := newTestClient(t, z, Config{Retries: 2, RetryBackoff: time.Millisecond})
+
+	resp, err := c.Get("/flaky").Do()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(resp.Body))
+	assert.Equal(t, int32(3), calls.Load())
+
+	calls.Store(0)
+	resp, err = c.Post("/flaky").Do()
+	assert.NoError(t, err)
+	assert.Equal(t, zeno.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRequest_Propagate(t *testing.T) {
+	z := zeno.New()
+	var got *fasthttp.Request
+	z.Get("/", func(c *zeno.Context) error {
+		r := Default.Get("http://example.com").Propagate(c)
+		got = &fasthttp.Request{}
+		return r.build(got)
+	})
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/")
+	ctx.Request.Header.Set("traceparent", "00-abc-01")
+	ctx.Request.Header.Set(zeno.HeaderXRequestID, "r1")
+	z.HandleRequest(ctx)
+	assert.Equal(t, "00-abc-01", string(got.Header.Peek("traceparent")))
+	assert.Equal(t, "r1", string(got.Header.Peek(zeno.HeaderXRequestID)))
+	assert.Empty(t, got.Header.Peek("tracestate"))
+}
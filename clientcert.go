@@ -0,0 +1,170 @@
+package zeno
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"slices"
+	"time"
+)
+
+// ClientCertificate is a verified TLS client certificate. The subject and
+// subject alternative names (DNSNames, EmailAddresses, IPAddresses and
+// URIs) of the embedded certificate identify the client.
+type ClientCertificate struct {
+	*x509.Certificate
+
+	// Chains are the verified chains from the certificate to a trusted
+	// root.
+	Chains [][]*x509.Certificate
+}
+
+// SANs returns the subject alternative names of the certificate: DNS
+// names, e-mail addresses, IP addresses and URIs, such as SPIFFE IDs.
+func (cc *ClientCertificate) SANs() []string {
+	sans := slices.Concat(cc.DNSNames, cc.EmailAddresses)
+	for _, ip := range cc.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, u := range cc.URIs {
+		sans = append(sans, u.String())
+	}
+	return sans
+}
+
+// ClientCertConfig configures the ClientCert middleware.
+type ClientCertConfig struct {
+	// CAs verifies the client certificates. When nil, certificates must
+	// have been verified in the TLS handshake, against Zeno.ClientCAs or
+	// the ClientCAs of the server's tls.Config.
+	CAs *x509.CertPool
+
+	// KeyUsages lists the accepted extended key usages when verifying
+	// against CAs. Defaults to client authentication.
+	KeyUsages []x509.ExtKeyUsage
+
+	// AllowedSANs, when set, only accepts certificates with one of these
+	// subject alternative names (see ClientCertificate.SANs).
+	AllowedSANs []string
+
+	// Authorize, when set, makes further decisions from the certificate.
+	// An error rejects the request: HTTPErrors are returned as they are,
+	// others as 403 Forbidden.
+	Authorize func(c *Context, cert *ClientCertificate) error
+
+	// Optional accepts requests without a certificate; certificates that
+	// are presented must still be valid.
+	Optional bool
+
+	// Skipper, when set, bypasses the middleware for requests for which
+	// it returns true.
+	Skipper Skipper
+}
+
+// clientCertKey is the Context key of the verified client certificate.
+const clientCertKey = "zeno.clientcert"
+
+// ClientCert returns a middleware authenticating clients by the
+// certificates they present over TLS (mutual TLS). Requests without a
+// valid certificate are answered with 401 Unauthorized, and certificates
+// rejected by AllowedSANs or Authorize with 403 Forbidden. The verified
+// certificate is available from Context.ClientCertificate.
+//
+// Clients are only asked for certificates when the TLS handshake requests
+// them; set Zeno.ClientCAs, or the ClientAuth of Server().TLSConfig.
+//
+// Example:
+//
+//	app.ClientCAs = caPool
+//	internal := app.Group("/internal")
+//	internal.Use(zeno.ClientCert(zeno.ClientCertConfig{
+//	    AllowedSANs: []string{"spiffe://example.org/billing"},
+//	}))
+//	internal.Get("/invoices", func(c *zeno.Context) error {
+//	    return c.SendString("hello " + c.ClientCertificate().Subject.CommonName)
+//	})
+//	log.Fatal(app.RunTLS(":8443", "server.crt", "server.key"))
+func ClientCert(config ...ClientCertConfig) Handler {
+	var cfg ClientCertConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.KeyUsages == nil {
+		cfg.KeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	return func(c *Context) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		var peers []*x509.Certificate
+		state := c.TLSConnectionState()
+		if state != nil {
+			peers = state.PeerCertificates
+		}
+		if len(peers) == 0 {
+			if cfg.Optional {
+				return c.Next()
+			}
+			return NewHTTPError(StatusUnauthorized, "client certificate required")
+		}
+
+		cert := &ClientCertificate{Certificate: peers[0], Chains: state.VerifiedChains}
+		if cfg.CAs != nil {
+			intermediates := x509.NewCertPool()
+			for _, ic := range peers[1:] {
+				intermediates.AddCert(ic)
+			}
+			chains, err := peers[0].Verify(x509.VerifyOptions{
+				Roots:         cfg.CAs,
+				Intermediates: intermediates,
+				CurrentTime:   time.Now(),
+				KeyUsages:     cfg.KeyUsages,
+			})
+			if err != nil {
+				return NewHTTPError(StatusUnauthorized, "invalid client certificate: "+err.Error())
+			}
+			cert.Chains = chains
+		} else if len(cert.Chains) == 0 {
+			return NewHTTPError(StatusUnauthorized, "client certificate not verified")
+		}
+
+		if len(cfg.AllowedSANs) > 0 && !slices.ContainsFunc(cert.SANs(), func(san string) bool {
+			return slices.Contains(cfg.AllowedSANs, san)
+		}) {
+			return NewHTTPError(StatusForbidden, "client certificate not allowed")
+		}
+		if cfg.Authorize != nil {
+			if err := cfg.Authorize(c, cert); err != nil {
+				var httpErr HTTPError
+				if errors.As(err, &httpErr) {
+					return err
+				}
+				return NewHTTPError(StatusForbidden, err.Error())
+			}
+		}
+		c.Set(clientCertKey, cert)
+		return c.Next()
+	}
+}
+
+// ClientCertificate returns the client certificate verified by the
+// ClientCert middleware, or nil if there is none.
+func (c *Context) ClientCertificate() *ClientCertificate {
+	cert, _ := c.Get(clientCertKey).(*ClientCertificate)
+	return cert
+}
+
+// configureClientAuth makes cfg request client certificates and verify
+// them against ClientCAs, if set.
+func (z *Zeno) configureClientAuth(cfg *tls.Config) {
+	if z.ClientCAs == nil {
+		return
+	}
+	cfg.ClientCAs = z.ClientCAs
+	switch cfg.ClientAuth {
+	case tls.NoClientCert, tls.RequestClientCert:
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	case tls.RequireAnyClientCert:
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+}
@@ -0,0 +1,201 @@
+package zeno
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// testCA issues certificates for TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+func (ca *testCA) issue(t *testing.T, tmpl *x509.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.NotBefore, tmpl.NotAfter = time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	assert.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestClientCert(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert := ca.issue(t, &x509.Certificate{DNSNames: []string{"api.test"}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+	spiffe, _ := url.Parse("spiffe://example.org/billing")
+	billing := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "billing"},
+		URIs:        []*url.URL{spiffe},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	reports := ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "reports"},
+		DNSNames:    []string{"reports.internal"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	rogue := newTestCA(t).issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "rogue"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	z := New()
+	z.ClientCAs = ca.pool
+	z.Get("/public", func(c *Context) error {
+		return c.SendString(c.NegotiatedProtocol() + "|" + c.TLSConnectionState().ServerName)
+	})
+	internal := z.Group("/internal")
+	internal.Use(ClientCert(ClientCertConfig{
+		AllowedSANs: []string{"spiffe://example.org/billing", "reports.internal"},
+		Authorize: func(c *Context, cert *ClientCertificate) error {
+			if cert.Subject.CommonName == "reports" && c.Method() != MethodGet {
+				return errors.New("read only")
+			}
+			return nil
+		},
+	}))
+	internal.Get("/whoami", func(c *Context) error {
+		cert := c.ClientCertificate()
+		return c.SendString(cert.Subject.CommonName + " " + strings.Join(cert.SANs(), ","))
+	})
+	internal.Post("/invoices", func(c *Context) error {
+		return c.SendStatus(StatusCreated)
+	})
+
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{serverCert}}
+	z.configureClientAuth(tlsConfig)
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	z.Server().Logger = log.New(io.Discard, "", 0) // failed handshakes are expected
+	go z.Server().Serve(tls.NewListener(ln, tlsConfig))
+
+	do := func(method, path string, cert *tls.Certificate) (int, string) {
+		tc := &tls.Config{RootCAs: ca.pool, ServerName: "api.test"}
+		if cert != nil {
+			tc.Certificates = []tls.Certificate{*cert}
+		}
+		client := &fasthttp.Client{TLSConfig: tc, Dial: func(string) (net.Conn, error) { return ln.Dial() }}
+		req, resp := fasthttp.AcquireRequest(), fasthttp.AcquireResponse()
+		defer fasthttp.ReleaseRequest(req)
+		defer fasthttp.ReleaseResponse(resp)
+		req.Header.SetMethod(method)
+		req.SetRequestURI("https://api.test" + path)
+		if err := client.Do(req, resp); err != nil {
+			return 0, err.Error()
+		}
+		return resp.StatusCode(), string(resp.Body())
+	}
+
+	status, body := do("GET", "/public", nil)
+	assert.Equal(t, StatusOK, status)
+	assert.Equal(t, "|api.test", body)
+
+	status, body = do("GET", "/internal/whoami", &billing)
+	assert.Equal(t, StatusOK, status)
+	assert.Equal(t, "billing spiffe://example.org/billing", body)
+	status, _ = do("POST", "/internal/invoices", &billing)
+	assert.Equal(t, StatusCreated, status)
+
+	status, body = do("GET", "/internal/whoami", &reports)
+	assert.Equal(t, StatusOK, status)
+	assert.Equal(t, "reports reports.internal", body)
+	status, body = do("POST", "/internal/invoices", &reports)
+	assert.Equal(t, StatusForbidden, status)
+	assert.Equal(t, "read only", body)
+
+	status, body = do("GET", "/internal/whoami", nil)
+	assert.Equal(t, StatusUnauthorized, status)
+	assert.Equal(t, "client certificate required", body)
+
+	// Certificates from other CAs fail the handshake.
+	status, _ = do("GET", "/internal/whoami", &rogue)
+	assert.Zero(t, status)
+}
+
+func TestClientCert_CAs(t *testing.T) {
+	ca := newTestCA(t)
+	leaf, _ := x509.ParseCertificate(ca.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "worker"},
+		DNSNames:    []string{"worker.internal"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}).Certificate[0])
+
+	for _, tt := range []struct {
+		name   string
+		config ClientCertConfig
+		state  *tls.ConnectionState
+		status int
+	}{
+		{"plain HTTP", ClientCertConfig{}, nil, StatusUnauthorized},
+		{"optional", ClientCertConfig{Optional: true}, nil, StatusOK},
+		{"unverified", ClientCertConfig{}, &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}, StatusUnauthorized},
+		{"verified", ClientCertConfig{CAs: ca.pool}, &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}, StatusOK},
+		{"other CA", ClientCertConfig{CAs: newTestCA(t).pool}, &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}, StatusUnauthorized},
+		{"wrong usage", ClientCertConfig{CAs: ca.pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}}, &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}, StatusUnauthorized},
+		{"not allowed", ClientCertConfig{CAs: ca.pool, AllowedSANs: []string{"other.internal"}}, &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}, StatusForbidden},
+		{"authorize", ClientCertConfig{CAs: ca.pool, Authorize: func(*Context, *ClientCertificate) error {
+			return NewHTTPError(StatusPaymentRequired)
+		}}, &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}, StatusPaymentRequired},
+	} {
+		z := New()
+		z.Use(ClientCert(tt.config))
+		z.Get("/", func(c *Context) error { return c.SendStatus(StatusOK) })
+		ctx := &fasthttp.RequestCtx{}
+		if tt.state != nil {
+			ctx.Init2(&tlsStateConn{state: *tt.state}, nil, false)
+		}
+		ctx.Request.SetRequestURI("/")
+		z.HandleRequest(ctx)
+		assert.Equal(t, tt.status, ctx.Response.StatusCode(), tt.name)
+	}
+}
+
+// tlsStateConn is a connection reporting a fixed TLS state.
+type tlsStateConn struct {
+	net.Conn
+	state tls.ConnectionState
+}
+
+func (c *tlsStateConn) ConnectionState() tls.ConnectionState { return c.state }
+func (c *tlsStateConn) RemoteAddr() net.Addr                 { return &net.TCPAddr{} }
+func (c *tlsStateConn) LocalAddr() net.Addr                  { return &net.TCPAddr{} }
+func (c *tlsStateConn) Handshake() error                     { return nil }
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// watchedExts are the extensions of the files whose changes trigger a
+// rebuild.
+var watchedExts = []string{".go", ".html", ".tmpl", ".gohtml", ".json", ".yaml", ".yml", ".toml", ".env", ".mod", ".sum"}
+
+// skippedDirs are never watched.
+var skippedDirs = []string{"vendor", "node_modules", "bin", "tmp"}
+
+// stopTimeout is how long a stopped application may take to shut down
+// gracefully before it is killed.
+const stopTimeout = 5 * time.Second
+
+// cmdDev implements "zeno dev".
+func cmdDev(args []string) error {
+	fs := flag.NewFlagSet("dev", flag.ContinueOnError)
+	interval := fs.Duration("interval", 500*time.Millisecond, "how often to check for changes")
+	var appArgs []string
+	if i := slices.Index(args, "--"); i >= 0 {
+		args, appArgs = args[:i], args[i+1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("%w: dev takes at most one package", errUsage)
+	}
+	pkg := "."
+	if fs.NArg() == 1 {
+		pkg = fs.Arg(0)
+	}
+	root := pkg
+	if fi, err := os.Stat(root); err != nil || !fi.IsDir() {
+		root = "."
+	}
+
+	dir, err := os.MkdirTemp("", "zeno-dev-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	d := &devServer{pkg: pkg, args: appArgs, bin: filepath.Join(dir, "app")}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	files := snapshot(root)
+	d.restart()
+	for {
+		select {
+		case <-signals:
+			d.stop()
+			return nil
+		case <-ticker.C:
+			if current := snapshot(root); !maps.Equal(files, current) {
+				files = current
+				fmt.Fprintln(os.Stderr, "zeno: change detected, rebuilding")
+				d.restart()
+			}
+		}
+	}
+}
+
+// devServer is the application run by "zeno dev".
+type devServer struct {
+	pkg  string
+	args []string
+	bin  string
+	cmd  *exec.Cmd
+	done chan struct{} // closed when cmd has exited
+}
+
+// restart rebuilds the application and, if that succeeds, replaces the
+// running process. After a failed build the previous process keeps
+// running, so the application stays reachable while errors are fixed.
+func (d *devServer) restart() {
+	if err := goBuild(d.bin, d.pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "zeno:", err)
+		return
+	}
+	d.stop()
+	cmd := exec.Command(d.bin, d.args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintln(os.Stderr, "zeno:", err)
+		return
+	}
+	d.cmd, d.done = cmd, make(chan struct{})
+	go func(done chan struct{}) {
+		_ = cmd.Wait()
+		close(done)
+	}(d.done)
+}
+
+// stop interrupts the running process, letting it shut down gracefully,
+// and kills it if it has not exited after stopTimeout.
+func (d *devServer) stop() {
+	if d.cmd == nil {
+		return
+	}
+	if err := d.cmd.Process.Signal(os.Interrupt); err != nil {
+		_ = d.cmd.Process.Kill()
+	}
+	select {
+	case <-d.done:
+	case <-time.After(stopTimeout):
+		_ = d.cmd.Process.Kill()
+		<-d.done
+	}
+	d.cmd = nil
+}
+
+// snapshot returns the modification times of the watched files below
+// root.
+func snapshot(root string) map[string]time.Time {
+	files := make(map[string]time.Time)
+	_ = filepath.WalkDir(root, func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := e.Name()
+		if e.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || slices.Contains(skippedDirs, name)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !slices.Contains(watchedExts, filepath.Ext(name)) {
+			return nil
+		}
+		if info, err := e.Info(); err == nil {
+			files[path] = info.ModTime()
+		}
+		return nil
+	})
+	return files
+}
@@ -0,0 +1,71 @@
+// Command zeno helps build applications with the zeno framework.
+//
+// Usage:
+//
+//	zeno new [-dir DIR] MODULE          scaffold a new project
+//	zeno generate handler NAME          add a handler stub to handlers/
+//	zeno generate middleware NAME       add a middleware stub to middleware/
+//	zeno routes [-json] [BINARY|PKG]    print the route table of an application
+//	zeno dev [PKG] [-- ARGS]            run an application, rebuilding it on change
+//
+// The routes command runs the application with ZENO_PRINT_ROUTES set,
+// which makes applications that opted in with Zeno.PrintRoutesOnRequest
+// print their routes instead of serving, as projects created by zeno new
+// do; it accepts a built binary or a package to build first. The dev command restarts the
+// application whenever a source file changes; pages served by an
+// application started with Zeno.RunDev and LiveReload then reload in the
+// browser.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// usage is printed for -h and unknown commands.
+const usage = `usage: zeno <command> [arguments]
+
+commands:
+  new [-dir DIR] MODULE                     scaffold a new project
+  generate (handler|middleware) [-dir DIR] NAME
+                                            add a handler or middleware stub
+  routes [-json] [BINARY|PACKAGE]           print the route table of an application
+  dev [-interval D] [PACKAGE] [-- ARGS]     run an application, rebuilding it on change
+`
+
+// errUsage reports invalid arguments; the usage text is printed for it.
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "zeno:", err)
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+// run executes the command given by args.
+func run(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch cmd, rest := args[0], args[1:]; cmd {
+	case "new":
+		return cmdNew(rest)
+	case "generate", "gen", "g":
+		return cmdGenerate(rest)
+	case "routes":
+		return cmdRoutes(rest)
+	case "dev":
+		return cmdDev(rest)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, cmd)
+	}
+}
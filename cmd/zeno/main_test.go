@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNames(t *testing.T) {
+	for name, want := range map[string]string{
+		"list-users": "ListUsers",
+		"list_users": "ListUsers",
+		"listUsers":  "ListUsers",
+		"2fa":        "Fa",
+		"auth2":      "Auth2",
+		"bad/name":   "",
+	} {
+		assert.Equal(t, want, exportedName(name), name)
+	}
+	for ident, want := range map[string]string{
+		"ListUsers": "list_users",
+		"HTTPProxy": "http_proxy",
+		"GetUserID": "get_user_id",
+		"Auth2Code": "auth2_code",
+	} {
+		assert.Equal(t, want, fileName(ident), ident)
+	}
+}
+
+func TestScaffold(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app")
+	assert.NoError(t, scaffold(dir, templateData{Module: "example.com/app", Zeno: zenoModule, Version: "v1.2.3"}))
+
+	for name := range projectFiles {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		assert.NoError(t, err, name)
+	}
+	mod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+	assert.Contains(t, string(mod), "module example.com/app")
+	assert.Contains(t, string(mod), "require "+zenoModule+" v1.2.3")
+	routes, _ := os.ReadFile(filepath.Join(dir, "routes.go"))
+	assert.Contains(t, string(routes), `"example.com/app/handlers"`)
+
+	assert.ErrorContains(t, scaffold(dir, templateData{Module: "example.com/app", Zeno: zenoModule}), "not empty")
+
+	file, err := generateStub(filepath.Join(dir, "handlers"), "handler", "list-users")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "handlers", "list_users.go"), file)
+	src, _ := os.ReadFile(file)
+	assert.Contains(t, string(src), "package handlers")
+	assert.Contains(t, string(src), "func ListUsers(c *zeno.Context) error {")
+
+	_, err = generateStub(filepath.Join(dir, "handlers"), "handler", "ListUsers")
+	assert.ErrorContains(t, err, "already exists")
+
+	file, err = generateStub(filepath.Join(dir, "middleware"), "middleware", "auth")
+	assert.NoError(t, err)
+	src, _ = os.ReadFile(file)
+	assert.Contains(t, string(src), "func Auth() zeno.Handler {")
+}
+
+func TestRouteTableOutput(t *testing.T) {
+	out := []byte("2024/01/01 connecting to db\n" + `[
+  {"method": "GET", "path": "/users/{id}", "name": "user", "handlers": ["main.auth", "example.com/app/handlers.GetUser"]},
+  {"method": "POST", "path": "/users", "name": "/users", "handlers": ["example.com/app/handlers.CreateUser"]}
+]
+`)
+	table, err := parseRouteTable(out)
+	assert.NoError(t, err)
+	assert.Len(t, table, 2)
+
+	var buf bytes.Buffer
+	assert.NoError(t, printRouteTable(&buf, table))
+	assert.Equal(t, ""+
+		"METHOD  PATH         NAME  HANDLER\n"+
+		"GET     /users/{id}  user  handlers.GetUser\n"+
+		"POST    /users             handlers.CreateUser\n", buf.String())
+
+	_, err = parseRouteTable([]byte("listening on :3000\n"))
+	assert.ErrorContains(t, err, "no route table")
+
+	table, err = parseRouteTable([]byte("[]\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []zeno.RouteTableEntry{}, table)
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("docs"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "x.go"), []byte("package x"), 0o644))
+
+	before := snapshot(dir)
+	assert.Equal(t, []string{filepath.Join(dir, "main.go")}, keys(before))
+
+	later := time.Now().Add(time.Second)
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, "main.go"), later, later))
+	assert.NotEqual(t, before, snapshot(dir))
+}
+
+func keys(m map[string]time.Time) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+)
+
+// routesTimeout bounds how long an application may take to reach Run.
+const routesTimeout = 30 * time.Second
+
+// cmdRoutes implements "zeno routes".
+func cmdRoutes(args []string) error {
+	fs := flag.NewFlagSet("routes", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the route table as JSON")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("%w: routes takes at most one binary or package", errUsage)
+	}
+	target := "."
+	if fs.NArg() == 1 {
+		target = fs.Arg(0)
+	}
+
+	bin, cleanup, err := binaryFor(target)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	table, err := loadRouteTable(bin)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(table)
+	}
+	return printRouteTable(os.Stdout, table)
+}
+
+// binaryFor returns target if it is an executable file, and otherwise
+// builds target as a package into a temporary directory removed by
+// cleanup.
+func binaryFor(target string) (bin string, cleanup func(), err error) {
+	if fi, err := os.Stat(target); err == nil && fi.Mode().IsRegular() {
+		return target, func() {}, nil
+	}
+	dir, err := os.MkdirTemp("", "zeno-routes-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	bin = filepath.Join(dir, "app")
+	if err := goBuild(bin, target); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return bin, cleanup, nil
+}
+
+// goBuild builds pkg into bin, reporting compiler errors on stderr.
+func goBuild(bin, pkg string) error {
+	cmd := exec.Command("go", "build", "-o", bin, pkg)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("building %s: %w", pkg, err)
+	}
+	return nil
+}
+
+// loadRouteTable runs bin with zeno.EnvPrintRoutes set and decodes the
+// route table it prints when it calls Run, having opted in with
+// Zeno.PrintRoutesOnRequest.
+func loadRouteTable(bin string) ([]zeno.RouteTableEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), routesTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, bin)
+	cmd.Env = append(os.Environ(), zeno.EnvPrintRoutes+"=1")
+	var stdout bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, os.Stderr
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%s did not print its routes within %s; does it call PrintRoutesOnRequest and Run?", bin, routesTimeout)
+	}
+	// Run returns ErrRoutesPrinted after printing, which applications
+	// may treat as fatal, so the exit status alone is not conclusive.
+	table, perr := parseRouteTable(stdout.Bytes())
+	if perr != nil && err != nil {
+		return nil, fmt.Errorf("running %s: %w", bin, err)
+	}
+	return table, perr
+}
+
+// parseRouteTable decodes the route table from the output of an
+// application. Anything the application printed before it is skipped.
+func parseRouteTable(out []byte) ([]zeno.RouteTableEntry, error) {
+	start := bytes.LastIndex(out, []byte("\n["))
+	if bytes.HasPrefix(out, []byte("[")) {
+		start = 0
+	} else if start >= 0 {
+		start++
+	}
+	if start < 0 {
+		return nil, errors.New("no route table in the output; is the application built with a zeno version that supports it?")
+	}
+	var table []zeno.RouteTableEntry
+	if err := json.Unmarshal(out[start:], &table); err != nil {
+		return nil, fmt.Errorf("decoding the route table: %w", err)
+	}
+	return table, nil
+}
+
+// printRouteTable writes table to w as aligned columns: the method, path,
+// name if it differs from the path, and the final handler.
+func printRouteTable(w io.Writer, table []zeno.RouteTableEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tNAME\tHANDLER")
+	for _, r := range table {
+		name := r.Name
+		if name == r.Path {
+			name = ""
+		}
+		handler := ""
+		if n := len(r.Handlers); n > 0 {
+			handler = r.Handlers[n-1]
+			handler = handler[strings.LastIndexByte(handler, '/')+1:]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Method, r.Path, name, handler)
+	}
+	return tw.Flush()
+}
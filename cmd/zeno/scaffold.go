@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// zenoModule is the import path of the framework.
+const zenoModule = "github.com/Abhishek2010dev/zeno"
+
+// projectFiles are the files written by "zeno new", by path relative to
+// the project directory.
+var projectFiles = map[string]string{
+	"go.mod": `module {{.Module}}
+
+go 1.24
+{{if .Version}}
+require {{.Zeno}} {{.Version}}
+{{end}}`,
+
+	"main.go": `package main
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"{{.Zeno}}"
+)
+
+func main() {
+	app := zeno.New()
+	// Lets "zeno routes" list the routes of this application.
+	app.PrintRoutesOnRequest()
+	registerRoutes(app)
+
+	addr := ":3000"
+	if port := os.Getenv("PORT"); port != "" {
+		addr = ":" + port
+	}
+	log.Printf("listening on %s", addr)
+	if err := app.Run(addr); err != nil && !errors.Is(err, zeno.ErrRoutesPrinted) {
+		log.Fatal(err)
+	}
+}
+`,
+
+	"routes.go": `package main
+
+import (
+	"{{.Module}}/handlers"
+	"{{.Module}}/middleware"
+
+	"{{.Zeno}}"
+)
+
+// registerRoutes registers the application's middleware and routes.
+func registerRoutes(app *zeno.Zeno) {
+	app.Use(zeno.AccessLog(), middleware.RequestTimer())
+
+	app.Get("/health", handlers.Health)
+}
+`,
+
+	"handlers/health.go": `package handlers
+
+import "{{.Zeno}}"
+
+// Health reports that the application is up.
+func Health(c *zeno.Context) error {
+	return c.SendJSON(map[string]string{"status": "ok"})
+}
+`,
+
+	"middleware/request_timer.go": `package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"{{.Zeno}}"
+)
+
+// RequestTimer returns a middleware reporting the time spent handling
+// each request in the Server-Timing header.
+func RequestTimer() zeno.Handler {
+	return func(c *zeno.Context) error {
+		start := time.Now()
+		err := c.Next()
+		ms := float64(time.Since(start)) / float64(time.Millisecond)
+		c.SetHeader("Server-Timing", "app;dur="+strconv.FormatFloat(ms, 'f', 2, 64))
+		return err
+	}
+}
+`,
+
+	".gitignore": `/bin/
+`,
+}
+
+// stubs are the templates used by "zeno generate", by kind.
+var stubs = map[string]string{
+	"handler": `package {{.Package}}
+
+import "{{.Zeno}}"
+
+// {{.Name}} handles requests to ...
+func {{.Name}}(c *zeno.Context) error {
+	// TODO: implement {{.Name}}.
+	return zeno.ErrNotImplemented
+}
+`,
+
+	"middleware": `package {{.Package}}
+
+import "{{.Zeno}}"
+
+// {{.Name}} returns a middleware that ...
+func {{.Name}}() zeno.Handler {
+	return func(c *zeno.Context) error {
+		// TODO: work done before the handler.
+		err := c.Next()
+		// TODO: work done after the handler.
+		return err
+	}
+}
+`,
+}
+
+// templateData is passed to the project and stub templates.
+type templateData struct {
+	Module  string // module path of the project
+	Zeno    string // import path of the framework
+	Version string // framework version to require, if known
+	Package string // package name of a stub
+	Name    string // identifier of a stub
+}
+
+// cmdNew implements "zeno new".
+func cmdNew(args []string) error {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	dir := fs.String("dir", "", "project directory (default: last element of MODULE)")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: new takes a module path", errUsage)
+	}
+	module := fs.Arg(0)
+	if *dir == "" {
+		*dir = path.Base(module)
+	}
+	data := templateData{Module: module, Zeno: zenoModule, Version: zenoVersion()}
+	if err := scaffold(*dir, data); err != nil {
+		return err
+	}
+
+	fmt.Printf("created %s in %s\n\nnext steps:\n  cd %s\n", module, *dir, *dir)
+	if data.Version == "" {
+		fmt.Printf("  go get %s@latest\n", zenoModule)
+	}
+	fmt.Print("  go mod tidy\n  zeno dev\n")
+	return nil
+}
+
+// scaffold writes the project files into dir, which must not exist or be
+// empty.
+func scaffold(dir string, data templateData) error {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("directory %s is not empty", dir)
+	}
+	for name, text := range projectFiles {
+		if err := writeTemplate(filepath.Join(dir, filepath.FromSlash(name)), text, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// zenoVersion returns the version of the framework this command was built
+// with, or "" for development builds.
+func zenoVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if v := info.Main.Version; info.Main.Path == zenoModule && strings.HasPrefix(v, "v") && !strings.Contains(v, "+dirty") {
+		return v
+	}
+	return ""
+}
+
+// cmdGenerate implements "zeno generate".
+func cmdGenerate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: generate takes a kind, handler or middleware", errUsage)
+	}
+	kind := args[0]
+	if _, ok := stubs[kind]; !ok {
+		return fmt.Errorf("%w: unknown kind %q", errUsage, kind)
+	}
+	fs := flag.NewFlagSet("generate "+kind, flag.ContinueOnError)
+	dir := fs.String("dir", defaultStubDir(kind), "directory of the package to add the stub to")
+	if err := fs.Parse(args[1:]); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: generate %s takes a name", errUsage, kind)
+	}
+	file, err := generateStub(*dir, kind, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Println("created", file)
+	return nil
+}
+
+// defaultStubDir returns the directory stubs of kind are written to.
+func defaultStubDir(kind string) string {
+	if kind == "handler" {
+		return "handlers"
+	}
+	return kind
+}
+
+// generateStub writes a stub of kind called name into dir and returns the
+// file's path. Existing files are never overwritten.
+func generateStub(dir, kind, name string) (string, error) {
+	ident := exportedName(name)
+	if ident == "" {
+		return "", fmt.Errorf("%q is not a valid name", name)
+	}
+	pkg := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, filepath.Base(dir))
+	file := filepath.Join(dir, fileName(ident)+".go")
+	if _, err := os.Stat(file); err == nil {
+		return "", fmt.Errorf("%s already exists", file)
+	}
+	return file, writeTemplate(file, stubs[kind], templateData{Zeno: zenoModule, Package: pkg, Name: ident})
+}
+
+// writeTemplate executes text with data and writes the result, gofmt'ed
+// if it is Go source, to name.
+func writeTemplate(name, text string, data templateData) error {
+	var buf bytes.Buffer
+	if err := template.Must(template.New(name).Parse(text)).Execute(&buf, data); err != nil {
+		return err
+	}
+	out := buf.Bytes()
+	if strings.HasSuffix(name, ".go") {
+		formatted, err := format.Source(out)
+		if err != nil {
+			return fmt.Errorf("formatting %s: %w", name, err)
+		}
+		out = formatted
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, out, 0o644)
+}
+
+// exportedName converts name, e.g. "list-users", "list_users" or
+// "listUsers", into an exported Go identifier such as "ListUsers".
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		switch {
+		case r == '-' || r == '_' || r == ' ' || r == '.':
+			upper = true
+		case unicode.IsLetter(r) || (unicode.IsDigit(r) && b.Len() > 0):
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			upper = false
+		case unicode.IsDigit(r):
+			// Identifiers cannot start with a digit.
+		default:
+			return ""
+		}
+	}
+	return b.String()
+}
+
+// fileName converts an identifier such as "ListUsers" or "HTTPProxy" into
+// a file name such as "list_users" or "http_proxy".
+func fileName(ident string) string {
+	runes := []rune(ident)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -1,5 +1,7 @@
 package zeno
 
+import "io"
+
 // EncoderFunc defines a function signature used for encoding a Go value into a specific format,
 // such as JSON, XML, or other content types. It takes a value of any type and returns the
 // encoded byte slice or an error if encoding fails.
@@ -39,3 +41,14 @@ type DecoderFunc func(data []byte, v any) error
 //	    return json.MarshalIndent(v, prefix, indent)
 //	}
 type IndentFunc func(v any, prefix, indent string) ([]byte, error)
+
+// StreamEncoderFunc defines a function signature used for encoding a Go value directly
+// into an io.Writer instead of returning a byte slice. It is used by streaming helpers
+// such as SendJSONStream so large payloads never have to be held in memory at once.
+//
+// Example:
+//
+//	jsonStreamEncoder := func(w io.Writer, v any) error {
+//	    return json.NewEncoder(w).Encode(v)
+//	}
+type StreamEncoderFunc func(w io.Writer, v any) error
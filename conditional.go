@@ -0,0 +1,168 @@
+package zeno
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// fileETag returns a strong entity tag derived from the size and
+// modification time of a file.
+func fileETag(fi fs.FileInfo) string {
+	return contentETag(fi.Size(), fi.ModTime())
+}
+
+// contentHashETag returns a strong entity tag derived from a hash of the
+// content of f, for files without a modification time, such as those of
+// embed.FS, whose versions the size alone does not tell apart. f is
+// rewound afterwards. The tag is empty if f cannot be rewound.
+func contentHashETag(f fs.File) (string, error) {
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		return "", nil
+	}
+	h := fnv.New64a()
+	n, err := io.Copy(h, rs)
+	if err != nil {
+		return "", err
+	}
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`"%x-%x"`, n, h.Sum64()), nil
+}
+
+// contentETag returns a strong entity tag derived from the size and
+// modification time of some content.
+func contentETag(size int64, modTime time.Time) string {
+	return fmt.Sprintf(`"%x-%x"`, size, modTime.UnixNano())
+}
+
+// etagStrongMatch reports whether the If-Match header value matches etag,
+// using the strong comparison required for If-Match and If-Range.
+func etagStrongMatch(header, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for candidate := range strings.SplitSeq(header, ",") {
+		if strings.TrimSpace(candidate) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPreconditions evaluates If-Match, If-Unmodified-Since, If-None-Match
+// and If-Modified-Since, in the order of RFC 9110 section 13.2.2, against
+// the validators of the response about to be sent. A zero modTime disables
+// the date based checks. It reports true when the response has already
+// been completed with 304 Not Modified or 412 Precondition Failed.
+func (c *Context) checkPreconditions(etag string, modTime time.Time) bool {
+	modTime = modTime.Truncate(time.Second)
+	if im := c.GetHeader(HeaderIfMatch); im != "" {
+		if !etagStrongMatch(im, etag) {
+			c.SendStatus(StatusPreconditionFailed)
+			return true
+		}
+	} else if ius := c.GetHeader(HeaderIfUnmodifiedSince); ius != "" && !modTime.IsZero() {
+		if t, err := http.ParseTime(ius); err == nil && modTime.After(t) {
+			c.SendStatus(StatusPreconditionFailed)
+			return true
+		}
+	}
+
+	safe := c.ctx.IsGet() || c.ctx.IsHead()
+	if inm := c.GetHeader(HeaderIfNoneMatch); inm != "" {
+		if etagMatches(inm, etag) {
+			if safe {
+				c.ctx.NotModified()
+			} else {
+				c.SendStatus(StatusPreconditionFailed)
+			}
+			return true
+		}
+	} else if ims := c.GetHeader(HeaderIfModifiedSince); ims != "" && safe && !modTime.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil && !modTime.After(t) {
+			c.ctx.NotModified()
+			return true
+		}
+	}
+	return false
+}
+
+// ifRangeValid reports whether a Range header may be honoured, i.e. the
+// request has no If-Range header or its validator still matches the
+// current representation. Entity tags are compared strongly and dates
+// must match the modification time exactly.
+func (c *Context) ifRangeValid(etag string, modTime time.Time) bool {
+	ir := c.GetHeader(HeaderIfRange)
+	if ir == "" {
+		return true
+	}
+	if strings.HasPrefix(ir, `"`) || strings.HasPrefix(ir, "W/") {
+		return !strings.HasPrefix(ir, "W/") && !strings.HasPrefix(etag, "W/") && ir == etag
+	}
+	t, err := http.ParseTime(ir)
+	return err == nil && !modTime.IsZero() && modTime.Truncate(time.Second).Equal(t)
+}
+
+// sendOSFile serves the file at name from disk via fasthttp, after setting
+// a strong ETag and evaluating the conditional request headers. A Range
+// header is dropped when If-Range no longer matches, so the client gets
+// the full, current file instead of a mismatched fragment.
+func (c *Context) sendOSFile(name string) {
+	if fi, err := os.Stat(name); err == nil && !fi.IsDir() {
+		etag := fileETag(fi)
+		c.SetHeader(HeaderETag, etag)
+		if c.checkPreconditions(etag, fi.ModTime()) {
+			return
+		}
+		if !c.ifRangeValid(etag, fi.ModTime()) {
+			c.ctx.Request.Header.Del(HeaderRange)
+		}
+	}
+	c.ctx.SendFile(name)
+}
+
+// sendRange answers a single-range Range request for content of the given
+// size with 206 Partial Content, or 416 Range Not Satisfiable for invalid
+// ranges. It reports false, writing nothing, when the request has no
+// usable Range header and the full content should be sent instead.
+// Multi-range requests are answered with the full content.
+func (c *Context) sendRange(content io.ReadSeeker, size int64, etag string, modTime time.Time) (bool, error) {
+	rng := c.ctx.Request.Header.Peek(HeaderRange)
+	if len(rng) == 0 || strings.Contains(string(rng), ",") || !c.ifRangeValid(etag, modTime) {
+		return false, nil
+	}
+	start, end, err := fasthttp.ParseByteRange(rng, int(size))
+	if err != nil {
+		c.SetHeader(HeaderContentRange, "bytes */"+strconv.FormatInt(size, 10))
+		return true, c.SendStatus(StatusRequestedRangeNotSatisfiable)
+	}
+	if _, err := content.Seek(int64(start), io.SeekStart); err != nil {
+		return true, NewHTTPError(StatusInternalServerError, "failed to seek: "+err.Error())
+	}
+	n := end - start + 1
+	c.ctx.Response.Header.SetContentRange(start, end, int(size))
+	c.Status(StatusPartialContent)
+	body := io.Reader(io.LimitReader(content, int64(n)))
+	if cl, ok := content.(io.Closer); ok {
+		body = struct {
+			io.Reader
+			io.Closer
+		}{body, cl}
+	}
+	c.ctx.SetBodyStream(body, n)
+	return true, nil
+}
@@ -0,0 +1,134 @@
+package zeno
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendFile_Conditional(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data.txt")
+	assert.NoError(t, os.WriteFile(name, []byte("0123456789"), 0o644))
+	mod := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, os.Chtimes(name, mod, mod))
+
+	z := New()
+	z.Get("/file", func(c *Context) error { return c.SendFile(name) })
+
+	ctx := performRequest(z, "GET", "/file", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	etag := string(ctx.Response.Header.Peek(HeaderETag))
+	assert.NotEmpty(t, etag)
+
+	ctx = performRequest(z, "GET", "/file", map[string]string{HeaderIfNoneMatch: etag}, nil)
+	assert.Equal(t, StatusNotModified, ctx.Response.StatusCode())
+
+	ctx = performRequest(z, "GET", "/file", map[string]string{HeaderIfMatch: `"other"`}, nil)
+	assert.Equal(t, StatusPreconditionFailed, ctx.Response.StatusCode())
+
+	ctx = performRequest(z, "GET", "/file", map[string]string{
+		HeaderIfUnmodifiedSince: mod.Add(-time.Hour).Format(http.TimeFormat),
+	}, nil)
+	assert.Equal(t, StatusPreconditionFailed, ctx.Response.StatusCode())
+
+	ctx = performRequest(z, "GET", "/file", map[string]string{HeaderRange: "bytes=2-4", HeaderIfRange: etag}, nil)
+	assert.Equal(t, StatusPartialContent, ctx.Response.StatusCode())
+	assert.Equal(t, "234", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/file", map[string]string{HeaderRange: "bytes=2-4", HeaderIfRange: `"stale"`}, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "0123456789", string(ctx.Response.Body()))
+}
+
+func TestSendFS_Range(t *testing.T) {
+	mod := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{"data.txt": {Data: []byte("0123456789"), ModTime: mod}}
+	z := New()
+	z.Get("/file", func(c *Context) error { return c.SendFS(fsys, "data.txt") })
+
+	ctx := performRequest(z, "GET", "/file", map[string]string{HeaderRange: "bytes=-3"}, nil)
+	assert.Equal(t, StatusPartialContent, ctx.Response.StatusCode())
+	assert.Equal(t, "789", string(ctx.Response.Body()))
+	assert.Equal(t, "bytes 7-9/10", string(ctx.Response.Header.Peek(HeaderContentRange)))
+
+	ctx = performRequest(z, "GET", "/file", map[string]string{
+		HeaderRange:   "bytes=0-1",
+		HeaderIfRange: mod.Add(-time.Hour).Format(http.TimeFormat),
+	}, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "0123456789", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/file", map[string]string{HeaderRange: "bytes=20-30"}, nil)
+	assert.Equal(t, StatusRequestedRangeNotSatisfiable, ctx.Response.StatusCode())
+	assert.Equal(t, "bytes */10", string(ctx.Response.Header.Peek(HeaderContentRange)))
+
+	// Unsatisfiable ranges close the file.
+	tracked := &openFilesFS{FS: fsys}
+	z.Get("/tracked", func(c *Context) error { return c.SendFS(tracked, "data.txt") })
+	ctx = performRequest(z, "GET", "/tracked", map[string]string{HeaderRange: "bytes=20-30"}, nil)
+	assert.Equal(t, StatusRequestedRangeNotSatisfiable, ctx.Response.StatusCode())
+	assert.Zero(t, tracked.open)
+}
+
+func TestSendFS_ETagWithoutModTime(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt": {Data: []byte("aaaa")},
+		"b.txt": {Data: []byte("bbbb")},
+	}
+	z := New()
+	z.Get("/{name}", func(c *Context) error { return c.SendFS(fsys, c.Param("name")) })
+
+	etagA := string(performRequest(z, "GET", "/a.txt", nil, nil).Response.Header.Peek(HeaderETag))
+	etagB := string(performRequest(z, "GET", "/b.txt", nil, nil).Response.Header.Peek(HeaderETag))
+	assert.NotEmpty(t, etagA)
+	assert.NotEqual(t, etagA, etagB, "equal-sized files share an ETag")
+
+	ctx := performRequest(z, "GET", "/a.txt", map[string]string{HeaderIfNoneMatch: etagA}, nil)
+	assert.Equal(t, StatusNotModified, ctx.Response.StatusCode())
+
+	// A rebuilt file of the same size is sent again.
+	fsys["a.txt"] = &fstest.MapFile{Data: []byte("AAAA")}
+	ctx = performRequest(z, "GET", "/a.txt", map[string]string{HeaderIfNoneMatch: etagA}, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "AAAA", string(ctx.Response.Body()))
+}
+
+// openFilesFS counts the files of FS that are open.
+type openFilesFS struct {
+	fs.FS
+	open int
+}
+
+func (f *openFilesFS) Open(name string) (fs.File, error) {
+	file, err := f.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	f.open++
+	return trackedFile{file.(seekableFile), f}, nil
+}
+
+// seekableFile is an fs.File implementing io.Seeker, as those of
+// fstest.MapFS do.
+type seekableFile interface {
+	fs.File
+	io.Seeker
+}
+
+// trackedFile decrements the count of its openFilesFS when closed.
+type trackedFile struct {
+	seekableFile
+	fsys *openFilesFS
+}
+
+func (f trackedFile) Close() error {
+	f.fsys.open--
+	return f.seekableFile.Close()
+}
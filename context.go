@@ -1,15 +1,29 @@
 package zeno
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/tls"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
 	"mime/multipart"
 	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"runtime/debug"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/valyala/fasthttp"
 )
@@ -27,13 +41,61 @@ type Context struct {
 	index    int
 	handlers []Handler
 	data     sync.Map
+
+	// fallbackValues receives the parameters of group prefixes matched to
+	// find a group's error handler or default headers, which must not
+	// overwrite pvalues.
+	fallbackValues []string
+
+	// hasData is set once Set has stored a value, so that requests that
+	// never use data do not pay for clearing it.
+	hasData atomic.Bool
+
+	// afterResponse holds the functions registered via AfterResponse.
+	afterResponse []func()
+
+	// copied is set on contexts returned by Copy, which must not be used
+	// to write a response.
+	copied bool
+
+	// dispatches counts internal re-dispatches (see Rewrite) to detect loops.
+	dispatches int
+
+	// handlerHeader holds the response header as it was before the last
+	// handler of the chain ran, restored by discardResponse when
+	// Zeno.BufferResponses is set; handlerHeaderSaved reports whether it
+	// was taken for the current request.
+	handlerHeader      fasthttp.ResponseHeader
+	handlerHeaderSaved bool
+
+	// held lists the resources middleware has acquired for the request,
+	// so that a re-dispatched chain running it again does not acquire
+	// them twice. See hold.
+	held []any
+
+	// released is set in debug mode once the context has been handed back
+	// after its request completed. See Zeno.Debug.
+	released bool
+
+	// bodyCache holds request bodies decoded by BodyParsed, by target type.
+	bodyCache map[reflect.Type]reflect.Value
+
+	// streamed counts the bytes of a response body stream of unknown
+	// length. See ResponseSize.
+	streamed *atomic.Int64
 }
 
 // Next executes the next handler in the middleware chain.
 // It returns early if any handler returns an error.
 func (c *Context) Next() error {
+	c.mustBeWritable()
 	c.index++
-	for n := len(c.handlers); c.index < n; c.index++ {
+	// The length is re-read on every iteration because Rewrite may swap
+	// in a different handler chain while the loop is running.
+	for ; c.index < len(c.handlers); c.index++ {
+		if c.index == len(c.handlers)-1 && c.zeno.BufferResponses {
+			c.saveHandlerHeader()
+		}
 		if err := c.handlers[c.index](c); err != nil {
 			return err
 		}
@@ -48,16 +110,168 @@ func (c *Context) Abort() {
 
 // URL returns a URL for a named route with optional path parameters.
 func (c *Context) URL(route string, pairs ...any) string {
-	if r := c.zeno.routes[route]; r != nil {
+	if r := c.zeno.GetRoute(route); r != nil {
 		return r.URL(pairs...)
 	}
 	return ""
 }
 
+// AbsoluteURL returns the absolute URL of a named route. The scheme and
+// host come from Zeno.BaseURL when set, otherwise from the current request.
+//
+// Example:
+//
+//	u, err := c.AbsoluteURL("user.show", "id", 42) // "https://example.com/users/42"
+//	c.SetHeader(HeaderLocation, u)
+func (c *Context) AbsoluteURL(route string, pairs ...any) (string, error) {
+	if c.zeno.BaseURL != "" {
+		return c.zeno.AbsoluteURL(route, pairs...)
+	}
+	path, err := c.zeno.URL(route, pairs...)
+	if err != nil {
+		return "", err
+	}
+	return c.Scheme() + "://" + c.Host() + path, nil
+}
+
 // init prepares the context with a new fasthttp.ctx.
 func (c *Context) init(ctx *fasthttp.RequestCtx) {
 	c.ctx = ctx
 	c.index = -1
+	c.afterResponse = c.afterResponse[:0]
+	c.dispatches = 0
+	c.handlerHeaderSaved = false
+	clear(c.held)
+	c.held = c.held[:0]
+	if c.hasData.Swap(false) {
+		c.data.Clear()
+	}
+	clear(c.bodyCache)
+	c.streamed = nil
+}
+
+// maxDispatches bounds how many times a single request may be re-routed
+// internally before it is considered a loop.
+const maxDispatches = 10
+
+// Rewrite changes the request path to path and re-dispatches the request
+// through the router without a client round trip. The handler chain
+// matching the new path replaces the current one and runs from the start,
+// global middleware included, so that middleware depending on the matched
+// route, such as Permission, sees the new route; the remaining handlers of
+// the current chain are skipped.
+//
+// The query string is preserved. A 508 Loop Detected error is returned if
+// a request is re-dispatched too many times.
+//
+// Example:
+//
+//	app.Get("/latest", func(c *zeno.Context) error {
+//	    return c.Rewrite("/releases/" + latestVersion)
+//	})
+func (c *Context) Rewrite(path string) error {
+	return c.dispatch(c.Method(), path)
+}
+
+// ReRoute re-enters the router with a new method and path and executes
+// the matched handler chain within the same request, replacing the rest of
+// the current chain. It enables error-page routes, soft 404 fallbacks, and
+// forwarding between controllers without a client redirect. As with
+// Rewrite, the new chain runs in full.
+//
+// Route parameters are replaced by those of the new match; the request
+// body, headers, query string, and values stored with Set are kept.
+// Strings previously returned by Method or Path share memory with the
+// request and change along with it; clone them if they must survive.
+//
+// Example:
+//
+//	app.Get("/legacy/profile", func(c *zeno.Context) error {
+//	    return c.ReRoute(zeno.MethodGet, "/users/me")
+//	})
+func (c *Context) ReRoute(method, path string) error {
+	return c.dispatch(method, path)
+}
+
+// dispatch routes the request again using method and path and runs the
+// resulting handler chain from its first handler.
+func (c *Context) dispatch(method, path string) error {
+	c.mustBeWritable()
+	c.dispatches++
+	if c.dispatches > maxDispatches {
+		return ErrLoopDetected
+	}
+	c.ctx.Request.Header.SetMethod(method)
+	c.ctx.URI().SetPath(path)
+	c.zeno.match(c)
+	c.index = -1
+	return c.Next()
+}
+
+// hold records that middleware acquired the per-request resource key,
+// such as a MaxConcurrent slot, so that it can pass through when a
+// re-dispatched chain runs it a second time. See holds.
+func (c *Context) hold(key any) {
+	c.held = append(c.held, key)
+}
+
+// holds reports whether key was recorded by hold during the request.
+func (c *Context) holds(key any) bool {
+	return slices.Contains(c.held, key)
+}
+
+// AfterResponse registers fn to run once the handler chain (including error
+// handling) has completed, so work such as audit logging, webhooks, or cache
+// warming does not delay the client.
+//
+// Registered functions run sequentially, in registration order, on a
+// separate goroutine started when the chain returns, concurrently with
+// fasthttp writing the response; they must not assume the client has
+// received it. By then the Context has been returned to the pool, so fn
+// must not use it: copy any values it needs before registering. A panic in
+// fn is recovered and logged to Server().Logger, or the standard logger if
+// none is set, and the remaining functions still run.
+//
+// Example:
+//
+//	id := c.Param("id")
+//	c.AfterResponse(func() {
+//	    audit.Record("user.viewed", id)
+//	})
+func (c *Context) AfterResponse(fn func()) {
+	c.afterResponse = append(c.afterResponse, fn)
+}
+
+// runAfterResponse launches the functions registered via AfterResponse.
+// The slice is copied because the Context is reused by the next request.
+func (c *Context) runAfterResponse() {
+	if len(c.afterResponse) == 0 {
+		return
+	}
+	fns := make([]func(), len(c.afterResponse))
+	copy(fns, c.afterResponse)
+	clear(c.afterResponse)
+	z := c.zeno
+	go func() {
+		for _, fn := range fns {
+			z.runAfterResponseFunc(fn)
+		}
+	}()
+}
+
+// runAfterResponseFunc calls fn, logging any panic instead of letting it
+// crash the process.
+func (z *Zeno) runAfterResponseFunc(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			var logger fasthttp.Logger = log.Default()
+			if l := z.Server().Logger; l != nil {
+				logger = l
+			}
+			logger.Printf("zeno: panic in AfterResponse function: %v\n%s", r, debug.Stack())
+		}
+	}()
+	fn()
 }
 
 // Zeno returns the underlying Zeno engine instance.
@@ -65,14 +279,166 @@ func (c *Context) Zeno() *Zeno {
 	return c.zeno
 }
 
+// Set stores a value in the context under key, making it available to
+// subsequent handlers in the chain. Values are cleared between requests.
+//
+// Example:
+//
+//	c.Set("user", currentUser)
+func (c *Context) Set(key string, value any) {
+	c.data.Store(key, value)
+	c.hasData.Store(true)
+}
+
+// Get returns the value stored under key by Set, or nil if there is none.
+//
+// Example:
+//
+//	user, _ := c.Get("user").(*User)
+func (c *Context) Get(key string) any {
+	v, _ := c.data.Load(key)
+	return v
+}
+
+// Copy returns a detached snapshot of the Context that is safe to use from
+// other goroutines after the handler has returned.
+//
+// Contexts are pooled and reused as soon as the handler chain completes, so
+// passing c itself to a goroutine leads to data races and corrupted values.
+// The copy owns its own request (method, URI, headers, body), route
+// parameters, and stored values, and stays valid indefinitely.
+//
+// The copy is read-only: calling Next or writing a response through it
+// panics, since the original response has already been sent.
+//
+// Example:
+//
+//	cp := c.Copy()
+//	go func() {
+//	    notify(cp.Param("id"), cp.GetHeader("X-Request-ID"))
+//	}()
+func (c *Context) Copy() *Context {
+	native := &fasthttp.RequestCtx{}
+	native.Init(&c.ctx.Request, c.ctx.RemoteAddr(), nil)
+
+	cp := &Context{
+		ctx:     native,
+		zeno:    c.zeno,
+		pnames:  make([]string, len(c.pnames)),
+		pvalues: make([]string, len(c.pvalues)),
+		index:   len(c.handlers),
+		copied:  true,
+	}
+	copy(cp.pnames, c.pnames)
+	for i, v := range c.pvalues {
+		cp.pvalues[i] = strings.Clone(v)
+	}
+	c.data.Range(func(key, value any) bool {
+		cp.data.Store(key, value)
+		return true
+	})
+	return cp
+}
+
+// release poisons c after its request has completed so that any later use
+// panics via mustBeAlive instead of silently reading another request's data.
+func (c *Context) release() {
+	c.released = true
+	c.ctx = nil
+	c.handlers = nil
+	c.pnames = nil
+	clear(c.pvalues)
+	clear(c.fallbackValues)
+	c.afterResponse = nil
+	c.data.Clear()
+}
+
+// mustBeAlive panics if c has been released by the engine. Only contexts
+// created while Zeno.Debug is enabled are ever released.
+func (c *Context) mustBeAlive() {
+	if c.released {
+		panic("zeno: Context used after its request completed; use Context.Copy to pass request data to goroutines")
+	}
+}
+
+// mustBeWritable panics if c has been released or is a read-only copy
+// created by Copy.
+func (c *Context) mustBeWritable() {
+	c.mustBeAlive()
+	if c.copied {
+		panic("zeno: response methods cannot be used on a Context returned by Copy")
+	}
+}
+
 // Status sets the HTTP status code for the response.
 func (c *Context) Status(code int) *Context {
+	c.mustBeWritable()
 	c.ctx.SetStatusCode(code)
 	return c
 }
 
+// NoContent sends 204 No Content with an empty body.
+func (c *Context) NoContent() error {
+	c.Status(StatusNoContent)
+	c.ctx.Response.ResetBody()
+	return nil
+}
+
+// Created sends 201 Created. If location is not empty it is set as the
+// Location header, and if body is not nil it is sent as JSON.
+//
+// Example:
+//
+//	u, _ := c.AbsoluteURL("user.show", "id", user.ID)
+//	return c.Created(u, user)
+func (c *Context) Created(location string, body any) error {
+	c.Status(StatusCreated)
+	if location != "" {
+		c.SetHeader(HeaderLocation, location)
+	}
+	if body == nil {
+		return nil
+	}
+	return c.SendJSON(body)
+}
+
+// BadRequest returns a 400 Bad Request error with an optional message,
+// to be rendered by the ErrorHandler.
+//
+// Example:
+//
+//	if name == "" {
+//	    return c.BadRequest("name is required")
+//	}
+func (c *Context) BadRequest(msg ...string) error {
+	return NewHTTPError(StatusBadRequest, msg...)
+}
+
+// Unauthorized returns a 401 Unauthorized error with an optional message.
+func (c *Context) Unauthorized(msg ...string) error {
+	return NewHTTPError(StatusUnauthorized, msg...)
+}
+
+// Forbidden returns a 403 Forbidden error with an optional message.
+func (c *Context) Forbidden(msg ...string) error {
+	return NewHTTPError(StatusForbidden, msg...)
+}
+
+// NotFound returns a 404 Not Found error with an optional message.
+//
+// Example:
+//
+//	user, ok := users[id]
+//	if !ok {
+//	    return c.NotFound("user not found")
+//	}
+func (c *Context) NotFound(msg ...string) error {
+	return NewHTTPError(StatusNotFound, msg...)
+}
+
 // SendString writes a plain text response body.
 func (c *Context) SendString(value string) error {
+	c.mustBeWritable()
 	c.ctx.Response.SetBodyString(value)
 	return nil
 }
@@ -87,9 +453,10 @@ func (c *Context) SendString(value string) error {
 //	id := ctx.Param("id")              // returns "" if not found
 //	id := ctx.Param("id", "default")   // returns "default" if not found
 func (c *Context) Param(name string, defaultValue ...string) string {
+	c.mustBeAlive()
 	for i, n := range c.pnames {
 		if n == name {
-			return c.pvalues[i]
+			return c.zeno.filterInput(c.pvalues[i])
 		}
 	}
 	if 0 < len(defaultValue) {
@@ -103,11 +470,12 @@ func (c *Context) Param(name string, defaultValue ...string) string {
 // It works like this:
 //
 //   - If *name* exists in the current request’s path parameters, its raw
-//     string is passed to toType[T] for conversion.
+//     string is converted to T using the built-in conversions or a
+//     converter registered with Zeno.RegisterConverter.
 //   - If the parameter is missing or empty, and a *defaultValue* is provided,
 //     the first default value is returned instead.
 //   - If conversion fails, Param returns the zero value of T.
-//     (toType never panics and never returns an error.)
+//     (Use ParamErr to receive the error instead.)
 //
 // Example:
 //
@@ -128,11 +496,54 @@ func Param[T any](c *Context, name string, defaultValue ...T) T {
 	if raw == "" && len(defaultValue) > 0 {
 		return defaultValue[0]
 	}
-	return toType[T](raw)
+	v, _ := parseType[T](raw, c.zeno.converter())
+	return v
+}
+
+// ParamErr returns the value of the route parameter *name* converted to
+// type *T*, or a 400 Bad Request HTTPError if the parameter is missing or
+// cannot be converted. Unlike Param it never silently falls back to the
+// zero value, so handlers can fail fast with a useful message.
+//
+// Example:
+//
+//	id, err := zeno.ParamErr[int](ctx, "id")
+//	if err != nil {
+//	    return err // 400: invalid value for parameter "id": ...
+//	}
+func ParamErr[T any](c *Context, name string) (T, error) {
+	raw := c.Param(name)
+	if raw == "" {
+		var zero T
+		return zero, NewHTTPError(StatusBadRequest, fmt.Sprintf("missing parameter %q", name))
+	}
+	v, err := parseType[T](raw, c.zeno.converter())
+	if err != nil {
+		return v, NewHTTPError(StatusBadRequest, fmt.Sprintf("invalid value for parameter %q: %v", name, err))
+	}
+	return v, nil
+}
+
+// ParamInt returns the route parameter *name* as an int, or a 400 Bad
+// Request HTTPError if it is missing or not a valid integer.
+func (c *Context) ParamInt(name string) (int, error) {
+	return ParamErr[int](c, name)
+}
+
+// ParamUUID returns the route parameter *name* if it is a UUID in the
+// canonical 8-4-4-4-12 hexadecimal form, or a 400 Bad Request HTTPError
+// otherwise. The value is returned in lower case.
+func (c *Context) ParamUUID(name string) (string, error) {
+	raw := c.Param(name)
+	if !isUUID(raw) {
+		return "", NewHTTPError(StatusBadRequest, fmt.Sprintf("invalid UUID for parameter %q", name))
+	}
+	return strings.ToLower(raw), nil
 }
 
 // Params returns a map of all route parameters.
 func (c *Context) Params() map[string]string {
+	c.mustBeAlive()
 	params := map[string]string{}
 	for i, n := range c.pnames {
 		if i < len(c.pvalues) {
@@ -152,11 +563,12 @@ func (c *Context) Params() map[string]string {
 //	name := ctx.Query("name")                   // returns "" if not found
 //	name := ctx.Query("name", "default-name")   // returns "default-name" if not found
 func (c *Context) Query(key string, defaultValue ...string) string {
+	c.mustBeAlive()
 	val := c.ctx.QueryArgs().Peek(key)
 	if len(val) == 0 && len(defaultValue) > 0 {
 		return defaultValue[0]
 	}
-	return c.zeno.toString(val)
+	return c.zeno.filterInput(c.zeno.toString(val))
 }
 
 // Query returns the value of the query parameter *name* converted to type *T*.
@@ -164,11 +576,12 @@ func (c *Context) Query(key string, defaultValue ...string) string {
 // It works like this:
 //
 //   - If *name* exists in the current request’s query string, its raw
-//     string is passed to toType[T] for conversion.
+//     string is converted to T using the built-in conversions or a
+//     converter registered with Zeno.RegisterConverter.
 //   - If the parameter is missing or empty, and a *defaultValue* is provided,
 //     the first default value is returned instead.
 //   - If conversion fails, Query returns the zero value of T.
-//     (toType never panics and never returns an error.)
+//     (Use QueryErr to receive the error instead.)
 //
 // Example:
 //
@@ -189,21 +602,47 @@ func Query[T any](c *Context, name string, defaultValue ...T) T {
 	if raw == "" && len(defaultValue) > 0 {
 		return defaultValue[0]
 	}
-	return toType[T](raw)
+	v, _ := parseType[T](raw, c.zeno.converter())
+	return v
+}
+
+// QueryErr returns the value of the query parameter *name* converted to
+// type *T*, or a 400 Bad Request HTTPError if the parameter is missing or
+// cannot be converted.
+//
+// Example:
+//
+//	limit, err := zeno.QueryErr[int](ctx, "limit")
+//	if err != nil {
+//	    return err
+//	}
+func QueryErr[T any](c *Context, name string) (T, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		var zero T
+		return zero, NewHTTPError(StatusBadRequest, fmt.Sprintf("missing query parameter %q", name))
+	}
+	v, err := parseType[T](raw, c.zeno.converter())
+	if err != nil {
+		return v, NewHTTPError(StatusBadRequest, fmt.Sprintf("invalid value for query parameter %q: %v", name, err))
+	}
+	return v, nil
 }
 
 // QueryArray returns all query values for a given key.
 func (c *Context) QueryArray(key string) []string {
+	c.mustBeAlive()
 	args := c.ctx.QueryArgs().PeekMulti(key)
 	arr := make([]string, len(args))
 	for i, b := range args {
-		arr[i] = c.zeno.toString(b)
+		arr[i] = c.zeno.filterInput(c.zeno.toString(b))
 	}
 	return arr
 }
 
 // QueryMap returns all query parameters as a map.
 func (c *Context) QueryMap() map[string]string {
+	c.mustBeAlive()
 	m := map[string]string{}
 	c.ctx.QueryArgs().VisitAll(func(key, value []byte) {
 		m[c.zeno.toString(key)] = c.zeno.toString(value)
@@ -211,13 +650,113 @@ func (c *Context) QueryMap() map[string]string {
 	return m
 }
 
+// queryTree parses the query string into a valueTree according to the
+// engine's QueryParser configuration.
+func (c *Context) queryTree() valueTree {
+	sep := c.zeno.QueryParser.Separator
+	tree := valueTree{}
+	c.ctx.QueryArgs().VisitAll(func(key, value []byte) {
+		tree.add(string(key), string(value), sep)
+	})
+	return tree
+}
+
+// QueryNested returns the query string as a nested structure, following
+// the bracket conventions of Rails and the qs library:
+//
+//	?filter[status]=open&filter[tag]=go&ids[]=1&ids[]=2&q=zeno
+//
+// yields
+//
+//	map[string]any{
+//	    "filter": map[string]any{"status": "open", "tag": "go"},
+//	    "ids":    []string{"1", "2"},
+//	    "q":      "zeno",
+//	}
+//
+// Keys with a single value map to a string, repeated keys to a []string.
+// When Zeno.QueryParser.Separator is set, values are also split on it.
+func (c *Context) QueryNested() map[string]any {
+	return c.queryTree().simplify()
+}
+
+// BindQuery decodes the query string into out, which must be a pointer to
+// a struct or map. Struct fields are matched by their `query:"..."` tag or
+// name, nested structs and maps are filled from bracket keys, and slices
+// from repeated keys (see QueryNested). A 400 Bad Request error is
+// returned if a value cannot be converted to its field type.
+//
+// Example:
+//
+//	type Search struct {
+//	    Filter struct {
+//	        Status string `query:"status"`
+//	    } `query:"filter"`
+//	    IDs  []int `query:"ids"`
+//	    Page int   `query:"page"`
+//	}
+//
+//	var s Search
+//	if err := c.BindQuery(&s); err != nil {
+//	    return err
+//	}
+func (c *Context) BindQuery(out any) error {
+	return c.bindQuery(out, bindTree)
+}
+
+// bindQuery decodes the query string into out with bind, either bindTree
+// or bindTaggedTree.
+func (c *Context) bindQuery(out any, bind func(any, valueTree, string, converter) error) error {
+	if err := bind(out, c.queryTree(), "query", c.zeno.converter()); err != nil {
+		return NewHTTPError(StatusBadRequest, "Invalid query: "+err.Error())
+	}
+	return nil
+}
+
+// BindParams decodes the route parameters into out, which must be a
+// pointer to a struct or map. Struct fields are matched by their
+// `param:"..."` tag or name. A 400 Bad Request error is returned if a
+// value cannot be converted to its field type.
+//
+// Example:
+//
+//	// app.Get("/users/{id}/posts/{slug}", ...)
+//	var p struct {
+//	    ID   int    `param:"id"`
+//	    Slug string `param:"slug"`
+//	}
+//	if err := c.BindParams(&p); err != nil {
+//	    return err
+//	}
+func (c *Context) BindParams(out any) error {
+	return c.bindParams(out, bindTree)
+}
+
+// bindParams decodes the route parameters into out with bind, either
+// bindTree or bindTaggedTree.
+func (c *Context) bindParams(out any, bind func(any, valueTree, string, converter) error) error {
+	c.mustBeAlive()
+	tree := valueTree{}
+	for i, n := range c.pnames {
+		if i < len(c.pvalues) {
+			tree[n] = []string{c.pvalues[i]}
+		}
+	}
+	if err := bind(out, tree, "param", c.zeno.converter()); err != nil {
+		return NewHTTPError(StatusBadRequest, "Invalid parameter: "+err.Error())
+	}
+	return nil
+}
+
 // Method returns the HTTP method of the request.
 func (c *Context) Method() string {
+	c.mustBeAlive()
 	return c.zeno.toString(c.ctx.Method())
 }
 
 // Path returns the request URL path.
 func (c *Context) Path() string {
+	c.mustBeAlive()
 	return c.zeno.toString(c.ctx.Path())
 }
 
@@ -250,14 +789,66 @@ func (c *Context) GetForwardedIPs() []string {
 
 // GetHeader returns the value of the specified request header.
 func (c *Context) GetHeader(key string) string {
+	c.mustBeAlive()
 	return c.zeno.toString(c.ctx.Request.Header.Peek(key))
 }
 
 // SetHeader sets the HTTP response header with the given key and value.
 func (c *Context) SetHeader(key, value string) {
+	c.mustBeWritable()
 	c.ctx.Response.Header.Set(key, value)
 }
 
+// Vary adds the given request header names to the Vary response header,
+// skipping names that are already listed (compared case-insensitively).
+//
+// Example:
+//
+//	c.Vary(zeno.HeaderAccept, zeno.HeaderAcceptEncoding)
+func (c *Context) Vary(headers ...string) {
+	current := c.zeno.toString(c.ctx.Response.Header.Peek(HeaderVary))
+	values := make([]string, 0, len(headers)+1)
+	if current != "" {
+		values = append(values, current)
+	}
+	for _, h := range headers {
+		dup := false
+		for v := range strings.SplitSeq(strings.Join(values, ","), ",") {
+			if v = strings.TrimSpace(v); v == "*" || strings.EqualFold(v, h) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			values = append(values, h)
+		}
+	}
+	if len(values) > 0 {
+		c.SetHeader(HeaderVary, strings.Join(values, ", "))
+	}
+}
+
+// CacheControl sets the Cache-Control response header from cc, replacing
+// any existing value.
+//
+// Example:
+//
+//	c.CacheControl(zeno.CacheControl{
+//	    Public:               true,
+//	    MaxAge:               time.Minute,
+//	    StaleWhileRevalidate: 30 * time.Second,
+//	})
+func (c *Context) CacheControl(cc CacheControl) {
+	c.SetHeader(HeaderCacheControl, cc.String())
+}
+
+// NoCache marks the response as not cacheable by browsers or proxies.
+func (c *Context) NoCache() {
+	c.SetHeader(HeaderCacheControl, "no-store, no-cache, must-revalidate")
+	c.SetHeader(HeaderPragma, "no-cache")
+	c.SetHeader(HeaderExpires, "0")
+}
+
 // RealIP returns the client's real IP address, considering X-Forwarded-For.
 func (c *Context) RealIP() string {
 	xForwardedFor := c.GetHeader(HeaderForwardedFor)
@@ -281,11 +872,12 @@ func (c *Context) HeaderMap() map[string]string {
 
 // FormValue returns the value of a form field or a default if not present.
 func (c *Context) FormValue(key string, defaultValue ...string) string {
+	c.mustBeAlive()
 	val := c.ctx.FormValue(key)
 	if len(val) == 0 && len(defaultValue) > 0 {
 		return defaultValue[0]
 	}
-	return c.zeno.toString(val)
+	return c.zeno.filterInput(c.zeno.toString(val))
 }
 
 // FormFile returns the uploaded file header for the given form key.
@@ -300,11 +892,29 @@ func (c *Context) MultipartForm() (*multipart.Form, error) {
 
 // Body returns the raw request body.
 func (c *Context) Body() []byte {
+	c.mustBeAlive()
 	return c.ctx.Request.Body()
 }
 
+// BodyReader returns the request body as an io.Reader.
+//
+// When Zeno.StreamRequestBody is enabled the reader streams the body from
+// the connection as it is consumed, so large uploads never have to be held
+// in memory. Otherwise it simply reads from the already buffered body.
+//
+// Example:
+//
+//	n, err := io.Copy(dst, c.BodyReader())
+func (c *Context) BodyReader() io.Reader {
+	if c.ctx.Request.IsBodyStream() {
+		return c.ctx.RequestBodyStream()
+	}
+	return bytes.NewReader(c.ctx.Request.Body())
+}
+
 // PostBody returns the POST request body.
 func (c *Context) PostBody() []byte {
+	c.mustBeAlive()
 	return c.ctx.PostBody()
 }
 
@@ -313,65 +923,55 @@ func (c *Context) IsAJAX() bool {
 	return c.GetHeader("X-Requested-With") == "XMLHttpRequest"
 }
 
-type acceptItem struct {
-	value string
-	q     float64 // Quality factor
+// AcceptEntries returns the parsed Accept header, ordered by preference.
+// It is useful when the built-in negotiation helpers are not flexible
+// enough, e.g. to inspect media type parameters such as "version".
+func (c *Context) AcceptEntries() []AcceptEntry {
+	return ParseAccept(c.GetHeader(HeaderAccept))
 }
 
-func parseAccept(header string) []acceptItem {
-	parts := strings.Split(header, ",")
-	items := make([]acceptItem, 0, len(parts))
-
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		q := 1.0
-		if idx := strings.Index(part, ";q="); idx != -1 {
-			qValStr := part[idx+3:]
-			part = part[:idx]
-			if qVal, err := strconv.ParseFloat(qValStr, 64); err == nil {
-				q = qVal
-			}
-		}
-		items = append(items, acceptItem{value: strings.ToLower(part), q: q})
-	}
-
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].q > items[j].q
-	})
-
-	return items
+// Accepts returns the best match from the offers based on the Accept header.
+//
+// Negotiation follows RFC 7231: each offer is weighted by the q-value of
+// the most specific range that matches it (exact type with parameters,
+// then exact type, then "type/*", then "*/*"), ranges with q=0 exclude an
+// offer, and ties are broken by specificity and then by offer order.
+func (c *Context) Accepts(offers ...string) string {
+	return matchAccept(c.GetHeader(HeaderAccept), offers)
 }
 
-func matchAccept(header string, offers []string) string {
-	if header == "" || len(offers) == 0 {
-		return ""
+// Format runs the handler whose media type best matches the request's
+// Accept header, simplifying endpoints that serve both HTML and JSON.
+// Keys of offers are media types such as "text/html" or "application/json".
+//
+// A "Vary: Accept" header is added. When the request has no Accept header,
+// the first offer in lexical order is used; when nothing matches, a 406 Not
+// Acceptable error is returned.
+//
+// Example:
+//
+//	return c.Format(map[string]zeno.Handler{
+//	    "text/html":        func(c *zeno.Context) error { return c.Render("user.html", u) },
+//	    "application/json": func(c *zeno.Context) error { return c.SendJSON(u) },
+//	})
+func (c *Context) Format(offers map[string]Handler) error {
+	types := make([]string, 0, len(offers))
+	for t := range offers {
+		types = append(types, t)
 	}
+	sort.Strings(types)
+	c.Vary(HeaderAccept)
 
-	accepted := parseAccept(header)
-	offersLower := make([]string, len(offers))
-	for i, o := range offers {
-		offersLower[i] = strings.ToLower(o)
+	if len(types) == 0 {
+		return ErrNotAcceptable
 	}
-
-	for _, acc := range accepted {
-		for i, offer := range offersLower {
-			if acc.value == offer || acc.value == "*" {
-				return offers[i]
-			}
-			if strings.HasSuffix(acc.value, "/*") {
-				prefix := strings.TrimSuffix(acc.value, "*")
-				if strings.HasPrefix(offer, prefix) {
-					return offers[i]
-				}
-			}
-		}
+	if c.GetHeader(HeaderAccept) == "" {
+		return offers[types[0]](c)
 	}
-	return ""
-}
-
-// Accepts returns the best match from the offers based on the Accept header.
-func (c *Context) Accepts(offers ...string) string {
-	return matchAccept(c.GetHeader(HeaderAccept), offers)
+	if best := c.Accepts(types...); best != "" {
+		return offers[best](c)
+	}
+	return ErrNotAcceptable
 }
 
 // AcceptsCharset returns the best match from the offers based on Accept-Charset.
@@ -390,10 +990,27 @@ func (c *Context) AcceptsLanguage(offers ...string) string {
 }
 
 // Protocol returns the request protocol version (e.g., HTTP/1.1).
+//
+// For TLS connections on which the client negotiated HTTP/2 via ALPN,
+// "HTTP/2.0" is reported even if the serving adapter did not rewrite the
+// request line.
 func (c *Context) Protocol() string {
+	if c.NegotiatedProtocol() == "h2" {
+		return "HTTP/2.0"
+	}
 	return c.zeno.toString(c.ctx.Request.Header.Protocol())
 }
 
+// NegotiatedProtocol returns the application protocol agreed on via TLS
+// ALPN (such as "h2" or "http/1.1"), or "" for plain connections and when
+// no protocol was negotiated.
+func (c *Context) NegotiatedProtocol() string {
+	if state := c.TLSConnectionState(); state != nil {
+		return state.NegotiatedProtocol
+	}
+	return ""
+}
+
 // Scheme returns the request scheme, "http" or "https".
 func (c *Context) Scheme() string {
 	if c.ctx.IsTLS() {
@@ -407,6 +1024,13 @@ func (c *Context) IsSecure() bool {
 	return c.ctx.IsTLS()
 }
 
+// TLSConnectionState returns the state of the TLS connection the request
+// arrived on, including the certificates presented by the client, or nil
+// for plain HTTP.
+func (c *Context) TLSConnectionState() *tls.ConnectionState {
+	return c.ctx.TLSConnectionState()
+}
+
 // HTTPRange represents a parsed byte range from the Range header.
 type HTTPRange struct {
 	Start, End int64
@@ -424,7 +1048,13 @@ func (c *Context) Ranges(maxSize int64) (*Range, error) {
 	if header == "" {
 		return nil, errors.New("no Range header")
 	}
+	return ParseRange(header, maxSize)
+}
 
+// ParseRange parses a Range header value for a representation of maxSize
+// bytes. Ranges are clamped to the representation and unsatisfiable ones
+// dropped; an error is returned if none remain or the header is malformed.
+func ParseRange(header string, maxSize int64) (*Range, error) {
 	parts := strings.SplitN(header, "=", 2)
 	if len(parts) != 2 {
 		return nil, errors.New("invalid Range header format")
@@ -507,6 +1137,8 @@ func max(a, b int64) int64 {
 //
 // This method is typically used when you already have the response
 // body as a raw byte slice, such as when serving JSON, Yaml, or binary data.
+// b must not be modified afterwards unless Zeno.BufferResponses is set, in
+// which case it is copied.
 //
 // Example:
 //
@@ -515,23 +1147,54 @@ func max(a, b int64) int64 {
 //	    // handle error
 //	}
 func (c *Context) SendBytes(b []byte) error {
+	c.mustBeWritable()
+	if c.zeno.BufferResponses {
+		c.ctx.Response.SetBody(b)
+		return nil
+	}
 	c.ctx.Response.SetBodyRaw(b)
 	return nil
 }
 
 // SendStatusCode sets the HTTP response status code to the given `code`.
-// It does not modify the response body unless the body is currently empty,
-// in which case it sets the body to the default status text.
+// It is equivalent to SendStatus.
 //
 // Example:
 //
 //	ctx.SendStatusCode(fasthttp.StatusNoContent) // sets 204 No Content
 func (c *Context) SendStatusCode(code int) error {
-	c.ctx.SetStatusCode(code)
-	if len(c.ctx.Response.Body()) == 0 {
-		return c.SendString(StatusMessage(code))
+	return c.SendStatus(code)
+}
+
+// SendStatus sets the response status code and makes sure the body fits
+// it:
+//
+//   - for 1xx, 204 No Content and 304 Not Modified, which must not carry a
+//     body, any body is removed;
+//   - otherwise a body that was already written is left untouched;
+//   - otherwise the status text is sent, as JSON ({"status":404,
+//     "message":"Not Found"}) when the client prefers application/json and
+//     as plain text otherwise.
+//
+// Set Zeno.DisableStatusBody to never add a body automatically.
+//
+// Example:
+//
+//	return c.SendStatus(StatusAccepted)
+func (c *Context) SendStatus(code int) error {
+	c.Status(code)
+	if code < 200 || code == StatusNoContent || code == StatusNotModified {
+		c.ctx.Response.ResetBody()
+		return nil
 	}
-	return nil
+	if c.zeno.DisableStatusBody || len(c.ctx.Response.Body()) > 0 {
+		return nil
+	}
+	if c.Accepts("text/plain", "application/json") == "application/json" {
+		return c.SendJSON(httpError{Status: code, Message: StatusMessage(code)})
+	}
+	c.setMediaType("text/plain", nil)
+	return c.SendString(StatusMessage(code))
 }
 
 // SetContentType sets the “Content‑Type” response header.
@@ -549,16 +1212,78 @@ func (c *Context) SetContentType(value string) {
 // "text/html; charset=utf-8", then delegates to SendString to transmit the
 // body.  Useful for quick inline responses or template output.
 //
+// The value is sent verbatim; never concatenate untrusted input into it.
+// Use EscapeHTML or SendHTMLSafe instead.
+//
 // Example:
 //
 //	if err := ctx.SendHTML("<h1>Hello, Zeno!</h1>"); err != nil {
 //	    // handle error
 //	}
 func (c *Context) SendHTML(value string) error {
-	c.SetContentType("text/html; charset=utf-8")
+	c.setMediaType("text/html", nil)
 	return c.SendString(value)
 }
 
+// Render executes the template called name with data using the configured
+// Zeno.Renderer and writes the result as an HTML response.
+//
+// A 500 Internal Server Error is returned if no renderer is configured or
+// if template execution fails. The response body is only written once the
+// template has rendered successfully.
+//
+// Example:
+//
+//	return c.Render("users.html", users)
+func (c *Context) Render(name string, data any) error {
+	if c.zeno.Renderer == nil {
+		return NewHTTPError(StatusInternalServerError, "No renderer configured")
+	}
+	var buf bytes.Buffer
+	if err := c.zeno.Renderer.Render(&buf, name, data); err != nil {
+		return NewHTTPError(StatusInternalServerError, "Failed to render template: "+err.Error())
+	}
+	c.setMediaType("text/html", nil)
+	return c.SendBytes(buf.Bytes())
+}
+
+// RenderPartial renders a single fragment, such as a {{block}} or {{define}}
+// inside a larger page, and writes it as an HTML response.
+//
+// It behaves exactly like Render; the separate name documents intent at the
+// call site and pairs with SendTemplate for htmx-style partial updates.
+//
+// Example:
+//
+//	return c.RenderPartial("rows", users)
+func (c *Context) RenderPartial(name string, data any) error {
+	return c.Render(name, data)
+}
+
+// IsHTMX reports whether the request was issued by htmx, i.e. it carries
+// an "HX-Request: true" header.
+func (c *Context) IsHTMX() bool {
+	return c.GetHeader(HeaderHXRequest) == "true"
+}
+
+// SendTemplate renders either a full page or one of its fragments depending
+// on who is asking. htmx requests (see IsHTMX) receive the fragment, while
+// regular navigations receive the full page. When fragment is empty the
+// page is always rendered.
+//
+// A "Vary: HX-Request" header is added so caches keep both variants apart.
+//
+// Example:
+//
+//	return c.SendTemplate("users.html", "rows", users)
+func (c *Context) SendTemplate(page, fragment string, data any) error {
+	c.Vary(HeaderHXRequest)
+	if fragment != "" && c.IsHTMX() {
+		return c.RenderPartial(fragment, data)
+	}
+	return c.Render(page, data)
+}
+
 // SendFile streams the file located at the specified path to the client.
 //
 // This method uses fasthttp’s zero-copy `ctx.SendFile` under the hood,
@@ -568,14 +1293,104 @@ func (c *Context) SendHTML(value string) error {
 // The Content-Type header is automatically set based on the file’s extension using
 // fasthttp’s internal MIME type detection.
 //
+// An ETag is set and If-Match, If-Unmodified-Since, If-None-Match and
+// If-Modified-Since are evaluated, answering 304 Not Modified or 412
+// Precondition Failed as appropriate. Range requests yield 206 Partial
+// Content unless an If-Range validator no longer matches, in which case the
+// full file is sent.
+//
 // Any I/O errors encountered during file transmission are handled internally by fasthttp,
 // and thus SendFile always returns nil.
 //
+// When Zeno.FS is set, the file is read from it through SendFS instead.
+//
 // Example:
 //
 //	err := ctx.SendFile("static/image.png")
 func (c *Context) SendFile(path string) error {
-	c.ctx.SendFile(path)
+	if c.zeno.FS != nil {
+		return c.SendFS(c.zeno.FS, path)
+	}
+	c.sendOSFile(path)
+	return nil
+}
+
+// SendFS streams the file name from fsys to the client, so responses can
+// come from embedded, in-memory or remote file systems as well as disk.
+//
+// The Content-Type is derived from the file extension. Last-Modified (when
+// the file reports a modification time) and an ETag built from the size and
+// modification time are set, and conditional requests are evaluated as for
+// SendFile. Files without a modification time, such as those of embed.FS,
+// get an ETag hashed from their content instead, when they implement
+// io.Seeker. Single byte ranges are served with 206 Partial Content when the
+// file implements io.Seeker.
+//
+// A 404 Not Found is returned if the file does not exist or is a directory.
+//
+// Example:
+//
+//	//go:embed assets
+//	var assets embed.FS
+//
+//	app.Get("/logo", func(c *zeno.Context) error {
+//	    return c.SendFS(assets, "assets/logo.png")
+//	})
+func (c *Context) SendFS(fsys fs.FS, name string) error {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "."
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+			return ErrNotFound
+		}
+		return NewHTTPError(StatusInternalServerError, "failed to open file: "+err.Error())
+	}
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
+		f.Close()
+		if err != nil {
+			return NewHTTPError(StatusInternalServerError, "failed to stat file: "+err.Error())
+		}
+		return ErrNotFound
+	}
+
+	modTime := fi.ModTime()
+	etag := fileETag(fi)
+	if modTime.IsZero() {
+		if etag, err = contentHashETag(f); err != nil {
+			f.Close()
+			return NewHTTPError(StatusInternalServerError, "failed to read file: "+err.Error())
+		}
+	}
+	if etag != "" {
+		c.SetHeader(HeaderETag, etag)
+	}
+	c.SetHeader(HeaderAcceptRanges, "bytes")
+	if !modTime.IsZero() {
+		c.SetHeader(HeaderLastModified, modTime.UTC().Format(http.TimeFormat))
+	}
+	if c.checkPreconditions(etag, modTime) {
+		f.Close()
+		return nil
+	}
+
+	ct := mime.TypeByExtension(path.Ext(name))
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	c.SetContentType(ct)
+	if rs, ok := f.(io.ReadSeeker); ok {
+		if sent, err := c.sendRange(rs, fi.Size(), etag, modTime); sent {
+			if err != nil || c.ctx.Response.StatusCode() != StatusPartialContent {
+				f.Close()
+			}
+			return err
+		}
+	}
+	c.ctx.SetBodyStream(f, int(fi.Size()))
 	return nil
 }
 
@@ -598,11 +1413,7 @@ func (c *Context) SendHeader(key, value string) error {
 //	return c.SendJSON(data)
 //	return c.SendJSON(data, "application/vnd.api+json")
 func (c *Context) SendJSON(value any, ctype ...string) error {
-	contentType := "application/json"
-	if len(ctype) > 0 {
-		contentType = ctype[0]
-	}
-	c.SetContentType(contentType)
+	c.setMediaType("application/json", ctype)
 
 	bytes, err := c.zeno.JsonEncoder(value)
 	if err != nil {
@@ -611,21 +1422,79 @@ func (c *Context) SendJSON(value any, ctype ...string) error {
 	return c.SendBytes(bytes)
 }
 
+// SendJSONStream encodes the given value as JSON directly into the response
+// body stream instead of building the whole payload in memory first. It is
+// intended for multi-megabyte responses where SendJSON's intermediate
+// []byte would be expensive.
+//
+// It sets the Content-Type to "application/json" unless overridden with the
+// optional ctype argument. Encoding happens after the handler returns, while
+// fasthttp writes the response, so:
+//
+//   - v must not reference data owned by the pooled Context (parameters,
+//     header values, the request body); copy such values first.
+//   - encoding errors cannot change the status code any more and simply
+//     truncate the response.
+//
+// The encoder used is Zeno().JsonStreamEncoder, or sonic's streaming
+// encoder if it is nil.
+//
+// Example:
+//
+//	return c.SendJSONStream(hugeReport)
+func (c *Context) SendJSONStream(value any, ctype ...string) error {
+	c.setMediaType("application/json", ctype)
+
+	encode := c.zeno.JsonStreamEncoder
+	if encode == nil {
+		encode = sonicStreamEncoder
+	}
+	c.setBodyStreamWriter(func(w *bufio.Writer) {
+		if err := encode(w, value); err != nil {
+			return
+		}
+		_ = w.Flush()
+	})
+	return nil
+}
+
 // BindJSON decodes the JSON request body into the provided destination structure.
 // Returns an error if the body is empty or invalid.
 //
+// Content-Type and unknown field checks follow Zeno().JSONBinding unless a
+// config is passed, which replaces it for this call.
+//
 // Example:
 //
 //	var req UserInput
 //	if err := c.BindJSON(&req); err != nil {
 //	    return err
 //	}
-func (c *Context) BindJSON(out any) error {
+//
+//	// Reject typos in field names for this endpoint only
+//	err := c.BindJSON(&req, zeno.JSONBindingConfig{DisallowUnknownFields: true})
+func (c *Context) BindJSON(out any, config ...JSONBindingConfig) error {
+	cfg := c.zeno.JSONBinding
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.RequireContentType {
+		if mt, _ := c.ContentType(); !isJSONMediaType(mt) {
+			return NewHTTPError(StatusUnsupportedMediaType, "Content-Type must be application/json")
+		}
+	}
 	body := c.PostBody()
 	if len(body) == 0 {
 		return NewHTTPError(StatusBadRequest, "Request body is empty")
 	}
-	if err := c.zeno.JsonDecoder(body, out); err != nil {
+	if err := c.checkCharset(); err != nil {
+		return err
+	}
+	decode := c.zeno.JsonDecoder
+	if cfg.DisallowUnknownFields {
+		decode = c.zeno.JsonStrictDecoder
+	}
+	if err := decode(body, out); err != nil {
 		return NewHTTPError(StatusBadRequest, "Invalid JSON: "+err.Error())
 	}
 	return nil
@@ -645,7 +1514,7 @@ func (c *Context) SendJSONP(value any, callback ...string) error {
 	if len(callback) > 0 {
 		cback = callback[0]
 	}
-	c.SetContentType("application/javascript")
+	c.setMediaType("application/javascript", nil)
 	bytes, err := c.zeno.JsonEncoder(value)
 	if err != nil {
 		return NewHTTPError(StatusInternalServerError, "Failed to encode JSON: "+err.Error())
@@ -679,11 +1548,7 @@ func (c *Context) SendPrettyJSON(value any, ctype ...string) error {
 //
 //	err := c.SendJSONIndent(data, "--", ">>", "application/vnd.api+json")
 func (c *Context) SendJSONIndent(value any, prefix, indent string, ctype ...string) error {
-	contentType := "application/json"
-	if len(ctype) > 0 {
-		contentType = ctype[0]
-	}
-	c.SetContentType(contentType)
+	c.setMediaType("application/json", ctype)
 
 	bytes, err := c.zeno.JsonIndent(value, prefix, indent)
 	if err != nil {
@@ -700,11 +1565,7 @@ func (c *Context) SendJSONIndent(value any, prefix, indent string, ctype ...stri
 //
 //	return c.SendSecureJSON(data)
 func (c *Context) SendSecureJSON(value any, ctype ...string) error {
-	contentType := "application/json"
-	if len(ctype) > 0 {
-		contentType = ctype[0]
-	}
-	c.SetContentType(contentType)
+	c.setMediaType("application/json", ctype)
 
 	b, err := c.zeno.JsonEncoder(value)
 	if err != nil {
@@ -734,11 +1595,7 @@ func (c *Context) SendSecureJSON(value any, ctype ...string) error {
 //
 //	return c.SendXML(User{Name: "Alice"})
 func (c *Context) SendXML(value any, ctype ...string) error {
-	contentType := "application/xml; charset=utf-8"
-	if len(ctype) > 0 {
-		contentType = ctype[0]
-	}
-	c.SetContentType(contentType)
+	c.setMediaType("application/xml", ctype)
 
 	b, err := c.zeno.XmlEncoder(value)
 	if err != nil {
@@ -763,11 +1620,7 @@ func (c *Context) SendXML(value any, ctype ...string) error {
 //
 //	return c.SendXMLIndent(User{Name: "Alice"}, "", "  ")
 func (c *Context) SendXMLIndent(value any, prefix, indent string, ctype ...string) error {
-	contentType := "application/xml; charset=utf-8"
-	if len(ctype) > 0 {
-		contentType = ctype[0]
-	}
-	c.SetContentType(contentType)
+	c.setMediaType("application/xml", ctype)
 
 	b, err := c.zeno.XmlIndent(value, prefix, indent)
 	if err != nil {
@@ -793,6 +1646,9 @@ func (c *Context) BindXML(out any) error {
 	if len(body) == 0 {
 		return NewHTTPError(StatusBadRequest, "Request body is empty")
 	}
+	if err := c.checkCharset(); err != nil {
+		return err
+	}
 	if err := c.zeno.XmlDecoder(body, out); err != nil {
 		return NewHTTPError(StatusBadRequest, "Invalid XML: "+err.Error())
 	}
@@ -823,6 +1679,9 @@ func (c *Context) BindYAML(out any) error {
 	if len(body) == 0 {
 		return NewHTTPError(StatusBadRequest, "Request body is empty")
 	}
+	if err := c.checkCharset(); err != nil {
+		return err
+	}
 	if err := c.zeno.YamlDecoder(body, out); err != nil {
 		return NewHTTPError(StatusBadRequest, "Invalid YAML: "+err.Error())
 	}
@@ -851,11 +1710,7 @@ func (c *Context) BindYAML(out any) error {
 //	    ...
 //	}
 func (c *Context) SendYAML(v any, ctype ...string) error {
-	contentType := "application/yaml; charset=utf-8"
-	if len(ctype) > 0 {
-		contentType = ctype[0]
-	}
-	c.SetContentType(contentType)
+	c.setMediaType("application/yaml", ctype)
 	bytes, err := c.zeno.YamlEncoder(v)
 	if err != nil {
 		return NewHTTPError(StatusInternalServerError, "Failed to encode YAML: "+err.Error())
@@ -887,6 +1742,9 @@ func (c *Context) BindTOML(out any) error {
 	if len(body) == 0 {
 		return NewHTTPError(StatusBadRequest, "Request body is empty")
 	}
+	if err := c.checkCharset(); err != nil {
+		return err
+	}
 	if err := c.zeno.TomlDecoder(body, out); err != nil {
 		return NewHTTPError(StatusBadRequest, "Invalid TOML: "+err.Error())
 	}
@@ -915,11 +1773,7 @@ func (c *Context) BindTOML(out any) error {
 //	    ...
 //	}
 func (c *Context) SendTOML(v any, ctype ...string) error {
-	contentType := "application/toml; charset=utf-8"
-	if len(ctype) > 0 {
-		contentType = ctype[0]
-	}
-	c.SetContentType(contentType)
+	c.setMediaType("application/toml", ctype)
 	bytes, err := c.zeno.TomlEncoder(v)
 	if err != nil {
 		return NewHTTPError(StatusInternalServerError, "Failed to encode TOML: "+err.Error())
@@ -972,11 +1826,7 @@ func (c *Context) BindCBOR(out any) error {
 //	    ...
 //	}
 func (c *Context) SendCBOR(v any, ctype ...string) error {
-	contentType := "application/cbor"
-	if len(ctype) > 0 {
-		contentType = ctype[0]
-	}
-	c.SetContentType(contentType)
+	c.setMediaType("application/cbor", ctype)
 	bytes, err := c.zeno.CborEncoder(v)
 	if err != nil {
 		return NewHTTPError(StatusInternalServerError, "Failed to encode CBOR: "+err.Error())
@@ -984,6 +1834,80 @@ func (c *Context) SendCBOR(v any, ctype ...string) error {
 	return c.SendBytes(bytes)
 }
 
+// SendCSV encodes v as CSV and writes it to the response.
+//
+// v may be a [][]string, written as-is, or a slice of structs whose
+// exported fields become columns. Column names come from the `csv:"..."`
+// tag or the field name; fields tagged `csv:"-"` are skipped and a header
+// row is always written first.
+//
+// The Content-Type is set to "text/csv; charset=utf-8". When a filename is
+// given, a Content-Disposition header is added so browsers download the
+// response as an attachment. A 500 Internal Server Error is returned if
+// encoding fails.
+//
+// Example:
+//
+//	type Row struct {
+//	    ID   int    `csv:"id"`
+//	    Name string `csv:"name"`
+//	}
+//
+//	return c.SendCSV([]Row{{1, "Alice"}}, "users.csv")
+func (c *Context) SendCSV(v any, filename ...string) error {
+	records, err := encodeCSV(v)
+	if err != nil {
+		return NewHTTPError(StatusInternalServerError, "Failed to encode CSV: "+err.Error())
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return NewHTTPError(StatusInternalServerError, "Failed to encode CSV: "+err.Error())
+	}
+
+	c.setMediaType("text/csv", nil)
+	if len(filename) > 0 && filename[0] != "" {
+		c.SetHeader(HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{
+			"filename": filename[0],
+		}))
+	}
+	return c.SendBytes(buf.Bytes())
+}
+
+// BindCSV reads the request body, parses it as CSV, and stores the records
+// in out.
+//
+// out must be a pointer to a [][]string or to a slice of structs. For
+// structs the first row is treated as a header and columns are matched to
+// fields by their `csv:"..."` tag or name, ignoring case; unknown columns
+// are skipped. A 400 Bad Request error is returned if the body is empty,
+// malformed, or a value cannot be converted to its field type.
+//
+// Example:
+//
+//	var rows []Row
+//	if err := c.BindCSV(&rows); err != nil {
+//	    return err
+//	}
+func (c *Context) BindCSV(out any) error {
+	body := c.PostBody()
+	if len(body) == 0 {
+		return NewHTTPError(StatusBadRequest, "Request body is empty")
+	}
+	if err := c.checkCharset(); err != nil {
+		return err
+	}
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		return NewHTTPError(StatusBadRequest, "Invalid CSV: "+err.Error())
+	}
+	if err := decodeCSV(records, out, c.zeno.converter()); err != nil {
+		return NewHTTPError(StatusBadRequest, "Invalid CSV: "+err.Error())
+	}
+	return nil
+}
+
 // BindString binds the raw request body to the given string pointer.
 // It returns a 400 Bad Request error if the body is empty.
 //
@@ -1001,6 +1925,9 @@ func (c *Context) BindString(out *string) error {
 	if len(body) == 0 {
 		return NewHTTPError(StatusBadRequest, "Request body is empty")
 	}
+	if err := c.checkCharset(); err != nil {
+		return err
+	}
 	*out = c.zeno.toString(body)
 	return nil
 }
@@ -1021,6 +1948,55 @@ func (c *Context) Redirect(url string, code ...int) error {
 	return nil
 }
 
+// RedirectPermanent redirects to url with 301 Moved Permanently.
+func (c *Context) RedirectPermanent(url string) error {
+	return c.Redirect(url, StatusMovedPermanently)
+}
+
+// RedirectTemporary redirects to url with 307 Temporary Redirect, which
+// unlike 302 requires the client to repeat the request method and body.
+func (c *Context) RedirectTemporary(url string) error {
+	return c.Redirect(url, StatusTemporaryRedirect)
+}
+
+// RedirectToRoute redirects to the named route with 302 Found, building the
+// path with Route.URLE so redirects keep working when paths change. A
+// trailing url.Values is encoded as the query string. A missing route or
+// parameter results in a 500 error.
+//
+// Example:
+//
+//	return c.RedirectToRoute("user.show", "id", 42)
+func (c *Context) RedirectToRoute(name string, pairs ...any) error {
+	u, err := c.zeno.URL(name, pairs...)
+	if err != nil {
+		return NewHTTPError(StatusInternalServerError, "Redirect failed: "+err.Error())
+	}
+	return c.Redirect(u)
+}
+
+// RedirectBack redirects to the page named in the Referer header, or to
+// fallback when the header is missing or points to another host, including
+// through a path starting with "//" or "/\", so it cannot be abused as an
+// open redirect.
+//
+// Example:
+//
+//	return c.RedirectBack("/dashboard")
+func (c *Context) RedirectBack(fallback string) error {
+	ref := c.GetHeader(HeaderReferer)
+	if ref != "" {
+		if u, err := url.Parse(ref); err == nil && (u.Host == "" || u.Host == c.Host()) {
+			// Browsers read "//host" and "/\host" as another host.
+			if !strings.HasPrefix(u.Path, "/") || strings.HasPrefix(u.Path, "//") || strings.HasPrefix(u.Path, "/\\") {
+				return c.Redirect(fallback)
+			}
+			return c.Redirect(u.RequestURI())
+		}
+	}
+	return c.Redirect(fallback)
+}
+
 // Host returns the host part of the request, from the Host header.
 func (c *Context) Host() string {
 	return c.zeno.toString(c.ctx.Host())
@@ -1034,11 +2010,36 @@ func (c *Context) WriteString(s string) (int, error) {
 	return c.ctx.WriteString(s)
 }
 
+// Write appends p to the response body, making Context an io.Writer.
+//
+// This lets libraries that render into writers (template engines, encoders,
+// image libraries) target the response directly:
+//
+//	return tmpl.Execute(c, data)
+func (c *Context) Write(p []byte) (int, error) {
+	c.mustBeWritable()
+	return c.ctx.Write(p)
+}
+
+// BodyWriter returns an io.Writer that appends to the response body.
+//
+// Unlike Write on the Context itself, the returned writer does not expose
+// the rest of the Context API, which is handy when passing it to code that
+// should only be able to produce output.
+//
+// Example:
+//
+//	err := png.Encode(c.BodyWriter(), img)
+func (c *Context) BodyWriter() io.Writer {
+	return c.ctx.Response.BodyWriter()
+}
+
 // Request returns the underlying *fasthttp.Request object.
 //
 // You can use it to access low-level request information such as headers,
 // URI, body, method, and more.
 func (c *Context) Request() *fasthttp.Request {
+	c.mustBeAlive()
 	return &c.ctx.Request
 }
 
@@ -1047,6 +2048,7 @@ func (c *Context) Request() *fasthttp.Request {
 // It allows you to inspect or modify the response before it is sent to the client,
 // including headers, status code, and body.
 func (c *Context) Response() *fasthttp.Response {
+	c.mustBeAlive()
 	return &c.ctx.Response
 }
 
@@ -1055,5 +2057,6 @@ func (c *Context) Response() *fasthttp.Response {
 // This provides direct access to the full fasthttp context if you need
 // lower-level control over the request and response handling.
 func (c *Context) RequestCtx() *fasthttp.RequestCtx {
+	c.mustBeAlive()
 	return c.ctx
 }
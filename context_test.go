@@ -2,9 +2,15 @@ package zeno
 
 import (
 	"bytes"
+	"fmt"
+	"html/template"
+	"io"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
 	"github.com/valyala/fasthttp"
 )
 
@@ -258,3 +264,413 @@ func TestContext_String(t *testing.T) {
 		t.Fatalf("expected response body 'Hello, Zeno!', got '%s'", native.Response.Body())
 	}
 }
+
+type csvRow struct {
+	ID     int    `csv:"id"`
+	Name   string `csv:"name"`
+	Secret string `csv:"-"`
+}
+
+func TestContext_CSV(t *testing.T) {
+	input := []byte("name,id,extra\nAlice,1,x\nBob,2,y\n")
+	c, native := newTestContext("POST", "/", map[string]string{
+		"Content-Type": "text/csv",
+	}, input)
+
+	var rows []csvRow
+	if err := c.BindCSV(&rows); err != nil {
+		t.Fatalf("BindCSV failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Name != "Alice" || rows[1].ID != 2 {
+		t.Fatalf("CSV bind incorrect: %+v", rows)
+	}
+
+	if err := c.SendCSV(rows, "users.csv"); err != nil {
+		t.Fatalf("SendCSV failed: %v", err)
+	}
+	if got := string(native.Response.Body()); got != "id,name\n1,Alice\n2,Bob\n" {
+		t.Fatalf("response CSV = %q", got)
+	}
+	if got := string(native.Response.Header.Peek("Content-Disposition")); got != `attachment; filename=users.csv` {
+		t.Fatalf("Content-Disposition = %q", got)
+	}
+}
+
+func TestContext_SendTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("page").Parse(
+		`{{define "page"}}<ul>{{block "rows" .}}{{range .}}<li>{{.}}</li>{{end}}{{end}}</ul>{{end}}`))
+
+	c, native := newTestContext("GET", "/", nil, nil)
+	c.zeno.Renderer = NewHTMLRenderer(tmpl)
+	if err := c.SendTemplate("page", "rows", []string{"<a>"}); err != nil {
+		t.Fatalf("SendTemplate failed: %v", err)
+	}
+	if got := string(native.Response.Body()); got != "<ul><li>&lt;a&gt;</li></ul>" {
+		t.Fatalf("page body = %q", got)
+	}
+
+	c, native = newTestContext("GET", "/", map[string]string{"HX-Request": "true"}, nil)
+	c.zeno.Renderer = NewHTMLRenderer(tmpl)
+	if err := c.SendTemplate("page", "rows", []string{"b"}); err != nil {
+		t.Fatalf("SendTemplate failed: %v", err)
+	}
+	if got := string(native.Response.Body()); got != "<li>b</li>" {
+		t.Fatalf("fragment body = %q", got)
+	}
+}
+
+func TestContext_SendJSONStream(t *testing.T) {
+	c, native := newTestContext("GET", "/", nil, nil)
+
+	if err := c.SendJSONStream(user{Name: "Alice", Age: 30}); err != nil {
+		t.Fatalf("SendJSONStream failed: %v", err)
+	}
+	if !bytes.Contains(native.Response.Body(), []byte(`"name":"Alice"`)) {
+		t.Fatalf("response JSON = %s", native.Response.Body())
+	}
+	if got := string(native.Response.Header.ContentType()); got != "application/json; charset=utf-8" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+
+	c, native = newTestContext("GET", "/", nil, nil)
+	c.zeno.JsonStreamEncoder = nil
+	if err := c.SendJSONStream(user{Name: "Bob"}); err != nil {
+		t.Fatalf("SendJSONStream without encoder failed: %v", err)
+	}
+	if !bytes.Contains(native.Response.Body(), []byte(`"name":"Bob"`)) {
+		t.Fatalf("response JSON without encoder = %s", native.Response.Body())
+	}
+}
+
+func TestContext_Write(t *testing.T) {
+	c, native := newTestContext("GET", "/", nil, nil)
+
+	if _, err := fmt.Fprint(c, "hello, "); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := io.WriteString(c.BodyWriter(), "world"); err != nil {
+		t.Fatalf("BodyWriter failed: %v", err)
+	}
+	if got := string(native.Response.Body()); got != "hello, world" {
+		t.Fatalf("response body = %q", got)
+	}
+}
+
+func TestContext_BodyReader(t *testing.T) {
+	c, _ := newTestContext("POST", "/", nil, []byte("payload"))
+
+	b, err := io.ReadAll(c.BodyReader())
+	if err != nil {
+		t.Fatalf("BodyReader failed: %v", err)
+	}
+	if string(b) != "payload" {
+		t.Fatalf("BodyReader = %q; want %q", b, "payload")
+	}
+}
+
+func TestContext_Copy(t *testing.T) {
+	c, native := newTestContext("POST", "/users/7?x=1", map[string]string{"X-Trace": "abc"}, []byte("body"))
+	c.pnames = []string{"id"}
+	c.pvalues = []string{"7"}
+	c.Set("user", "alice")
+
+	cp := c.Copy()
+
+	// Reusing the original native context must not affect the copy.
+	native.Request.Reset()
+	c.pvalues[0] = "8"
+	c.Set("user", "bob")
+
+	assert.Equal(t, "7", cp.Param("id"))
+	assert.Equal(t, "abc", cp.GetHeader("X-Trace"))
+	assert.Equal(t, "1", cp.Query("x"))
+	assert.Equal(t, "body", string(cp.Body()))
+	assert.Equal(t, "alice", cp.Get("user"))
+	assert.Panics(t, func() { _ = cp.SendString("nope") })
+}
+
+func TestContext_VaryAndCacheControl(t *testing.T) {
+	c, native := newTestContext("GET", "/", nil, nil)
+
+	c.Vary("Accept")
+	c.Vary("accept", "Accept-Encoding")
+	assert.Equal(t, "Accept, Accept-Encoding", string(native.Response.Header.Peek("Vary")))
+
+	c.CacheControl(CacheControl{Public: true, MaxAge: time.Minute, StaleWhileRevalidate: 30 * time.Second})
+	assert.Equal(t, "public, max-age=60, stale-while-revalidate=30", string(native.Response.Header.Peek("Cache-Control")))
+
+	c.NoCache()
+	assert.Equal(t, "no-store, no-cache, must-revalidate", string(native.Response.Header.Peek("Cache-Control")))
+}
+
+func TestContext_Format(t *testing.T) {
+	offers := map[string]Handler{
+		"text/html":        func(c *Context) error { return c.SendHTML("<p>hi</p>") },
+		"application/json": func(c *Context) error { return c.SendJSON(Map{"msg": "hi"}) },
+	}
+
+	c, native := newTestContext("GET", "/", map[string]string{"Accept": "application/json"}, nil)
+	assert.NoError(t, c.Format(offers))
+	assert.JSONEq(t, `{"msg":"hi"}`, string(native.Response.Body()))
+
+	c, native = newTestContext("GET", "/", map[string]string{"Accept": "text/*"}, nil)
+	assert.NoError(t, c.Format(offers))
+	assert.Equal(t, "<p>hi</p>", string(native.Response.Body()))
+
+	c, _ = newTestContext("GET", "/", map[string]string{"Accept": "image/png"}, nil)
+	assert.Equal(t, ErrNotAcceptable, c.Format(offers))
+}
+
+func TestContext_BindQuery(t *testing.T) {
+	c, _ := newTestContext("GET", "/?filter[status]=open&filter[tags][]=a&filter[tags][]=b&ids=1,2&page=3&meta[x]=1", nil, nil)
+	c.zeno.QueryParser.Separator = ","
+
+	var q struct {
+		Filter struct {
+			Status string   `query:"status"`
+			Tags   []string `query:"tags"`
+		} `query:"filter"`
+		IDs  []int          `query:"ids"`
+		Page *int           `query:"page"`
+		Meta map[string]int `query:"meta"`
+	}
+	if err := c.BindQuery(&q); err != nil {
+		t.Fatalf("BindQuery failed: %v", err)
+	}
+	assert.Equal(t, "open", q.Filter.Status)
+	assert.Equal(t, []string{"a", "b"}, q.Filter.Tags)
+	assert.Equal(t, []int{1, 2}, q.IDs)
+	assert.Equal(t, 3, *q.Page)
+	assert.Equal(t, map[string]int{"x": 1}, q.Meta)
+
+	nested := c.QueryNested()
+	assert.Equal(t, map[string]any{"status": "open", "tags": []string{"a", "b"}}, nested["filter"])
+
+	c, _ = newTestContext("GET", "/?page=abc", nil, nil)
+	assert.Error(t, c.BindQuery(&q))
+}
+
+func TestParamErr(t *testing.T) {
+	c, _ := newTestContext("GET", "/?limit=ten", nil, nil)
+	c.pnames = []string{"id", "uuid"}
+	c.pvalues = []string{"42", "3F2504E0-4F89-11D3-9A0C-0305E82C3301"}
+
+	id, err := ParamErr[int](c, "id")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, id)
+
+	_, err = ParamErr[int](c, "missing")
+	assert.Equal(t, StatusBadRequest, ToHTTPError(err).StatusCode())
+
+	u, err := c.ParamUUID("uuid")
+	assert.NoError(t, err)
+	assert.Equal(t, "3f2504e0-4f89-11d3-9a0c-0305e82c3301", u)
+	_, err = c.ParamUUID("id")
+	assert.Error(t, err)
+
+	_, err = QueryErr[int](c, "limit")
+	assert.EqualError(t, err, `invalid value for query parameter "limit": strconv.Atoi: parsing "ten": invalid syntax`)
+}
+
+func TestContext_RedirectHelpers(t *testing.T) {
+	c, ctx := newTestContext("GET", "http://example.com/x", nil, nil)
+	c.zeno.Get("/users/{id}", func(*Context) error { return nil }).Name("user.show")
+
+	assert.NoError(t, c.RedirectToRoute("user.show", "id", 42))
+	assert.Equal(t, StatusFound, ctx.Response.StatusCode())
+	assert.Equal(t, "http://example.com/users/42", string(ctx.Response.Header.Peek(HeaderLocation)))
+
+	assert.Error(t, c.RedirectToRoute("nope"))
+
+	assert.NoError(t, c.RedirectPermanent("/new"))
+	assert.Equal(t, StatusMovedPermanently, ctx.Response.StatusCode())
+
+	c, ctx = newTestContext("GET", "http://example.com/x", map[string]string{HeaderReferer: "http://example.com/cart?step=2"}, nil)
+	assert.NoError(t, c.RedirectBack("/"))
+	assert.Equal(t, "http://example.com/cart?step=2", string(ctx.Response.Header.Peek(HeaderLocation)))
+
+	c, ctx = newTestContext("GET", "http://example.com/x", map[string]string{HeaderReferer: "https://evil.com/"}, nil)
+	assert.NoError(t, c.RedirectBack("/home"))
+	assert.Equal(t, "http://example.com/home", string(ctx.Response.Header.Peek(HeaderLocation)))
+
+	for _, ref := range []string{"//evil.com/x", "/\\evil.com/x", "http://example.com//evil.com", "http://example.com/\\evil.com"} {
+		c, ctx = newTestContext("GET", "http://example.com/x", map[string]string{HeaderReferer: ref}, nil)
+		assert.NoError(t, c.RedirectBack("/home"))
+		assert.Equal(t, "http://example.com/home", string(ctx.Response.Header.Peek(HeaderLocation)), ref)
+	}
+}
+
+func TestContext_BodyParsed(t *testing.T) {
+	type order struct {
+		ID    int      `json:"id" xml:"id"`
+		Items []string `json:"items" xml:"item"`
+	}
+
+	c, _ := newTestContext("POST", "/", map[string]string{HeaderContentType: "application/json"}, []byte(`{"id":1,"items":["a"]}`))
+	var first order
+	assert.NoError(t, c.BodyParsed(&first))
+	assert.Equal(t, order{ID: 1, Items: []string{"a"}}, first)
+
+	// The body is not decoded again for the same type.
+	c.ctx.Request.SetBodyString(`{"id":2}`)
+	var second order
+	assert.NoError(t, c.BodyParsed(&second))
+	assert.Equal(t, first, second)
+	assert.Equal(t, `{"id":2}`, string(c.RawBody()))
+
+	c, _ = newTestContext("POST", "/", map[string]string{HeaderContentType: "application/xml; charset=utf-8"}, []byte(`<order><id>3</id></order>`))
+	var x order
+	assert.NoError(t, c.BodyParsed(&x))
+	assert.Equal(t, 3, x.ID)
+
+	c, _ = newTestContext("POST", "/", map[string]string{HeaderContentType: "application/octet-stream"}, []byte("x"))
+	err := c.BodyParsed(&x)
+	assert.Equal(t, StatusUnsupportedMediaType, err.(HTTPError).StatusCode())
+}
+
+func TestContext_StatusHelpers(t *testing.T) {
+	c, ctx := newTestContext("POST", "/", nil, nil)
+	assert.NoError(t, c.Created("/users/1", Map{"id": 1}))
+	assert.Equal(t, StatusCreated, ctx.Response.StatusCode())
+	assert.Equal(t, "/users/1", string(ctx.Response.Header.Peek(HeaderLocation)))
+	assert.JSONEq(t, `{"id":1}`, string(ctx.Response.Body()))
+
+	assert.NoError(t, c.NoContent())
+	assert.Equal(t, StatusNoContent, ctx.Response.StatusCode())
+	assert.Empty(t, ctx.Response.Body())
+
+	err := c.NotFound("user not found")
+	assert.Equal(t, StatusNotFound, err.(HTTPError).StatusCode())
+	assert.Equal(t, "user not found", err.Error())
+	assert.Equal(t, "Bad Request", c.BadRequest().Error())
+	assert.Equal(t, StatusUnauthorized, c.Unauthorized().(HTTPError).StatusCode())
+}
+
+func TestContext_SendStatus(t *testing.T) {
+	c, ctx := newTestContext("GET", "/", nil, nil)
+	assert.NoError(t, c.SendStatus(StatusNotFound))
+	assert.Equal(t, "Not Found", string(ctx.Response.Body()))
+	assert.Equal(t, "text/plain; charset=utf-8", string(ctx.Response.Header.ContentType()))
+
+	c, ctx = newTestContext("GET", "/", map[string]string{HeaderAccept: "application/json"}, nil)
+	assert.NoError(t, c.SendStatus(StatusNotFound))
+	assert.JSONEq(t, `{"status":404,"message":"Not Found"}`, string(ctx.Response.Body()))
+
+	c, ctx = newTestContext("GET", "/", nil, nil)
+	_ = c.SendString("partial")
+	assert.NoError(t, c.SendStatus(StatusNoContent))
+	assert.Empty(t, ctx.Response.Body())
+
+	assert.NoError(t, c.SendString("kept"))
+	assert.NoError(t, c.SendStatus(StatusAccepted))
+	assert.Equal(t, "kept", string(ctx.Response.Body()))
+
+	c, ctx = newTestContext("GET", "/", nil, nil)
+	c.zeno.DisableStatusBody = true
+	assert.NoError(t, c.SendStatus(StatusAccepted))
+	assert.Empty(t, ctx.Response.Body())
+}
+
+func TestContext_SendFS(t *testing.T) {
+	mod := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"docs/readme.txt": {Data: []byte("hello"), ModTime: mod},
+	}
+
+	z := New()
+	z.Get("/direct", func(c *Context) error { return c.SendFS(fsys, "/docs/readme.txt") })
+	z.Get("/file/{name*}", func(c *Context) error { return c.SendFile(c.Param("name")) })
+	z.FS = fsys
+
+	ctx := performRequest(z, "GET", "/direct", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "hello", string(ctx.Response.Body()))
+	assert.Contains(t, string(ctx.Response.Header.ContentType()), "text/plain")
+	assert.Equal(t, "Wed, 01 May 2024 12:00:00 GMT", string(ctx.Response.Header.Peek(HeaderLastModified)))
+	etag := string(ctx.Response.Header.Peek(HeaderETag))
+	assert.NotEmpty(t, etag)
+
+	ctx = performRequest(z, "GET", "/file/docs/readme.txt", nil, nil)
+	assert.Equal(t, "hello", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/direct", map[string]string{HeaderIfNoneMatch: etag}, nil)
+	assert.Equal(t, StatusNotModified, ctx.Response.StatusCode())
+
+	ctx = performRequest(z, "GET", "/direct", map[string]string{HeaderIfModifiedSince: "Thu, 02 May 2024 00:00:00 GMT"}, nil)
+	assert.Equal(t, StatusNotModified, ctx.Response.StatusCode())
+
+	ctx = performRequest(z, "GET", "/file/docs", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+	ctx = performRequest(z, "GET", "/file/missing.txt", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+}
+
+func TestContext_SendHTMLSafe(t *testing.T) {
+	z := New()
+	z.Get("/hello", func(c *Context) error {
+		return c.SendHTMLSafe(`<p title="{{.name}}">{{.name}}</p>`, map[string]any{"name": c.Query("name")})
+	})
+	z.Get("/broken", func(c *Context) error {
+		return c.SendHTMLSafe(`{{.name`, nil)
+	})
+
+	ctx := performRequest(z, "GET", "/hello?name=%3Cscript%3E%22", nil, nil)
+	assert.Equal(t, `<p title="&lt;script&gt;&#34;">&lt;script&gt;&#34;</p>`, string(ctx.Response.Body()))
+	assert.Equal(t, "text/html; charset=utf-8", string(ctx.Response.Header.ContentType()))
+
+	ctx = performRequest(z, "GET", "/broken", nil, nil)
+	assert.Equal(t, StatusInternalServerError, ctx.Response.StatusCode())
+	assert.Equal(t, "Internal Server Error", string(ctx.Response.Body()))
+
+	first, err := z.htmlTemplate(`<p>{{.name}}</p>`)
+	assert.NoError(t, err)
+	second, _ := z.htmlTemplate(`<p>{{.name}}</p>`)
+	assert.Same(t, first, second)
+
+	assert.Equal(t, "&lt;a href=&#39;x&#39;&gt;", EscapeHTML("<a href='x'>"))
+}
+
+func TestContext_Charset(t *testing.T) {
+	c, ctx := newTestContext("GET", "/", nil, nil)
+	assert.NoError(t, c.SendJSON(Map{"a": 1}))
+	assert.Equal(t, "application/json; charset=utf-8", string(ctx.Response.Header.ContentType()))
+	assert.NoError(t, c.SendCBOR(Map{"a": 1}))
+	assert.Equal(t, "application/cbor", string(ctx.Response.Header.ContentType()))
+	assert.NoError(t, c.SendJSON(Map{"a": 1}, "application/vnd.api+json"))
+	assert.Equal(t, "application/vnd.api+json", string(ctx.Response.Header.ContentType()))
+
+	c.Zeno().DefaultCharset = ""
+	assert.NoError(t, c.SendXML(user{Name: "Alice"}))
+	assert.Equal(t, "application/xml", string(ctx.Response.Header.ContentType()))
+
+	c, _ = newTestContext("POST", "/", map[string]string{HeaderContentType: `application/json; Charset="UTF-8"`}, []byte(`{"name":"Alice"}`))
+	mt, params := c.ContentType()
+	assert.Equal(t, "application/json", mt)
+	assert.Equal(t, map[string]string{"charset": "UTF-8"}, params)
+	var u user
+	assert.NoError(t, c.BindJSON(&u))
+
+	c, _ = newTestContext("POST", "/", map[string]string{HeaderContentType: "application/json; charset=iso-8859-1"}, []byte(`{"name":"Alice"}`))
+	err := c.BindJSON(&u)
+	assert.Error(t, err)
+	assert.Equal(t, StatusUnsupportedMediaType, err.(HTTPError).StatusCode())
+}
+
+func TestContext_BindJSONConfig(t *testing.T) {
+	body := []byte(`{"name":"Alice","extra":1}`)
+	c, _ := newTestContext("POST", "/", map[string]string{HeaderContentType: "text/plain"}, body)
+	var u user
+	assert.NoError(t, c.BindJSON(&u))
+	assert.Equal(t, "Alice", u.Name)
+
+	c.Zeno().JSONBinding = JSONBindingConfig{RequireContentType: true, DisallowUnknownFields: true}
+	err := c.BindJSON(&u)
+	assert.Equal(t, StatusUnsupportedMediaType, err.(HTTPError).StatusCode())
+	assert.NoError(t, c.BindJSON(&u, JSONBindingConfig{}))
+
+	c, _ = newTestContext("POST", "/", map[string]string{HeaderContentType: "application/problem+json"}, body)
+	c.Zeno().JSONBinding.DisallowUnknownFields = true
+	err = c.BindJSON(&u)
+	assert.Equal(t, StatusBadRequest, err.(HTTPError).StatusCode())
+	assert.NoError(t, c.BindJSON(&u, JSONBindingConfig{RequireContentType: true}))
+}
@@ -0,0 +1,103 @@
+package zeno
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// converterRegistry maps a target type to a function that parses a string
+// into a value of that type.
+type converterRegistry map[reflect.Type]func(string) (reflect.Value, error)
+
+// converter bundles the settings used to turn request strings into typed
+// values: custom converters take precedence over the built-in handling of
+// time values and primitives.
+type converter struct {
+	custom  converterRegistry
+	time    TimeParserConfig
+	filters inputFilters
+}
+
+// converter returns the conversion settings currently configured on z.
+func (z *Zeno) converter() converter {
+	return converter{
+		custom:  z.converters,
+		time:    z.TimeParser,
+		filters: inputFilters{global: z.InputFilters, named: z.namedFilters},
+	}
+}
+
+// has reports whether t is converted as a single value, either through a
+// custom converter or one of the built-in time types.
+func (c converter) has(t reflect.Type) bool {
+	if t == timeType || t == durationType {
+		return true
+	}
+	_, ok := c.custom[t]
+	return ok
+}
+
+// set converts s with the converter registered for v's type, or the
+// built-in time handling, and stores the result in v. It reports false if
+// no converter applies.
+func (c converter) set(v reflect.Value, s string) (bool, error) {
+	switch v.Type() {
+	case timeType:
+		t, err := c.time.parseTime(s)
+		if err == nil {
+			v.Set(reflect.ValueOf(t))
+		}
+		return true, err
+	case durationType:
+		d, err := parseDuration(s)
+		if err == nil {
+			v.SetInt(int64(d))
+		}
+		return true, err
+	}
+	fn := c.custom[v.Type()]
+	if fn == nil {
+		return false, nil
+	}
+	res, err := fn(s)
+	if err != nil {
+		return true, err
+	}
+	v.Set(res)
+	return true, nil
+}
+
+// RegisterConverter registers fn to convert strings into a custom type.
+// fn must have the signature func(string) (T, error); the converter is
+// then used by Param, Query, ParamErr, QueryErr, and struct binding
+// (BindQuery, BindCSV, ...) whenever the target type is T.
+//
+// Converters should be registered before the server starts. It panics if
+// fn does not have the expected signature.
+//
+// Example:
+//
+//	app.RegisterConverter(uuid.Parse)
+//	app.RegisterConverter(func(s string) (Color, error) { return ParseColor(s) })
+//
+//	id := zeno.Param[uuid.UUID](ctx, "id")
+func (z *Zeno) RegisterConverter(fn any) {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	errType := reflect.TypeFor[error]()
+	if ft.Kind() != reflect.Func || ft.NumIn() != 1 || ft.In(0).Kind() != reflect.String ||
+		ft.NumOut() != 2 || ft.Out(1) != errType {
+		panic(fmt.Sprintf("zeno: RegisterConverter expects func(string) (T, error), got %T", fn))
+	}
+	if z.converters == nil {
+		z.converters = make(converterRegistry)
+	}
+	in := ft.In(0)
+	z.converters[ft.Out(0)] = func(s string) (reflect.Value, error) {
+		out := fv.Call([]reflect.Value{reflect.ValueOf(s).Convert(in)})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return reflect.Value{}, err
+		}
+		return out[0], nil
+	}
+}
@@ -0,0 +1,154 @@
+package zeno
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// csvField describes a struct field that takes part in CSV encoding
+// or decoding, together with the column name derived from its tag.
+type csvField struct {
+	name  string
+	index int
+}
+
+// csvFields returns the exported fields of struct type t in declaration
+// order. The column name is taken from the `csv:"..."` tag when present,
+// otherwise the field name is used. Fields tagged `csv:"-"` are skipped.
+func csvFields(t reflect.Type) []csvField {
+	fields := make([]csvField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("csv"); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, csvField{name: name, index: i})
+	}
+	return fields
+}
+
+// encodeCSV converts v into CSV records. v may be a [][]string, which is
+// returned unchanged, or a slice of structs (or struct pointers), in which
+// case a header row is emitted followed by one record per element.
+func encodeCSV(v any) ([][]string, error) {
+	if rows, ok := v.([][]string); ok {
+		return rows, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("csv: unsupported type %T", v)
+	}
+
+	et := rv.Type().Elem()
+	if et.Kind() == reflect.Pointer {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("csv: unsupported element type %s", et)
+	}
+
+	fields := csvFields(et)
+	records := make([][]string, 0, rv.Len()+1)
+
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.name
+	}
+	records = append(records, header)
+
+	for i := 0; i < rv.Len(); i++ {
+		ev := rv.Index(i)
+		if ev.Kind() == reflect.Pointer {
+			if ev.IsNil() {
+				continue
+			}
+			ev = ev.Elem()
+		}
+		record := make([]string, len(fields))
+		for j, f := range fields {
+			record[j] = formatValue(ev.Field(f.index))
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// decodeCSV stores records into out, which must be a pointer to a
+// [][]string or to a slice of structs (or struct pointers). For structs the
+// first record is treated as a header and columns are matched to fields by
+// their csv tag or name, case-insensitively. Unknown columns are ignored.
+// Values are converted with setValue using conv.
+func decodeCSV(records [][]string, out any, conv converter) error {
+	if p, ok := out.(*[][]string); ok {
+		*p = records
+		return nil
+	}
+
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("csv: destination must be a pointer to a slice")
+	}
+	sv := rv.Elem()
+
+	et := sv.Type().Elem()
+	isPtr := et.Kind() == reflect.Pointer
+	if isPtr {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: unsupported element type %s", et)
+	}
+	if len(records) == 0 {
+		sv.SetLen(0)
+		return nil
+	}
+
+	byName := map[string]int{}
+	for _, f := range csvFields(et) {
+		byName[strings.ToLower(f.name)] = f.index
+	}
+	columns := make([]int, len(records[0]))
+	for i, name := range records[0] {
+		idx, ok := byName[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			idx = -1
+		}
+		columns[i] = idx
+	}
+
+	result := reflect.MakeSlice(sv.Type(), 0, len(records)-1)
+	for line, record := range records[1:] {
+		ev := reflect.New(et).Elem()
+		for i, value := range record {
+			if i >= len(columns) || columns[i] < 0 {
+				continue
+			}
+			if err := setValue(ev.Field(columns[i]), value, conv); err != nil {
+				return fmt.Errorf("csv: line %d, column %q: %w", line+2, records[0][i], err)
+			}
+		}
+		if isPtr {
+			result = reflect.Append(result, ev.Addr())
+		} else {
+			result = reflect.Append(result, ev)
+		}
+	}
+	sv.Set(result)
+	return nil
+}
@@ -0,0 +1,223 @@
+package zeno
+
+import (
+	"bytes"
+	"html/template"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// RouteNode describes one node of a routing tree, as reported by
+// Zeno.RouteTrees and Zeno.DebugHandler.
+type RouteNode struct {
+	// Key is the literal path segment of a static node, or the parameter
+	// token, e.g. "{id:[0-9]+}", of a parameter node.
+	Key      string `json:"key"`
+	Static   bool   `json:"static"`
+	Param    string `json:"param,omitempty"`
+	Regex    string `json:"regex,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+	Wildcard bool   `json:"wildcard,omitempty"`
+
+	// Order is the registration order of the route ending at this node,
+	// 0 if none does; MinOrder is the lowest order in the subtree. When
+	// several routes match a path, the one with the lowest order wins.
+	Order    int `json:"order,omitempty"`
+	MinOrder int `json:"min_order"`
+
+	// Handlers names the handler chain of the route ending at this node,
+	// middleware first.
+	Handlers []string `json:"handlers,omitempty"`
+
+	// Children lists the static children, then the parameter children,
+	// in the order they are tried.
+	Children []*RouteNode `json:"children,omitempty"`
+}
+
+// RouteMatch reports how a request would be routed.
+type RouteMatch struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Result is "matched", "method_not_allowed" or "not_found".
+	Result string `json:"result"`
+
+	// Static is set when the route was found in the fast path map of
+	// routes without parameters rather than in the tree.
+	Static bool `json:"static,omitempty"`
+
+	Order    int               `json:"order,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+	Allow    string            `json:"allow,omitempty"`
+	Handlers []string          `json:"handlers,omitempty"`
+
+	// Trace lists the routing decisions, see TraceRoute.
+	Trace []string `json:"trace,omitempty"`
+}
+
+// RouteTrees returns a snapshot of the routing tree of every method that
+// has routes, keyed by method.
+func (z *Zeno) RouteTrees() map[string]*RouteNode {
+	table := z.table.Load()
+	trees := make(map[string]*RouteNode)
+	table.eachTree(func(m string, t *tree) {
+		trees[m] = describeNode(t.root)
+	})
+	return trees
+}
+
+// describeNode converts n and its subtree into RouteNodes.
+func describeNode(n *node) *RouteNode {
+	d := &RouteNode{
+		Key:      string(n.key),
+		Static:   n.static,
+		Optional: n.optional,
+		Wildcard: n.wildcard,
+		Order:    n.order,
+		MinOrder: n.minOrder,
+		Handlers: handlerNames(n.handlers),
+	}
+	if !n.static && n.pindex >= 0 {
+		d.Param = n.pnames[n.pindex]
+	}
+	if n.regex != nil {
+		d.Regex = strings.TrimPrefix(n.regex.String(), "^")
+	}
+	for _, c := range n.children {
+		d.Children = append(d.Children, describeNode(c))
+	}
+	for _, c := range n.pchildren {
+		d.Children = append(d.Children, describeNode(c))
+	}
+	return d
+}
+
+// MatchRoute reports how a request with method and path would be routed,
+// without running any handler.
+//
+// Example:
+//
+//	m := app.MatchRoute("GET", "/users/42")
+//	fmt.Println(m.Result, m.Params["id"], m.Handlers)
+func (z *Zeno) MatchRoute(method, path string) RouteMatch {
+	table := z.table.Load()
+	m := RouteMatch{Method: method, Path: path, Result: "not_found", Trace: z.TraceRoute(method, path)}
+	pvalues := make([]string, table.maxParams)
+
+	if h := table.static[method][path]; h != nil {
+		m.Result, m.Static, m.Handlers = "matched", true, handlerNames(h)
+		return m
+	}
+	if t := table.treeForMethod(method); t != nil {
+		if h, pnames, order := t.lookup([]byte(path), pvalues); h != nil {
+			m.Result, m.Order, m.Handlers = "matched", order, handlerNames(h)
+			m.Params = make(map[string]string, len(pnames))
+			for i, name := range pnames {
+				m.Params[name] = pvalues[i]
+			}
+			return m
+		}
+	}
+	if a := table.allowFor([]byte(path), pvalues); a != nil {
+		m.Result, m.Allow = "method_not_allowed", allowHeader(table.allowedMethods([]byte(path), pvalues))
+	}
+	return m
+}
+
+// handlerNames returns the function names of handlers.
+func handlerNames(handlers []Handler) []string {
+	if len(handlers) == 0 {
+		return nil
+	}
+	names := make([]string, len(handlers))
+	for i, h := range handlers {
+		if fn := runtime.FuncForPC(reflect.ValueOf(h).Pointer()); fn != nil {
+			names[i] = fn.Name()
+		}
+	}
+	return names
+}
+
+// routeDebugInfo is the document served by DebugHandler.
+type routeDebugInfo struct {
+	MaxParams int                   `json:"max_params"`
+	Trees     map[string]*RouteNode `json:"trees"`
+	Methods   []string              `json:"-"`
+	Match     *RouteMatch           `json:"match,omitempty"`
+}
+
+// debugTemplate renders routeDebugInfo as an HTML page.
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>zeno routes</title>
+<style>
+body{font-family:sans-serif} ul{list-style:none;border-left:1px dotted #999;margin:0;padding-left:1.2em}
+code{background:#f3f3f3} .param{color:#a0509e} .route{color:#2a7d2a} small{color:#777}
+</style></head><body>
+<h1>Routing trees</h1>
+<form><input name="method" placeholder="GET" size="8"> <input name="path" placeholder="/users/42" size="40"> <button>Match</button></form>
+{{with .Match}}<h2>{{.Method}} <code>{{.Path}}</code>: {{.Result}}</h2>
+<ul>{{if .Static}}<li>static fast path</li>{{end}}{{if .Order}}<li>order {{.Order}}</li>{{end}}
+{{range $k, $v := .Params}}<li><span class="param">{{$k}}</span> = <code>{{$v}}</code></li>{{end}}
+{{if .Allow}}<li>Allow: {{.Allow}}</li>{{end}}
+{{range .Handlers}}<li><code>{{.}}</code></li>{{end}}</ul>
+{{if .Trace}}<h3>Trace</h3><pre>{{range .Trace}}{{.}}
+{{end}}</pre>{{end}}{{end}}
+{{range $m := .Methods}}<h2>{{$m}}</h2><ul>{{template "node" index $.Trees $m}}</ul>{{end}}
+</body></html>
+{{define "node"}}<li><code{{if not .Static}} class="param"{{end}}>{{printf "%q" .Key}}</code>
+{{if .Regex}} <small>regex {{.Regex}}</small>{{end}}{{if .Optional}} <small>optional</small>{{end}}{{if .Wildcard}} <small>wildcard</small>{{end}}
+{{if .Order}} <span class="route">route #{{.Order}}: {{range $i, $h := .Handlers}}{{if $i}} &rarr; {{end}}{{$h}}{{end}}</span>{{end}}
+<small>min order {{.MinOrder}}</small>
+{{if .Children}}<ul>{{range .Children}}{{template "node" .}}{{end}}</ul>{{end}}</li>{{end}}`))
+
+// DebugHandler returns a handler serving the routing trees, with every
+// node's parameter, regular expression, registration order and handler
+// chain, to help find out why a request is not routed as expected. The
+// "method" and "path" query parameters additionally show how a request
+// would be matched (see MatchRoute).
+//
+// The response is HTML for browsers and JSON otherwise. It reveals the
+// application's internals, so mount it only in development or behind
+// authentication.
+//
+// Example:
+//
+//	if debug {
+//	    app.Get("/_debug/routes", app.DebugHandler())
+//	}
+//	// curl 'localhost:8080/_debug/routes?method=GET&path=/users/42'
+func (z *Zeno) DebugHandler() Handler {
+	return func(c *Context) error {
+		info := routeDebugInfo{
+			MaxParams: z.table.Load().maxParams,
+			Trees:     z.RouteTrees(),
+		}
+		for m := range info.Trees {
+			info.Methods = append(info.Methods, m)
+		}
+		sort.Strings(info.Methods)
+		// Read the query unfiltered: input filters must not alter paths.
+		args := c.ctx.QueryArgs()
+		if path := string(args.Peek("path")); path != "" {
+			method := strings.ToUpper(string(args.Peek("method")))
+			if method == "" {
+				method = MethodGet
+			}
+			m := z.MatchRoute(method, path)
+			info.Match = &m
+		}
+
+		return c.Format(map[string]Handler{
+			"application/json": func(c *Context) error { return c.SendJSON(info) },
+			"text/html": func(c *Context) error {
+				var buf bytes.Buffer
+				if err := debugTemplate.Execute(&buf, info); err != nil {
+					return NewHTTPError(StatusInternalServerError, "Failed to render template: "+err.Error())
+				}
+				return c.SendHTML(buf.String())
+			},
+		})
+	}
+}
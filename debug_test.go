@@ -0,0 +1,74 @@
+package zeno
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listUsers(c *Context) error { return c.SendString("users") }
+
+func TestZeno_MatchRoute(t *testing.T) {
+	z := New()
+	z.Get("/users", listUsers)
+	z.Get("/users/{id:[0-9]+}", func(c *Context) error { return nil })
+	z.Post("/files/{path*}", func(c *Context) error { return nil })
+
+	m := z.MatchRoute(MethodGet, "/users")
+	assert.Equal(t, "matched", m.Result)
+	assert.True(t, m.Static)
+	assert.Equal(t, []string{"github.com/Abhishek2010dev/zeno.listUsers"}, m.Handlers)
+
+	m = z.MatchRoute(MethodGet, "/users/42")
+	assert.Equal(t, "matched", m.Result)
+	assert.Equal(t, 2, m.Order)
+	assert.Equal(t, map[string]string{"id": "42"}, m.Params)
+
+	m = z.MatchRoute(MethodGet, "/files/a/b")
+	assert.Equal(t, "method_not_allowed", m.Result)
+	assert.Equal(t, "OPTIONS, POST", m.Allow)
+
+	assert.Equal(t, "not_found", z.MatchRoute(MethodGet, "/users/abc").Result)
+
+	trees := z.RouteTrees()
+	assert.Contains(t, trees, MethodGet)
+	assert.Contains(t, trees, MethodPost)
+}
+
+func TestZeno_DebugHandler(t *testing.T) {
+	z := New()
+	z.Get("/users/{id:[0-9]+}", listUsers)
+	z.Get("/_debug/routes", z.DebugHandler())
+
+	ctx := performRequest(z, "GET", "/_debug/routes?path=/users/7", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	var info struct {
+		Trees map[string]*RouteNode `json:"trees"`
+		Match *RouteMatch           `json:"match"`
+	}
+	assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &info))
+	assert.Equal(t, "7", info.Match.Params["id"])
+
+	var param *RouteNode
+	var find func(n *RouteNode)
+	find = func(n *RouteNode) {
+		if n.Param == "id" {
+			param = n
+		}
+		for _, c := range n.Children {
+			find(c)
+		}
+	}
+	find(info.Trees[MethodGet])
+	if assert.NotNil(t, param) {
+		assert.Equal(t, "[0-9]+", param.Regex)
+		assert.Equal(t, 1, param.Order)
+	}
+
+	ctx = performRequest(z, "GET", "/_debug/routes", map[string]string{"Accept": "text/html"}, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.True(t, strings.HasPrefix(string(ctx.Response.Header.ContentType()), "text/html"))
+	assert.Contains(t, string(ctx.Response.Body()), "route #1: github.com/Abhishek2010dev/zeno.listUsers")
+}
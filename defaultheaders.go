@@ -0,0 +1,56 @@
+package zeno
+
+// DefaultHeaders sets response headers added to every response for the
+// requests below the group's prefix, such as X-Powered-By, Cache-Control
+// or Cross-Origin-Resource-Policy. Called on the engine itself, it applies
+// to all requests, including unmatched ones.
+//
+// The headers are set once per response before any handler runs, so
+// handlers can still override or delete them. Calling DefaultHeaders again
+// adds to the headers set before, replacing values of the same keys; an
+// empty value removes a key. When groups are nested, the innermost
+// group's value of a header wins.
+//
+// Example:
+//
+//	app.DefaultHeaders(map[string]string{
+//	    "X-Powered-By":                 "zeno",
+//	    "Cross-Origin-Resource-Policy": "same-origin",
+//	})
+//	api.DefaultHeaders(map[string]string{"Cache-Control": "no-store"})
+func (r *RouteGroup) DefaultHeaders(headers map[string]string) {
+	r.zeno.setFallback(r.prefix, func(f *groupFallback) {
+		if f.headers == nil {
+			f.headers = make(map[string]string, len(headers))
+		}
+		for k, v := range headers {
+			if v == "" {
+				delete(f.headers, k)
+			} else {
+				f.headers[k] = v
+			}
+		}
+	})
+	r.zeno.defaultHeaders.Store(true)
+}
+
+// applyDefaultHeaders sets the default headers of the groups path lies
+// below, from the outermost group in.
+func (z *Zeno) applyDefaultHeaders(c *Context, path string) {
+	if !z.defaultHeaders.Load() {
+		return
+	}
+	fallbacks := z.table.Load().fallbacks
+	for i := len(fallbacks) - 1; i >= 0; i-- {
+		f := fallbacks[i]
+		if len(f.headers) == 0 {
+			continue
+		}
+		if _, ok := f.matches(path, c.fallbackValues); !ok {
+			continue
+		}
+		for k, v := range f.headers {
+			c.ctx.Response.Header.Set(k, v)
+		}
+	}
+}
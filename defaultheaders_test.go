@@ -0,0 +1,39 @@
+package zeno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteGroup_DefaultHeaders(t *testing.T) {
+	z := New()
+	z.DefaultHeaders(map[string]string{"X-Powered-By": "zeno", "Cache-Control": "public"})
+	api := z.Group("/api")
+	api.DefaultHeaders(map[string]string{"Cache-Control": "no-store"})
+	api.Get("/users", func(c *Context) error { return c.SendString("users") })
+	api.Get("/override", func(c *Context) error {
+		c.SetHeader("X-Powered-By", "custom")
+		return c.SendString("ok")
+	})
+	z.Get("/", func(c *Context) error { return c.SendString("home") })
+
+	ctx := performRequest(z, "GET", "/", nil, nil)
+	assert.Equal(t, "zeno", string(ctx.Response.Header.Peek("X-Powered-By")))
+	assert.Equal(t, "public", string(ctx.Response.Header.Peek("Cache-Control")))
+
+	ctx = performRequest(z, "GET", "/api/users", nil, nil)
+	assert.Equal(t, "zeno", string(ctx.Response.Header.Peek("X-Powered-By")))
+	assert.Equal(t, "no-store", string(ctx.Response.Header.Peek("Cache-Control")))
+
+	ctx = performRequest(z, "GET", "/api/override", nil, nil)
+	assert.Equal(t, "custom", string(ctx.Response.Header.Peek("X-Powered-By")))
+
+	ctx = performRequest(z, "GET", "/missing", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+	assert.Equal(t, "zeno", string(ctx.Response.Header.Peek("X-Powered-By")))
+
+	z.DefaultHeaders(map[string]string{"X-Powered-By": ""})
+	ctx = performRequest(z, "GET", "/", nil, nil)
+	assert.Empty(t, ctx.Response.Header.Peek("X-Powered-By"))
+}
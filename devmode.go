@@ -0,0 +1,296 @@
+package zeno
+
+import (
+	"bufio"
+	"io"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// DevConfig configures RunDev.
+type DevConfig struct {
+	// Dirs lists the directories watched for changes, typically templates
+	// and static assets. Defaults to those of "views", "templates",
+	// "static" and "public" that exist.
+	Dirs []string
+
+	// Templates, when set, parses the application's templates. It is
+	// called on start and again whenever a watched file changes, and the
+	// result is used by Render and the other template helpers. When it
+	// fails after a change, the error is logged and the previous templates
+	// stay in use.
+	Templates func() (Renderer, error)
+
+	// LiveReload injects a script into HTML responses that reloads the
+	// page when a watched file changes or the server restarts, e.g. after
+	// the zeno dev command rebuilt it.
+	LiveReload bool
+
+	// Path is the event stream the live reload script listens to.
+	// Defaults to "/__zeno/livereload".
+	Path string
+
+	// Interval is how often the directories are checked for changes.
+	// Defaults to 500ms.
+	Interval time.Duration
+}
+
+// RunDev starts the HTTP server on addr like Run, in a development mode
+// that watches the configured directories: templates are re-parsed and,
+// with LiveReload, open pages are reloaded in the browser whenever a file
+// changes. Go source changes require a rebuild, which the zeno dev command
+// automates; pages served by RunDev also reload after such a restart.
+//
+// RunDev is meant for local development only: it exposes the live reload
+// endpoint and modifies HTML responses.
+//
+// Example:
+//
+//	if os.Getenv("APP_ENV") == "development" {
+//	    log.Fatal(app.RunDev(":3000", zeno.DevConfig{
+//	        Templates: func() (zeno.Renderer, error) {
+//	            t, err := template.ParseGlob("views/*.html")
+//	            if err != nil {
+//	                return nil, err
+//	            }
+//	            return zeno.NewHTMLRenderer(t), nil
+//	        },
+//	        LiveReload: true,
+//	    }))
+//	}
+//	log.Fatal(app.Run(":3000"))
+func (z *Zeno) RunDev(addr string, config ...DevConfig) error {
+	var cfg DevConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	d, err := newDevServer(z, cfg)
+	if err != nil {
+		return err
+	}
+	s := z.Server()
+	next := s.Handler
+	if next == nil {
+		next = z.HandleRequest
+	}
+	s.Handler = d.handler(next)
+	go d.watch()
+	return z.Run(addr)
+}
+
+// devServer implements RunDev.
+type devServer struct {
+	z        *Zeno
+	config   DevConfig
+	renderer *devRenderer
+	files    map[string]time.Time
+	script   []byte // injected into HTML responses
+
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+}
+
+// devRenderer lets the templates be replaced while requests render them.
+type devRenderer struct {
+	current atomic.Pointer[Renderer]
+}
+
+// Render implements Renderer.
+func (r *devRenderer) Render(w io.Writer, name string, data any) error {
+	return (*r.current.Load()).Render(w, name, data)
+}
+
+// newDevServer applies the defaults documented on DevConfig and parses
+// the templates.
+func newDevServer(z *Zeno, config DevConfig) (*devServer, error) {
+	if config.Dirs == nil {
+		for _, dir := range []string{"views", "templates", "static", "public"} {
+			if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+				config.Dirs = append(config.Dirs, dir)
+			}
+		}
+	}
+	if config.Path == "" {
+		config.Path = "/__zeno/livereload"
+	}
+	if config.Interval <= 0 {
+		config.Interval = 500 * time.Millisecond
+	}
+	d := &devServer{
+		z:       z,
+		config:  config,
+		script:  liveReloadScript(config.Path),
+		clients: make(map[chan struct{}]struct{}),
+	}
+	if config.Templates != nil {
+		r, err := config.Templates()
+		if err != nil {
+			return nil, err
+		}
+		d.renderer = &devRenderer{}
+		d.renderer.current.Store(&r)
+		z.Renderer = d.renderer
+	}
+	d.files = d.snapshot()
+	return d, nil
+}
+
+// handler wraps next to serve the live reload events and inject the
+// script into HTML responses.
+func (d *devServer) handler(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if !d.config.LiveReload {
+		return next
+	}
+	return func(ctx *fasthttp.RequestCtx) {
+		if string(ctx.Path()) == d.config.Path {
+			d.serveEvents(ctx)
+			return
+		}
+		next(ctx)
+		d.inject(ctx)
+	}
+}
+
+// watch checks the directories for changes until the server shuts down.
+func (d *devServer) watch() {
+	t := time.NewTicker(d.config.Interval)
+	defer t.Stop()
+	for range t.C {
+		if d.z.IsShuttingDown() {
+			return
+		}
+		d.check()
+	}
+}
+
+// check reloads the templates and notifies the browsers if a watched
+// file changed since the last call, reporting whether one did.
+func (d *devServer) check() bool {
+	files := d.snapshot()
+	if maps.Equal(files, d.files) {
+		return false
+	}
+	d.files = files
+	if d.config.Templates != nil {
+		r, err := d.config.Templates()
+		if err != nil {
+			log.Printf("zeno: reloading templates: %v", err)
+			return true
+		}
+		d.renderer.current.Store(&r)
+	}
+	d.notify()
+	return true
+}
+
+// snapshot returns the modification times of the files in the watched
+// directories.
+func (d *devServer) snapshot() map[string]time.Time {
+	files := make(map[string]time.Time)
+	for _, dir := range d.config.Dirs {
+		_ = filepath.WalkDir(dir, func(path string, e fs.DirEntry, err error) error {
+			if err != nil || e.IsDir() {
+				return nil
+			}
+			if info, err := e.Info(); err == nil {
+				files[path] = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// notify tells every connected browser to reload.
+func (d *devServer) notify() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for ch := range d.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// serveEvents streams reload events to a browser. A "hello" event is sent
+// on every connection, so the script can tell a reconnect after a restart
+// from its first connection.
+func (d *devServer) serveEvents(ctx *fasthttp.RequestCtx) {
+	ch := make(chan struct{}, 1)
+	d.mu.Lock()
+	d.clients[ch] = struct{}{}
+	d.mu.Unlock()
+
+	ctx.SetContentType("text/event-stream")
+	ctx.Response.Header.Set(HeaderCacheControl, "no-cache")
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer func() {
+			d.mu.Lock()
+			delete(d.clients, ch)
+			d.mu.Unlock()
+		}()
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+		msg, idle := "event: hello\ndata: \n\n", 0
+		for {
+			if msg != "" {
+				if _, err := w.WriteString(msg); err != nil || w.Flush() != nil {
+					return
+				}
+				msg, idle = "", 0
+			}
+			select {
+			case <-ch:
+				msg = "event: reload\ndata: \n\n"
+			case <-t.C:
+				if d.z.IsShuttingDown() {
+					return
+				}
+				// Comments keep the connection alive and detect closed
+				// ones.
+				if idle++; idle == 15 {
+					msg = ": ping\n\n"
+				}
+			}
+		}
+	})
+}
+
+// liveReloadScript returns the script injected into HTML responses,
+// listening to the event stream at path.
+func liveReloadScript(path string) []byte {
+	return []byte(`<script>(function(){var up=false,es=new EventSource(` + strconv.Quote(path) + `);` +
+		`es.addEventListener("hello",function(){if(up)location.reload();up=true});` +
+		`es.addEventListener("reload",function(){location.reload()})})();</script>`)
+}
+
+// inject adds the live reload script to an HTML response, before its
+// closing body tag if it has one. Streamed and encoded bodies are left
+// unchanged.
+func (d *devServer) inject(ctx *fasthttp.RequestCtx) {
+	resp := &ctx.Response
+	if resp.IsBodyStream() || len(resp.Header.ContentEncoding()) > 0 ||
+		!strings.HasPrefix(string(resp.Header.ContentType()), "text/html") {
+		return
+	}
+	script := d.script
+	body := resp.Body()
+	i := lastIndexFold(body, "</body>")
+	if i < 0 {
+		i = len(body)
+	}
+	out := make([]byte, 0, len(body)+len(script))
+	out = append(append(append(out, body[:i]...), script...), body[i:]...)
+	resp.SetBody(out)
+}
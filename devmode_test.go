@@ -0,0 +1,116 @@
+package zeno
+
+import (
+	"bufio"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestRunDev_ReloadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+	assert.NoError(t, os.WriteFile(page, []byte(`<p>{{.}} v1</p>`), 0o644))
+
+	z := New()
+	d, err := newDevServer(z, DevConfig{
+		Dirs: []string{dir},
+		Templates: func() (Renderer, error) {
+			t, err := template.ParseFiles(page)
+			if err != nil {
+				return nil, err
+			}
+			return NewHTMLRenderer(t), nil
+		},
+	})
+	assert.NoError(t, err)
+	z.Get("/", func(c *Context) error { return c.Render("page.html", "hello") })
+
+	render := func() string {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/")
+		z.HandleRequest(ctx)
+		return string(ctx.Response.Body())
+	}
+	assert.Equal(t, "<p>hello v1</p>", render())
+	assert.False(t, d.check())
+
+	client := make(chan struct{}, 1)
+	d.clients[client] = struct{}{}
+	later := time.Now().Add(time.Second)
+	assert.NoError(t, os.WriteFile(page, []byte(`<p>{{.}} v2</p>`), 0o644))
+	assert.NoError(t, os.Chtimes(page, later, later))
+	assert.True(t, d.check())
+	assert.Equal(t, "<p>hello v2</p>", render())
+	assert.Len(t, client, 1, "browsers are notified")
+
+	// A broken template is reported and the previous one kept.
+	later = later.Add(time.Second)
+	assert.NoError(t, os.WriteFile(page, []byte(`<p>{{.}</p>`), 0o644))
+	assert.NoError(t, os.Chtimes(page, later, later))
+	assert.True(t, d.check())
+	assert.Equal(t, "<p>hello v2</p>", render())
+}
+
+func TestRunDev_LiveReload(t *testing.T) {
+	z := New()
+	z.Get("/page", func(c *Context) error { return c.SendHTML("<html><body><h1>Hi</h1></BODY></html>") })
+	z.Get("/data", func(c *Context) error { return c.SendJSON(map[string]string{"body": "</body>"}) })
+	z.Get("/unicode", func(c *Context) error { return c.SendHTML(strings.Repeat("İ", 8) + "\xff</body>") })
+	d, err := newDevServer(z, DevConfig{Dirs: []string{}, LiveReload: true})
+	assert.NoError(t, err)
+	h := d.handler(z.HandleRequest)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/page")
+	h(ctx)
+	body := string(ctx.Response.Body())
+	assert.True(t, strings.HasPrefix(body, "<html><body><h1>Hi</h1><script>"), body)
+	assert.Contains(t, body, `new EventSource("/__zeno/livereload")`)
+	assert.True(t, strings.HasSuffix(body, "</script></BODY></html>"), body)
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/data")
+	h(ctx)
+	assert.Equal(t, `{"body":"</body>"}`, string(ctx.Response.Body()))
+
+	// Lower-casing would change the length of the body before the tag.
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/unicode")
+	h(ctx)
+	body = string(ctx.Response.Body())
+	assert.True(t, strings.HasPrefix(body, strings.Repeat("İ", 8)+"\xff<script>"), body)
+	assert.True(t, strings.HasSuffix(body, "</script></body>"), body)
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	go func() { _ = (&fasthttp.Server{Handler: h}).Serve(ln) }()
+	conn, err := ln.Dial()
+	assert.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /__zeno/livereload HTTP/1.1\r\nHost: test\r\n\r\n"))
+	assert.NoError(t, err)
+
+	r := bufio.NewReader(conn)
+	readEvent := func() string {
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return ""
+			}
+			if name, ok := strings.CutPrefix(strings.TrimSpace(line), "event: "); ok {
+				return name
+			}
+		}
+	}
+	assert.Equal(t, "hello", readEvent())
+	d.notify()
+	assert.Equal(t, "reload", readEvent())
+}
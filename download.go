@@ -0,0 +1,122 @@
+package zeno
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+)
+
+// ContentInfo describes the content served by SendContent.
+type ContentInfo struct {
+	// Name is the file name of the content. Its extension determines the
+	// Content-Type when ContentType is empty, and it is the file name
+	// suggested to the client when Attachment is set.
+	Name string
+
+	// ContentType overrides the type derived from Name.
+	ContentType string
+
+	// ModTime is the last modification time, sent as Last-Modified and
+	// used for If-Modified-Since, If-Unmodified-Since and date based
+	// If-Range validators. The zero time disables them.
+	ModTime time.Time
+
+	// ETag is the entity tag of the content, including its quotes, e.g.
+	// the ETag of an S3 object or a content hash stored with a blob. When
+	// empty and ModTime is set, a strong tag is derived from the size and
+	// ModTime. Range requests validated with If-Range need a strong tag.
+	ETag string
+
+	// Attachment makes browsers download the content rather than display
+	// it, with Content-Disposition: attachment.
+	Attachment bool
+}
+
+// SendContent serves content from any io.ReadSeeker, such as an object
+// storage stream or a database blob, with the same support for resumable
+// downloads as SendFS: the ETag and Last-Modified validators are sent,
+// conditional requests are answered with 304 Not Modified or 412
+// Precondition Failed, and a single byte range is served with 206 Partial
+// Content unless an If-Range validator no longer matches, in which case
+// the full content is sent. Unsatisfiable ranges yield 416 Range Not
+// Satisfiable; multi-range requests get the full content.
+//
+// The size is found by seeking to the end of content. If content
+// implements io.Closer it is closed once the response has been sent.
+//
+// Example:
+//
+//	app.Get("/reports/{id}", func(c *zeno.Context) error {
+//	    blob, err := db.OpenBlob(c.Param("id"))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return c.SendContent(blob, zeno.ContentInfo{
+//	        Name:       blob.Name,
+//	        ModTime:    blob.UpdatedAt,
+//	        ETag:       `"` + blob.SHA256 + `"`,
+//	        Attachment: true,
+//	    })
+//	})
+func (c *Context) SendContent(content io.ReadSeeker, info ContentInfo) error {
+	size, err := content.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = content.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		closeContent(content)
+		return NewHTTPError(StatusInternalServerError, "failed to seek: "+err.Error())
+	}
+
+	etag := info.ETag
+	if etag == "" && !info.ModTime.IsZero() {
+		etag = contentETag(size, info.ModTime)
+	}
+	if etag != "" {
+		c.SetHeader(HeaderETag, etag)
+	}
+	if !info.ModTime.IsZero() {
+		c.SetHeader(HeaderLastModified, info.ModTime.UTC().Format(http.TimeFormat))
+	}
+	c.SetHeader(HeaderAcceptRanges, "bytes")
+	if c.checkPreconditions(etag, info.ModTime) {
+		closeContent(content)
+		return nil
+	}
+
+	ct := info.ContentType
+	if ct == "" {
+		ct = mime.TypeByExtension(path.Ext(info.Name))
+	}
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	c.SetContentType(ct)
+	if info.Attachment {
+		disposition := "attachment"
+		if name := path.Base(info.Name); info.Name != "" && name != "/" {
+			if d := mime.FormatMediaType(disposition, map[string]string{"filename": name}); d != "" {
+				disposition = d
+			}
+		}
+		c.SetHeader(HeaderContentDisposition, disposition)
+	}
+
+	if sent, err := c.sendRange(content, size, etag, info.ModTime); sent {
+		if err != nil || c.ctx.Response.StatusCode() != StatusPartialContent {
+			closeContent(content)
+		}
+		return err
+	}
+	c.ctx.SetBodyStream(content, int(size))
+	return nil
+}
+
+// closeContent closes content if it is an io.Closer.
+func closeContent(content io.Reader) {
+	if cl, ok := content.(io.Closer); ok {
+		cl.Close()
+	}
+}
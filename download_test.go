@@ -0,0 +1,88 @@
+package zeno
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blob is a non-file io.ReadSeeker that records whether it was closed.
+type blob struct {
+	*strings.Reader
+	closed bool
+}
+
+func (b *blob) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestSendContent(t *testing.T) {
+	mod := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var last *blob
+	z := New()
+	z.Get("/blob", func(c *Context) error {
+		last = &blob{Reader: strings.NewReader("0123456789")}
+		return c.SendContent(last, ContentInfo{Name: "reports/q1.csv", ModTime: mod, ETag: `"v1"`, Attachment: true})
+	})
+
+	ctx := performRequest(z, "GET", "/blob", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "0123456789", string(ctx.Response.Body()))
+	assert.Equal(t, `"v1"`, string(ctx.Response.Header.Peek(HeaderETag)))
+	assert.Equal(t, mod.Format(http.TimeFormat), string(ctx.Response.Header.Peek(HeaderLastModified)))
+	assert.Equal(t, "bytes", string(ctx.Response.Header.Peek(HeaderAcceptRanges)))
+	assert.Equal(t, `attachment; filename=q1.csv`, string(ctx.Response.Header.Peek(HeaderContentDisposition)))
+	assert.True(t, strings.HasPrefix(string(ctx.Response.Header.ContentType()), "text/csv"))
+
+	// Resuming an interrupted download.
+	ctx = performRequest(z, "GET", "/blob", map[string]string{HeaderRange: "bytes=4-", HeaderIfRange: `"v1"`}, nil)
+	assert.Equal(t, StatusPartialContent, ctx.Response.StatusCode())
+	assert.Equal(t, "456789", string(ctx.Response.Body()))
+	assert.Equal(t, "bytes 4-9/10", string(ctx.Response.Header.Peek(HeaderContentRange)))
+
+	// The content changed since the first part was downloaded.
+	ctx = performRequest(z, "GET", "/blob", map[string]string{HeaderRange: "bytes=4-", HeaderIfRange: `"v0"`}, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "0123456789", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/blob", map[string]string{HeaderIfNoneMatch: `"v1"`}, nil)
+	assert.Equal(t, StatusNotModified, ctx.Response.StatusCode())
+	assert.True(t, last.closed)
+
+	ctx = performRequest(z, "GET", "/blob", map[string]string{HeaderRange: "bytes=10-"}, nil)
+	assert.Equal(t, StatusRequestedRangeNotSatisfiable, ctx.Response.StatusCode())
+	assert.True(t, last.closed)
+}
+
+func TestSendContent_DerivedETag(t *testing.T) {
+	mod := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	z := New()
+	z.Get("/blob", func(c *Context) error {
+		return c.SendContent(bytes.NewReader([]byte("abc")), ContentInfo{ModTime: mod})
+	})
+	z.Get("/plain", func(c *Context) error {
+		return c.SendContent(bytes.NewReader([]byte("abc")), ContentInfo{ContentType: "text/plain"})
+	})
+
+	ctx := performRequest(z, "GET", "/blob", nil, nil)
+	assert.Equal(t, contentETag(3, mod), string(ctx.Response.Header.Peek(HeaderETag)))
+	assert.Equal(t, "application/octet-stream", string(ctx.Response.Header.ContentType()))
+
+	ctx = performRequest(z, "GET", "/blob", map[string]string{HeaderIfModifiedSince: mod.Format(http.TimeFormat)}, nil)
+	assert.Equal(t, StatusNotModified, ctx.Response.StatusCode())
+
+	// Without validators If-Range never matches.
+	ctx = performRequest(z, "GET", "/plain", map[string]string{HeaderRange: "bytes=1-", HeaderIfRange: `"x"`}, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "abc", string(ctx.Response.Body()))
+	assert.Empty(t, ctx.Response.Header.Peek(HeaderETag))
+
+	ctx = performRequest(z, "GET", "/plain", map[string]string{HeaderRange: "bytes=1-"}, nil)
+	assert.Equal(t, StatusPartialContent, ctx.Response.StatusCode())
+	assert.Equal(t, "bc", string(ctx.Response.Body()))
+}
@@ -0,0 +1,45 @@
+package zeno
+
+// EarlyHints immediately sends a 103 Early Hints informational response
+// (RFC 8297) carrying a Link header for each of links, so browsers can
+// start preloading or preconnecting while the handler is still preparing
+// the final response. The links are also added to the final response.
+//
+// It does nothing for HTTP/1.0 clients, which do not understand
+// informational responses, or when the request is not being served over a
+// connection, e.g. when HandleRequest is called directly in tests.
+//
+// Example:
+//
+//	c.EarlyHints(
+//	    "</style.css>; rel=preload; as=style",
+//	    "<https://cdn.example.com>; rel=preconnect",
+//	)
+//	page := renderSlowly()
+//	return c.SendHTML(page)
+func (c *Context) EarlyHints(links ...string) error {
+	c.mustBeWritable()
+	for _, l := range links {
+		c.ctx.Response.Header.Add(HeaderLink, l)
+	}
+	if len(links) == 0 || c.ctx.Conn() == nil || !c.ctx.Request.Header.IsHTTP11() {
+		return nil
+	}
+	return c.ctx.EarlyHints()
+}
+
+// suppressBody enforces the body rules of RFC 9110 once the handler chain
+// has completed: 1xx, 204 and 304 responses never carry a body, so any
+// body or stream set for them is dropped without being produced, and
+// responses to HEAD keep their headers, including the Content-Length a GET
+// would have had, but no body.
+func (c *Context) suppressBody() {
+	resp := &c.ctx.Response
+	switch code := resp.StatusCode(); {
+	case code < 200 || code == StatusNoContent || code == StatusNotModified:
+		resp.ResetBody()
+		resp.SkipBody = true
+	case c.ctx.IsHead():
+		resp.SkipBody = true
+	}
+}
@@ -0,0 +1,62 @@
+package zeno
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestContext_EarlyHints(t *testing.T) {
+	z := New()
+	z.Get("/", func(c *Context) error {
+		if err := c.EarlyHints("</app.css>; rel=preload; as=style"); err != nil {
+			return err
+		}
+		return c.SendString("page")
+	})
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	go fasthttp.Serve(ln, z.HandleRequest)
+
+	conn, err := ln.Dial()
+	assert.NoError(t, err)
+	defer conn.Close()
+	_, err = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: x\r\n\r\n")
+	assert.NoError(t, err)
+
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "HTTP/1.1 103"), line)
+	for line != "\r\n" {
+		line, err = r.ReadString('\n')
+		assert.NoError(t, err)
+	}
+	resp, err := http.ReadResponse(r, nil)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, StatusOK, resp.StatusCode)
+	assert.Equal(t, "page", string(body))
+	assert.Equal(t, "</app.css>; rel=preload; as=style", resp.Header.Get(HeaderLink))
+}
+
+func TestZeno_BodySuppression(t *testing.T) {
+	z := New()
+	z.Get("/empty", func(c *Context) error { return c.Status(StatusNoContent).SendString("ignored") })
+	z.Head("/page", func(c *Context) error { return c.SendString("hello") })
+
+	ctx := performRequest(z, "GET", "/empty", nil, nil)
+	assert.Empty(t, ctx.Response.Body())
+
+	ctx = performRequest(z, "HEAD", "/page", nil, nil)
+	assert.True(t, ctx.Response.SkipBody)
+	assert.Contains(t, ctx.Response.String(), "Content-Length: 5")
+	assert.NotContains(t, ctx.Response.String(), "hello")
+}
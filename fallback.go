@@ -0,0 +1,164 @@
+package zeno
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// groupFallback holds the not-found chain, error handler and default
+// response headers a RouteGroup configured for the requests below its
+// prefix.
+type groupFallback struct {
+	prefix       string
+	notFound     []Handler
+	errorHandler func(*Context, error) error
+	headers      map[string]string
+
+	// pattern matches the paths below a prefix with parameters, such as
+	// "/tenants/{tenant}"; it is nil for static prefixes. nparams is the
+	// number of parameters in the prefix.
+	pattern *tree
+	nparams int
+}
+
+// newGroupFallback returns the fallback for prefix, compiling it into a
+// tree when it contains parameters so they match like they do in routes.
+func newGroupFallback(prefix string) *groupFallback {
+	f := &groupFallback{prefix: prefix}
+	if strings.IndexByte(prefix, '{') < 0 {
+		return f
+	}
+	f.pattern = newTree()
+	f.nparams = f.pattern.Add([]byte(prefix), fallbackMatch)
+	rest := "/{:.*}"
+	if strings.HasSuffix(prefix, "/") {
+		rest = "{:.*}"
+	}
+	f.pattern.Add([]byte(prefix+rest), fallbackMatch)
+	return f
+}
+
+// fallbackMatch is the chain stored in fallback patterns; only whether a
+// lookup finds it matters.
+var fallbackMatch = []Handler{func(*Context) error { return nil }}
+
+// matches reports whether path lies below the fallback's prefix. For
+// prefixes with parameters, their values are written to pvalues, which
+// should hold at least nparams+1 entries, and their names are returned.
+func (f *groupFallback) matches(path string, pvalues []string) ([]string, bool) {
+	if f.pattern != nil {
+		if len(pvalues) <= f.nparams {
+			// Only reached by contexts sized before the group was set up.
+			pvalues = make([]string, f.nparams+1)
+		}
+		h, names := f.pattern.Get([]byte(path), pvalues)
+		if h == nil {
+			return nil, false
+		}
+		return names[:f.nparams], true
+	}
+	if strings.HasSuffix(f.prefix, "/") || f.prefix == "" {
+		return nil, strings.HasPrefix(path, f.prefix)
+	}
+	return nil, path == f.prefix || strings.HasPrefix(path, f.prefix+"/")
+}
+
+// NotFound sets the handlers run for requests below the group's prefix
+// that match no route, in place of the ones set with Zeno.NotFound. The
+// group's middleware runs first. Paths registered for other methods are
+// answered by the 405 chain instead (see Zeno.MethodNotAllowed).
+//
+// When groups are nested, the one with the longest matching prefix wins.
+// Parameters in the group's prefix are available to the handlers through
+// Context.Param.
+//
+// Example:
+//
+//	api := app.Group("/api")
+//	api.NotFound(func(c *zeno.Context) error {
+//	    return c.Status(zeno.StatusNotFound).SendJSON(map[string]string{"error": "not found"})
+//	})
+func (r *RouteGroup) NotFound(handlers ...Handler) {
+	hh := combineHandlers(r.handlers, handlers)
+	r.zeno.setFallback(r.prefix, func(f *groupFallback) { f.notFound = hh })
+}
+
+// ErrorHandler sets the function handling errors returned by handlers for
+// requests below the group's prefix, in place of Zeno.ErrorHandler. This
+// lets an API group answer with JSON while the rest of the site renders
+// HTML error pages.
+//
+// When groups are nested, the one with the longest matching prefix wins.
+//
+// Example:
+//
+//	api.ErrorHandler(func(c *zeno.Context, err error) error {
+//	    code := zeno.StatusInternalServerError
+//	    if he, ok := err.(zeno.HTTPError); ok {
+//	        code = he.StatusCode()
+//	    }
+//	    return c.Status(code).SendJSON(map[string]string{"error": err.Error()})
+//	})
+func (r *RouteGroup) ErrorHandler(fn func(c *Context, err error) error) {
+	r.zeno.setFallback(r.prefix, func(f *groupFallback) { f.errorHandler = fn })
+}
+
+// setFallback applies set to a copy of the fallback registered for prefix,
+// creating it if needed, and publishes the result in a new routing table.
+// Fallbacks are never modified once published, so requests read them
+// without locking. They are kept ordered from the longest prefix down.
+func (z *Zeno) setFallback(prefix string, set func(f *groupFallback)) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	fallbacks := slices.Clone(z.fallbacks)
+	if i := slices.IndexFunc(fallbacks, func(f *groupFallback) bool { return f.prefix == prefix }); i >= 0 {
+		f := *fallbacks[i]
+		f.headers = maps.Clone(f.headers)
+		set(&f)
+		fallbacks[i] = &f
+	} else {
+		f := newGroupFallback(prefix)
+		set(f)
+		fallbacks = append(fallbacks, f)
+		sort.SliceStable(fallbacks, func(i, j int) bool {
+			return len(fallbacks[i].prefix) > len(fallbacks[j].prefix)
+		})
+	}
+	z.fallbacks = fallbacks
+	table := *z.table.Load()
+	table.setFallbacks(fallbacks)
+	z.table.Store(&table)
+}
+
+// notFoundFor returns the not-found chain for path from table: the one of
+// the innermost group that set one, or the global chain, along with the
+// names of the group prefix parameters whose values were written to
+// pvalues.
+func (z *Zeno) notFoundFor(table *routingTable, path string, pvalues []string) ([]Handler, []string) {
+	for _, f := range table.fallbacks {
+		if f.notFound == nil {
+			continue
+		}
+		if pnames, ok := f.matches(path, pvalues); ok {
+			return f.notFound, pnames
+		}
+	}
+	return z.notFoundHandlers, nil
+}
+
+// errorHandlerFor returns the error handler for c's path: the one of the
+// innermost group that set one, or Zeno.ErrorHandler.
+func (z *Zeno) errorHandlerFor(c *Context) func(*Context, error) error {
+	path := c.Path()
+	for _, f := range z.table.Load().fallbacks {
+		if f.errorHandler == nil {
+			continue
+		}
+		if _, ok := f.matches(path, c.fallbackValues); ok {
+			return f.errorHandler
+		}
+	}
+	return z.ErrorHandler
+}
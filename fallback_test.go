@@ -0,0 +1,100 @@
+package zeno
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteGroup_Fallbacks(t *testing.T) {
+	z := New()
+	api := z.Group("/api")
+	api.NotFound(func(c *Context) error {
+		return c.Status(StatusNotFound).SendJSON(map[string]string{"error": "not found"})
+	})
+	api.ErrorHandler(func(c *Context, err error) error {
+		return c.Status(err.(HTTPError).StatusCode()).SendJSON(map[string]string{"error": err.Error()})
+	})
+	api.Get("/users", func(c *Context) error { return c.SendString("users") })
+	api.Get("/fail", func(c *Context) error { return NewHTTPError(StatusBadRequest, "bad") })
+	z.Get("/fail", func(c *Context) error { return NewHTTPError(StatusBadRequest, "bad") })
+
+	ctx := performRequest(z, "GET", "/api/missing", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"error":"not found"}`, string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "POST", "/api/users", nil, nil)
+	assert.Equal(t, StatusMethodNotAllowed, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"error":"Method Not Allowed"}`, string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/apix", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+	assert.Equal(t, "Not Found", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/api/fail", nil, nil)
+	assert.Equal(t, StatusBadRequest, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"error":"bad"}`, string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/fail", nil, nil)
+	assert.Equal(t, StatusBadRequest, ctx.Response.StatusCode())
+	assert.Equal(t, "bad", string(ctx.Response.Body()))
+}
+
+func TestRouteGroup_PrefixParams(t *testing.T) {
+	z := New()
+	tenants := z.Group("/tenants/{tenant:[a-z]+}", func(c *Context) error {
+		c.Set("tenant", c.Param("tenant"))
+		return c.Next()
+	})
+	tenants.NotFound(func(c *Context) error {
+		return c.Status(StatusNotFound).SendString("no such page for " + c.Get("tenant").(string))
+	})
+	tenants.Get("/users/{id}", func(c *Context) error {
+		return c.SendString(c.Get("tenant").(string) + ":" + c.Param("id"))
+	})
+
+	ctx := performRequest(z, "GET", "/tenants/acme/users/7", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "acme:7", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/tenants/acme/missing", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+	assert.Equal(t, "no such page for acme", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/tenants/ACME/users/7", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+	assert.Equal(t, "Not Found", string(ctx.Response.Body()))
+
+	assert.Panics(t, func() { z.Group("/tenants/{tenant") })
+}
+
+func TestRouteGroup_FallbacksWhileServing(t *testing.T) {
+	z := New()
+	tenants := z.Group("/tenants/{tenant}")
+	tenants.Get("/users/{id}", func(c *Context) error { return ErrBadRequest })
+	tenants.ErrorHandler(func(c *Context, err error) error {
+		return c.Status(StatusBadRequest).SendString(c.Param("tenant") + ":" + c.Param("id"))
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 50 {
+			z.Group(fmt.Sprintf("/g%d/{x}", i)).NotFound(NotFoundHandler)
+			tenants.DefaultHeaders(map[string]string{"X-Seq": strconv.Itoa(i)})
+		}
+	}()
+	for range 50 {
+		ctx := performRequest(z, "GET", "/tenants/acme/users/7", nil, nil)
+		assert.Equal(t, "acme:7", string(ctx.Response.Body()))
+	}
+	wg.Wait()
+
+	ctx := performRequest(z, "GET", "/tenants/acme/users/7", nil, nil)
+	assert.Equal(t, "49", string(ctx.Response.Header.Peek("X-Seq")))
+	assert.Equal(t, StatusNotFound, performRequest(z, "GET", "/g7/a/b", nil, nil).Response.StatusCode())
+}
@@ -0,0 +1,87 @@
+package zeno
+
+import (
+	"maps"
+	"os"
+)
+
+// staticCacheControl is the Cache-Control value used for small assets
+// that are served from memory and rarely change.
+const staticCacheControl = "public, max-age=31536000"
+
+// Favicon returns a middleware that answers GET and HEAD requests for
+// /favicon.ico with the contents of file, held in memory and sent with a
+// long-lived Cache-Control header. Other requests pass through.
+//
+// When registered with Zeno.Use, requests for /favicon.ico skip the route
+// lookup once it has been served: only the global middleware runs, up to
+// Favicon itself.
+//
+// The file is read once when Favicon is called; it panics if the file
+// cannot be read, so misconfiguration surfaces at startup. Register it
+// with Zeno.Use so it also applies when no route matches.
+//
+// Example:
+//
+//	app.Use(zeno.Favicon("./public/favicon.ico"))
+func Favicon(file string) Handler {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		panic("zeno: cannot read favicon: " + err.Error())
+	}
+	return serveFromMemory("/favicon.ico", "image/x-icon", data)
+}
+
+// Robots returns a middleware that answers GET and HEAD requests for
+// /robots.txt with content, served from memory with a long-lived
+// Cache-Control header. Other requests pass through. Like Favicon, it
+// skips the route lookup when registered with Zeno.Use.
+//
+// Example:
+//
+//	app.Use(zeno.Robots("User-agent: *\nDisallow: /admin/\n"))
+func Robots(content string) Handler {
+	return serveFromMemory("/robots.txt", "text/plain; charset=utf-8", []byte(content))
+}
+
+// learnAssetPath records that path is answered from memory by global
+// middleware when c is running the not-found chain, which starts with
+// the global middleware, so that later requests for path skip the route
+// lookup. Paths served by group or route middleware are not recorded.
+func (z *Zeno) learnAssetPath(c *Context, path string) {
+	if len(c.handlers) == 0 || len(z.notFoundHandlers) == 0 || &c.handlers[0] != &z.notFoundHandlers[0] {
+		return
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	old := z.assetPaths.Load()
+	if old != nil && (*old)[path] {
+		return
+	}
+	paths := map[string]bool{path: true}
+	if old != nil {
+		maps.Copy(paths, *old)
+	}
+	z.assetPaths.Store(&paths)
+}
+
+// serveFromMemory returns a middleware serving data at path.
+func serveFromMemory(path, contentType string, data []byte) Handler {
+	return func(c *Context) error {
+		if c.Path() != path {
+			return c.Next()
+		}
+		c.zeno.learnAssetPath(c, path)
+		if m := c.Method(); m != MethodGet && m != MethodHead {
+			c.SetHeader(HeaderAllow, "GET, HEAD, OPTIONS")
+			if m == MethodOptions {
+				return c.SendStatusCode(StatusOK)
+			}
+			return ErrMethodNotAllowed
+		}
+		c.SetContentType(contentType)
+		c.SetHeader(HeaderCacheControl, staticCacheControl)
+		c.Abort()
+		return c.SendBytes(data)
+	}
+}
@@ -0,0 +1,181 @@
+package zeno
+
+import (
+	"hash/fnv"
+	"slices"
+	"sync"
+)
+
+// FlagAttributes describe the request a feature flag is evaluated for.
+type FlagAttributes struct {
+	// User is the ID returned by Zeno.FlagUser, or "".
+	User string
+
+	// Tenant is the ID of the tenant resolved by ResolveTenant, or "".
+	Tenant string
+
+	// Context is the request, for providers using further attributes
+	// such as headers or the client country.
+	Context *Context
+}
+
+// FlagProvider evaluates feature flags, e.g. from a configuration file or
+// an adapter for a flag service such as LaunchDarkly, Unleash or an
+// OpenFeature client. Enabled is called from request goroutines
+// concurrently and must report unknown flags as disabled.
+type FlagProvider interface {
+	Enabled(name string, attrs FlagAttributes) bool
+}
+
+// FlagProviderFunc adapts a function to a FlagProvider.
+type FlagProviderFunc func(name string, attrs FlagAttributes) bool
+
+// Enabled calls f(name, attrs).
+func (f FlagProviderFunc) Enabled(name string, attrs FlagAttributes) bool { return f(name, attrs) }
+
+// Flag is the rule of a feature flag in a FlagSet. A flag is enabled for
+// a request when any of its conditions holds.
+type Flag struct {
+	// Enabled turns the flag on for everyone.
+	Enabled bool
+
+	// Users and Tenants turn the flag on for these user and tenant IDs.
+	Users   []string
+	Tenants []string
+
+	// Percentage turns the flag on for this share, from 0 to 100, of the
+	// users, or of the tenants for requests without a user. Each user
+	// keeps the same decision as the percentage grows.
+	Percentage float64
+}
+
+// FlagSet is a FlagProvider evaluating Flags held in memory. Flags can be
+// changed while the server runs.
+type FlagSet struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+// NewFlagSet returns a FlagSet with flags.
+//
+// Example:
+//
+//	app.Flags = zeno.NewFlagSet(map[string]zeno.Flag{
+//	    "new-checkout": {Tenants: []string{"acme"}, Percentage: 10},
+//	    "dark-mode":    {Enabled: true},
+//	})
+func NewFlagSet(flags map[string]Flag) *FlagSet {
+	s := &FlagSet{flags: make(map[string]Flag, len(flags))}
+	for name, f := range flags {
+		s.flags[name] = f
+	}
+	return s
+}
+
+// Set sets the rule of the flag name.
+func (s *FlagSet) Set(name string, f Flag) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flags[name] = f
+}
+
+// Delete removes the flag name, disabling it.
+func (s *FlagSet) Delete(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.flags, name)
+}
+
+// Enabled implements FlagProvider.
+func (s *FlagSet) Enabled(name string, attrs FlagAttributes) bool {
+	s.mu.RLock()
+	f, ok := s.flags[name]
+	s.mu.RUnlock()
+	switch {
+	case !ok:
+		return false
+	case f.Enabled:
+		return true
+	case attrs.User != "" && slices.Contains(f.Users, attrs.User):
+		return true
+	case attrs.Tenant != "" && slices.Contains(f.Tenants, attrs.Tenant):
+		return true
+	}
+	key := attrs.User
+	if key == "" {
+		key = attrs.Tenant
+	}
+	return key != "" && f.Percentage > 0 && rolloutBucket(name, key) < f.Percentage*100
+}
+
+// rolloutBucket places key in one of 10000 buckets for the flag name,
+// so rollouts of different flags reach different users.
+func rolloutBucket(name, key string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return float64(h.Sum32() % 10000)
+}
+
+// featuresKey is the Context key of the flags evaluated for the request.
+const featuresKey = "zeno.features"
+
+// Feature reports whether the feature flag name is enabled for the
+// request by Zeno.Flags, evaluated with the user from Zeno.FlagUser and
+// the tenant from Context.Tenant. Each flag is evaluated once per
+// request, so a request sees a consistent decision.
+//
+// Example:
+//
+//	app.Get("/checkout", func(c *zeno.Context) error {
+//	    if c.Feature("new-checkout") {
+//	        return newCheckout(c)
+//	    }
+//	    return legacyCheckout(c)
+//	})
+func (c *Context) Feature(name string) bool {
+	if c.zeno.Flags == nil {
+		return false
+	}
+	seen, _ := c.Get(featuresKey).(map[string]bool)
+	if enabled, ok := seen[name]; ok {
+		return enabled
+	}
+	attrs := FlagAttributes{Context: c}
+	if c.zeno.FlagUser != nil {
+		attrs.User = c.zeno.FlagUser(c)
+	}
+	if t := c.Tenant(); t != nil {
+		attrs.Tenant = t.ID
+	}
+	enabled := c.zeno.Flags.Enabled(name, attrs)
+	if seen == nil {
+		seen = make(map[string]bool)
+		c.Set(featuresKey, seen)
+	}
+	seen[name] = enabled
+	return enabled
+}
+
+// RequireFeature returns a middleware answering requests with 404 Not
+// Found unless all of the feature flags names are enabled for them (see
+// Context.Feature), so routes behind disabled flags look like they do not
+// exist.
+//
+// Example:
+//
+//	beta := app.Group("/v2")
+//	beta.Use(zeno.RequireFeature("api-v2"))
+//
+//	app.Post("/exports", zeno.RequireFeature("exports"), createExport)
+func RequireFeature(names ...string) Handler {
+	return func(c *Context) error {
+		for _, name := range names {
+			if !c.Feature(name) {
+				return ErrNotFound
+			}
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package zeno
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_Feature(t *testing.T) {
+	flags := NewFlagSet(map[string]Flag{
+		"dark-mode":    {Enabled: true},
+		"new-checkout": {Users: []string{"ann"}, Tenants: []string{"acme"}},
+		"api-v2":       {Users: []string{"ann"}},
+	})
+	calls := 0
+	z := New()
+	z.Flags = FlagProviderFunc(func(name string, attrs FlagAttributes) bool {
+		calls++
+		return flags.Enabled(name, attrs)
+	})
+	z.FlagUser = func(c *Context) string { return c.GetHeader("X-User") }
+	z.Use(ResolveTenant(TenantConfig{Resolvers: []TenantResolver{TenantFromHeader("X-Tenant-ID")}}))
+	z.Get("/", func(c *Context) error {
+		c.Feature("new-checkout")
+		return c.SendString(fmt.Sprint(c.Feature("dark-mode"), c.Feature("new-checkout"), c.Feature("unknown")))
+	})
+	v2 := z.Group("/v2")
+	v2.Use(RequireFeature("api-v2"))
+	v2.Get("/orders", func(c *Context) error { return c.SendString("v2") })
+
+	ctx := performRequest(z, "GET", "/", nil, nil)
+	assert.Equal(t, "true false false", string(ctx.Response.Body()))
+	assert.Equal(t, 3, calls)
+	ctx = performRequest(z, "GET", "/", map[string]string{"X-User": "ann"}, nil)
+	assert.Equal(t, "true true false", string(ctx.Response.Body()))
+	ctx = performRequest(z, "GET", "/", map[string]string{"X-Tenant-ID": "acme"}, nil)
+	assert.Equal(t, "true true false", string(ctx.Response.Body()))
+
+	assert.Equal(t, StatusNotFound, performRequest(z, "GET", "/v2/orders", nil, nil).Response.StatusCode())
+	ctx = performRequest(z, "GET", "/v2/orders", map[string]string{"X-User": "ann"}, nil)
+	assert.Equal(t, "v2", string(ctx.Response.Body()))
+
+	flags.Set("api-v2", Flag{Enabled: true})
+	assert.Equal(t, StatusOK, performRequest(z, "GET", "/v2/orders", nil, nil).Response.StatusCode())
+	flags.Delete("api-v2")
+	assert.Equal(t, StatusNotFound, performRequest(z, "GET", "/v2/orders", nil, nil).Response.StatusCode())
+}
+
+func TestContext_FeatureWithoutProvider(t *testing.T) {
+	z := New()
+	z.Get("/", RequireFeature("anything"), func(c *Context) error { return c.SendString("ok") })
+	assert.Equal(t, StatusNotFound, performRequest(z, "GET", "/", nil, nil).Response.StatusCode())
+}
+
+func TestFlagSet_Percentage(t *testing.T) {
+	flags := NewFlagSet(map[string]Flag{"rollout": {Percentage: 25}})
+	enabled := 0
+	for i := range 4000 {
+		if flags.Enabled("rollout", FlagAttributes{User: fmt.Sprint("user-", i)}) {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 1000, enabled, 150)
+
+	// Growing the rollout keeps the users already in it.
+	grown := NewFlagSet(map[string]Flag{"rollout": {Percentage: 50}})
+	for i := range 4000 {
+		attrs := FlagAttributes{User: fmt.Sprint("user-", i)}
+		if flags.Enabled("rollout", attrs) {
+			assert.True(t, grown.Enabled("rollout", attrs))
+		}
+	}
+
+	assert.True(t, NewFlagSet(map[string]Flag{"all": {Percentage: 100}}).Enabled("all", FlagAttributes{Tenant: "acme"}))
+	assert.False(t, flags.Enabled("rollout", FlagAttributes{}))
+}
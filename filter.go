@@ -0,0 +1,111 @@
+package zeno
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// InputFilter transforms a raw input value, e.g. by trimming or escaping
+// it, before it reaches a handler.
+type InputFilter func(string) string
+
+// FilterTrimSpace removes leading and trailing white space.
+func FilterTrimSpace(s string) string { return strings.TrimSpace(s) }
+
+// FilterStripControl removes control characters other than tab, newline
+// and carriage return.
+func FilterStripControl(s string) string {
+	if strings.IndexFunc(s, isStrippedControl) < 0 {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if isStrippedControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// isStrippedControl reports whether FilterStripControl removes r.
+func isStrippedControl(r rune) bool {
+	return unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r'
+}
+
+// FilterEscapeHTML escapes the characters <, >, &, ' and ".
+func FilterEscapeHTML(s string) string { return html.EscapeString(s) }
+
+// builtinFilters are the filters available by name in `filter` tags
+// without registration.
+var builtinFilters = map[string]InputFilter{
+	"trim":         FilterTrimSpace,
+	"stripcontrol": FilterStripControl,
+	"escapehtml":   FilterEscapeHTML,
+}
+
+// inputFilters holds the filter settings of an engine.
+type inputFilters struct {
+	global []InputFilter
+	named  map[string]InputFilter
+}
+
+// applyFilters runs s through chain in order.
+func applyFilters(chain []InputFilter, s string) string {
+	for _, f := range chain {
+		s = f(s)
+	}
+	return s
+}
+
+// forField returns the filter chain for struct field f: the chain named
+// by its `filter` tag, none for `filter:"-"`, or the global chain if the
+// field has no tag.
+func (fs inputFilters) forField(f reflect.StructField) ([]InputFilter, error) {
+	tag, ok := f.Tag.Lookup("filter")
+	if !ok {
+		return fs.global, nil
+	}
+	if tag == "-" || tag == "" {
+		return nil, nil
+	}
+	var chain []InputFilter
+	for name := range strings.SplitSeq(tag, ",") {
+		name = strings.TrimSpace(name)
+		fn, ok := fs.named[name]
+		if !ok {
+			fn, ok = builtinFilters[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown input filter %q", name)
+		}
+		chain = append(chain, fn)
+	}
+	return chain, nil
+}
+
+// RegisterInputFilter makes fn available under name in `filter` struct
+// tags, next to the built-in "trim", "stripcontrol" and "escapehtml".
+//
+// Example:
+//
+//	app.RegisterInputFilter("lower", strings.ToLower)
+//
+//	type Search struct {
+//	    Term string `query:"q" filter:"trim,lower"`
+//	}
+func (z *Zeno) RegisterInputFilter(name string, fn InputFilter) {
+	if z.namedFilters == nil {
+		z.namedFilters = make(map[string]InputFilter)
+	}
+	z.namedFilters[name] = fn
+}
+
+// filterInput runs s through z.InputFilters.
+func (z *Zeno) filterInput(s string) string {
+	if len(z.InputFilters) == 0 {
+		return s
+	}
+	return applyFilters(z.InputFilters, s)
+}
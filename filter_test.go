@@ -0,0 +1,55 @@
+package zeno
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputFilters(t *testing.T) {
+	assert.Equal(t, "a b", FilterTrimSpace("  a b\t"))
+	assert.Equal(t, "ab\tc\n", FilterStripControl("a\x00b\tc\x7f\n"))
+	assert.Equal(t, "&lt;b&gt; &amp; &#34;", FilterEscapeHTML(`<b> & "`))
+}
+
+func TestZeno_InputFilters(t *testing.T) {
+	z := New()
+	z.InputFilters = []InputFilter{FilterTrimSpace, FilterEscapeHTML}
+	z.RegisterInputFilter("upper", strings.ToUpper)
+
+	var got []string
+	z.Get("/users/{name}", func(c *Context) error {
+		got = append(got, c.Param("name"), c.Query("q"), c.Query("missing", " default "))
+		return nil
+	})
+	performRequest(z, "GET", "/users/%3Cb%3E?q=%20hi%20", nil, nil)
+	assert.Equal(t, []string{"&lt;b&gt;", "hi", " default "}, got)
+
+	type search struct {
+		Term  string   `query:"q"`
+		Raw   string   `query:"raw" filter:"-"`
+		Code  string   `query:"code" filter:"trim,upper"`
+		Tags  []string `query:"tag"`
+		Limit int      `query:"limit"`
+	}
+	var s search
+	var bindErr error
+	z.Get("/search", func(c *Context) error {
+		bindErr = c.BindQuery(&s)
+		return nil
+	})
+	performRequest(z, "GET", "/search?q=+%3Ci%3E+&raw=+%3Ci%3E+&code=+ab+&tag=+x+&tag=y&limit=+5+", nil, nil)
+	assert.NoError(t, bindErr)
+	assert.Equal(t, search{Term: "&lt;i&gt;", Raw: " <i> ", Code: "AB", Tags: []string{"x", "y"}, Limit: 5}, s)
+
+	var bad struct {
+		Q string `query:"q" filter:"nope"`
+	}
+	z.Get("/bad", func(c *Context) error {
+		bindErr = c.BindQuery(&bad)
+		return nil
+	})
+	performRequest(z, "GET", "/bad?q=1", nil, nil)
+	assert.ErrorContains(t, bindErr, `unknown input filter "nope"`)
+}
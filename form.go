@@ -0,0 +1,194 @@
+package zeno
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// BindForm decodes a URL-encoded or multipart form into out.
+//
+// Value fields are bound like BindQuery, but from `form` tags. In multipart
+// forms, fields tagged `file:"name"` receive the uploaded files: a
+// *multipart.FileHeader or []byte receives the first file submitted under
+// name, a []*multipart.FileHeader or [][]byte all of them. Adding a max
+// option limits the size of each file, in bytes or with a KB, MB or GB
+// suffix; larger uploads are answered with 413 Request Entity Too Large.
+//
+// A 400 Bad Request error is returned if the form is malformed or a value
+// cannot be converted.
+//
+// Example:
+//
+//	type Profile struct {
+//	    Name   string                `form:"name"`
+//	    Avatar *multipart.FileHeader `file:"avatar,max=2MB"`
+//	    Docs   [][]byte              `file:"docs,max=512KB"`
+//	}
+//
+//	var p Profile
+//	if err := c.BindForm(&p); err != nil {
+//	    return err
+//	}
+func (c *Context) BindForm(out any) error {
+	tree := valueTree{}
+	var files map[string][]*multipart.FileHeader
+	if mt, _ := c.ContentType(); mt == "multipart/form-data" {
+		form, err := c.ctx.MultipartForm()
+		if err != nil {
+			return NewHTTPError(StatusBadRequest, "Invalid multipart form: "+err.Error())
+		}
+		for k, values := range form.Value {
+			for _, v := range values {
+				tree.add(k, v, "")
+			}
+		}
+		files = form.File
+	} else {
+		c.ctx.PostArgs().VisitAll(func(key, value []byte) {
+			tree.add(string(key), string(value), "")
+		})
+	}
+	if err := bindTree(out, tree, "form", c.zeno.converter()); err != nil {
+		return NewHTTPError(StatusBadRequest, "Invalid form: "+err.Error())
+	}
+	if v := reflect.ValueOf(out).Elem(); v.Kind() == reflect.Struct {
+		return bindFiles(v, files)
+	}
+	return nil
+}
+
+// fileHeaderType is the type of the *multipart.FileHeader fields bound by
+// bindFiles.
+var fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+
+// bindFiles fills the `file` tagged fields of struct v, including those of
+// embedded structs, from files.
+func bindFiles(v reflect.Value, files map[string][]*multipart.FileHeader) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("file")
+		if !ok {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				if err := bindFiles(v.Field(i), files); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		name, limit, err := parseFileTag(tag)
+		if err != nil {
+			return NewHTTPError(StatusInternalServerError, f.Name+": "+err.Error())
+		}
+		if name == "" {
+			name = f.Name
+		}
+		headers := files[name]
+		if len(headers) == 0 {
+			continue
+		}
+		for _, fh := range headers {
+			if limit > 0 && fh.Size > limit {
+				return NewHTTPError(StatusRequestEntityTooLarge, fmt.Sprintf("file %q exceeds %d bytes", name, limit))
+			}
+		}
+		if err := setFileField(v.Field(i), headers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setFileField stores headers, or their contents, in field.
+func setFileField(field reflect.Value, headers []*multipart.FileHeader) error {
+	switch {
+	case field.Type() == fileHeaderType:
+		field.Set(reflect.ValueOf(headers[0]))
+	case field.Type() == reflect.SliceOf(fileHeaderType):
+		field.Set(reflect.ValueOf(headers))
+	case field.Type() == reflect.TypeOf([]byte(nil)):
+		b, err := readFormFile(headers[0])
+		if err != nil {
+			return err
+		}
+		field.SetBytes(b)
+	case field.Type() == reflect.TypeOf([][]byte(nil)):
+		all := make([][]byte, len(headers))
+		for i, fh := range headers {
+			b, err := readFormFile(fh)
+			if err != nil {
+				return err
+			}
+			all[i] = b
+		}
+		field.Set(reflect.ValueOf(all))
+	default:
+		return NewHTTPError(StatusInternalServerError, "cannot bind files to "+field.Type().String())
+	}
+	return nil
+}
+
+// readFormFile returns the contents of an uploaded file.
+func readFormFile(fh *multipart.FileHeader) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, NewHTTPError(StatusBadRequest, "failed to open uploaded file: "+err.Error())
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, NewHTTPError(StatusBadRequest, "failed to read uploaded file: "+err.Error())
+	}
+	return b, nil
+}
+
+// parseFileTag splits a `file` tag such as "avatar,max=2MB" into the form
+// field name and the maximum file size, 0 meaning unlimited.
+func parseFileTag(tag string) (string, int64, error) {
+	name, opts, _ := strings.Cut(tag, ",")
+	var limit int64
+	for opt := range strings.SplitSeq(opts, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "":
+		case "max":
+			n, err := parseByteSize(value)
+			if err != nil {
+				return "", 0, err
+			}
+			limit = n
+		default:
+			return "", 0, fmt.Errorf("unknown file tag option %q", key)
+		}
+	}
+	return name, limit, nil
+}
+
+// parseByteSize parses sizes such as "512", "64KB", "2MB" or "1GB", using
+// binary multiples.
+func parseByteSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		mult   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(upper, u.suffix) {
+			upper, mult = strings.TrimSpace(strings.TrimSuffix(upper, u.suffix)), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * mult, nil
+}
@@ -0,0 +1,63 @@
+package zeno
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for in, want := range map[string]int64{"512": 512, "64KB": 64 << 10, "2 mb": 2 << 20, "1GB": 1 << 30, "10B": 10} {
+		got, err := parseByteSize(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := parseByteSize("lots")
+	assert.Error(t, err)
+}
+
+func TestContext_BindForm(t *testing.T) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	_ = w.WriteField("name", "Alice")
+	_ = w.WriteField("age", "30")
+	fw, _ := w.CreateFormFile("avatar", "a.png")
+	_, _ = fw.Write([]byte("png"))
+	for _, doc := range []string{"one", "two"} {
+		fw, _ = w.CreateFormFile("docs", doc+".txt")
+		_, _ = fw.Write([]byte(doc))
+	}
+	_ = w.Close()
+	headers := map[string]string{HeaderContentType: w.FormDataContentType()}
+
+	type profile struct {
+		Name   string                `form:"name"`
+		Age    int                   `form:"age"`
+		Avatar *multipart.FileHeader `file:"avatar,max=1KB"`
+		Docs   [][]byte              `file:"docs"`
+		Raw    []byte                `file:"avatar"`
+	}
+	c, _ := newTestContext("POST", "/", headers, body.Bytes())
+	var p profile
+	assert.NoError(t, c.Bind(&p))
+	assert.Equal(t, "Alice", p.Name)
+	assert.Equal(t, 30, p.Age)
+	assert.Equal(t, "a.png", p.Avatar.Filename)
+	assert.Equal(t, [][]byte{[]byte("one"), []byte("two")}, p.Docs)
+	assert.Equal(t, []byte("png"), p.Raw)
+
+	var small struct {
+		Docs []*multipart.FileHeader `file:"docs,max=2B"`
+	}
+	c, _ = newTestContext("POST", "/", headers, body.Bytes())
+	err := c.BindForm(&small)
+	assert.Equal(t, StatusRequestEntityTooLarge, err.(HTTPError).StatusCode())
+
+	c, _ = newTestContext("POST", "/", map[string]string{HeaderContentType: "application/x-www-form-urlencoded"}, []byte("name=Bob&age=7"))
+	p = profile{}
+	assert.NoError(t, c.BodyParsed(&p))
+	assert.Equal(t, "Bob", p.Name)
+	assert.Equal(t, 7, p.Age)
+}
@@ -0,0 +1,186 @@
+package zeno
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func FuzzParseAccept(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"text/html",
+		`*/*;q=0.8, text/*;q=0.8, text/html;charset="utf-8";q=0.8, application/json`,
+		"application/json;q=0, */*;q=",
+		";;=;q=1.5,/,*",
+	} {
+		f.Add(seed)
+	}
+	offers := []string{"text/html", "application/json", "application/vnd.api+json;version=2"}
+	f.Fuzz(func(t *testing.T, header string) {
+		for _, e := range ParseAccept(header) {
+			if e.Q < 0 || e.Q > 1 {
+				t.Fatalf("q = %v out of range for %q", e.Q, header)
+			}
+		}
+		if best := matchAccept(header, offers); best != "" && !slices.Contains(offers, best) {
+			t.Fatalf("matchAccept returned %q, not an offer", best)
+		}
+	})
+}
+
+func FuzzParseRange(f *testing.F) {
+	for _, seed := range []string{"bytes=0-99", "bytes=-5", "bytes=10-", "bytes=0-0,5-9,-1", "bytes=9-1", "items=0-1", "bytes=-0"} {
+		f.Add(seed, int64(100))
+	}
+	f.Add("bytes=0-", int64(0))
+	f.Fuzz(func(t *testing.T, header string, size int64) {
+		r, err := ParseRange(header, size)
+		if err != nil {
+			return
+		}
+		for _, rng := range r.Ranges {
+			if rng.Start < 0 || rng.Start > rng.End || rng.End >= size {
+				t.Fatalf("ParseRange(%q, %d) = invalid range %+v", header, size, rng)
+			}
+		}
+	})
+}
+
+func FuzzTree_Get(f *testing.F) {
+	patterns := []string{
+		"/",
+		"/users",
+		"/users/{id}",
+		"/users/{id}/posts/{post?}",
+		"/files/{path*}",
+		"/v{version:[0-9]+}/items",
+		"/items/{name}.{ext}",
+		"/{lang:(en|fr)}/about",
+	}
+	tr := newTree()
+	maxParams := 0
+	for _, p := range patterns {
+		if n := tr.Add([]byte(p), []Handler{NotFoundHandler}); n > maxParams {
+			maxParams = n
+		}
+	}
+	for _, seed := range []string{"/", "/users/1/posts/", "/files/a/b/c", "/v12/items", "/items/a.b.c", "/fr/about", "//", "/users/{id}", "\x00"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, path string) {
+		pvalues := make([]string, maxParams)
+		handlers, names, order := tr.lookup([]byte(path), pvalues)
+		if _, _, traced := tr.root.get([]byte(path), len(path), make([]int, 2*maxParams), &routeTracer{}, 0); handlers != nil && traced != order {
+			t.Fatalf("trace matched route #%d, lookup #%d", traced, order)
+		}
+		if handlers == nil {
+			return
+		}
+		for i := range names {
+			if !strings.Contains(path, pvalues[i]) {
+				t.Fatalf("parameter %s = %q is not part of %q", names[i], pvalues[i], path)
+			}
+		}
+	})
+}
+
+func FuzzTree_Add(f *testing.F) {
+	for _, seed := range []string{"/users/{id}", "/{a}{b}", "/{", "/}", "/{x*}/y", "/{re:[}", "/{*}", "/{?}", "{}", "/a/{b:c:d}"} {
+		f.Add(seed, "/users/{name:[a-z]+}", "/users/1")
+	}
+	f.Fuzz(func(t *testing.T, pattern, other, path string) {
+		tr := newTree()
+		maxParams := 0
+		for _, p := range []string{pattern, other} {
+			if p == "" {
+				continue
+			}
+			valid := ValidatePath(p) == nil
+			n, err := tryAdd(tr, routePattern(p))
+			if err != nil {
+				if valid {
+					t.Fatalf("ValidatePath accepted %q, but adding it failed: %v", p, err)
+				}
+				return
+			}
+			if n > maxParams {
+				maxParams = n
+			}
+		}
+		tr.Get([]byte(path), make([]string, maxParams))
+	})
+}
+
+// tryAdd adds pattern to tr, turning the panics that report invalid
+// patterns into errors.
+func tryAdd(tr *tree, pattern string) (n int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if msg, ok := r.(string); ok && (strings.HasPrefix(msg, "routing:") || strings.HasPrefix(msg, "regexp:")) {
+				err = errString(msg)
+				return
+			}
+			panic(r)
+		}
+	}()
+	return tr.Add([]byte(pattern), []Handler{NotFoundHandler}), nil
+}
+
+type errString string
+
+func (e errString) Error() string { return string(e) }
+
+func FuzzCleanPath(f *testing.F) {
+	for _, seed := range []string{"/", "", "/a//b/./../c", "/%2e%2e/etc", "/a%2Fb", "/x%00", "/%", "/%zz", "../.."} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, raw string) {
+		cleaned, ok := cleanPath([]byte(raw), false)
+		if !ok {
+			return
+		}
+		p := string(cleaned)
+		if !strings.HasPrefix(p, "/") {
+			t.Fatalf("cleanPath(%q) = %q, not absolute", raw, p)
+		}
+		for seg := range strings.SplitSeq(p, "/") {
+			if seg == ".." {
+				t.Fatalf("cleanPath(%q) = %q, contains ..", raw, p)
+			}
+		}
+	})
+}
+
+func FuzzZeno_HandleRequest(f *testing.F) {
+	z := New()
+	z.Get("/users/{id}", func(c *Context) error { return c.SendString(c.Param("id")) })
+	z.Get("/files/{path*}", func(c *Context) error { return c.SendString(c.Param("path")) })
+	z.Post("/items", func(c *Context) error { return c.SendStatus(StatusCreated) })
+	z.PathCleaning.PreserveEncodedSlash = true
+	for _, seed := range []string{"/users/1", "/files/a%2Fb", "/items", "/%", "//x/../users/2", "*"} {
+		f.Add("GET", seed, "text/html")
+	}
+	f.Fuzz(func(t *testing.T, method, uri, accept string) {
+		performRequest(z, method, uri, map[string]string{HeaderAccept: accept}, nil)
+	})
+}
+
+func FuzzParseUserAgent(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"curl/8.5.0",
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/126.0.0.0 Safari/537.36",
+		strings.Repeat("\xff", 10) + "curl/",
+		"İK" + "Wget/1.21",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		ua := ParseUserAgent(s)
+		if !strings.Contains(s, ua.Version) {
+			t.Fatalf("version %q not in %q", ua.Version, s)
+		}
+	})
+}
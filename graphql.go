@@ -0,0 +1,248 @@
+package zeno
+
+import (
+	"bytes"
+	"html/template"
+	"mime"
+	"strings"
+)
+
+// GraphQLRequest is a single GraphQL operation as sent by clients.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLExecutor executes a GraphQL operation and returns the response to
+// encode as JSON, typically a value with "data" and "errors" fields as
+// produced by the executor of a GraphQL library.
+type GraphQLExecutor func(c *Context, req GraphQLRequest) any
+
+// GraphQLConfig configures the GraphQL handler.
+type GraphQLConfig struct {
+	// Batching accepts a JSON array of operations in one POST request and
+	// answers with an array of results in the same order.
+	Batching bool
+
+	// MaxBatch limits the number of operations in a batch. Defaults to 10.
+	MaxBatch int
+}
+
+// graphQLError is the response body for requests that cannot be executed.
+type graphQLError struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// GraphQL returns a handler serving a GraphQL endpoint over HTTP. It
+// accepts operations as GET query parameters (query, operationName and
+// variables as JSON), as POST bodies of type application/json, and as
+// POST bodies of type application/graphql containing only the query.
+// Results are encoded with the engine's JSON encoder.
+//
+// GET requests may only run queries: a link or an image tag must not be
+// able to trigger a mutation, so other operations are answered with 405
+// Method Not Allowed. POST requests without one of the two content types
+// are rejected with 415 Unsupported Media Type, which keeps cross-site
+// forms from posting operations.
+//
+// Register the same handler for GET and POST, and use GraphiQL to serve
+// an in-browser IDE on a separate route.
+//
+// Example:
+//
+//	gql := zeno.GraphQL(func(c *zeno.Context, req zeno.GraphQLRequest) any {
+//	    return schema.Exec(c.RequestCtx(), req.Query, req.OperationName, req.Variables)
+//	}, zeno.GraphQLConfig{Batching: true})
+//	app.To("GET,POST", "/graphql", gql)
+//	app.Get("/graphiql", zeno.GraphiQL("/graphql"))
+func GraphQL(exec GraphQLExecutor, config ...GraphQLConfig) Handler {
+	var cfg GraphQLConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = 10
+	}
+
+	fail := func(c *Context, status int, msg string) error {
+		var body graphQLError
+		body.Errors = append(body.Errors, struct {
+			Message string `json:"message"`
+		}{msg})
+		c.Status(status)
+		return c.SendJSON(body)
+	}
+
+	return func(c *Context) error {
+		switch c.Method() {
+		case MethodGet:
+			req := GraphQLRequest{
+				Query:         c.Query("query"),
+				OperationName: c.Query("operationName"),
+			}
+			if v := c.Query("variables"); v != "" {
+				if err := c.zeno.JsonDecoder([]byte(v), &req.Variables); err != nil {
+					return fail(c, StatusBadRequest, "invalid variables: "+err.Error())
+				}
+			}
+			if req.Query == "" {
+				return fail(c, StatusBadRequest, "missing query")
+			}
+			if !graphQLReadOnly(req.Query, req.OperationName) {
+				c.SetHeader(HeaderAllow, "POST")
+				return fail(c, StatusMethodNotAllowed, "only queries may be sent with GET")
+			}
+			return c.SendJSON(exec(c, req))
+
+		case MethodPost:
+			mt, _, _ := mime.ParseMediaType(c.GetHeader(HeaderContentType))
+			body := c.PostBody()
+			if mt == "application/graphql" {
+				return c.SendJSON(exec(c, GraphQLRequest{Query: string(body)}))
+			}
+			if mt != "application/json" {
+				return fail(c, StatusUnsupportedMediaType, "unsupported content type "+mt)
+			}
+			if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+				if !cfg.Batching {
+					return fail(c, StatusBadRequest, "batching is not enabled")
+				}
+				var reqs []GraphQLRequest
+				if err := c.zeno.JsonDecoder(trimmed, &reqs); err != nil {
+					return fail(c, StatusBadRequest, "invalid request: "+err.Error())
+				}
+				if len(reqs) > cfg.MaxBatch {
+					return fail(c, StatusBadRequest, "too many operations in batch")
+				}
+				results := make([]any, len(reqs))
+				for i, req := range reqs {
+					results[i] = exec(c, req)
+				}
+				return c.SendJSON(results)
+			}
+			var req GraphQLRequest
+			if err := c.zeno.JsonDecoder(body, &req); err != nil {
+				return fail(c, StatusBadRequest, "invalid request: "+err.Error())
+			}
+			if req.Query == "" {
+				return fail(c, StatusBadRequest, "missing query")
+			}
+			return c.SendJSON(exec(c, req))
+		}
+		c.SetHeader(HeaderAllow, "GET, POST")
+		return ErrMethodNotAllowed
+	}
+}
+
+// graphQLReadOnly reports whether the operations of doc that a request
+// for operationName may execute are all queries. Only the top level of
+// the document is scanned, which is enough to tell the operation types
+// apart without parsing selections. With no operationName every
+// operation of the document is considered.
+func graphQLReadOnly(doc, operationName string) bool {
+	var (
+		depth int    // nesting of braces, parentheses and brackets
+		kind  string // keyword of the definition being read
+		name  string // name of the definition being read
+	)
+	for i := 0; i < len(doc); i++ {
+		switch ch := doc[i]; {
+		case ch == '#':
+			for i < len(doc) && doc[i] != '\n' {
+				i++
+			}
+		case ch == '"':
+			if strings.HasPrefix(doc[i:], `"""`) {
+				end := strings.Index(doc[i+3:], `"""`)
+				if end < 0 {
+					return true
+				}
+				i += end + 5
+				break
+			}
+			for i++; i < len(doc) && doc[i] != '"'; i++ {
+				if doc[i] == '\\' {
+					i++
+				}
+			}
+		case ch == '{' || ch == '(' || ch == '[':
+			if depth == 0 && ch == '{' {
+				// A selection set without a keyword is a query.
+				if kind != "" && kind != "query" && kind != "fragment" &&
+					(operationName == "" || name == operationName) {
+					return false
+				}
+				kind, name = "", ""
+			}
+			depth++
+		case ch == '}' || ch == ')' || ch == ']':
+			depth--
+		case depth == 0 && isGraphQLName(ch, false):
+			j := i
+			for j < len(doc) && isGraphQLName(doc[j], true) {
+				j++
+			}
+			switch word := doc[i:j]; {
+			case i > 0 && doc[i-1] == '@':
+				// A directive, not the operation's name.
+			case kind == "":
+				kind = word
+			case name == "":
+				name = word
+			}
+			i = j - 1
+		}
+	}
+	return true
+}
+
+// isGraphQLName reports whether b may start a GraphQL name, or continue
+// one if rest is set.
+func isGraphQLName(b byte, rest bool) bool {
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || b == '_' || rest && '0' <= b && b <= '9'
+}
+
+// graphiqlPage is the GraphiQL IDE, loaded from a CDN.
+var graphiqlPage = template.Must(template.New("graphiql").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>GraphiQL</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css">
+  <style>html, body, #graphiql { height: 100%; margin: 0; }</style>
+</head>
+<body>
+  <div id="graphiql"></div>
+  <script src="https://unpkg.com/react@18/umd/react.production.min.js" crossorigin></script>
+  <script src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js" crossorigin></script>
+  <script src="https://unpkg.com/graphiql@3/graphiql.min.js" crossorigin></script>
+  <script>
+    const fetcher = GraphiQL.createFetcher({ url: {{.}} });
+    ReactDOM.createRoot(document.getElementById('graphiql'))
+      .render(React.createElement(GraphiQL, { fetcher }));
+  </script>
+</body>
+</html>`))
+
+// GraphiQL returns a handler serving the GraphiQL in-browser IDE, sending
+// its queries to endpoint. The page loads GraphiQL from a public CDN, so it
+// is meant for development and internal tools.
+//
+// Example:
+//
+//	app.Get("/graphiql", zeno.GraphiQL("/graphql"))
+func GraphiQL(endpoint string) Handler {
+	var buf bytes.Buffer
+	if err := graphiqlPage.Execute(&buf, endpoint); err != nil {
+		panic(err)
+	}
+	page := buf.Bytes()
+	return func(c *Context) error {
+		c.setMediaType("text/html", nil)
+		return c.SendBytes(page)
+	}
+}
@@ -0,0 +1,53 @@
+package zeno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQL(t *testing.T) {
+	z := New()
+	exec := func(c *Context, req GraphQLRequest) any {
+		return Map{"data": Map{"query": req.Query, "vars": req.Variables}}
+	}
+	z.To("GET,POST", "/graphql", GraphQL(exec, GraphQLConfig{Batching: true}))
+	z.Get("/graphiql", GraphiQL("/graphql"))
+	jsonCT := map[string]string{HeaderContentType: "application/json"}
+
+	ctx := performRequest(z, "GET", `/graphql?query={me}&variables={"id":1}`, nil, nil)
+	assert.JSONEq(t, `{"data":{"query":"{me}","vars":{"id":1}}}`, string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "POST", "/graphql", jsonCT, []byte(`{"query":"{a}"}`))
+	assert.JSONEq(t, `{"data":{"query":"{a}","vars":null}}`, string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "POST", "/graphql", jsonCT, []byte(`[{"query":"{a}"},{"query":"{b}"}]`))
+	assert.JSONEq(t, `[{"data":{"query":"{a}","vars":null}},{"data":{"query":"{b}","vars":null}}]`, string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "POST", "/graphql", map[string]string{HeaderContentType: "application/graphql"}, []byte(`{c}`))
+	assert.JSONEq(t, `{"data":{"query":"{c}","vars":null}}`, string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "POST", "/graphql", jsonCT, []byte(`{}`))
+	assert.Equal(t, StatusBadRequest, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"errors":[{"message":"missing query"}]}`, string(ctx.Response.Body()))
+
+	// Mutations may not be sent with GET.
+	for _, q := range []string{"mutation{del}", "mutation%20M{del}", "query%20Q{a}%20mutation%20M{del}", "%23%20{x}%0Amutation{del}"} {
+		ctx = performRequest(z, "GET", "/graphql?query="+q, nil, nil)
+		assert.Equal(t, StatusMethodNotAllowed, ctx.Response.StatusCode(), q)
+		assert.Equal(t, "POST", string(ctx.Response.Header.Peek(HeaderAllow)))
+	}
+	ctx = performRequest(z, "GET", "/graphql?query=query%20Q{a}%20mutation%20M{del}&operationName=Q", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	ctx = performRequest(z, "GET", `/graphql?query=query($m:String="mutation{"){a(m:$m)}%20fragment%20F%20on%20T{b}`, nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+
+	// POST requires a GraphQL content type.
+	for _, ct := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		ctx = performRequest(z, "POST", "/graphql", map[string]string{HeaderContentType: ct}, []byte(`{"query":"{a}"}`))
+		assert.Equal(t, StatusUnsupportedMediaType, ctx.Response.StatusCode(), ct)
+	}
+
+	ctx = performRequest(z, "GET", "/graphiql", nil, nil)
+	assert.Contains(t, string(ctx.Response.Body()), `url: "/graphql"`)
+}
@@ -1,6 +1,9 @@
 package zeno
 
-import "strings"
+import (
+	"slices"
+	"strings"
+)
 
 // RouteGroup represents a collection of routes with a common prefix and shared middleware handlers.
 // It allows organizing routes into subgroups for modular design.
@@ -8,15 +11,27 @@ type RouteGroup struct {
 	prefix   string    // Common path prefix for all routes in the group
 	zeno     *Zeno     // Reference to the parent Zeno instance
 	handlers []Handler // Middleware handlers applied to all routes in the group
+
+	parent   *RouteGroup   // Group this one was created from, nil for the root
+	children []*RouteGroup // Groups created from this one, guarded by zeno.mu
 }
 
 // NewRouteGroup creates and returns a new route group with the given path prefix,
 // associated Zeno instance, and optional middleware handlers.
 //
+// The prefix may contain parameters, including regular expression
+// constraints, which are matched and made available through Context.Param
+// to every route and middleware in the group. NewRouteGroup panics if the
+// prefix is not a valid pattern (see ValidatePath).
+//
 // Example:
 //
 //	api := NewRouteGroup("/api", app, []Handler{authMiddleware})
+//	tenant := NewRouteGroup("/tenants/{tenant:[a-z0-9-]+}", app, nil)
 func NewRouteGroup(prefix string, zeno *Zeno, handlers []Handler) *RouteGroup {
+	if err := ValidatePath(prefix); err != nil {
+		panic(err)
+	}
 	return &RouteGroup{
 		prefix:   prefix,
 		zeno:     zeno,
@@ -91,6 +106,17 @@ func (r *RouteGroup) To(methods, path string, handlers ...Handler) *Route {
 	return route
 }
 
+// Handle registers a new route in the group for an arbitrary HTTP method,
+// including methods without a dedicated helper such as the WebDAV methods
+// PROPFIND, MKCOL or REPORT. Methods are case-sensitive.
+//
+// Example:
+//
+//	g.Handle("PROPFIND", "/files/{path*}", propfindHandler)
+func (r *RouteGroup) Handle(method, path string, handlers ...Handler) *Route {
+	return newRoute(path, r).add(method, handlers)
+}
+
 // Use registers one or multiple handlers to the current route group.
 // These handlers will be shared by all routes belong to this group and its subgroups.
 func (r *RouteGroup) Use(handlers ...Handler) {
@@ -114,7 +140,12 @@ func (r *RouteGroup) Group(prefix string, handlers ...Handler) *RouteGroup {
 		handlers = make([]Handler, len(r.handlers))
 		copy(handlers, r.handlers)
 	}
-	return NewRouteGroup(r.prefix+prefix, r.zeno, handlers)
+	g := NewRouteGroup(r.prefix+prefix, r.zeno, handlers)
+	g.parent = r
+	r.zeno.mu.Lock()
+	r.children = append(r.children, g)
+	r.zeno.mu.Unlock()
+	return g
 }
 
 // Route creates a new sub-route group with the given path prefix and optional
@@ -126,6 +157,59 @@ func (r *RouteGroup) Group(prefix string, handlers ...Handler) *RouteGroup {
 //	    r.Get("/users", listUsers)
 //	})
 func (r *RouteGroup) Route(prefix string, fn func(*RouteGroup), handlers ...Handler) {
-	g := r.Group(r.prefix+prefix, handlers...)
+	g := r.Group(prefix, handlers...)
 	fn(g)
 }
+
+// Prefix returns the full path prefix of the group, including the
+// prefixes of the groups it was created from.
+func (r *RouteGroup) Prefix() string {
+	return r.prefix
+}
+
+// Parent returns the group r was created from with Group or Route, or nil
+// for the engine's root group and groups made with NewRouteGroup.
+func (r *RouteGroup) Parent() *RouteGroup {
+	return r.parent
+}
+
+// Children returns the groups created from r, in creation order.
+func (r *RouteGroup) Children() []*RouteGroup {
+	r.zeno.mu.RLock()
+	defer r.zeno.mu.RUnlock()
+	return slices.Clone(r.children)
+}
+
+// Middleware returns the handlers shared by the routes of the group, in
+// the order they run, including the ones inherited from its parent.
+func (r *RouteGroup) Middleware() []Handler {
+	return slices.Clone(r.handlers)
+}
+
+// Walk calls fn for r and then, depth first, for every group below it,
+// with depth 0 for r. It stops early when fn returns false, which makes
+// it suitable for generating documentation or auditing which middleware
+// protects which part of an application.
+//
+// Example:
+//
+//	app.Walk(func(g *zeno.RouteGroup, depth int) bool {
+//	    fmt.Printf("%s%s (%d middleware)\n", strings.Repeat("  ", depth), g.Prefix(), len(g.Middleware()))
+//	    return true
+//	})
+func (r *RouteGroup) Walk(fn func(g *RouteGroup, depth int) bool) {
+	r.walk(fn, 0)
+}
+
+// walk implements Walk, reporting whether the walk should continue.
+func (r *RouteGroup) walk(fn func(g *RouteGroup, depth int) bool, depth int) bool {
+	if !fn(r, depth) {
+		return false
+	}
+	for _, g := range r.Children() {
+		if !g.walk(fn, depth+1) {
+			return false
+		}
+	}
+	return true
+}
@@ -49,6 +49,9 @@ const (
 	// HeaderCacheControl specifies directives for caching mechanisms.
 	HeaderCacheControl = "Cache-Control"
 
+	// HeaderCacheStatus reports how caches handled the request (RFC 9211).
+	HeaderCacheStatus = "Cache-Status"
+
 	// HeaderConnection controls whether the network connection stays open after the transaction finishes.
 	HeaderConnection = "Connection"
 
@@ -139,6 +142,9 @@ const (
 	// HeaderRange specifies the range of bytes a client is requesting.
 	HeaderRange = "Range"
 
+	// HeaderXHTTPMethodOverride indicates the method a POST request should be handled as.
+	HeaderXHTTPMethodOverride = "X-HTTP-Method-Override"
+
 	// HeaderReferer indicates the address of the previous web page from which a link to the currently requested page was followed.
 	HeaderReferer = "Referer"
 
@@ -202,6 +208,18 @@ const (
 	// HeaderDigest provides a message digest for the resource.
 	HeaderDigest = "Digest"
 
+	// HeaderContentDigest carries digests of the message content (RFC 9530).
+	HeaderContentDigest = "Content-Digest"
+
+	// HeaderSignature carries HTTP message signatures (RFC 9421).
+	HeaderSignature = "Signature"
+
+	// HeaderSignatureInput describes the components and parameters of the message signatures (RFC 9421).
+	HeaderSignatureInput = "Signature-Input"
+
+	// HeaderAcceptSignature requests a message signature from the recipient (RFC 9421).
+	HeaderAcceptSignature = "Accept-Signature"
+
 	// HeaderEarlyData indicates if early data (0-RTT) is accepted.
 	HeaderEarlyData = "Early-Data"
 
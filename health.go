@@ -0,0 +1,90 @@
+package zeno
+
+// HealthChecker reports whether a dependency (database, cache, upstream
+// service) is usable. A non-nil error marks the service as not ready.
+type HealthChecker func(c *Context) error
+
+// HealthCheckConfig configures the HealthCheck middleware.
+type HealthCheckConfig struct {
+	// LivenessPath answers whether the process is alive. It always
+	// returns 200 while the server is able to handle requests.
+	// Defaults to "/livez".
+	LivenessPath string
+
+	// ReadinessPath answers whether the service can take traffic. It runs
+	// all Checkers and returns 503 if any fails or if the engine is
+	// shutting down. Defaults to "/readyz".
+	ReadinessPath string
+
+	// Checkers are run in order on every readiness probe.
+	Checkers []HealthChecker
+
+	// Detail adds a JSON body describing the result, including checker
+	// error messages. When false, probes answer with the plain status text.
+	Detail bool
+}
+
+// healthReport is the JSON body returned when HealthCheckConfig.Detail is set.
+type healthReport struct {
+	Status string   `json:"status"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// HealthCheck returns a middleware serving liveness and readiness probes
+// for orchestrators such as Kubernetes. Requests to other paths pass
+// through untouched.
+//
+// Readiness starts failing as soon as Zeno.Shutdown is called, so load
+// balancers stop routing new requests while existing connections drain.
+// Set Zeno.ShutdownDelay to keep accepting connections until they have
+// noticed.
+//
+// Example:
+//
+//	app.Use(zeno.HealthCheck(zeno.HealthCheckConfig{
+//	    Checkers: []zeno.HealthChecker{
+//	        func(c *zeno.Context) error { return db.PingContext(c.RequestCtx()) },
+//	    },
+//	    Detail: true,
+//	}))
+func HealthCheck(config HealthCheckConfig) Handler {
+	if config.LivenessPath == "" {
+		config.LivenessPath = "/livez"
+	}
+	if config.ReadinessPath == "" {
+		config.ReadinessPath = "/readyz"
+	}
+
+	respond := func(c *Context, errs []string) error {
+		c.Abort()
+		c.SetHeader(HeaderCacheControl, "no-store")
+		status, code := "ok", StatusOK
+		if len(errs) > 0 {
+			status, code = "unavailable", StatusServiceUnavailable
+		}
+		c.Status(code)
+		if config.Detail {
+			return c.SendJSON(healthReport{Status: status, Errors: errs})
+		}
+		return c.SendString(StatusMessage(code))
+	}
+
+	return func(c *Context) error {
+		switch c.Path() {
+		case config.LivenessPath:
+			return respond(c, nil)
+		case config.ReadinessPath:
+			var errs []string
+			if c.Zeno().IsShuttingDown() {
+				errs = append(errs, "shutting down")
+			}
+			for _, check := range config.Checkers {
+				if err := check(c); err != nil {
+					errs = append(errs, err.Error())
+				}
+			}
+			return respond(c, errs)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,213 @@
+package zeno
+
+import (
+	"math/rand/v2"
+	"net/netip"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HoneypotEvent describes a decoy hit or a tarpitted request, reported to
+// HoneypotConfig.OnEvent.
+type HoneypotEvent struct {
+	Time      time.Time
+	IP        string
+	Method    string
+	Path      string
+	UserAgent string
+
+	// Kind is "decoy" for requests to a decoy path and "tarpit" for
+	// delayed requests from flagged clients.
+	Kind string
+
+	// Delay is how long the response was held back.
+	Delay time.Duration
+}
+
+// HoneypotConfig configures the Honeypot middleware.
+type HoneypotConfig struct {
+	// Decoys lists the paths no legitimate client requests, such as
+	// "/wp-login.php", "/.env" or "/phpmyadmin*". Patterns use path.Match
+	// syntax; a trailing "*" also matches across slashes. Clients
+	// requesting them are flagged and get the decoy response.
+	Decoys []string
+
+	// DecoyHandler answers requests to decoy paths. By default it returns
+	// ErrNotFound, so decoys are indistinguishable from missing pages.
+	DecoyHandler Handler
+
+	// FlagDuration is how long a client stays flagged after hitting a
+	// decoy. Defaults to one hour.
+	FlagDuration time.Duration
+
+	// MaxFlagged bounds the number of clients flagged at once. Defaults
+	// to 10000; further clients are not flagged until entries expire.
+	MaxFlagged int
+
+	// Reputation, when set, reports whether a client should be treated as
+	// flagged, e.g. based on an IP reputation feed. It is consulted for
+	// clients that are not flagged already.
+	Reputation func(c *Context, ip string) bool
+
+	// TarpitDelay is how long responses to flagged clients are held back.
+	// Defaults to 5 seconds. Each delay occupies a goroutine, so keep it
+	// moderate under heavy abuse.
+	TarpitDelay time.Duration
+
+	// TarpitJitter is the upper bound of a random duration added to
+	// TarpitDelay, so delays cannot be told apart from a slow server.
+	// Defaults to 2 seconds; set a negative value to disable.
+	TarpitJitter time.Duration
+
+	// TrustedProxies lists the reverse proxies whose X-Forwarded-For
+	// header is used to find the client, as in IPFilterConfig.
+	TrustedProxies []string
+
+	// OnEvent, when set, is called for every decoy hit and tarpitted
+	// request, e.g. to log it or count it in metrics.
+	OnEvent func(c *Context, e HoneypotEvent)
+
+	// Skipper, when set, lets requests for which it returns true bypass
+	// the middleware.
+	Skipper Skipper
+}
+
+// honeypot holds the state of a Honeypot middleware.
+type honeypot struct {
+	config  HoneypotConfig
+	trusted []netip.Prefix
+
+	mu      sync.Mutex
+	flagged map[netip.Addr]time.Time // expiry per flagged client
+}
+
+// Honeypot returns a middleware for security-sensitive deployments that
+// serves decoy endpoints and slows down the clients probing them: a client
+// requesting a decoy path is flagged, and every later request from it, as
+// well as requests from clients the Reputation callback flags, is delayed
+// (tarpitted) before being handled normally. It panics if a decoy pattern
+// is malformed.
+//
+// Example:
+//
+//	app.Use(zeno.Honeypot(zeno.HoneypotConfig{
+//	    Decoys: []string{"/wp-login.php", "/.env", "/phpmyadmin*"},
+//	    OnEvent: func(c *zeno.Context, e zeno.HoneypotEvent) {
+//	        log.Printf("honeypot %s: %s %s %s", e.Kind, e.IP, e.Method, e.Path)
+//	    },
+//	}))
+func Honeypot(config HoneypotConfig) Handler {
+	for _, p := range config.Decoys {
+		if _, err := path.Match(p, ""); err != nil {
+			panic("zeno: invalid honeypot decoy pattern " + p + ": " + err.Error())
+		}
+	}
+	trusted, err := parsePrefixes(config.TrustedProxies)
+	if err != nil {
+		panic(err)
+	}
+	if config.DecoyHandler == nil {
+		config.DecoyHandler = func(*Context) error { return ErrNotFound }
+	}
+	if config.FlagDuration <= 0 {
+		config.FlagDuration = time.Hour
+	}
+	if config.MaxFlagged <= 0 {
+		config.MaxFlagged = 10000
+	}
+	if config.TarpitDelay <= 0 {
+		config.TarpitDelay = 5 * time.Second
+	}
+	if config.TarpitJitter == 0 {
+		config.TarpitJitter = 2 * time.Second
+	}
+	h := &honeypot{config: config, trusted: trusted, flagged: make(map[netip.Addr]time.Time)}
+	return h.handle
+}
+
+// handle implements the middleware.
+func (h *honeypot) handle(c *Context) error {
+	if h.config.Skipper != nil && h.config.Skipper(c) {
+		return c.Next()
+	}
+	ip, ok := clientAddr(c, h.trusted)
+	if ok && h.isDecoy(c.Path()) {
+		h.flag(ip)
+		h.tarpit(c, ip, "decoy")
+		c.Abort()
+		return h.config.DecoyHandler(c)
+	}
+	if ok && (h.isFlagged(ip) || (h.config.Reputation != nil && h.config.Reputation(c, ip.String()))) {
+		h.tarpit(c, ip, "tarpit")
+	}
+	return c.Next()
+}
+
+// isDecoy reports whether p matches one of the decoy patterns.
+func (h *honeypot) isDecoy(p string) bool {
+	for _, pattern := range h.config.Decoys {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && !strings.ContainsAny(prefix, "*?[\\") {
+			if strings.HasPrefix(p, prefix) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// flag marks ip as flagged for FlagDuration.
+func (h *honeypot) flag(ip netip.Addr) {
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.flagged[ip]; !ok && len(h.flagged) >= h.config.MaxFlagged {
+		for k, expiry := range h.flagged {
+			if now.After(expiry) {
+				delete(h.flagged, k)
+			}
+		}
+		if len(h.flagged) >= h.config.MaxFlagged {
+			return
+		}
+	}
+	h.flagged[ip] = now.Add(h.config.FlagDuration)
+}
+
+// isFlagged reports whether ip is currently flagged.
+func (h *honeypot) isFlagged(ip netip.Addr) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	expiry, ok := h.flagged[ip]
+	if ok && time.Now().After(expiry) {
+		delete(h.flagged, ip)
+		return false
+	}
+	return ok
+}
+
+// tarpit holds the request back for TarpitDelay plus jitter and reports
+// the event.
+func (h *honeypot) tarpit(c *Context, ip netip.Addr, kind string) {
+	delay := h.config.TarpitDelay
+	if j := h.config.TarpitJitter; j > 0 {
+		delay += rand.N(j)
+	}
+	if h.config.OnEvent != nil {
+		h.config.OnEvent(c, HoneypotEvent{
+			Time:      time.Now(),
+			IP:        ip.String(),
+			Method:    c.Method(),
+			Path:      c.Path(),
+			UserAgent: c.GetHeader(HeaderUserAgent),
+			Kind:      kind,
+			Delay:     delay,
+		})
+	}
+	time.Sleep(delay)
+}
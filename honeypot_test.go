@@ -0,0 +1,48 @@
+package zeno
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoneypot(t *testing.T) {
+	var events []HoneypotEvent
+	z := New()
+	z.Use(Honeypot(HoneypotConfig{
+		Decoys:       []string{"/.env", "/phpmyadmin*", "/*.php"},
+		TarpitDelay:  20 * time.Millisecond,
+		TarpitJitter: -1,
+		Reputation:   func(c *Context, ip string) bool { return ip == "6.6.6.6" },
+		OnEvent:      func(c *Context, e HoneypotEvent) { events = append(events, e) },
+	}))
+	z.Get("/", func(c *Context) error { return c.SendString("home") })
+
+	start := time.Now()
+	ctx := requestFrom(z, "1.1.1.1", "/", nil)
+	assert.Equal(t, "home", string(ctx.Response.Body()))
+	assert.Less(t, time.Since(start), 20*time.Millisecond)
+	assert.Empty(t, events)
+
+	for _, p := range []string{"/.env", "/phpmyadmin/index.php", "/wp-login.php"} {
+		ctx = requestFrom(z, "2.2.2.2", p, nil)
+		assert.Equal(t, StatusNotFound, ctx.Response.StatusCode(), p)
+	}
+	assert.Len(t, events, 3)
+	assert.Equal(t, "decoy", events[0].Kind)
+	assert.Equal(t, "/.env", events[0].Path)
+
+	start = time.Now()
+	ctx = requestFrom(z, "2.2.2.2", "/", nil)
+	assert.Equal(t, "home", string(ctx.Response.Body()))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, "tarpit", events[3].Kind)
+	assert.Equal(t, "2.2.2.2", events[3].IP)
+
+	requestFrom(z, "6.6.6.6", "/", nil)
+	assert.Len(t, events, 5)
+	assert.Equal(t, "6.6.6.6", events[4].IP)
+
+	assert.Panics(t, func() { Honeypot(HoneypotConfig{Decoys: []string{"/[a"}}) })
+}
@@ -0,0 +1,104 @@
+package zeno
+
+import (
+	"io"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/valyala/fasthttp"
+	"golang.org/x/net/http2"
+)
+
+// http2Hop lists the connection-specific headers HTTP/2 forbids in
+// responses.
+var http2Hop = map[string]bool{
+	HeaderConnection:       true,
+	HeaderKeepAlive:        true,
+	HeaderTransferEncoding: true,
+	HeaderUpgrade:          true,
+	"Proxy-Connection":     true,
+}
+
+// enableHTTP2 registers the HTTP/2 implementation for "h2", unless the
+// application registered its own with NextProto.
+func (z *Zeno) enableHTTP2() {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if _, ok := z.nextProtos["h2"]; ok {
+		return
+	}
+	z.h2Base = &http.Server{}
+	z.h2 = &http2.Server{}
+	if err := http2.ConfigureServer(z.h2Base, z.h2); err != nil {
+		panic(err)
+	}
+	if z.nextProtos == nil {
+		z.nextProtos = make(map[string]fasthttp.ServeHandler)
+	}
+	z.nextProtos["h2"] = z.serveHTTP2
+}
+
+// serveHTTP2 serves an HTTP/2 connection, handing every stream to
+// HandleRequest.
+func (z *Zeno) serveHTTP2(conn net.Conn) error {
+	s := z.Server()
+	z.h2.ServeConn(conn, &http2.ServeConnOpts{
+		BaseConfig: z.h2Base,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			z.serveHTTP2Stream(s, conn, w, r)
+		}),
+	})
+	return nil
+}
+
+// serveHTTP2Stream runs a single HTTP/2 request through HandleRequest. The
+// request context is bound to conn, so TLS state and the remote address
+// are reported as for HTTP/1.1.
+func (z *Zeno) serveHTTP2Stream(s *fasthttp.Server, conn net.Conn, w http.ResponseWriter, r *http.Request) {
+	limit := int64(s.MaxRequestBodySize)
+	if limit <= 0 {
+		limit = fasthttp.DefaultMaxRequestBodySize
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		w.WriteHeader(StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > limit {
+		w.WriteHeader(StatusRequestEntityTooLarge)
+		return
+	}
+
+	var logger fasthttp.Logger = log.Default()
+	if s.Logger != nil {
+		logger = s.Logger
+	}
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init2(conn, logger, false)
+	req := &ctx.Request
+	req.Header.SetProtocol("HTTP/2.0")
+	req.Header.SetMethod(r.Method)
+	req.SetRequestURI(r.URL.RequestURI())
+	req.Header.SetHost(r.Host)
+	for k, vv := range r.Header {
+		for _, v := range vv {
+			req.Header.Add(k, v)
+		}
+	}
+	req.SetBody(body)
+
+	z.HandleRequest(ctx)
+
+	resp := &ctx.Response
+	h := w.Header()
+	resp.Header.VisitAll(func(k, v []byte) {
+		if key := string(k); !http2Hop[key] {
+			h.Add(key, string(v))
+		}
+	})
+	w.WriteHeader(resp.StatusCode())
+	if err := resp.BodyWriteTo(w); err != nil {
+		logger.Printf("zeno: writing HTTP/2 response: %v", err)
+	}
+}
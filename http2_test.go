@@ -0,0 +1,69 @@
+package zeno
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZeno_HTTP2(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issue(t, &x509.Certificate{DNSNames: []string{"localhost"}, IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)}})
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	z := New()
+	z.HTTP2 = true
+	z.Post("/echo", func(c *Context) error {
+		c.SetHeader("X-Scheme", c.Scheme())
+		return c.SendString(c.Protocol() + " " + string(c.PostBody()))
+	})
+	errc := make(chan error, 1)
+	go func() { errc <- z.RunTLS("127.0.0.1:0", certFile, keyFile) }()
+
+	var addr string
+	assert.Eventually(t, func() bool {
+		z.mu.RLock()
+		defer z.mu.RUnlock()
+		if len(z.listeners) == 0 {
+			return false
+		}
+		addr = z.listeners[0].ln.Addr().String()
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: ca.pool},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Post("https://"+addr+"/echo", "text/plain", strings.NewReader("hi"))
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "HTTP/2.0", resp.Proto)
+		assert.Equal(t, "https", resp.Header.Get("X-Scheme"))
+		assert.Equal(t, "HTTP/2.0 hi", string(body))
+	}
+
+	// Starting the shared server again does not offer "h2" twice.
+	z.newServer()
+	assert.Equal(t, []string{"h2"}, z.Server().TLSConfig.NextProtos)
+
+	assert.NoError(t, z.Shutdown(context.Background()))
+	assert.NoError(t, <-errc)
+}
@@ -0,0 +1,730 @@
+package zeno
+
+import (
+	"container/list"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// CacheStore holds the responses kept by the Cache middleware as opaque
+// values. Implementations must be safe for concurrent use; a shared store
+// such as Redis lets several instances share one cache.
+type CacheStore interface {
+	// Get returns the value stored under key.
+	Get(key string) ([]byte, bool)
+
+	// Set stores value under key for at most ttl.
+	Set(key string, value []byte, ttl time.Duration)
+
+	// Delete removes the value stored under key.
+	Delete(key string)
+}
+
+// CacheConfig configures the Cache middleware.
+type CacheConfig struct {
+	// Store holds the cached responses. Defaults to a NewMemoryCacheStore
+	// of 64 MiB.
+	Store CacheStore
+
+	// Skipper, when set, bypasses the cache for requests for which it
+	// returns true.
+	Skipper Skipper
+
+	// MaxBodySize is the largest response body stored. Defaults to 1 MiB.
+	MaxBodySize int
+
+	// StaleTTL is how long a stale response with an ETag or Last-Modified
+	// validator is kept so it can be revalidated with a conditional
+	// request rather than fetched again. Defaults to one hour.
+	StaleTTL time.Duration
+}
+
+// cacheableStatus lists the status codes stored by Cache, mapped to
+// whether their freshness may be computed heuristically from
+// Last-Modified when the response has no explicit lifetime.
+var cacheableStatus = map[int]bool{
+	StatusOK: true, StatusNonAuthoritativeInfo: true, StatusNoContent: true,
+	StatusMultipleChoices: true, StatusMovedPermanently: true, StatusPermanentRedirect: true,
+	StatusNotFound: true, StatusMethodNotAllowed: true, StatusGone: true,
+	StatusRequestURITooLong: true, StatusNotImplemented: true,
+	StatusFound: false, StatusTemporaryRedirect: false,
+}
+
+// Cache returns a middleware that acts as a shared HTTP cache (RFC 9111)
+// in front of the rest of the chain, like a caching reverse proxy inside
+// the application. GET responses are stored according to their
+// Cache-Control, Expires and Vary headers and served to later requests
+// while fresh, with an Age header; HEAD requests are answered from stored
+// GET responses.
+//
+// Stale responses with an ETag or Last-Modified validator are revalidated
+// by running the chain with If-None-Match and If-Modified-Since, so a
+// handler answering 304 Not Modified (e.g. through SendFile or
+// SendContent) refreshes the stored response without rebuilding it.
+// Within a response's stale-while-revalidate window the stale response is
+// served at once and revalidated in the background; within its
+// stale-if-error window it is served when the chain fails or answers with
+// a 5xx status. must-revalidate, proxy-revalidate and s-maxage forbid
+// serving stale responses.
+//
+// Request directives are honoured too: no-cache forces revalidation,
+// max-age, min-fresh and max-stale bound the acceptable age, no-store
+// prevents storing the response and only-if-cached answers 504 Gateway
+// Timeout when nothing usable is stored. Responses that are private, set
+// cookies, vary on "*" or answer a request with an Authorization header
+// (unless public, s-maxage or must-revalidate allows it) are not stored.
+// Successful POST, PUT, PATCH and DELETE requests invalidate the stored
+// responses of their URL and of their Location and Content-Location.
+//
+// The Cache-Status header (RFC 9211) reports how each request was handled.
+//
+// Example:
+//
+//	app.Use(zeno.Cache())
+//
+//	app.Get("/products", func(c *zeno.Context) error {
+//	    c.CacheControl(zeno.CacheControl{
+//	        Public:               true,
+//	        MaxAge:               time.Minute,
+//	        StaleWhileRevalidate: time.Hour,
+//	        StaleIfError:         24 * time.Hour,
+//	    })
+//	    return c.SendJSON(listProducts())
+//	})
+func Cache(config ...CacheConfig) Handler {
+	var cfg CacheConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryCacheStore(64 << 20)
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1 << 20
+	}
+	if cfg.StaleTTL <= 0 {
+		cfg.StaleTTL = time.Hour
+	}
+	h := &httpCache{config: cfg, revalidating: make(map[string]struct{})}
+	return h.handle
+}
+
+// httpCache implements Cache.
+type httpCache struct {
+	config CacheConfig
+
+	mu           sync.Mutex
+	revalidating map[string]struct{} // keys being revalidated in the background
+}
+
+// cacheRevalidation marks the requests made to revalidate a response in
+// the background. Its value is the *httpCache that made the request.
+type cacheRevalidation struct{}
+
+// cachedResponse is a stored response.
+type cachedResponse struct {
+	// Vary holds the request headers selected by the response's Vary
+	// header, by lower-case name.
+	Vary map[string]string `json:"vary,omitempty"`
+
+	Status int         `json:"status"`
+	Header [][2]string `json:"header"`
+	Body   []byte      `json:"body,omitempty"`
+
+	// Stored is when the response was received; InitialAge is its Age
+	// when it was.
+	Stored     time.Time     `json:"stored"`
+	InitialAge time.Duration `json:"initial_age,omitempty"`
+
+	// Until is when the response can be discarded.
+	Until time.Time `json:"until"`
+}
+
+// handle is the middleware.
+func (h *httpCache) handle(c *Context) error {
+	if h.config.Skipper != nil && h.config.Skipper(c) {
+		return c.Next()
+	}
+	key := cacheKey(c.ctx.Host(), c.ctx.RequestURI())
+	method := c.Method()
+	if method != MethodGet && method != MethodHead {
+		err := c.Next()
+		if err == nil && c.ctx.Response.StatusCode() < 400 && isUnsafeMethod(method) {
+			h.invalidate(c, key)
+		}
+		return err
+	}
+
+	variants := h.load(key)
+	e := matchVariant(variants, &c.ctx.Request)
+	if c.ctx.UserValue(cacheRevalidation{}) == h {
+		return h.forward(c, key, variants, e, nil)
+	}
+	reqCC := cacheDirectives(c.GetHeader(HeaderCacheControl))
+	if len(reqCC) == 0 && strings.Contains(c.GetHeader(HeaderPragma), "no-cache") {
+		reqCC["no-cache"] = ""
+	}
+
+	if e != nil {
+		now := time.Now()
+		respCC := cacheDirectives(e.header(HeaderCacheControl))
+		age, lifetime := e.age(now), e.lifetime()
+		staleness := age - lifetime
+		_, validate := reqCC["no-cache"]
+		if _, ok := respCC["no-cache"]; ok {
+			validate = true
+		}
+		if maxAge, ok := directiveSeconds(reqCC, "max-age"); ok && age > maxAge {
+			validate = true
+		}
+		if minFresh, ok := directiveSeconds(reqCC, "min-fresh"); ok && lifetime-age < minFresh {
+			validate = true
+		}
+		if !validate {
+			if staleness < 0 {
+				return h.serve(c, e, age, "hit; ttl="+strconv.Itoa(int(-staleness/time.Second)))
+			}
+			if !forbidsStale(respCC) {
+				if v, ok := reqCC["max-stale"]; ok {
+					if maxStale, ok := directiveSeconds(reqCC, "max-stale"); v == "" || ok && staleness <= maxStale {
+						return h.serve(c, e, age, "hit; ttl="+strconv.Itoa(int(-staleness/time.Second)))
+					}
+				}
+				if swr, ok := directiveSeconds(respCC, "stale-while-revalidate"); ok && staleness <= swr {
+					h.revalidate(c, key)
+					return h.serve(c, e, age, "hit; ttl="+strconv.Itoa(int(-staleness/time.Second))+"; detail=stale-while-revalidate")
+				}
+			}
+		}
+	}
+	if _, ok := reqCC["only-if-cached"]; ok {
+		c.Abort()
+		c.SetHeader(HeaderCacheStatus, "zeno; fwd=miss; detail=only-if-cached")
+		return c.SendStatus(StatusGatewayTimeout)
+	}
+	return h.forward(c, key, variants, e, reqCC)
+}
+
+// forward runs the rest of the chain, as a conditional request if e has
+// validators, and stores the response if allowed. reqCC is nil for
+// background revalidations.
+func (h *httpCache) forward(c *Context, key string, variants []*cachedResponse, e *cachedResponse, reqCC map[string]string) error {
+	req := &c.ctx.Request.Header
+	etag, lastModified := "", ""
+	if e != nil {
+		etag, lastModified = e.header(HeaderETag), e.header(HeaderLastModified)
+	}
+	validating := etag != "" || lastModified != ""
+	var inm, ims []byte
+	if validating {
+		// Ask with the stored validators; the client's own conditions are
+		// restored afterwards and evaluated against the final response by
+		// serve.
+		inm = append(inm, req.Peek(HeaderIfNoneMatch)...)
+		ims = append(ims, req.Peek(HeaderIfModifiedSince)...)
+		setConditions(req, []byte(etag), []byte(lastModified))
+	}
+
+	err := c.Next()
+	if validating {
+		setConditions(req, inm, ims)
+	}
+	now := time.Now()
+	resp := &c.ctx.Response
+	if e != nil && (err != nil || resp.StatusCode() >= 500) {
+		respCC := cacheDirectives(e.header(HeaderCacheControl))
+		sie, ok := directiveSeconds(respCC, "stale-if-error")
+		if v, rok := directiveSeconds(reqCC, "stale-if-error"); rok && (!ok || v > sie) {
+			sie, ok = v, true
+		}
+		if age := e.age(now); ok && !forbidsStale(respCC) && age-e.lifetime() <= sie {
+			return h.serve(c, e, age, "hit; ttl="+strconv.Itoa(int((e.lifetime()-age)/time.Second))+"; detail=stale-if-error")
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if validating && resp.StatusCode() == StatusNotModified {
+		e.refresh(resp, now)
+		e.Until = h.until(e, now)
+		h.save(key, variants)
+		return h.serve(c, e, e.age(now), "fwd=stale; fwd-status=304")
+	}
+
+	fwd := "fwd=miss"
+	if e != nil {
+		fwd = "fwd=stale"
+	}
+	status := "zeno; " + fwd + "; fwd-status=" + strconv.Itoa(resp.StatusCode())
+	if stored := h.storable(c, reqCC, now); stored != nil {
+		h.save(key, replaceVariant(variants, stored))
+		status += "; stored"
+	}
+	resp.Header.Set(HeaderCacheStatus, status)
+	return nil
+}
+
+// serve answers the request with e, or with 304 Not Modified if the
+// client's conditional headers match it.
+func (h *httpCache) serve(c *Context, e *cachedResponse, age time.Duration, status string) error {
+	c.Abort()
+	resp := &c.ctx.Response
+	resp.Reset()
+	resp.SetStatusCode(e.Status)
+	for _, kv := range e.Header {
+		resp.Header.Add(kv[0], kv[1])
+	}
+	resp.Header.Set(HeaderAge, strconv.Itoa(int(age/time.Second)))
+	resp.Header.Set(HeaderCacheStatus, "zeno; "+status)
+	if e.Status == StatusOK && clientNotModified(c, e) {
+		resp.SetStatusCode(StatusNotModified)
+		return nil
+	}
+	resp.SetBody(e.Body)
+	return nil
+}
+
+// clientNotModified evaluates the client's If-None-Match and
+// If-Modified-Since headers against e.
+func clientNotModified(c *Context, e *cachedResponse) bool {
+	if inm := c.GetHeader(HeaderIfNoneMatch); inm != "" {
+		etag := e.header(HeaderETag)
+		return etag != "" && etagMatches(inm, etag)
+	}
+	ims, lm := c.GetHeader(HeaderIfModifiedSince), e.header(HeaderLastModified)
+	if ims == "" || lm == "" {
+		return false
+	}
+	since, err1 := http.ParseTime(ims)
+	modified, err2 := http.ParseTime(lm)
+	return err1 == nil && err2 == nil && !modified.After(since)
+}
+
+// storable returns the response to store, or nil if it may not be.
+func (h *httpCache) storable(c *Context, reqCC map[string]string, now time.Time) *cachedResponse {
+	resp := &c.ctx.Response
+	heuristic, ok := cacheableStatus[resp.StatusCode()]
+	if c.Method() != MethodGet || !ok || resp.IsBodyStream() || len(resp.Body()) > h.config.MaxBodySize {
+		return nil
+	}
+	respCC := cacheDirectives(string(resp.Header.Peek(HeaderCacheControl)))
+	if _, ok := reqCC["no-store"]; ok {
+		return nil
+	}
+	for _, d := range []string{"no-store", "private"} {
+		if _, ok := respCC[d]; ok {
+			return nil
+		}
+	}
+	if len(c.ctx.Request.Header.Peek(HeaderAuthorization)) > 0 {
+		_, public := respCC["public"]
+		_, smaxage := respCC["s-maxage"]
+		_, revalidate := respCC["must-revalidate"]
+		if !public && !smaxage && !revalidate {
+			return nil
+		}
+	}
+	if len(resp.Header.Peek(HeaderSetCookie)) > 0 {
+		return nil
+	}
+
+	e := &cachedResponse{Status: resp.StatusCode(), Body: append([]byte(nil), resp.Body()...), Stored: now}
+	for name := range strings.SplitSeq(string(resp.Header.Peek(HeaderVary)), ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name == "*" {
+			return nil
+		} else if name != "" {
+			if e.Vary == nil {
+				e.Vary = make(map[string]string)
+			}
+			e.Vary[name] = string(c.ctx.Request.Header.Peek(name))
+		}
+	}
+	resp.Header.VisitAll(func(k, v []byte) {
+		switch string(k) {
+		case HeaderContentLength, HeaderDate, HeaderConnection, HeaderTransferEncoding, HeaderAge, HeaderCacheStatus:
+			if string(k) == HeaderAge {
+				if age, err := strconv.Atoi(string(v)); err == nil && age > 0 {
+					e.InitialAge = time.Duration(age) * time.Second
+				}
+			}
+			return
+		}
+		e.Header = append(e.Header, [2]string{string(k), string(v)})
+	})
+
+	if e.explicitLifetime() < 0 && !heuristic {
+		return nil
+	}
+	// Keep only responses that can still be served fresh, stale or after
+	// revalidation.
+	if e.Until = h.until(e, now); !e.Until.After(now) {
+		return nil
+	}
+	return e
+}
+
+// until returns when e can be discarded: once it can no longer be served
+// stale, or after StaleTTL if it has validators.
+func (h *httpCache) until(e *cachedResponse, now time.Time) time.Time {
+	respCC := cacheDirectives(e.header(HeaderCacheControl))
+	keep := time.Duration(0)
+	if !forbidsStale(respCC) {
+		for _, d := range []string{"stale-while-revalidate", "stale-if-error"} {
+			if v, ok := directiveSeconds(respCC, d); ok && v > keep {
+				keep = v
+			}
+		}
+	}
+	if (e.header(HeaderETag) != "" || e.header(HeaderLastModified) != "") && h.config.StaleTTL > keep {
+		keep = h.config.StaleTTL
+	}
+	return now.Add(e.lifetime() - e.InitialAge + keep)
+}
+
+// revalidate refreshes the response stored under key in the background,
+// by running a copy of the current request through the engine.
+func (h *httpCache) revalidate(c *Context, key string) {
+	if c.zeno.IsShuttingDown() {
+		return
+	}
+	h.mu.Lock()
+	if _, ok := h.revalidating[key]; ok {
+		h.mu.Unlock()
+		return
+	}
+	h.revalidating[key] = struct{}{}
+	h.mu.Unlock()
+
+	ctx := &fasthttp.RequestCtx{}
+	c.ctx.Request.CopyTo(&ctx.Request)
+	ctx.Request.Header.SetMethod(MethodGet)
+	ctx.SetUserValue(cacheRevalidation{}, h)
+	go func() {
+		defer func() {
+			h.mu.Lock()
+			delete(h.revalidating, key)
+			h.mu.Unlock()
+		}()
+		c.zeno.HandleRequest(ctx)
+	}()
+}
+
+// invalidate removes the responses stored for the target of an unsafe
+// request and for its Location and Content-Location on the same host.
+func (h *httpCache) invalidate(c *Context, key string) {
+	h.config.Store.Delete(key)
+	host := c.ctx.Host()
+	for _, name := range []string{HeaderLocation, HeaderContentLocation} {
+		u, err := url.Parse(string(c.ctx.Response.Header.Peek(name)))
+		if err != nil || u.Path == "" || (u.Host != "" && u.Host != string(host)) {
+			continue
+		}
+		h.config.Store.Delete(cacheKey(host, []byte(u.RequestURI())))
+	}
+}
+
+// load returns the responses stored under key.
+func (h *httpCache) load(key string) []*cachedResponse {
+	data, ok := h.config.Store.Get(key)
+	if !ok {
+		return nil
+	}
+	var variants []*cachedResponse
+	if json.Unmarshal(data, &variants) != nil {
+		return nil
+	}
+	return variants
+}
+
+// save stores variants under key until the last of them expires.
+func (h *httpCache) save(key string, variants []*cachedResponse) {
+	var until time.Time
+	for _, v := range variants {
+		if v.Until.After(until) {
+			until = v.Until
+		}
+	}
+	data, err := json.Marshal(variants)
+	if err != nil {
+		return
+	}
+	if ttl := time.Until(until); ttl > 0 {
+		h.config.Store.Set(key, data, ttl)
+	}
+}
+
+// setConditions replaces the If-None-Match and If-Modified-Since headers
+// of req, removing them when empty.
+func setConditions(req *fasthttp.RequestHeader, inm, ims []byte) {
+	req.Del(HeaderIfNoneMatch)
+	req.Del(HeaderIfModifiedSince)
+	if len(inm) > 0 {
+		req.SetBytesV(HeaderIfNoneMatch, inm)
+	}
+	if len(ims) > 0 {
+		req.SetBytesV(HeaderIfModifiedSince, ims)
+	}
+}
+
+// cacheKey returns the key of the responses for uri on host.
+func cacheKey(host, uri []byte) string {
+	return string(host) + string(uri)
+}
+
+// isUnsafeMethod reports whether method may change the target resource.
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case MethodPost, MethodPut, MethodPatch, MethodDelete:
+		return true
+	}
+	return false
+}
+
+// matchVariant returns the variant whose Vary headers match req.
+func matchVariant(variants []*cachedResponse, req *fasthttp.Request) *cachedResponse {
+	for _, v := range variants {
+		if v.matches(req) {
+			return v
+		}
+	}
+	return nil
+}
+
+// replaceVariant adds e to variants, in place of the variant it
+// supersedes, keeping at most eight.
+func replaceVariant(variants []*cachedResponse, e *cachedResponse) []*cachedResponse {
+	out := []*cachedResponse{e}
+	for _, v := range variants {
+		if len(out) == 8 {
+			break
+		}
+		if !sameVary(v.Vary, e.Vary) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// sameVary reports whether a and b select the same request headers.
+func sameVary(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether req has the header values e varies on.
+func (e *cachedResponse) matches(req *fasthttp.Request) bool {
+	for name, value := range e.Vary {
+		if string(req.Header.Peek(name)) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// header returns the first stored value of the header name.
+func (e *cachedResponse) header(name string) string {
+	for _, kv := range e.Header {
+		if strings.EqualFold(kv[0], name) {
+			return kv[1]
+		}
+	}
+	return ""
+}
+
+// age returns the current age of e (RFC 9111, section 4.2.3).
+func (e *cachedResponse) age(now time.Time) time.Duration {
+	return e.InitialAge + now.Sub(e.Stored)
+}
+
+// explicitLifetime returns the freshness lifetime set by s-maxage,
+// max-age or Expires, or -1 if there is none.
+func (e *cachedResponse) explicitLifetime() time.Duration {
+	cc := cacheDirectives(e.header(HeaderCacheControl))
+	if v, ok := directiveSeconds(cc, "s-maxage"); ok {
+		return v
+	}
+	if v, ok := directiveSeconds(cc, "max-age"); ok {
+		return v
+	}
+	if exp := e.header(HeaderExpires); exp != "" {
+		t, err := http.ParseTime(exp)
+		if err != nil {
+			return 0 // invalid dates mean "already expired"
+		}
+		date := e.Stored
+		if d, err := http.ParseTime(e.header(HeaderDate)); err == nil {
+			date = d
+		}
+		if t.Before(date) {
+			return 0
+		}
+		return t.Sub(date)
+	}
+	return -1
+}
+
+// lifetime returns the freshness lifetime of e, falling back to a tenth
+// of the time since Last-Modified, at most a day.
+func (e *cachedResponse) lifetime() time.Duration {
+	if l := e.explicitLifetime(); l >= 0 {
+		return l
+	}
+	if !cacheableStatus[e.Status] {
+		return 0
+	}
+	lm, err := http.ParseTime(e.header(HeaderLastModified))
+	if err != nil || !lm.Before(e.Stored) {
+		return 0
+	}
+	return min(e.Stored.Sub(lm)/10, 24*time.Hour)
+}
+
+// refresh updates e with the headers of a 304 Not Modified response
+// received at now (RFC 9111, section 4.3.4).
+func (e *cachedResponse) refresh(resp *fasthttp.Response, now time.Time) {
+	updated := map[string]bool{}
+	var header [][2]string
+	resp.Header.VisitAll(func(k, v []byte) {
+		name := string(k)
+		switch {
+		case strings.HasPrefix(name, "Content-"), name == HeaderDate, name == HeaderConnection,
+			name == HeaderTransferEncoding, name == HeaderAge, name == HeaderCacheStatus:
+			return
+		}
+		updated[strings.ToLower(name)] = true
+		header = append(header, [2]string{name, string(v)})
+	})
+	for _, kv := range e.Header {
+		if !updated[strings.ToLower(kv[0])] {
+			header = append(header, kv)
+		}
+	}
+	e.Header, e.Stored, e.InitialAge = header, now, 0
+}
+
+// forbidsStale reports whether the directives forbid serving the
+// response once stale.
+func forbidsStale(cc map[string]string) bool {
+	for _, d := range []string{"must-revalidate", "proxy-revalidate", "s-maxage"} {
+		if _, ok := cc[d]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheDirectives parses a Cache-Control header into its directives, by
+// lower-case name, with unquoted values.
+func cacheDirectives(header string) map[string]string {
+	d := map[string]string{}
+	for part := range strings.SplitSeq(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			d[name] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return d
+}
+
+// directiveSeconds returns the value of a delta-seconds directive.
+func directiveSeconds(d map[string]string, name string) (time.Duration, bool) {
+	v, ok := d[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// memoryCacheStore is the CacheStore returned by NewMemoryCacheStore.
+type memoryCacheStore struct {
+	max int64
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List // of *memoryCacheItem, most recently used first
+	entries map[string]*list.Element
+}
+
+// memoryCacheItem is a value held by a memoryCacheStore.
+type memoryCacheItem struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCacheStore returns a CacheStore keeping up to maxBytes of
+// values in memory, evicting the least recently used ones first.
+func NewMemoryCacheStore(maxBytes int64) CacheStore {
+	return &memoryCacheStore{max: maxBytes, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements CacheStore.
+func (s *memoryCacheStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	item := el.Value.(*memoryCacheItem)
+	if time.Now().After(item.expires) {
+		s.remove(el)
+		return nil, false
+	}
+	s.order.MoveToFront(el)
+	return item.value, true
+}
+
+// Set implements CacheStore.
+func (s *memoryCacheStore) Set(key string, value []byte, ttl time.Duration) {
+	n := int64(len(key) + len(value))
+	if n > s.max {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[key]; ok {
+		s.remove(el)
+	}
+	s.entries[key] = s.order.PushFront(&memoryCacheItem{key, value, time.Now().Add(ttl)})
+	s.size += n
+	for s.size > s.max {
+		s.remove(s.order.Back())
+	}
+}
+
+// Delete implements CacheStore.
+func (s *memoryCacheStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[key]; ok {
+		s.remove(el)
+	}
+}
+
+// remove drops el. The caller holds s.mu.
+func (s *memoryCacheStore) remove(el *list.Element) {
+	item := el.Value.(*memoryCacheItem)
+	s.order.Remove(el)
+	delete(s.entries, item.key)
+	s.size -= int64(len(item.key) + len(item.value))
+}
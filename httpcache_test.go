@@ -0,0 +1,241 @@
+package zeno
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_FreshAndVary(t *testing.T) {
+	z := New()
+	z.Use(Cache())
+	var calls atomic.Int32
+	z.Get("/greet", func(c *Context) error {
+		calls.Add(1)
+		c.SetHeader(HeaderCacheControl, "max-age=60")
+		c.SetHeader(HeaderVary, HeaderAcceptLanguage)
+		return c.SendString("hello " + c.GetHeader(HeaderAcceptLanguage))
+	})
+
+	ctx := performRequest(z, "GET", "/greet", map[string]string{HeaderAcceptLanguage: "en"}, nil)
+	assert.Equal(t, "hello en", string(ctx.Response.Body()))
+	assert.Equal(t, "zeno; fwd=miss; fwd-status=200; stored", string(ctx.Response.Header.Peek(HeaderCacheStatus)))
+
+	ctx = performRequest(z, "GET", "/greet", map[string]string{HeaderAcceptLanguage: "en"}, nil)
+	assert.Equal(t, "hello en", string(ctx.Response.Body()))
+	assert.Equal(t, "0", string(ctx.Response.Header.Peek(HeaderAge)))
+	assert.Contains(t, string(ctx.Response.Header.Peek(HeaderCacheStatus)), "zeno; hit; ttl=")
+	assert.Equal(t, int32(1), calls.Load())
+
+	ctx = performRequest(z, "GET", "/greet", map[string]string{HeaderAcceptLanguage: "fr"}, nil)
+	assert.Equal(t, "hello fr", string(ctx.Response.Body()))
+	assert.Equal(t, int32(2), calls.Load())
+	performRequest(z, "GET", "/greet", map[string]string{HeaderAcceptLanguage: "en"}, nil)
+	performRequest(z, "HEAD", "/greet", map[string]string{HeaderAcceptLanguage: "fr"}, nil)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Request directives.
+	performRequest(z, "GET", "/greet", map[string]string{HeaderAcceptLanguage: "en", HeaderCacheControl: "no-cache"}, nil)
+	assert.Equal(t, int32(3), calls.Load())
+	ctx = performRequest(z, "GET", "/other", map[string]string{HeaderCacheControl: "only-if-cached"}, nil)
+	assert.Equal(t, StatusGatewayTimeout, ctx.Response.StatusCode())
+}
+
+func TestCache_NotStored(t *testing.T) {
+	z := New()
+	z.Use(Cache())
+	var calls atomic.Int32
+	handler := func(cc string, cookie bool) Handler {
+		return func(c *Context) error {
+			calls.Add(1)
+			c.SetHeader(HeaderCacheControl, cc)
+			if cookie {
+				c.SetHeader(HeaderSetCookie, "session=1")
+			}
+			return c.SendString("x")
+		}
+	}
+	z.Get("/private", handler("private, max-age=60", false))
+	z.Get("/no-store", handler("no-store", false))
+	z.Get("/cookie", handler("max-age=60", true))
+	z.Get("/auth", handler("max-age=60", false))
+
+	for _, tt := range []struct {
+		path    string
+		headers map[string]string
+	}{
+		{"/private", nil},
+		{"/no-store", nil},
+		{"/cookie", nil},
+		{"/auth", map[string]string{HeaderAuthorization: "Bearer t"}},
+	} {
+		calls.Store(0)
+		performRequest(z, "GET", tt.path, tt.headers, nil)
+		performRequest(z, "GET", tt.path, tt.headers, nil)
+		assert.Equal(t, int32(2), calls.Load(), tt.path)
+	}
+}
+
+func TestCache_Revalidate(t *testing.T) {
+	z := New()
+	z.Use(Cache())
+	var calls, notModified atomic.Int32
+	z.Get("/doc", func(c *Context) error {
+		calls.Add(1)
+		c.SetHeader(HeaderCacheControl, "max-age=0")
+		c.SetHeader(HeaderETag, `"v1"`)
+		if c.GetHeader(HeaderIfNoneMatch) == `"v1"` {
+			notModified.Add(1)
+			return c.SendStatus(StatusNotModified)
+		}
+		return c.SendString("document")
+	})
+
+	performRequest(z, "GET", "/doc", nil, nil)
+	ctx := performRequest(z, "GET", "/doc", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "document", string(ctx.Response.Body()))
+	assert.Equal(t, "zeno; fwd=stale; fwd-status=304", string(ctx.Response.Header.Peek(HeaderCacheStatus)))
+	assert.Equal(t, int32(1), notModified.Load())
+
+	// The client's own validator is answered by the cache.
+	ctx = performRequest(z, "GET", "/doc", map[string]string{HeaderIfNoneMatch: `"v1"`}, nil)
+	assert.Equal(t, StatusNotModified, ctx.Response.StatusCode())
+	ctx = performRequest(z, "GET", "/doc", map[string]string{HeaderIfNoneMatch: `"v0"`}, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "document", string(ctx.Response.Body()))
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestCache_StaleWhileRevalidate(t *testing.T) {
+	z := New()
+	z.Use(Cache())
+	var version atomic.Int32
+	refreshed := make(chan struct{}, 1)
+	z.Get("/news", func(c *Context) error {
+		v := version.Add(1)
+		c.SetHeader(HeaderCacheControl, "max-age=0, stale-while-revalidate=60")
+		if v > 1 {
+			c.AfterResponse(func() { refreshed <- struct{}{} })
+		}
+		return c.SendString("v" + string(rune('0'+v)))
+	})
+
+	assert.Equal(t, "v1", string(performRequest(z, "GET", "/news", nil, nil).Response.Body()))
+	ctx := performRequest(z, "GET", "/news", nil, nil)
+	assert.Equal(t, "v1", string(ctx.Response.Body()))
+	assert.Contains(t, string(ctx.Response.Header.Peek(HeaderCacheStatus)), "detail=stale-while-revalidate")
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("stale response was not revalidated")
+	}
+	assert.Eventually(t, func() bool {
+		return string(performRequest(z, "GET", "/news", nil, nil).Response.Body()) != "v1"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCache_StaleIfError(t *testing.T) {
+	z := New()
+	z.Use(Cache())
+	var failing atomic.Bool
+	z.Get("/feed", func(c *Context) error {
+		if failing.Load() {
+			return NewHTTPError(StatusServiceUnavailable, "down")
+		}
+		c.SetHeader(HeaderCacheControl, "max-age=0, stale-if-error=60")
+		return c.SendString("feed")
+	})
+	z.Get("/strict", func(c *Context) error {
+		if failing.Load() {
+			return NewHTTPError(StatusServiceUnavailable, "down")
+		}
+		c.SetHeader(HeaderCacheControl, "max-age=0, must-revalidate, stale-if-error=60")
+		c.SetHeader(HeaderLastModified, time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+		return c.SendString("strict")
+	})
+
+	performRequest(z, "GET", "/feed", nil, nil)
+	performRequest(z, "GET", "/strict", nil, nil)
+	failing.Store(true)
+
+	ctx := performRequest(z, "GET", "/feed", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "feed", string(ctx.Response.Body()))
+	assert.Contains(t, string(ctx.Response.Header.Peek(HeaderCacheStatus)), "detail=stale-if-error")
+
+	assert.Equal(t, StatusServiceUnavailable, performRequest(z, "GET", "/strict", nil, nil).Response.StatusCode())
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	z := New()
+	z.Use(Cache())
+	var calls atomic.Int32
+	z.Get("/items/{id}", func(c *Context) error {
+		calls.Add(1)
+		c.SetHeader(HeaderCacheControl, "max-age=60")
+		return c.SendString("item")
+	})
+	z.Put("/items/{id}", func(c *Context) error {
+		return c.SendStatus(StatusNoContent)
+	})
+	z.Post("/items/{id}/move", func(c *Context) error {
+		c.SetHeader(HeaderLocation, "/items/2")
+		return c.SendStatus(StatusSeeOther)
+	})
+
+	performRequest(z, "GET", "/items/1", nil, nil)
+	performRequest(z, "GET", "/items/2", nil, nil)
+	performRequest(z, "GET", "/items/1", nil, nil)
+	assert.Equal(t, int32(2), calls.Load())
+
+	performRequest(z, "PUT", "/items/1", nil, nil)
+	performRequest(z, "GET", "/items/1", nil, nil)
+	assert.Equal(t, int32(3), calls.Load())
+
+	performRequest(z, "POST", "/items/1/move", nil, nil)
+	performRequest(z, "GET", "/items/2", nil, nil)
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestCacheEntry_Lifetime(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		header [][2]string
+		want   time.Duration
+	}{
+		{[][2]string{{HeaderCacheControl, "max-age=60, s-maxage=120"}}, 120 * time.Second},
+		{[][2]string{{HeaderCacheControl, `max-age="30"`}}, 30 * time.Second},
+		{[][2]string{{HeaderDate, now.Format(http.TimeFormat)}, {HeaderExpires, now.Add(time.Hour).Format(http.TimeFormat)}}, time.Hour},
+		{[][2]string{{HeaderExpires, "0"}}, 0},
+		{[][2]string{{HeaderLastModified, now.Add(-10 * time.Hour).Format(http.TimeFormat)}}, time.Hour},
+		{nil, 0},
+	} {
+		e := &cachedResponse{Status: StatusOK, Header: tt.header, Stored: now}
+		assert.Equal(t, tt.want, e.lifetime(), tt.header)
+	}
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	s := NewMemoryCacheStore(10)
+	s.Set("a", []byte("aaa"), time.Minute)
+	s.Set("b", []byte("bbb"), time.Minute)
+	_, _ = s.Get("a")
+	s.Set("c", []byte("ccc"), time.Minute)
+	_, ok := s.Get("b")
+	assert.False(t, ok, "least recently used entry is evicted")
+	v, ok := s.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "aaa", string(v))
+
+	s.Set("d", []byte("d"), -time.Second)
+	_, ok = s.Get("d")
+	assert.False(t, ok, "expired entry is not returned")
+	s.Delete("a")
+	_, ok = s.Get("a")
+	assert.False(t, ok)
+}
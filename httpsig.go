@@ -0,0 +1,955 @@
+package zeno
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Algorithms of HTTP message signatures (RFC 9421, section 3.3).
+const (
+	SignatureEd25519         = "ed25519"
+	SignatureHMACSHA256      = "hmac-sha256"
+	SignatureECDSAP256SHA256 = "ecdsa-p256-sha256"
+	SignatureRSAPSSSHA512    = "rsa-pss-sha512"
+	SignatureRSAv15SHA256    = "rsa-v1_5-sha256"
+)
+
+// ErrUnknownSignatureKey is returned by a SignatureKeyStore for key IDs it
+// does not know.
+var ErrUnknownSignatureKey = errors.New("zeno: unknown signature key")
+
+// SignatureKey is a key creating or verifying HTTP message signatures.
+//
+// Key holds an ed25519.PrivateKey, *ecdsa.PrivateKey (P-256) or
+// *rsa.PrivateKey to sign, the matching public key (or the private key) to
+// verify, and the shared secret as []byte for SignatureHMACSHA256.
+type SignatureKey struct {
+	// ID identifies the key to the recipient, as the keyid parameter.
+	ID string
+
+	// Algorithm is one of the Signature* algorithms.
+	Algorithm string
+
+	Key any
+}
+
+// SignatureKeyStore looks up the keys verifying message signatures, e.g.
+// from the published keys of federated servers.
+type SignatureKeyStore interface {
+	// SignatureKey returns the key with the given ID, or an error wrapping
+	// ErrUnknownSignatureKey.
+	SignatureKey(keyID string) (SignatureKey, error)
+}
+
+// SignatureKeyStoreFunc adapts a function to a SignatureKeyStore.
+type SignatureKeyStoreFunc func(keyID string) (SignatureKey, error)
+
+// SignatureKey calls f(keyID).
+func (f SignatureKeyStoreFunc) SignatureKey(keyID string) (SignatureKey, error) { return f(keyID) }
+
+// NewSignatureKeyStore returns a SignatureKeyStore holding keys, by ID.
+func NewSignatureKeyStore(keys ...SignatureKey) SignatureKeyStore {
+	byID := make(map[string]SignatureKey, len(keys))
+	for _, k := range keys {
+		byID[k.ID] = k
+	}
+	return SignatureKeyStoreFunc(func(keyID string) (SignatureKey, error) {
+		if k, ok := byID[keyID]; ok {
+			return k, nil
+		}
+		return SignatureKey{}, ErrUnknownSignatureKey
+	})
+}
+
+// Signature describes a verified request signature. See
+// Context.Signature.
+type Signature struct {
+	Label     string
+	KeyID     string
+	Algorithm string
+
+	// Components lists the covered components, e.g. "@method" or
+	// "content-digest".
+	Components []string
+
+	Created time.Time
+	Expires time.Time // zero when the signature does not expire
+	Nonce   string
+	Tag     string
+}
+
+// SignatureConfig configures the signatures created by SignResponses and
+// SignRequest.
+type SignatureConfig struct {
+	// Key signs the messages. Required.
+	Key SignatureKey
+
+	// Label names the signature in the Signature and Signature-Input
+	// headers. Defaults to "sig1".
+	Label string
+
+	// Components lists the covered components: derived components such as
+	// "@method", "@authority", "@path", "@query", "@target-uri" and
+	// "@status", and lower-case header names. In responses a ";req"
+	// suffix covers a component of the request, binding the response to
+	// it, e.g. "@method;req".
+	//
+	// Defaults to "@status" and Content-Type for responses, and to
+	// "@method", "@authority", "@path", "@query" and Content-Type for
+	// requests, plus Content-Digest for messages with a body.
+	// Content-Digest (RFC 9530) is computed when covered but missing.
+	Components []string
+
+	// Expires, when positive, limits the validity of the signatures.
+	Expires time.Duration
+
+	// Nonce, when set, returns the nonce parameter of each signature.
+	Nonce func() string
+
+	// Tag sets the tag parameter, naming the application of the
+	// signatures.
+	Tag string
+
+	// Skipper, when set, leaves the responses of requests for which it
+	// returns true unsigned.
+	Skipper Skipper
+}
+
+// SignResponses returns a middleware signing responses with HTTP message
+// signatures (RFC 9421), so recipients such as federated servers or
+// webhook consumers can verify their origin and integrity. Responses of
+// handlers returning an error are left unsigned; streamed bodies cannot be
+// covered by Content-Digest.
+//
+// Example:
+//
+//	app.Use(zeno.SignResponses(zeno.SignatureConfig{
+//	    Key: zeno.SignatureKey{ID: "2024-05", Algorithm: zeno.SignatureEd25519, Key: privateKey},
+//	    Components: []string{"@status", "content-type", "content-digest", "@method;req", "@target-uri;req"},
+//	}))
+func SignResponses(config SignatureConfig) Handler {
+	components, err := config.prepare()
+	if err != nil {
+		panic("zeno: SignResponses: " + err.Error())
+	}
+	return func(c *Context) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return c.Next()
+		}
+		if err := c.Next(); err != nil {
+			return err
+		}
+		m := sigMessage{req: &c.ctx.Request, resp: &c.ctx.Response, scheme: c.Scheme()}
+		if err := m.sign(config, components, time.Now()); err != nil {
+			return NewHTTPError(StatusInternalServerError, "sign response: "+err.Error())
+		}
+		return nil
+	}
+}
+
+// SignRequest signs an outgoing request with an HTTP message signature
+// (RFC 9421), e.g. a webhook delivery. Its URI must be absolute. The
+// Skipper of config is ignored.
+//
+// Example:
+//
+//	req.SetRequestURI("https://consumer.example/hooks")
+//	req.SetBodyString(payload)
+//	if err := zeno.SignRequest(req, zeno.SignatureConfig{Key: key, Tag: "webhook"}); err != nil {
+//	    return err
+//	}
+func SignRequest(req *fasthttp.Request, config SignatureConfig) error {
+	components, err := config.prepare()
+	if err != nil {
+		return fmt.Errorf("zeno: SignRequest: %w", err)
+	}
+	m := sigMessage{req: req, scheme: string(req.URI().Scheme())}
+	if err := m.sign(config, components, time.Now()); err != nil {
+		return fmt.Errorf("zeno: SignRequest: %w", err)
+	}
+	return nil
+}
+
+// prepare fills in the defaults of config and parses its components; nil
+// components mean the defaults of the message being signed.
+func (config *SignatureConfig) prepare() ([]sigComponent, error) {
+	if config.Key.Key == nil {
+		return nil, errors.New("a Key is required")
+	}
+	if !validSignatureAlgorithm(config.Key.Algorithm) {
+		return nil, fmt.Errorf("unsupported algorithm %q", config.Key.Algorithm)
+	}
+	if config.Label == "" {
+		config.Label = "sig1"
+	}
+	if config.Components == nil {
+		return nil, nil
+	}
+	return parseComponents(config.Components)
+}
+
+// VerifySignatureConfig configures the VerifySignatures middleware.
+type VerifySignatureConfig struct {
+	// Keys looks up the keys named by the keyid parameter of signatures.
+	// Required.
+	Keys SignatureKeyStore
+
+	// Label, when set, selects the signature to verify by label;
+	// otherwise the first signature (with Tag, if set) is verified.
+	Label string
+
+	// Tag, when set, only accepts signatures with this tag parameter.
+	Tag string
+
+	// Required lists the components signatures must cover, in the syntax
+	// of SignatureConfig.Components. Defaults to "@method", "@authority",
+	// "@path" and "@query". Content-Digest is always required for
+	// requests with a body, and checked against it.
+	Required []string
+
+	// MaxAge is how long after its creation a signature is accepted.
+	// Defaults to five minutes; negative values accept any age.
+	MaxAge time.Duration
+
+	// Skipper, when set, bypasses verification for requests for which it
+	// returns true.
+	Skipper Skipper
+}
+
+// VerifySignatures returns a middleware accepting only requests carrying a
+// valid HTTP message signature (RFC 9421), e.g. from federated servers or
+// webhook producers. Other requests are answered with 401 Unauthorized and
+// an Accept-Signature header listing the required components. The
+// verified signature is available from Context.Signature.
+//
+// Example:
+//
+//	hooks := app.Group("/hooks")
+//	hooks.Use(zeno.VerifySignatures(zeno.VerifySignatureConfig{
+//	    Keys: zeno.NewSignatureKeyStore(partnerKey),
+//	    Tag:  "webhook",
+//	}))
+//	hooks.Post("/orders", func(c *zeno.Context) error {
+//	    log.Printf("order event signed by %s", c.Signature().KeyID)
+//	    return c.SendStatus(zeno.StatusNoContent)
+//	})
+func VerifySignatures(config VerifySignatureConfig) Handler {
+	if config.Keys == nil {
+		panic("zeno: VerifySignatures requires Keys")
+	}
+	if config.Required == nil {
+		config.Required = []string{"@method", "@authority", "@path", "@query"}
+	}
+	required, err := parseComponents(config.Required)
+	if err != nil {
+		panic("zeno: VerifySignatures: " + err.Error())
+	}
+	if config.MaxAge == 0 {
+		config.MaxAge = 5 * time.Minute
+	}
+	accept := "sig1=" + signatureParams(required, "")
+	return func(c *Context) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return c.Next()
+		}
+		m := sigMessage{req: &c.ctx.Request, scheme: c.Scheme()}
+		sig, err := m.verify(config, required, time.Now())
+		if err != nil {
+			c.SetHeader(HeaderAcceptSignature, accept)
+			return NewHTTPError(StatusUnauthorized, "invalid signature: "+err.Error())
+		}
+		c.Set(signatureKey, sig)
+		return c.Next()
+	}
+}
+
+// signatureKey is the Context key of the verified request signature.
+const signatureKey = "zeno.signature"
+
+// Signature returns the request signature verified by VerifySignatures,
+// or nil if there is none.
+func (c *Context) Signature() *Signature {
+	sig, _ := c.Get(signatureKey).(*Signature)
+	return sig
+}
+
+// sigComponent identifies a component covered by a signature.
+type sigComponent struct {
+	name string // derived component or lower-case header name
+	req  bool   // taken from the request of a response
+}
+
+// String returns the serialized component identifier.
+func (sc sigComponent) String() string {
+	if sc.req {
+		return sfString(sc.name) + ";req"
+	}
+	return sfString(sc.name)
+}
+
+// parseComponents parses components in the syntax of
+// SignatureConfig.Components.
+func parseComponents(ids []string) ([]sigComponent, error) {
+	components := make([]sigComponent, 0, len(ids))
+	for _, id := range ids {
+		name, param, _ := strings.Cut(id, ";")
+		sc := sigComponent{name: strings.ToLower(strings.TrimSpace(name))}
+		switch strings.TrimSpace(param) {
+		case "":
+		case "req":
+			sc.req = true
+		default:
+			return nil, fmt.Errorf("unsupported component parameter in %q", id)
+		}
+		if sc.name == "" {
+			return nil, fmt.Errorf("invalid component %q", id)
+		}
+		components = append(components, sc)
+	}
+	return components, nil
+}
+
+// sigMessage is a request, or a response to a request, being signed or
+// verified.
+type sigMessage struct {
+	req    *fasthttp.Request
+	resp   *fasthttp.Response // nil for requests
+	scheme string
+}
+
+// header returns the header of the message, to add signatures to.
+func (m sigMessage) header() interface {
+	Set(key, value string)
+	PeekAll(key string) [][]byte
+} {
+	if m.resp != nil {
+		return &m.resp.Header
+	}
+	return &m.req.Header
+}
+
+// body returns the body of the message, or false if it is streamed.
+func (m sigMessage) body() ([]byte, bool) {
+	if m.resp != nil {
+		return m.resp.Body(), !m.resp.IsBodyStream()
+	}
+	return m.req.Body(), !m.req.IsBodyStream()
+}
+
+// field returns the combined value of the header name of the message.
+func (m sigMessage) field(name string) (string, bool) {
+	lines := m.header().PeekAll(name)
+	if len(lines) == 0 {
+		return "", false
+	}
+	values := make([]string, len(lines))
+	for i, l := range lines {
+		values[i] = strings.TrimSpace(string(l))
+	}
+	return strings.Join(values, ", "), true
+}
+
+// defaults returns the components covered when none are configured.
+func (m sigMessage) defaults() []sigComponent {
+	var components []sigComponent
+	if m.resp != nil {
+		components = []sigComponent{{name: "@status"}}
+	} else {
+		components = []sigComponent{{name: "@method"}, {name: "@authority"}, {name: "@path"}, {name: "@query"}}
+	}
+	if _, ok := m.field(HeaderContentType); ok {
+		components = append(components, sigComponent{name: "content-type"})
+	}
+	if body, ok := m.body(); ok && len(body) > 0 {
+		components = append(components, sigComponent{name: "content-digest"})
+	}
+	return components
+}
+
+// value returns the value of a component (RFC 9421, section 2).
+func (m sigMessage) value(sc sigComponent) (string, error) {
+	if sc.req && m.resp == nil {
+		return "", fmt.Errorf("component %s of a request", sc)
+	}
+	if !strings.HasPrefix(sc.name, "@") {
+		msg := m
+		if sc.req {
+			msg.resp = nil
+		}
+		v, ok := msg.field(sc.name)
+		if !ok {
+			return "", fmt.Errorf("component %s is missing", sc)
+		}
+		return v, nil
+	}
+	if m.resp != nil && !sc.req {
+		if sc.name == "@status" {
+			return strconv.Itoa(m.resp.StatusCode()), nil
+		}
+		return "", fmt.Errorf("component %s of a response", sc)
+	}
+
+	uri := m.req.URI()
+	switch sc.name {
+	case "@method":
+		return string(m.req.Header.Method()), nil
+	case "@scheme":
+		return strings.ToLower(m.scheme), nil
+	case "@authority":
+		return strings.ToLower(string(m.req.Host())), nil
+	case "@target-uri":
+		return strings.ToLower(m.scheme) + "://" + strings.ToLower(string(m.req.Host())) + string(uri.RequestURI()), nil
+	case "@request-target":
+		return string(uri.RequestURI()), nil
+	case "@path":
+		if p := uri.PathOriginal(); len(p) > 0 {
+			return string(p), nil
+		}
+		return "/", nil
+	case "@query":
+		return "?" + string(uri.QueryString()), nil
+	}
+	return "", fmt.Errorf("unsupported component %s", sc)
+}
+
+// base returns the signature base of the message (RFC 9421, section 2.5).
+func (m sigMessage) base(components []sigComponent, params string) ([]byte, error) {
+	var b bytes.Buffer
+	for _, sc := range components {
+		v, err := m.value(sc)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(sc.String())
+		b.WriteString(": ")
+		b.WriteString(v)
+		b.WriteByte('\n')
+	}
+	b.WriteString(`"@signature-params": `)
+	b.WriteString(params)
+	return b.Bytes(), nil
+}
+
+// sign adds a signature of the message to its Signature and
+// Signature-Input headers.
+func (m sigMessage) sign(config SignatureConfig, components []sigComponent, now time.Time) error {
+	if components == nil {
+		components = m.defaults()
+	}
+	for _, sc := range components {
+		if sc.name != "content-digest" || sc.req {
+			continue
+		}
+		if _, ok := m.field(HeaderContentDigest); !ok {
+			body, ok := m.body()
+			if !ok {
+				return errors.New("cannot digest a streamed body")
+			}
+			m.header().Set(HeaderContentDigest, contentDigest(body))
+		}
+	}
+
+	var params strings.Builder
+	params.WriteString(";created=" + strconv.FormatInt(now.Unix(), 10))
+	if config.Expires > 0 {
+		params.WriteString(";expires=" + strconv.FormatInt(now.Add(config.Expires).Unix(), 10))
+	}
+	params.WriteString(";keyid=" + sfString(config.Key.ID))
+	params.WriteString(";alg=" + sfString(config.Key.Algorithm))
+	if config.Nonce != nil {
+		params.WriteString(";nonce=" + sfString(config.Nonce()))
+	}
+	if config.Tag != "" {
+		params.WriteString(";tag=" + sfString(config.Tag))
+	}
+	input := signatureParams(components, params.String())
+
+	base, err := m.base(components, input)
+	if err != nil {
+		return err
+	}
+	sig, err := config.Key.sign(base)
+	if err != nil {
+		return err
+	}
+	m.addMember(HeaderSignatureInput, config.Label+"="+input)
+	m.addMember(HeaderSignature, config.Label+"=:"+base64.StdEncoding.EncodeToString(sig)+":")
+	return nil
+}
+
+// addMember adds a member to the dictionary header name.
+func (m sigMessage) addMember(name, member string) {
+	if v, ok := m.field(name); ok {
+		member = v + ", " + member
+	}
+	m.header().Set(name, member)
+}
+
+// verify verifies the signature of the request selected by config.
+func (m sigMessage) verify(config VerifySignatureConfig, required []sigComponent, now time.Time) (*Signature, error) {
+	field, _ := m.field(HeaderSignatureInput)
+	inputs, err := parseSFDictionary(field)
+	if err != nil {
+		return nil, fmt.Errorf("malformed Signature-Input: %w", err)
+	}
+	field, _ = m.field(HeaderSignature)
+	signatures, err := parseSFDictionary(field)
+	if err != nil {
+		return nil, fmt.Errorf("malformed Signature: %w", err)
+	}
+	for _, in := range inputs {
+		if config.Label != "" && in.key != config.Label {
+			continue
+		}
+		if config.Tag != "" && in.item.params["tag"] != config.Tag {
+			continue
+		}
+		for _, s := range signatures {
+			if s.key == in.key {
+				return m.verifySignature(in, s.item, config, required, now)
+			}
+		}
+		return nil, fmt.Errorf("no signature labelled %q", in.key)
+	}
+	return nil, errors.New("missing signature")
+}
+
+// verifySignature verifies one signature of the request.
+func (m sigMessage) verifySignature(in sfMember, sigItem sfItem, config VerifySignatureConfig, required []sigComponent, now time.Time) (*Signature, error) {
+	if !in.item.inner || sigItem.kind != ':' {
+		return nil, errors.New("malformed signature")
+	}
+	var components []sigComponent
+	covered := map[sigComponent]bool{}
+	sig := &Signature{Label: in.key}
+	for _, it := range in.item.list {
+		if it.kind != '"' {
+			return nil, errors.New("malformed component")
+		}
+		sc := sigComponent{name: it.value}
+		for p := range it.params {
+			if p != "req" {
+				return nil, fmt.Errorf("unsupported component parameter %q", p)
+			}
+			sc.req = true
+		}
+		components = append(components, sc)
+		covered[sc] = true
+		sig.Components = append(sig.Components, sc.name)
+	}
+	for _, sc := range required {
+		if !covered[sc] {
+			return nil, fmt.Errorf("component %s is not covered", sc)
+		}
+	}
+	if body, _ := m.body(); len(body) > 0 && !covered[sigComponent{name: "content-digest"}] {
+		return nil, errors.New(`component "content-digest" is not covered`)
+	}
+
+	params := in.item.params
+	created, err := strconv.ParseInt(params["created"], 10, 64)
+	if err != nil {
+		return nil, errors.New("missing created parameter")
+	}
+	sig.Created = time.Unix(created, 0)
+	if sig.Created.After(now.Add(time.Minute)) {
+		return nil, errors.New("created in the future")
+	}
+	if config.MaxAge > 0 && now.Sub(sig.Created) > config.MaxAge {
+		return nil, errors.New("signature expired")
+	}
+	if v, ok := params["expires"]; ok {
+		expires, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || now.Unix() > expires {
+			return nil, errors.New("signature expired")
+		}
+		sig.Expires = time.Unix(expires, 0)
+	}
+	sig.KeyID, sig.Nonce, sig.Tag = params["keyid"], params["nonce"], params["tag"]
+
+	key, err := config.Keys.SignatureKey(sig.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", sig.KeyID, err)
+	}
+	if alg, ok := params["alg"]; ok && alg != key.Algorithm {
+		return nil, fmt.Errorf("algorithm %q does not match key %q", alg, sig.KeyID)
+	}
+	sig.Algorithm = key.Algorithm
+
+	value, err := base64.StdEncoding.DecodeString(sigItem.value)
+	if err != nil {
+		return nil, errors.New("malformed signature")
+	}
+	base, err := m.base(components, in.raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := key.verify(base, value); err != nil {
+		return nil, err
+	}
+	if covered[sigComponent{name: "content-digest"}] {
+		if err := m.checkDigest(); err != nil {
+			return nil, err
+		}
+	}
+	return sig, nil
+}
+
+// checkDigest checks the Content-Digest header of the message against its
+// body. At least one digest must use a supported algorithm.
+func (m sigMessage) checkDigest() error {
+	field, _ := m.field(HeaderContentDigest)
+	digests, err := parseSFDictionary(field)
+	if err != nil {
+		return fmt.Errorf("malformed Content-Digest: %w", err)
+	}
+	body, _ := m.body()
+	checked := false
+	for _, d := range digests {
+		var sum []byte
+		switch d.key {
+		case "sha-256":
+			s := sha256.Sum256(body)
+			sum = s[:]
+		case "sha-512":
+			s := sha512.Sum512(body)
+			sum = s[:]
+		default:
+			continue
+		}
+		if got, err := base64.StdEncoding.DecodeString(d.item.value); err != nil || d.item.kind != ':' || !hmac.Equal(got, sum) {
+			return errors.New("content digest mismatch")
+		}
+		checked = true
+	}
+	if !checked {
+		return errors.New("no supported content digest")
+	}
+	return nil
+}
+
+// contentDigest returns the Content-Digest header of body.
+func contentDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+// signatureParams serializes the covered components and parameters of a
+// signature.
+func signatureParams(components []sigComponent, params string) string {
+	ids := make([]string, len(components))
+	for i, sc := range components {
+		ids[i] = sc.String()
+	}
+	return "(" + strings.Join(ids, " ") + ")" + params
+}
+
+// validSignatureAlgorithm reports whether alg is supported.
+func validSignatureAlgorithm(alg string) bool {
+	switch alg {
+	case SignatureEd25519, SignatureHMACSHA256, SignatureECDSAP256SHA256, SignatureRSAPSSSHA512, SignatureRSAv15SHA256:
+		return true
+	}
+	return false
+}
+
+// sign signs base with k.
+func (k SignatureKey) sign(base []byte) ([]byte, error) {
+	switch key := k.Key.(type) {
+	case ed25519.PrivateKey:
+		if k.Algorithm == SignatureEd25519 {
+			return ed25519.Sign(key, base), nil
+		}
+	case []byte:
+		if k.Algorithm == SignatureHMACSHA256 {
+			mac := hmac.New(sha256.New, key)
+			mac.Write(base)
+			return mac.Sum(nil), nil
+		}
+	case *ecdsa.PrivateKey:
+		if k.Algorithm == SignatureECDSAP256SHA256 && key.Curve == elliptic.P256() {
+			sum := sha256.Sum256(base)
+			r, s, err := ecdsa.Sign(rand.Reader, key, sum[:])
+			if err != nil {
+				return nil, err
+			}
+			sig := make([]byte, 64)
+			r.FillBytes(sig[:32])
+			s.FillBytes(sig[32:])
+			return sig, nil
+		}
+	case *rsa.PrivateKey:
+		switch k.Algorithm {
+		case SignatureRSAPSSSHA512:
+			sum := sha512.Sum512(base)
+			return rsa.SignPSS(rand.Reader, key, crypto.SHA512, sum[:], &rsa.PSSOptions{SaltLength: 64})
+		case SignatureRSAv15SHA256:
+			sum := sha256.Sum256(base)
+			return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+		}
+	}
+	return nil, fmt.Errorf("key %q cannot sign with %s", k.ID, k.Algorithm)
+}
+
+// verify checks that sig is a signature of base by k.
+func (k SignatureKey) verify(base, sig []byte) error {
+	valid := false
+	switch key := k.public().(type) {
+	case ed25519.PublicKey:
+		valid = k.Algorithm == SignatureEd25519 && ed25519.Verify(key, base, sig)
+	case []byte:
+		if k.Algorithm == SignatureHMACSHA256 {
+			mac := hmac.New(sha256.New, key)
+			mac.Write(base)
+			valid = hmac.Equal(mac.Sum(nil), sig)
+		}
+	case *ecdsa.PublicKey:
+		if k.Algorithm == SignatureECDSAP256SHA256 && key.Curve == elliptic.P256() && len(sig) == 64 {
+			sum := sha256.Sum256(base)
+			r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+			valid = ecdsa.Verify(key, sum[:], r, s)
+		}
+	case *rsa.PublicKey:
+		switch k.Algorithm {
+		case SignatureRSAPSSSHA512:
+			sum := sha512.Sum512(base)
+			valid = rsa.VerifyPSS(key, crypto.SHA512, sum[:], sig, &rsa.PSSOptions{SaltLength: 64}) == nil
+		case SignatureRSAv15SHA256:
+			sum := sha256.Sum256(base)
+			valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], sig) == nil
+		}
+	}
+	if !valid {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// public returns the key verifying the signatures of k.
+func (k SignatureKey) public() any {
+	switch key := k.Key.(type) {
+	case ed25519.PrivateKey:
+		return key.Public()
+	case *ecdsa.PrivateKey:
+		return &key.PublicKey
+	case *rsa.PrivateKey:
+		return &key.PublicKey
+	}
+	return k.Key
+}
+
+// sfString serializes s as a structured field string (RFC 8941).
+func sfString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// sfItem is a parsed structured field item or inner list (RFC 8941).
+type sfItem struct {
+	kind   byte   // '"' string, ':' byte sequence, '0' number, 't' token, '?' boolean
+	value  string // unquoted string, base64 of a byte sequence, or raw value
+	inner  bool   // inner list, with members in list
+	list   []sfItem
+	params map[string]string // unquoted values, "?1" for bare parameters
+}
+
+// sfMember is a member of a structured field dictionary.
+type sfMember struct {
+	key  string
+	item sfItem
+	raw  string // the serialized value, as received
+}
+
+// sfParser parses structured fields.
+type sfParser struct {
+	s string
+	i int
+}
+
+// parseSFDictionary parses a structured field dictionary.
+func parseSFDictionary(s string) ([]sfMember, error) {
+	p := &sfParser{s: s}
+	p.skip(" \t")
+	var members []sfMember
+	for p.i < len(p.s) {
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		m := sfMember{key: key}
+		start := p.i
+		if p.peek() == '=' {
+			p.i++
+			start = p.i
+			m.item, err = p.item()
+		} else {
+			m.item = sfItem{kind: '?', value: "?1"}
+			m.item.params, err = p.params()
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.raw = p.s[start:p.i]
+		members = append(members, m)
+
+		p.skip(" \t")
+		if p.i == len(p.s) {
+			break
+		}
+		if p.s[p.i] != ',' {
+			return nil, fmt.Errorf("unexpected %q", p.s[p.i])
+		}
+		p.i++
+		p.skip(" \t")
+		if p.i == len(p.s) {
+			return nil, errors.New("trailing comma")
+		}
+	}
+	return members, nil
+}
+
+// peek returns the next byte, or 0 at the end.
+func (p *sfParser) peek() byte {
+	if p.i < len(p.s) {
+		return p.s[p.i]
+	}
+	return 0
+}
+
+// skip skips the bytes in set.
+func (p *sfParser) skip(set string) {
+	for p.i < len(p.s) && strings.IndexByte(set, p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// key parses a dictionary or parameter key.
+func (p *sfParser) key() (string, error) {
+	start := p.i
+	for ; p.i < len(p.s); p.i++ {
+		c := p.s[p.i]
+		if c >= 'a' && c <= 'z' || c == '*' || p.i > start && (c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.') {
+			continue
+		}
+		break
+	}
+	if p.i == start {
+		return "", errors.New("missing key")
+	}
+	return p.s[start:p.i], nil
+}
+
+// item parses an item or inner list with its parameters.
+func (p *sfParser) item() (sfItem, error) {
+	var it sfItem
+	var err error
+	if p.peek() == '(' {
+		p.i++
+		it.inner = true
+		for {
+			p.skip(" ")
+			if p.peek() == ')' {
+				p.i++
+				break
+			}
+			member, err := p.item()
+			if err != nil {
+				return it, err
+			}
+			if member.inner {
+				return it, errors.New("nested inner list")
+			}
+			it.list = append(it.list, member)
+			if c := p.peek(); c != ' ' && c != ')' {
+				return it, errors.New("unterminated inner list")
+			}
+		}
+	} else if it.kind, it.value, err = p.bareItem(); err != nil {
+		return it, err
+	}
+	it.params, err = p.params()
+	return it, err
+}
+
+// params parses parameters.
+func (p *sfParser) params() (map[string]string, error) {
+	params := map[string]string{}
+	for p.peek() == ';' {
+		p.i++
+		p.skip(" ")
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		params[key] = "?1"
+		if p.peek() == '=' {
+			p.i++
+			if _, params[key], err = p.bareItem(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return params, nil
+}
+
+// bareItem parses a bare item.
+func (p *sfParser) bareItem() (kind byte, value string, err error) {
+	start := p.i
+	switch c := p.peek(); {
+	case c == '"':
+		var b strings.Builder
+		for p.i++; p.i < len(p.s); p.i++ {
+			switch c := p.s[p.i]; c {
+			case '\\':
+				p.i++
+				if p.i == len(p.s) || p.s[p.i] != '\\' && p.s[p.i] != '"' {
+					return 0, "", errors.New("invalid escape")
+				}
+				b.WriteByte(p.s[p.i])
+			case '"':
+				p.i++
+				return '"', b.String(), nil
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return 0, "", errors.New("unterminated string")
+	case c == ':':
+		end := strings.IndexByte(p.s[p.i+1:], ':')
+		if end < 0 {
+			return 0, "", errors.New("unterminated byte sequence")
+		}
+		p.i += end + 2
+		return ':', p.s[start+1 : p.i-1], nil
+	case c == '?':
+		p.i += 2
+		if p.i > len(p.s) || p.s[start+1] != '0' && p.s[start+1] != '1' {
+			return 0, "", errors.New("invalid boolean")
+		}
+		return '?', p.s[start:p.i], nil
+	case c == '-' || c >= '0' && c <= '9':
+		p.i++
+		p.skip("0123456789.")
+		return '0', p.s[start:p.i], nil
+	case c == '*' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+		p.skip("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!#$%&'*+-.^_`|~:/")
+		return 't', p.s[start:p.i], nil
+	}
+	return 0, "", fmt.Errorf("unexpected %q", p.peek())
+}
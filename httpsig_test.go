@@ -0,0 +1,164 @@
+package zeno
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// rfc9421Request is the test request of RFC 9421, appendix B.2.
+var rfc9421Request = map[string]string{
+	HeaderDate:          "Tue, 20 Apr 2021 02:07:55 GMT",
+	HeaderContentType:   "application/json",
+	HeaderContentDigest: "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:",
+}
+
+func TestVerifySignatures_RFC9421(t *testing.T) {
+	secret, _ := base64.StdEncoding.DecodeString("uzvJfB4u3N0Jy4T7NZ75MDVcr8zSTInedJtkgcu46YW4XByzNJjxBdtjUkdJPBtbmHhIDi6pcl8jsasjlTMtDQ==")
+	z := New()
+	z.Use(VerifySignatures(VerifySignatureConfig{
+		Keys:     NewSignatureKeyStore(SignatureKey{ID: "test-shared-secret", Algorithm: SignatureHMACSHA256, Key: secret}),
+		Required: []string{"@authority", "date"},
+		MaxAge:   -1,
+	}))
+	z.Post("/foo", func(c *Context) error {
+		sig := c.Signature()
+		assert.Equal(t, "sig-b25", sig.Label)
+		assert.Equal(t, []string{"date", "@authority", "content-type"}, sig.Components)
+		return c.SendString(sig.KeyID)
+	})
+
+	headers := map[string]string{
+		HeaderSignatureInput: `sig-b25=("date" "@authority" "content-type");created=1618884473;keyid="test-shared-secret"`,
+		HeaderSignature:      `sig-b25=:pxcQw6G3AjtMBQjwo8XzkZf/bws5LelbaMk5rGIGtE8=:`,
+	}
+	for k, v := range rfc9421Request {
+		headers[k] = v
+	}
+	ctx := performRequest(z, "POST", "http://example.com/foo?param=Value&Pet=dog", headers, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "test-shared-secret", string(ctx.Response.Body()))
+
+	// The body is not covered by the signature.
+	ctx = performRequest(z, "POST", "http://example.com/foo?param=Value&Pet=dog", headers, []byte(`{"hello": "world"}`))
+	assert.Equal(t, StatusUnauthorized, ctx.Response.StatusCode())
+
+	headers[HeaderDate] = "Wed, 21 Apr 2021 02:07:55 GMT"
+	ctx = performRequest(z, "POST", "http://example.com/foo?param=Value&Pet=dog", headers, nil)
+	assert.Equal(t, StatusUnauthorized, ctx.Response.StatusCode())
+	assert.Equal(t, `sig1=("@authority" "date")`, string(ctx.Response.Header.Peek(HeaderAcceptSignature)))
+}
+
+func TestSignRequest(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	keys := []SignatureKey{
+		{ID: "ed", Algorithm: SignatureEd25519, Key: edKey},
+		{ID: "hmac", Algorithm: SignatureHMACSHA256, Key: []byte("secret")},
+		{ID: "ec", Algorithm: SignatureECDSAP256SHA256, Key: ecKey},
+		{ID: "pss", Algorithm: SignatureRSAPSSSHA512, Key: rsaKey},
+		{ID: "rsa", Algorithm: SignatureRSAv15SHA256, Key: rsaKey},
+	}
+	z := New()
+	z.Use(VerifySignatures(VerifySignatureConfig{Keys: NewSignatureKeyStore(keys...), Tag: "webhook"}))
+	z.Post("/hooks", func(c *Context) error {
+		return c.SendString(c.Signature().KeyID)
+	})
+
+	send := func(req *fasthttp.Request) *fasthttp.RequestCtx {
+		ctx := &fasthttp.RequestCtx{}
+		req.CopyTo(&ctx.Request)
+		z.HandleRequest(ctx)
+		return ctx
+	}
+	for _, key := range keys {
+		req := &fasthttp.Request{}
+		req.Header.SetMethod(MethodPost)
+		req.SetRequestURI("https://api.example.com/hooks?event=order")
+		req.Header.SetContentType("application/json")
+		req.SetBodyString(`{"id":1}`)
+		assert.NoError(t, SignRequest(req, SignatureConfig{Key: key, Tag: "webhook"}))
+		assert.Contains(t, string(req.Header.Peek(HeaderContentDigest)), "sha-256=:")
+
+		ctx := send(req)
+		assert.Equal(t, StatusOK, ctx.Response.StatusCode(), key.ID)
+		assert.Equal(t, key.ID, string(ctx.Response.Body()))
+
+		req.SetBodyString(`{"id":2}`)
+		assert.Equal(t, StatusUnauthorized, send(req).Response.StatusCode(), key.ID)
+	}
+
+	// Untagged and unsigned requests are rejected.
+	req := &fasthttp.Request{}
+	req.Header.SetMethod(MethodPost)
+	req.SetRequestURI("https://api.example.com/hooks")
+	assert.Equal(t, StatusUnauthorized, send(req).Response.StatusCode())
+	assert.NoError(t, SignRequest(req, SignatureConfig{Key: keys[0]}))
+	assert.Equal(t, StatusUnauthorized, send(req).Response.StatusCode())
+
+	assert.Error(t, SignRequest(req, SignatureConfig{Key: SignatureKey{Algorithm: "md5", Key: []byte("x")}}))
+	assert.Error(t, SignRequest(req, SignatureConfig{Key: keys[1], Components: []string{"x-missing"}}))
+}
+
+func TestSignResponses(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	z := New()
+	z.Use(SignResponses(SignatureConfig{
+		Key:        SignatureKey{ID: "server", Algorithm: SignatureEd25519, Key: priv},
+		Components: []string{"@status", "content-type", "content-digest", "@method;req", "@path;req"},
+	}))
+	z.Get("/actor", func(c *Context) error {
+		return c.SendJSON(map[string]string{"name": "zeno"})
+	})
+
+	ctx := performRequest(z, "GET", "/actor", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	input := string(ctx.Response.Header.Peek(HeaderSignatureInput))
+	assert.Regexp(t, `^sig1=\("@status" "content-type" "content-digest" "@method";req "@path";req\);created=\d+;keyid="server";alg="ed25519"$`, input)
+
+	// Verify with the request the response answers.
+	members, err := parseSFDictionary(input)
+	assert.NoError(t, err)
+	signatures, err := parseSFDictionary(string(ctx.Response.Header.Peek(HeaderSignature)))
+	assert.NoError(t, err)
+	key := SignatureKey{ID: "server", Algorithm: SignatureEd25519, Key: pub}
+	m := sigMessage{req: &ctx.Request, resp: &ctx.Response, scheme: "http"}
+	components := []sigComponent{{name: "@status"}, {name: "content-type"}, {name: "content-digest"}, {name: "@method", req: true}, {name: "@path", req: true}}
+	base, err := m.base(components, members[0].raw)
+	assert.NoError(t, err)
+	sig, _ := base64.StdEncoding.DecodeString(signatures[0].item.value)
+	assert.NoError(t, key.verify(base, sig))
+	assert.NoError(t, m.checkDigest())
+
+	ctx.Response.SetStatusCode(StatusCreated)
+	base, _ = m.base(components, members[0].raw)
+	assert.Error(t, key.verify(base, sig))
+}
+
+func TestParseSFDictionary(t *testing.T) {
+	members, err := parseSFDictionary(`a=("x";req "y\"z");created=1;keyid="k", b=:AQI=:, c, d=?0;p=tok/1`)
+	assert.NoError(t, err)
+	assert.Len(t, members, 4)
+	assert.Equal(t, `("x";req "y\"z");created=1;keyid="k"`, members[0].raw)
+	assert.Equal(t, []sfItem{
+		{kind: '"', value: "x", params: map[string]string{"req": "?1"}},
+		{kind: '"', value: `y"z`, params: map[string]string{}},
+	}, members[0].item.list)
+	assert.Equal(t, map[string]string{"created": "1", "keyid": "k"}, members[0].item.params)
+	assert.Equal(t, "AQI=", members[1].item.value)
+	assert.Equal(t, "?1", members[2].item.value)
+	assert.Equal(t, "tok/1", members[3].item.params["p"])
+
+	for _, s := range []string{`a=("x"`, `a="x`, `a=1,`, `A=1`, `a=:AQI=`} {
+		_, err := parseSFDictionary(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,194 @@
+package zeno
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+)
+
+// IPAccessList holds the allow and deny lists used by IPFilter. The lists
+// can be replaced with Update while the server is running, e.g. when a
+// configuration file changes.
+type IPAccessList struct {
+	lists atomic.Pointer[ipLists]
+}
+
+// ipLists is an immutable snapshot of an IPAccessList.
+type ipLists struct {
+	allow, deny []netip.Prefix
+}
+
+// NewIPAccessList parses allow and deny, lists of IP addresses and CIDR
+// ranges such as "10.0.0.0/8" or "2001:db8::/32", and returns an access
+// list holding them.
+func NewIPAccessList(allow, deny []string) (*IPAccessList, error) {
+	l := &IPAccessList{}
+	if err := l.Update(allow, deny); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Update atomically replaces the lists. On error the lists in use are
+// left unchanged.
+//
+// Example:
+//
+//	if err := acl.Update(cfg.AllowedIPs, cfg.BlockedIPs); err != nil {
+//	    log.Printf("keeping previous IP lists: %v", err)
+//	}
+func (l *IPAccessList) Update(allow, deny []string) error {
+	a, err := parsePrefixes(allow)
+	if err != nil {
+		return err
+	}
+	d, err := parsePrefixes(deny)
+	if err != nil {
+		return err
+	}
+	l.lists.Store(&ipLists{allow: a, deny: d})
+	return nil
+}
+
+// Allowed reports whether ip passes the lists: it must not be in the deny
+// list and, if the allow list is not empty, must be in the allow list.
+func (l *IPAccessList) Allowed(ip netip.Addr) bool {
+	lists := l.lists.Load()
+	if prefixesContain(lists.deny, ip) {
+		return false
+	}
+	return len(lists.allow) == 0 || prefixesContain(lists.allow, ip)
+}
+
+// parsePrefixes parses IP addresses and CIDR ranges.
+func parsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if strings.Contains(e, "/") {
+			p, err := netip.ParsePrefix(e)
+			if err != nil {
+				return nil, fmt.Errorf("zeno: invalid CIDR %q: %w", e, err)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		ip, err := netip.ParseAddr(e)
+		if err != nil {
+			return nil, fmt.Errorf("zeno: invalid IP address %q: %w", e, err)
+		}
+		ip = ip.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(ip, ip.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// prefixesContain reports whether any of prefixes contains ip.
+func prefixesContain(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IPFilterConfig configures the IPFilter middleware.
+type IPFilterConfig struct {
+	// Allow lists the IP addresses and CIDR ranges allowed to pass. When
+	// empty, every address not denied passes.
+	Allow []string
+
+	// Deny lists the IP addresses and CIDR ranges rejected. It takes
+	// precedence over Allow.
+	Deny []string
+
+	// List, when set, is used instead of Allow and Deny, so the lists can
+	// be updated at runtime.
+	List *IPAccessList
+
+	// TrustedProxies lists the addresses and CIDR ranges of reverse
+	// proxies in front of the server. X-Forwarded-For is only consulted
+	// when the connection comes from one of them, and then the client is
+	// the rightmost address in it that is not a trusted proxy. Without
+	// trusted proxies the connection's remote address is used, as clients
+	// can put anything in X-Forwarded-For.
+	TrustedProxies []string
+
+	// Denied handles rejected requests. By default ErrForbidden is
+	// returned, answering 403 Forbidden.
+	Denied Handler
+
+	// Skipper, when set, lets requests for which it returns true bypass
+	// the filter.
+	Skipper Skipper
+}
+
+// IPFilter returns a middleware that admits requests by client IP address
+// according to allow and deny lists of addresses and CIDR ranges. It
+// panics if an entry cannot be parsed.
+//
+// Example:
+//
+//	admin := app.Group("/admin", zeno.IPFilter(zeno.IPFilterConfig{
+//	    Allow:          []string{"10.0.0.0/8", "192.168.1.17"},
+//	    TrustedProxies: []string{"10.0.0.1"},
+//	}))
+//
+// To reload the lists at runtime, pass an IPAccessList:
+//
+//	acl, err := zeno.NewIPAccessList(nil, blocked)
+//	app.Use(zeno.IPFilter(zeno.IPFilterConfig{List: acl}))
+//	// later:
+//	err = acl.Update(nil, newlyBlocked)
+func IPFilter(config IPFilterConfig) Handler {
+	list := config.List
+	if list == nil {
+		var err error
+		if list, err = NewIPAccessList(config.Allow, config.Deny); err != nil {
+			panic(err)
+		}
+	}
+	trusted, err := parsePrefixes(config.TrustedProxies)
+	if err != nil {
+		panic(err)
+	}
+	return func(c *Context) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return c.Next()
+		}
+		if ip, ok := clientAddr(c, trusted); ok && list.Allowed(ip) {
+			return c.Next()
+		}
+		if config.Denied != nil {
+			c.Abort()
+			return config.Denied(c)
+		}
+		return ErrForbidden
+	}
+}
+
+// clientAddr returns the address of the client, taken from
+// X-Forwarded-For only when the connection comes from a trusted proxy.
+func clientAddr(c *Context, trusted []netip.Prefix) (netip.Addr, bool) {
+	remote, ok := netip.AddrFromSlice(c.ctx.RemoteIP())
+	if !ok {
+		return netip.Addr{}, false
+	}
+	ip := remote.Unmap()
+	if !prefixesContain(trusted, ip) {
+		return ip, true
+	}
+	hops := c.GetForwardedIPs()
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		if ip = hop.Unmap(); !prefixesContain(trusted, ip) {
+			return ip, true
+		}
+	}
+	return ip, true
+}
@@ -0,0 +1,72 @@
+package zeno
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// requestFrom performs a GET request for uri from the given remote IP.
+func requestFrom(z *Zeno, ip, uri string, headers map[string]string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP(ip), Port: 1234})
+	ctx.Request.Header.SetMethod(MethodGet)
+	ctx.Request.SetRequestURI(uri)
+	for k, v := range headers {
+		ctx.Request.Header.Set(k, v)
+	}
+	z.HandleRequest(ctx)
+	return ctx
+}
+
+func TestIPFilter(t *testing.T) {
+	z := New()
+	z.Use(IPFilter(IPFilterConfig{
+		Allow:          []string{"10.0.0.0/8", "192.168.1.17"},
+		Deny:           []string{"10.0.0.66"},
+		TrustedProxies: []string{"172.16.0.0/12"},
+	}))
+	z.Get("/", func(c *Context) error { return c.SendString("ok") })
+
+	assert.Equal(t, StatusOK, requestFrom(z, "10.1.2.3", "/", nil).Response.StatusCode())
+	assert.Equal(t, StatusOK, requestFrom(z, "192.168.1.17", "/", nil).Response.StatusCode())
+	assert.Equal(t, StatusForbidden, requestFrom(z, "192.168.1.18", "/", nil).Response.StatusCode())
+	assert.Equal(t, StatusForbidden, requestFrom(z, "10.0.0.66", "/", nil).Response.StatusCode())
+
+	// X-Forwarded-For is ignored from untrusted peers.
+	xff := map[string]string{HeaderForwardedFor: "10.1.1.1"}
+	assert.Equal(t, StatusForbidden, requestFrom(z, "8.8.8.8", "/", xff).Response.StatusCode())
+
+	// Behind a trusted proxy, the rightmost untrusted hop is the client.
+	xff = map[string]string{HeaderForwardedFor: "8.8.8.8, 10.1.1.1, 172.16.0.9"}
+	assert.Equal(t, StatusOK, requestFrom(z, "172.16.0.2", "/", xff).Response.StatusCode())
+	xff = map[string]string{HeaderForwardedFor: "10.1.1.1, 8.8.8.8"}
+	assert.Equal(t, StatusForbidden, requestFrom(z, "172.16.0.2", "/", xff).Response.StatusCode())
+
+	assert.Panics(t, func() { IPFilter(IPFilterConfig{Deny: []string{"10.0.0.0/33"}}) })
+}
+
+func TestIPAccessList_Update(t *testing.T) {
+	acl, err := NewIPAccessList(nil, []string{"1.2.3.4"})
+	assert.NoError(t, err)
+	z := New()
+	z.Use(IPFilter(IPFilterConfig{
+		List:   acl,
+		Denied: func(c *Context) error { return c.Status(StatusUnauthorized).SendString("go away") },
+	}))
+	z.Get("/", func(c *Context) error { return c.SendString("ok") })
+
+	ctx := requestFrom(z, "1.2.3.4", "/", nil)
+	assert.Equal(t, StatusUnauthorized, ctx.Response.StatusCode())
+	assert.Equal(t, "go away", string(ctx.Response.Body()))
+	assert.Equal(t, StatusOK, requestFrom(z, "::ffff:5.6.7.8", "/", nil).Response.StatusCode())
+
+	assert.Error(t, acl.Update(nil, []string{"nope"}))
+	assert.Equal(t, StatusUnauthorized, requestFrom(z, "1.2.3.4", "/", nil).Response.StatusCode())
+
+	assert.NoError(t, acl.Update(nil, []string{"5.6.7.0/24"}))
+	assert.Equal(t, StatusOK, requestFrom(z, "1.2.3.4", "/", nil).Response.StatusCode())
+	assert.Equal(t, StatusUnauthorized, requestFrom(z, "5.6.7.8", "/", nil).Response.StatusCode())
+}
@@ -0,0 +1,104 @@
+package zeno
+
+import (
+	"context"
+	"errors"
+)
+
+// RouteInfo describes a route registration passed to OnRouteRegistered
+// hooks.
+type RouteInfo struct {
+	// Method is the HTTP method the handlers were registered for.
+	Method string
+
+	// Path is the full route pattern, including any group prefix.
+	Path string
+
+	// Route is the registered route. Its name may still change if Name is
+	// called after the method was registered.
+	Route *Route
+}
+
+// OnStart registers fn to run once before the server starts accepting
+// connections, in registration order. If a hook returns an error the
+// server is not started and Run returns that error. Typical uses are
+// opening database pools and registering with service discovery.
+//
+// Example:
+//
+//	app.OnStart(func() error {
+//	    return registry.Register("users-api", addr)
+//	})
+func (z *Zeno) OnStart(fn func() error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.onStart = append(z.onStart, fn)
+}
+
+// OnShutdown registers fn to run during Shutdown after the servers have
+// stopped accepting requests and drained, in reverse registration order so
+// resources are released in the opposite order they were acquired. The
+// context passed to Shutdown bounds the hooks as well.
+//
+// Example:
+//
+//	app.OnShutdown(func(ctx context.Context) error {
+//	    return db.Close()
+//	})
+func (z *Zeno) OnShutdown(fn func(ctx context.Context) error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.onShutdown = append(z.onShutdown, fn)
+}
+
+// OnRouteRegistered registers fn to be called for every route and method
+// registered afterwards, e.g. to build documentation or audit the routing
+// table. Hooks run synchronously during registration.
+//
+// Example:
+//
+//	app.OnRouteRegistered(func(r zeno.RouteInfo) {
+//	    log.Printf("%s %s", r.Method, r.Path)
+//	})
+func (z *Zeno) OnRouteRegistered(fn func(RouteInfo)) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.onRoute = append(z.onRoute, fn)
+}
+
+// start runs the OnStart hooks the first time it is called and returns
+// their combined result on every call. When the route table is requested
+// (see PrintRoutesOnRequest) it prints the table and returns
+// ErrRoutesPrinted instead.
+func (z *Zeno) start() error {
+	if err := z.printRoutesIfRequested(); err != nil {
+		return err
+	}
+	z.startOnce.Do(func() {
+		z.mu.RLock()
+		hooks := append([]func() error(nil), z.onStart...)
+		z.mu.RUnlock()
+		for _, fn := range hooks {
+			if err := fn(); err != nil {
+				z.startErr = err
+				return
+			}
+		}
+	})
+	return z.startErr
+}
+
+// runShutdownHooks runs the OnShutdown hooks in reverse order, collecting
+// their errors.
+func (z *Zeno) runShutdownHooks(ctx context.Context) error {
+	z.mu.RLock()
+	hooks := append([]func(context.Context) error(nil), z.onShutdown...)
+	z.mu.RUnlock()
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
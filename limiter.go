@@ -0,0 +1,70 @@
+package zeno
+
+import (
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// MaxConcurrent returns a middleware that allows at most n requests to run
+// the rest of the chain at the same time. Up to queueLen further requests
+// wait for a free slot for at most timeout; anything beyond that is shed
+// immediately with 503 Service Unavailable and a Retry-After header, so
+// latency stays bounded under overload instead of growing without limit.
+//
+// Register it with Zeno.Use to limit the whole server, or on a group or
+// route to limit only those handlers. Each call creates an independent
+// limit.
+//
+// Example:
+//
+//	// 100 in flight, 50 waiting for up to 200ms
+//	app.Use(zeno.MaxConcurrent(100, 50, 200*time.Millisecond))
+func MaxConcurrent(n, queueLen int, timeout time.Duration) Handler {
+	if n <= 0 {
+		panic("zeno: MaxConcurrent requires n > 0")
+	}
+	slots := make(chan struct{}, n)
+	var queued atomic.Int64
+
+	secs := int(math.Ceil(timeout.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	retryAfter := strconv.Itoa(secs)
+	shed := func(c *Context) error {
+		c.SetHeader(HeaderRetryAfter, retryAfter)
+		return NewHTTPError(StatusServiceUnavailable, "Server is overloaded")
+	}
+
+	return func(c *Context) error {
+		if c.holds(slots) {
+			// The request was re-dispatched while holding a slot.
+			return c.Next()
+		}
+		select {
+		case slots <- struct{}{}:
+		default:
+			if queueLen <= 0 || timeout <= 0 {
+				return shed(c)
+			}
+			if queued.Add(1) > int64(queueLen) {
+				queued.Add(-1)
+				return shed(c)
+			}
+			timer := time.NewTimer(timeout)
+			select {
+			case slots <- struct{}{}:
+				timer.Stop()
+				queued.Add(-1)
+			case <-timer.C:
+				queued.Add(-1)
+				return shed(c)
+			}
+		}
+		c.hold(slots)
+		defer func() { <-slots }()
+		return c.Next()
+	}
+}
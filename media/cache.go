@@ -0,0 +1,71 @@
+package media
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache stores transformed images by an opaque key. Implementations must
+// be safe for concurrent use.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, data []byte)
+}
+
+// MemoryCache is a Cache keeping the most recently used images in memory,
+// up to a total size.
+type MemoryCache struct {
+	max int64
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+// cacheEntry is an image held by a MemoryCache.
+type cacheEntry struct {
+	key  string
+	data []byte
+}
+
+// NewMemoryCache returns a MemoryCache holding up to maxBytes of images.
+func NewMemoryCache(maxBytes int64) *MemoryCache {
+	return &MemoryCache{max: maxBytes, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).data, true
+}
+
+// Set implements Cache. Images larger than the whole cache are not
+// stored.
+func (m *MemoryCache) Set(key string, data []byte) {
+	n := int64(len(data))
+	if n > m.max {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok {
+		m.size -= int64(len(e.Value.(*cacheEntry).data))
+		m.order.Remove(e)
+	}
+	m.entries[key] = m.order.PushFront(&cacheEntry{key, data})
+	m.size += n
+	for m.size > m.max {
+		e := m.order.Back()
+		ce := e.Value.(*cacheEntry)
+		m.order.Remove(e)
+		delete(m.entries, ce.key)
+		m.size -= int64(len(ce.data))
+	}
+}
@@ -0,0 +1,448 @@
+// Package media serves images with on-the-fly transformations requested in
+// the query string, for apps serving user-uploaded images: resizing,
+// cropping to an aspect ratio and conversion between JPEG, PNG and GIF.
+// Source images are read from a storage.Backend, results are cached and
+// given an ETag, so each variant is only computed once.
+//
+// The supported query parameters are:
+//
+//	w, h     target width and height in pixels; images are never enlarged
+//	fit      contain (default) fits the image within w×h, cover crops it
+//	         to exactly w×h around the center, fill stretches it to w×h
+//	format   jpeg, png or gif; defaults to the source format
+//	q        JPEG quality from 1 to 100
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/Abhishek2010dev/zeno/storage"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // WebP sources
+)
+
+// Config configures a media handler.
+type Config struct {
+	// Source holds the original images. It is required.
+	Source storage.Backend
+
+	// Cache stores transformed images. Defaults to a NewMemoryCache of
+	// 64 MiB.
+	Cache Cache
+
+	// MaxWidth and MaxHeight bound the requested dimensions. Both default
+	// to 4096.
+	MaxWidth, MaxHeight int
+
+	// MaxSourcePixels bounds the size of the images decoded, protecting
+	// against decompression bombs. Defaults to 50 million.
+	MaxSourcePixels int
+
+	// MaxSourceSize bounds the size in bytes of the images read for
+	// transformation. Defaults to 32 MiB.
+	MaxSourceSize int64
+
+	// MaxConcurrent bounds the transformations running at once; further
+	// requests for variants not in the cache wait for one to finish.
+	// Defaults to GOMAXPROCS.
+	MaxConcurrent int
+
+	// Presets, if not empty, lists the only transformations served, so
+	// that clients cannot have a variant computed for every combination
+	// of w, h and q. An empty Fit stands for FitContain. Other options
+	// yield 400 Bad Request.
+	Presets []Options
+
+	// Quality is the JPEG quality used when the request does not set q.
+	// Defaults to 85.
+	Quality int
+
+	// MaxAge sets a "public, max-age" Cache-Control header on served
+	// images. No Cache-Control header is sent when zero.
+	MaxAge time.Duration
+}
+
+// Options are the transformations applied to an image.
+type Options struct {
+	Width, Height int
+	Fit           string // "contain", "cover" or "fill"
+	Format        string // "jpeg", "png", "gif" or "" for the source format
+	Quality       int    // JPEG quality, or 0 for the default
+}
+
+// Fit modes.
+const (
+	FitContain = "contain"
+	FitCover   = "cover"
+	FitFill    = "fill"
+)
+
+// IsZero reports whether o leaves the image unchanged.
+func (o Options) IsZero() bool {
+	return o == Options{Fit: FitContain}
+}
+
+// String returns o in canonical query string form.
+func (o Options) String() string {
+	return fmt.Sprintf("w=%d&h=%d&fit=%s&format=%s&q=%d", o.Width, o.Height, o.Fit, o.Format, o.Quality)
+}
+
+// ParseOptions reads the transformation options from query parameters,
+// rejecting values outside the limits of config.
+func ParseOptions(query url.Values, config Config) (Options, error) {
+	config = withDefaults(config)
+	o := Options{Fit: FitContain}
+	for _, p := range []struct {
+		name string
+		dst  *int
+		max  int
+	}{{"w", &o.Width, config.MaxWidth}, {"h", &o.Height, config.MaxHeight}, {"q", &o.Quality, 100}} {
+		s := query.Get(p.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > p.max {
+			return Options{}, fmt.Errorf("%s must be an integer from 1 to %d", p.name, p.max)
+		}
+		*p.dst = n
+	}
+	switch fit := query.Get("fit"); fit {
+	case "", FitContain:
+	case FitCover, FitFill:
+		if o.Width == 0 || o.Height == 0 {
+			return Options{}, fmt.Errorf("fit=%s requires w and h", fit)
+		}
+		o.Fit = fit
+	default:
+		return Options{}, fmt.Errorf("unknown fit %q", fit)
+	}
+	switch format := strings.ToLower(query.Get("format")); format {
+	case "":
+	case "jpeg", "jpg":
+		o.Format = "jpeg"
+	case "png", "gif":
+		o.Format = format
+	default:
+		return Options{}, fmt.Errorf("unsupported format %q", format)
+	}
+	if len(config.Presets) > 0 && !o.IsZero() && !slices.Contains(config.Presets, o) {
+		return Options{}, errors.New("options do not match a preset")
+	}
+	return o, nil
+}
+
+// withDefaults applies the defaults documented on Config.
+func withDefaults(config Config) Config {
+	if config.MaxWidth <= 0 {
+		config.MaxWidth = 4096
+	}
+	if config.MaxHeight <= 0 {
+		config.MaxHeight = 4096
+	}
+	if config.MaxSourcePixels <= 0 {
+		config.MaxSourcePixels = 50_000_000
+	}
+	if config.MaxSourceSize <= 0 {
+		config.MaxSourceSize = 32 << 20
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = runtime.GOMAXPROCS(0)
+	}
+	if slices.ContainsFunc(config.Presets, func(o Options) bool { return o.Fit == "" }) {
+		presets := slices.Clone(config.Presets)
+		for i := range presets {
+			if presets[i].Fit == "" {
+				presets[i].Fit = FitContain
+			}
+		}
+		config.Presets = presets
+	}
+	if config.Quality <= 0 {
+		config.Quality = 85
+	}
+	return config
+}
+
+// Mount serves the images of config.Source under prefix for GET and HEAD
+// requests, using the request path below prefix as the key.
+//
+// Example:
+//
+//	uploads := storage.FS(os.DirFS("uploads"))
+//	media.Mount(&app.RouteGroup, "/img", media.Config{
+//	    Source: uploads,
+//	    MaxAge: 24 * time.Hour,
+//	})
+//
+//	// <img src="/img/avatars/42.jpg?w=96&h=96&fit=cover&format=png">
+func Mount(r *zeno.RouteGroup, prefix string, config Config) *zeno.Route {
+	h := Handler(config)
+	return r.Get(strings.TrimSuffix(prefix, "/")+"/{path*}", h).Head(h)
+}
+
+// Handler returns a zeno.Handler serving the image whose key is the
+// "path" route parameter. Use it to register the handler manually, e.g.
+// behind route-specific middleware; Mount is the usual entry point.
+//
+// Requests without transformations are served as stored, with range
+// support. Invalid options yield 400 Bad Request, missing images 404 Not
+// Found and images that cannot be decoded 422 Unprocessable Entity.
+func Handler(config Config) zeno.Handler {
+	if config.Source == nil {
+		panic("media: Config requires a Source")
+	}
+	config = withDefaults(config)
+	if config.Cache == nil {
+		config.Cache = NewMemoryCache(64 << 20)
+	}
+	t := &transformer{
+		config: config,
+		calls:  make(map[string]*call),
+		slots:  make(chan struct{}, config.MaxConcurrent),
+	}
+	var storageConfig storage.Config
+	var cacheControl string
+	if config.MaxAge > 0 {
+		storageConfig.MaxAge = config.MaxAge
+		cacheControl = "public, max-age=" + strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+
+	return func(c *zeno.Context) error {
+		key := strings.TrimPrefix(path.Clean("/"+c.Param("path")), "/")
+		if key == "" {
+			return zeno.ErrNotFound
+		}
+		query, _ := url.ParseQuery(string(c.RequestCtx().QueryArgs().QueryString()))
+		opts, err := ParseOptions(query, config)
+		if err != nil {
+			return zeno.NewHTTPError(zeno.StatusBadRequest, "invalid image options: "+err.Error())
+		}
+		if opts.IsZero() {
+			return storage.Send(c, config.Source, key, storageConfig)
+		}
+
+		obj, err := config.Source.Stat(context.Background(), key)
+		if errors.Is(err, fs.ErrNotExist) {
+			return zeno.ErrNotFound
+		}
+		if err != nil {
+			return zeno.NewHTTPError(zeno.StatusBadGateway, "failed to stat image: "+err.Error())
+		}
+		etag := variantETag(obj, opts)
+		if cacheControl != "" {
+			c.SetHeader(zeno.HeaderCacheControl, cacheControl)
+		}
+		if inm := c.GetHeader(zeno.HeaderIfNoneMatch); inm != "" && strings.Contains(inm, etag) {
+			c.SetHeader(zeno.HeaderETag, etag)
+			return c.SendStatus(zeno.StatusNotModified)
+		}
+
+		data, err := t.get(etag, key, opts)
+		if err != nil {
+			return err
+		}
+		return c.SendContent(bytes.NewReader(data), zeno.ContentInfo{
+			ContentType: contentType(data),
+			ModTime:     obj.ModTime,
+			ETag:        etag,
+		})
+	}
+}
+
+// variantETag returns the entity tag of the variant opts of obj, which
+// also identifies it in the cache.
+func variantETag(obj storage.Object, opts Options) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%d\x00%d\x00%s", obj.Key, obj.ETag, obj.Size, obj.ModTime.UnixNano(), opts)
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// transformer produces the variants of images, computing each one once
+// even when requested concurrently.
+type transformer struct {
+	config Config
+
+	mu    sync.Mutex
+	calls map[string]*call
+
+	// slots holds a token per transformation running
+	slots chan struct{}
+}
+
+// call is a transformation in progress.
+type call struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// get returns the variant opts of the image under key, identified by id,
+// from the cache or by transforming the image.
+func (t *transformer) get(id, key string, opts Options) ([]byte, error) {
+	if data, ok := t.config.Cache.Get(id); ok {
+		return data, nil
+	}
+	t.mu.Lock()
+	if cl, ok := t.calls[id]; ok {
+		t.mu.Unlock()
+		<-cl.done
+		return cl.data, cl.err
+	}
+	cl := &call{done: make(chan struct{})}
+	t.calls[id] = cl
+	t.mu.Unlock()
+
+	t.slots <- struct{}{}
+	cl.data, cl.err = t.transform(key, opts)
+	<-t.slots
+	if cl.err == nil {
+		t.config.Cache.Set(id, cl.data)
+	}
+	t.mu.Lock()
+	delete(t.calls, id)
+	t.mu.Unlock()
+	close(cl.done)
+	return cl.data, cl.err
+}
+
+// transform reads, transforms and encodes the image under key.
+func (t *transformer) transform(key string, opts Options) ([]byte, error) {
+	// The result is shared by concurrent requests, so it does not depend
+	// on the lifetime of any of them.
+	r, err := t.config.Source.Open(context.Background(), key)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, zeno.ErrNotFound
+		}
+		return nil, zeno.NewHTTPError(zeno.StatusBadGateway, "failed to open image: "+err.Error())
+	}
+	src, err := io.ReadAll(io.LimitReader(r, t.config.MaxSourceSize+1))
+	r.Close()
+	if err != nil {
+		return nil, zeno.NewHTTPError(zeno.StatusBadGateway, "failed to read image: "+err.Error())
+	}
+	if int64(len(src)) > t.config.MaxSourceSize {
+		return nil, zeno.NewHTTPError(zeno.StatusUnprocessableEntity, "image is too large to transform")
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return nil, zeno.NewHTTPError(zeno.StatusUnprocessableEntity, "failed to decode image: "+err.Error())
+	}
+	if cfg.Width*cfg.Height > t.config.MaxSourcePixels {
+		return nil, zeno.NewHTTPError(zeno.StatusUnprocessableEntity, "image is too large to transform")
+	}
+	img, _, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, zeno.NewHTTPError(zeno.StatusUnprocessableEntity, "failed to decode image: "+err.Error())
+	}
+	img = Transform(img, opts)
+
+	if opts.Format != "" {
+		format = opts.Format
+	}
+	quality := opts.Quality
+	if quality == 0 {
+		quality = t.config.Quality
+	}
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	default:
+		// PNG also stands in for source formats that cannot be encoded,
+		// such as WebP.
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, zeno.NewHTTPError(zeno.StatusInternalServerError, "failed to encode image: "+err.Error())
+	}
+	return buf.Bytes(), nil
+}
+
+// Transform resizes and crops img according to opts. Images are never
+// enlarged: the target dimensions are scaled down to fit the source.
+func Transform(img image.Image, opts Options) image.Image {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	w, h := opts.Width, opts.Height
+	if sw == 0 || sh == 0 || (w == 0 && h == 0) {
+		return img
+	}
+	src := b
+	switch {
+	case w == 0:
+		w = sw * h / sh
+	case h == 0:
+		h = sh * w / sw
+	case opts.Fit == FitCover:
+		// Crop the source to the target aspect ratio around its center.
+		if sw*h > sh*w {
+			cw := sh * w / h
+			src.Min.X += (sw - cw) / 2
+			src.Max.X = src.Min.X + cw
+		} else {
+			ch := sw * h / w
+			src.Min.Y += (sh - ch) / 2
+			src.Max.Y = src.Min.Y + ch
+		}
+	case opts.Fit != FitFill:
+		// Contain: shrink the box to the source aspect ratio.
+		if sw*h > sh*w {
+			h = sh * w / sw
+		} else {
+			w = sw * h / sh
+		}
+	}
+	// Never enlarge.
+	if cw, ch := src.Dx(), src.Dy(); w > cw || h > ch {
+		if opts.Fit == FitFill {
+			w, h = min(w, cw), min(h, ch)
+		} else if w*ch > h*cw {
+			w, h = cw, h*cw/w
+		} else {
+			w, h = w*ch/h, ch
+		}
+	}
+	w, h = max(w, 1), max(h, 1)
+	if src == b && w == sw && h == sh {
+		return img
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+	return dst
+}
+
+// contentType returns the media type of encoded image data.
+func contentType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8")):
+		return "image/jpeg"
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		return "image/gif"
+	}
+	return "image/png"
+}
@@ -0,0 +1,210 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/Abhishek2010dev/zeno/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// countingBackend counts the images opened for transformation.
+type countingBackend struct {
+	storage.Backend
+	opened atomic.Int32
+}
+
+func (b *countingBackend) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	b.opened.Add(1)
+	return b.Backend.Open(ctx, key)
+}
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func get(z *zeno.Zeno, uri string, headers map[string]string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(uri)
+	for k, v := range headers {
+		ctx.Request.Header.Set(k, v)
+	}
+	z.HandleRequest(ctx)
+	return ctx
+}
+
+func decode(t *testing.T, ctx *fasthttp.RequestCtx) (image.Config, string) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(ctx.Response.Body()))
+	assert.NoError(t, err)
+	return cfg, format
+}
+
+func TestMount(t *testing.T) {
+	original := encodePNG(t, 100, 200)
+	source := &countingBackend{Backend: storage.FS(fstest.MapFS{
+		"photos/a.png": {Data: original},
+		"broken.png":   {Data: []byte("not an image")},
+	})}
+	z := zeno.New()
+	Mount(&z.RouteGroup, "/img", Config{Source: source, MaxWidth: 1000})
+
+	ctx := get(z, "/img/photos/a.png", nil)
+	assert.Equal(t, zeno.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, original, ctx.Response.Body())
+	source.opened.Store(0)
+
+	ctx = get(z, "/img/photos/a.png?w=50", nil)
+	assert.Equal(t, zeno.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "image/png", string(ctx.Response.Header.ContentType()))
+	cfg, _ := decode(t, ctx)
+	assert.Equal(t, [2]int{50, 100}, [2]int{cfg.Width, cfg.Height})
+	etag := string(ctx.Response.Header.Peek(zeno.HeaderETag))
+	assert.NotEmpty(t, etag)
+
+	// Served from the cache, or not at all.
+	ctx = get(z, "/img/photos/a.png?w=50", nil)
+	assert.Equal(t, etag, string(ctx.Response.Header.Peek(zeno.HeaderETag)))
+	ctx = get(z, "/img/photos/a.png?w=50", map[string]string{zeno.HeaderIfNoneMatch: etag})
+	assert.Equal(t, zeno.StatusNotModified, ctx.Response.StatusCode())
+	assert.Equal(t, int32(1), source.opened.Load())
+
+	ctx = get(z, "/img/photos/a.png?w=40&h=40&fit=cover&format=jpg&q=70", nil)
+	assert.Equal(t, "image/jpeg", string(ctx.Response.Header.ContentType()))
+	cfg, format := decode(t, ctx)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, [2]int{40, 40}, [2]int{cfg.Width, cfg.Height})
+	assert.NotEqual(t, etag, string(ctx.Response.Header.Peek(zeno.HeaderETag)))
+
+	// Images are not enlarged.
+	cfg, _ = decode(t, get(z, "/img/photos/a.png?w=800", nil))
+	assert.Equal(t, [2]int{100, 200}, [2]int{cfg.Width, cfg.Height})
+
+	assert.Equal(t, zeno.StatusBadRequest, get(z, "/img/photos/a.png?w=5000", nil).Response.StatusCode())
+	assert.Equal(t, zeno.StatusBadRequest, get(z, "/img/photos/a.png?fit=cover&w=10", nil).Response.StatusCode())
+	assert.Equal(t, zeno.StatusBadRequest, get(z, "/img/photos/a.png?format=tiff", nil).Response.StatusCode())
+	assert.Equal(t, zeno.StatusNotFound, get(z, "/img/missing.png?w=10", nil).Response.StatusCode())
+	assert.Equal(t, zeno.StatusUnprocessableEntity, get(z, "/img/broken.png?w=10", nil).Response.StatusCode())
+}
+
+func TestMount_MaxSourcePixels(t *testing.T) {
+	z := zeno.New()
+	Mount(&z.RouteGroup, "/img", Config{
+		Source:          storage.FS(fstest.MapFS{"big.png": {Data: encodePNG(t, 100, 100)}}),
+		MaxSourcePixels: 5000,
+	})
+	assert.Equal(t, zeno.StatusUnprocessableEntity, get(z, "/img/big.png?w=10", nil).Response.StatusCode())
+}
+
+func TestMount_MaxSourceSize(t *testing.T) {
+	z := zeno.New()
+	Mount(&z.RouteGroup, "/img", Config{
+		Source:        storage.FS(fstest.MapFS{"big.png": {Data: encodePNG(t, 100, 100)}}),
+		MaxSourceSize: 100,
+	})
+	assert.Equal(t, zeno.StatusUnprocessableEntity, get(z, "/img/big.png?w=10", nil).Response.StatusCode())
+}
+
+func TestMount_Presets(t *testing.T) {
+	z := zeno.New()
+	Mount(&z.RouteGroup, "/img", Config{
+		Source:  storage.FS(fstest.MapFS{"a.png": {Data: encodePNG(t, 100, 100)}}),
+		Presets: []Options{{Width: 50, Height: 50, Fit: FitCover}, {Width: 20}},
+	})
+	assert.Equal(t, zeno.StatusOK, get(z, "/img/a.png", nil).Response.StatusCode())
+	assert.Equal(t, zeno.StatusOK, get(z, "/img/a.png?w=50&h=50&fit=cover", nil).Response.StatusCode())
+	assert.Equal(t, zeno.StatusBadRequest, get(z, "/img/a.png?w=51&h=50&fit=cover", nil).Response.StatusCode())
+	assert.Equal(t, zeno.StatusOK, get(z, "/img/a.png?w=20", nil).Response.StatusCode())
+}
+
+// blockingBackend blocks opening images until release is closed.
+type blockingBackend struct {
+	countingBackend
+	release chan struct{}
+}
+
+func (b *blockingBackend) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	r, err := b.countingBackend.Open(ctx, key)
+	<-b.release
+	return r, err
+}
+
+func TestMount_MaxConcurrent(t *testing.T) {
+	source := &blockingBackend{
+		countingBackend: countingBackend{Backend: storage.FS(fstest.MapFS{"a.png": {Data: encodePNG(t, 100, 100)}})},
+		release:         make(chan struct{}),
+	}
+	z := zeno.New()
+	Mount(&z.RouteGroup, "/img", Config{Source: source, MaxConcurrent: 1})
+
+	var wg sync.WaitGroup
+	for _, w := range []string{"10", "20", "30"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, zeno.StatusOK, get(z, "/img/a.png?w="+w, nil).Response.StatusCode())
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), source.opened.Load())
+	close(source.release)
+	wg.Wait()
+	assert.Equal(t, int32(3), source.opened.Load())
+}
+
+func TestTransform(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for _, tt := range []struct {
+		query string
+		w, h  int
+	}{
+		{"w=100&h=100", 100, 50},
+		{"w=100&h=100&fit=cover", 100, 100},
+		{"w=100&h=100&fit=fill", 100, 100},
+		{"h=50", 100, 50},
+		{"w=800&h=800&fit=cover", 200, 200},
+		{"w=800&h=100&fit=fill", 400, 100},
+		{"format=png", 400, 200},
+	} {
+		query, _ := url.ParseQuery(tt.query)
+		opts, err := ParseOptions(query, Config{})
+		assert.NoError(t, err, tt.query)
+		b := Transform(img, opts).Bounds()
+		assert.Equal(t, [2]int{tt.w, tt.h}, [2]int{b.Dx(), b.Dy()}, tt.query)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(10)
+	c.Set("a", []byte("aaaa"))
+	c.Set("b", []byte("bbbb"))
+	_, _ = c.Get("a")
+	c.Set("c", []byte("cccc"))
+	_, ok := c.Get("b")
+	assert.False(t, ok, "least recently used entry is evicted")
+	data, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "aaaa", string(data))
+	c.Set("d", make([]byte, 11))
+	_, ok = c.Get("d")
+	assert.False(t, ok)
+}
@@ -0,0 +1,143 @@
+package zeno
+
+import "strings"
+
+// builtinEncodings are the media types Negotiate offers without any
+// registration, in order of preference.
+var builtinEncodings = []string{
+	"application/json",
+	"application/xml",
+	"application/yaml",
+	"application/toml",
+	"application/cbor",
+}
+
+// RegisterDecoder makes the generic binding helpers (BodyParsed and the
+// body step of H) decode request bodies of mediaType with fn. Registered
+// decoders take precedence over the built-in ones, so this can also swap
+// the decoder for a standard type.
+//
+// Example:
+//
+//	app.RegisterDecoder("application/hal+json", halDecode)
+func (z *Zeno) RegisterDecoder(mediaType string, fn DecoderFunc) {
+	if z.decoders == nil {
+		z.decoders = make(map[string]DecoderFunc)
+	}
+	z.decoders[strings.ToLower(mediaType)] = fn
+}
+
+// RegisterEncoder makes SendAs and Negotiate encode responses of mediaType
+// with fn. Registered encoders take precedence over the built-in ones and
+// are offered by Negotiate after them, in registration order.
+//
+// Example:
+//
+//	app.RegisterEncoder("application/vnd.acme.v2+json", acmeEncode)
+func (z *Zeno) RegisterEncoder(mediaType string, fn EncoderFunc) {
+	mediaType = strings.ToLower(mediaType)
+	if z.encoders == nil {
+		z.encoders = make(map[string]EncoderFunc)
+	}
+	if _, ok := z.encoders[mediaType]; !ok {
+		z.encoderTypes = append(z.encoderTypes, mediaType)
+	}
+	z.encoders[mediaType] = fn
+}
+
+// encoderFor returns the encoder for media type mt: a registered one, or
+// the engine's JSON, XML, YAML, TOML or CBOR encoder.
+func (z *Zeno) encoderFor(mt string) EncoderFunc {
+	if fn, ok := z.encoders[mt]; ok {
+		return fn
+	}
+	switch {
+	case isJSONMediaType(mt):
+		return z.JsonEncoder
+	case mt == "application/xml" || mt == "text/xml" || strings.HasSuffix(mt, "+xml"):
+		return z.XmlEncoder
+	case mt == "application/yaml" || mt == "application/x-yaml" || mt == "text/yaml" || mt == "text/x-yaml":
+		return z.YamlEncoder
+	case mt == "application/toml" || mt == "text/x-toml":
+		return z.TomlEncoder
+	case mt == "application/cbor":
+		return z.CborEncoder
+	}
+	return nil
+}
+
+// decoderFor returns the decoder for media type mt: a registered one, or
+// the engine's JSON, XML, YAML, TOML or CBOR decoder.
+func (z *Zeno) decoderFor(mt string) DecoderFunc {
+	if fn, ok := z.decoders[mt]; ok {
+		return fn
+	}
+	switch {
+	case isJSONMediaType(mt):
+		return z.JsonDecoder
+	case mt == "application/xml" || mt == "text/xml" || strings.HasSuffix(mt, "+xml"):
+		return z.XmlDecoder
+	case mt == "application/yaml" || mt == "application/x-yaml" || mt == "text/yaml" || mt == "text/x-yaml":
+		return z.YamlDecoder
+	case mt == "application/toml" || mt == "text/x-toml":
+		return z.TomlDecoder
+	case mt == "application/cbor":
+		return z.CborDecoder
+	}
+	return nil
+}
+
+// Encoder returns the encoder z uses for mediaType, a registered one or a
+// built-in one, or nil if it has none. It lets code outside a request,
+// such as an HTTP client, share the engine's codecs.
+func (z *Zeno) Encoder(mediaType string) EncoderFunc {
+	return z.encoderFor(strings.ToLower(mediaType))
+}
+
+// Decoder returns the decoder z uses for mediaType, a registered one or a
+// built-in one, or nil if it has none.
+func (z *Zeno) Decoder(mediaType string) DecoderFunc {
+	return z.decoderFor(strings.ToLower(mediaType))
+}
+
+// SendAs encodes v with the encoder for mediaType (see RegisterEncoder)
+// and writes it with that Content-Type. A 500 Internal Server Error is
+// returned if no encoder handles mediaType or encoding fails.
+//
+// Example:
+//
+//	return c.SendAs("application/hal+json", order)
+func (c *Context) SendAs(mediaType string, v any) error {
+	mt := strings.ToLower(mediaType)
+	encode := c.zeno.encoderFor(mt)
+	if encode == nil {
+		return NewHTTPError(StatusInternalServerError, "No encoder for "+mt)
+	}
+	b, err := encode(v)
+	if err != nil {
+		return NewHTTPError(StatusInternalServerError, "Failed to encode "+mt+": "+err.Error())
+	}
+	c.setMediaType(mt, nil)
+	return c.SendBytes(b)
+}
+
+// Negotiate encodes v in the format that best matches the request's
+// Accept header, choosing among JSON, XML, YAML, TOML, CBOR and the media
+// types registered with RegisterEncoder. JSON is used when the request has
+// no Accept header; a 406 Not Acceptable error is returned when nothing
+// matches. A "Vary: Accept" header is added.
+//
+// Example:
+//
+//	return c.Negotiate(user)
+func (c *Context) Negotiate(v any) error {
+	c.Vary(HeaderAccept)
+	if c.GetHeader(HeaderAccept) == "" {
+		return c.SendAs(builtinEncodings[0], v)
+	}
+	offers := append(append([]string{}, builtinEncodings...), c.zeno.encoderTypes...)
+	if best := c.Accepts(offers...); best != "" {
+		return c.SendAs(best, v)
+	}
+	return ErrNotAcceptable
+}
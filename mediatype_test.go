@@ -0,0 +1,47 @@
+package zeno
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZeno_RegisterCodecs(t *testing.T) {
+	z := New()
+	z.RegisterDecoder("text/x-kv", func(data []byte, v any) error {
+		k, val, _ := strings.Cut(string(data), "=")
+		(*v.(*map[string]string))[k] = val
+		return nil
+	})
+	z.RegisterEncoder("text/x-kv", func(v any) ([]byte, error) {
+		var b strings.Builder
+		for k, val := range v.(map[string]string) {
+			b.WriteString(k + "=" + val)
+		}
+		return []byte(b.String()), nil
+	})
+	z.Post("/kv", func(c *Context) error {
+		m := map[string]string{}
+		if err := c.BodyParsed(&m); err != nil {
+			return err
+		}
+		return c.Negotiate(m)
+	})
+
+	ctx := performRequest(z, "POST", "/kv", map[string]string{HeaderContentType: "text/x-kv", HeaderAccept: "text/x-kv"}, []byte("a=1"))
+	assert.Equal(t, "a=1", string(ctx.Response.Body()))
+	assert.Equal(t, "text/x-kv; charset=utf-8", string(ctx.Response.Header.ContentType()))
+
+	ctx = performRequest(z, "POST", "/kv", map[string]string{HeaderContentType: "text/x-kv"}, []byte("a=1"))
+	assert.JSONEq(t, `{"a":"1"}`, string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "POST", "/kv", map[string]string{HeaderContentType: "text/x-kv", HeaderAccept: "*/*"}, []byte("a=1"))
+	assert.JSONEq(t, `{"a":"1"}`, string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "POST", "/kv", map[string]string{HeaderContentType: "text/x-kv", HeaderAccept: "application/yaml"}, []byte("a=1"))
+	assert.Equal(t, "a: \"1\"\n", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "POST", "/kv", map[string]string{HeaderContentType: "text/x-kv", HeaderAccept: "image/png"}, []byte("a=1"))
+	assert.Equal(t, StatusNotAcceptable, ctx.Response.StatusCode())
+}
@@ -0,0 +1,105 @@
+package zeno
+
+import "slices"
+
+// Meta attaches the annotation key with value to the route. Middleware
+// reads it through Context.Route, letting policies such as required auth
+// scopes or rate limits be declared next to the route instead of matched
+// against paths. Annotations should be set before the server starts.
+//
+// Example:
+//
+//	app.Delete("/users/{id}", deleteUser).Meta("scope", "users:write")
+//
+//	app.Use(func(c *zeno.Context) error {
+//	    if r := c.Route(); r != nil {
+//	        if scope, ok := r.MetaValue("scope"); ok && !hasScope(c, scope.(string)) {
+//	            return zeno.ErrForbidden
+//	        }
+//	    }
+//	    return c.Next()
+//	})
+func (r *Route) Meta(key string, value any) *Route {
+	if r.meta == nil {
+		r.meta = make(map[string]any)
+	}
+	r.meta[key] = value
+	return r
+}
+
+// MetaValue returns the annotation stored under key with Meta and whether
+// it is set.
+func (r *Route) MetaValue(key string) (any, bool) {
+	v, ok := r.meta[key]
+	return v, ok
+}
+
+// Tags adds tags to the route, ignoring ones it already has.
+//
+// Example:
+//
+//	app.Get("/admin/stats", stats).Tags("admin", "beta")
+func (r *Route) Tags(tags ...string) *Route {
+	for _, tag := range tags {
+		if !slices.Contains(r.tags, tag) {
+			r.tags = append(r.tags, tag)
+		}
+	}
+	return r
+}
+
+// HasTag reports whether the route has tag.
+func (r *Route) HasTag(tag string) bool {
+	return slices.Contains(r.tags, tag)
+}
+
+// TagList returns the tags of the route in the order they were added.
+func (r *Route) TagList() []string {
+	return slices.Clone(r.tags)
+}
+
+// Route returns the route matched by the current request, or nil when
+// none did, e.g. in the not-found and 405 chains.
+//
+// Example:
+//
+//	if r := c.Route(); r != nil && r.HasTag("admin") {
+//	    // require an administrator
+//	}
+func (c *Context) Route() *Route {
+	c.mustBeAlive()
+	return c.zeno.table.Load().routeFor(c.handlers)
+}
+
+// Pattern returns the path pattern the route was registered with,
+// including the group prefix and any parameter regular expressions,
+// e.g. "/users/{id:[0-9]+}".
+func (r *Route) Pattern() string {
+	return r.path
+}
+
+// Template returns the route's path pattern with parameter regular
+// expressions removed, e.g. "/users/{id}".
+func (r *Route) Template() string {
+	return r.template
+}
+
+// RoutePattern returns the template of the route matched by the current
+// request, e.g. "/users/{id}", or "" when none matched. Unlike the raw
+// path it has one value per route, so it suits labels for metrics, span
+// names and log fields without exploding their cardinality.
+//
+// Example:
+//
+//	app.Use(func(c *zeno.Context) error {
+//	    start := time.Now()
+//	    err := c.Next()
+//	    requestDuration.WithLabelValues(c.Method(), c.RoutePattern()).Observe(time.Since(start).Seconds())
+//	    return err
+//	})
+func (c *Context) RoutePattern() string {
+	if r := c.Route(); r != nil {
+		return r.template
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package zeno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoute_Meta(t *testing.T) {
+	z := New()
+	z.Use(func(c *Context) error {
+		r := c.Route()
+		if r == nil {
+			c.SetHeader("X-Route", "none")
+			return c.Next()
+		}
+		if r.HasTag("admin") && c.GetHeader("X-Admin") == "" {
+			return ErrForbidden
+		}
+		if scope, ok := r.MetaValue("scope"); ok {
+			c.SetHeader("X-Scope", scope.(string))
+		}
+		return c.Next()
+	})
+	ok := func(c *Context) error { return c.SendString("ok") }
+	r := z.Get("/admin/stats", ok).Tags("admin", "beta", "admin")
+	z.Get("/users/{id}", ok).Meta("scope", "users:read")
+
+	assert.Equal(t, []string{"admin", "beta"}, r.TagList())
+
+	ctx := performRequest(z, "GET", "/admin/stats", nil, nil)
+	assert.Equal(t, StatusForbidden, ctx.Response.StatusCode())
+	ctx = performRequest(z, "GET", "/admin/stats", map[string]string{"X-Admin": "1"}, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+
+	ctx = performRequest(z, "GET", "/users/1", nil, nil)
+	assert.Equal(t, "users:read", string(ctx.Response.Header.Peek("X-Scope")))
+
+	ctx = performRequest(z, "GET", "/missing", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+	assert.Equal(t, "none", string(ctx.Response.Header.Peek("X-Route")))
+}
+
+func TestContext_RoutePattern(t *testing.T) {
+	z := New()
+	var pattern string
+	z.Use(func(c *Context) error {
+		err := c.Next()
+		pattern = c.RoutePattern()
+		return err
+	})
+	api := z.Group("/api")
+	r := api.Get("/users/{id:[0-9]+}/posts/{slug}", func(c *Context) error { return c.SendString("ok") })
+
+	assert.Equal(t, "/api/users/{id:[0-9]+}/posts/{slug}", r.Pattern())
+	assert.Equal(t, "/api/users/{id}/posts/{slug}", r.Template())
+
+	performRequest(z, "GET", "/api/users/42/posts/hello", nil, nil)
+	assert.Equal(t, "/api/users/{id}/posts/{slug}", pattern)
+
+	performRequest(z, "GET", "/api/nothing", nil, nil)
+	assert.Equal(t, "", pattern)
+}
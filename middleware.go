@@ -0,0 +1,100 @@
+package zeno
+
+import (
+	"fmt"
+	"slices"
+)
+
+// UseNamed appends handler to the global middleware, like Use, under a
+// name that InsertBefore, InsertAfter and RemoveMiddleware can refer to.
+// It panics if the name is empty or already taken.
+//
+// As with Use, changes to the global middleware apply to routes registered
+// afterwards, so arrange the chain before registering routes.
+//
+// Example:
+//
+//	app.UseNamed("logger", logger)
+//	app.UseNamed("auth", auth)
+//	app.InsertBefore("auth", "ratelimit", limiter)
+//	fmt.Println(app.Middlewares()) // [logger ratelimit auth]
+func (z *Zeno) UseNamed(name string, handler Handler) {
+	z.insertMiddleware(len(z.handlers), name, handler)
+}
+
+// InsertBefore inserts handler into the global middleware under name,
+// directly before the middleware named target. It panics if target does
+// not exist or name is empty or already taken.
+func (z *Zeno) InsertBefore(target, name string, handler Handler) {
+	z.insertMiddleware(z.mustMiddlewareIndex(target), name, handler)
+}
+
+// InsertAfter inserts handler into the global middleware under name,
+// directly after the middleware named target. It panics if target does
+// not exist or name is empty or already taken.
+func (z *Zeno) InsertAfter(target, name string, handler Handler) {
+	z.insertMiddleware(z.mustMiddlewareIndex(target)+1, name, handler)
+}
+
+// RemoveMiddleware removes the global middleware registered under name,
+// reporting whether it existed. Routes registered earlier keep it.
+func (z *Zeno) RemoveMiddleware(name string) bool {
+	i := z.middlewareIndex(name)
+	if i < 0 {
+		return false
+	}
+	z.handlers = slices.Delete(slices.Clone(z.handlers), i, i+1)
+	z.middlewareNames = slices.Delete(z.middlewareNames, i, i+1)
+	z.notFoundHandlers = combineHandlers(z.handlers, z.notFound)
+	z.assetPaths.Store(nil)
+	return true
+}
+
+// Middlewares returns the names of the global middleware in execution
+// order. Middleware added with Use has an empty name.
+func (z *Zeno) Middlewares() []string {
+	return slices.Clone(z.syncMiddlewareNames())
+}
+
+// insertMiddleware adds handler under name at index i of the global chain.
+func (z *Zeno) insertMiddleware(i int, name string, handler Handler) {
+	if name == "" {
+		panic("zeno: middleware name must not be empty")
+	}
+	if z.middlewareIndex(name) >= 0 {
+		panic(fmt.Sprintf("zeno: middleware %q already registered", name))
+	}
+	// Clone so groups created earlier, which share the backing array, are
+	// not affected.
+	z.handlers = slices.Insert(slices.Clone(z.handlers), i, handler)
+	z.middlewareNames = slices.Insert(z.middlewareNames, i, name)
+	z.notFoundHandlers = combineHandlers(z.handlers, z.notFound)
+	z.assetPaths.Store(nil)
+}
+
+// middlewareIndex returns the position of the middleware called name in
+// the global chain, or -1.
+func (z *Zeno) middlewareIndex(name string) int {
+	if name == "" {
+		return -1
+	}
+	return slices.Index(z.syncMiddlewareNames(), name)
+}
+
+// mustMiddlewareIndex is middlewareIndex, panicking if name is unknown.
+func (z *Zeno) mustMiddlewareIndex(name string) int {
+	i := z.middlewareIndex(name)
+	if i < 0 {
+		panic(fmt.Sprintf("zeno: middleware %q not found", name))
+	}
+	return i
+}
+
+// syncMiddlewareNames pads middlewareNames to the length of the global
+// chain, covering handlers added through RouteGroup.Use directly.
+func (z *Zeno) syncMiddlewareNames() []string {
+	if n := len(z.handlers) - len(z.middlewareNames); n > 0 {
+		z.middlewareNames = append(z.middlewareNames, make([]string, n)...)
+	}
+	return z.middlewareNames
+}
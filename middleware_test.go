@@ -0,0 +1,32 @@
+package zeno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamedMiddleware(t *testing.T) {
+	z := New()
+	mark := func(s string) Handler {
+		return func(c *Context) error {
+			c.ctx.Response.AppendBodyString(s)
+			return c.Next()
+		}
+	}
+	z.UseNamed("a", mark("a"))
+	z.Use(mark("-"))
+	z.UseNamed("c", mark("c"))
+	z.InsertBefore("c", "b", mark("b"))
+	z.InsertAfter("a", "x", mark("x"))
+	assert.True(t, z.RemoveMiddleware("x"))
+	assert.False(t, z.RemoveMiddleware("x"))
+	assert.Equal(t, []string{"a", "", "b", "c"}, z.Middlewares())
+
+	z.Get("/", func(c *Context) error { return nil })
+	ctx := performRequest(z, "GET", "/", nil, nil)
+	assert.Equal(t, "a-bc", string(ctx.Response.Body()))
+
+	assert.Panics(t, func() { z.UseNamed("a", mark("a")) })
+	assert.Panics(t, func() { z.InsertAfter("missing", "y", mark("y")) })
+}
@@ -0,0 +1,295 @@
+package openapi
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Abhishek2010dev/zeno"
+)
+
+// MockConfig configures a mock server.
+type MockConfig struct {
+	// Validate checks requests against the document, as the Validator
+	// middleware does, so clients find out about malformed requests
+	// before the real handlers exist.
+	Validate bool
+}
+
+// NewMock returns a Zeno instance serving every operation of doc with a
+// mock response, for clients to develop against before the real handlers
+// exist. See Mock for how responses are chosen.
+//
+// Example:
+//
+//	doc, err := openapi.LoadFile("openapi.yaml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	log.Fatal(openapi.NewMock(doc, openapi.MockConfig{Validate: true}).Run(":4010"))
+func NewMock(doc *Document, config ...MockConfig) *zeno.Zeno {
+	z := zeno.New()
+	Mock(&z.RouteGroup, doc, config...)
+	return z
+}
+
+// Mock registers a route in r for every operation of doc, answering with
+// a documented response: the lowest documented 2xx status, and the
+// example of the media type the client accepts, or, when the document has
+// no example, a value generated from the schema. Clients pick another
+// documented response or a named example with the Prefer header, e.g.
+// "Prefer: code=404" or "Prefer: example=admin". Operations that already
+// have a route in r are skipped, so the real handlers take precedence. It
+// panics if a path of doc is not a valid route pattern.
+//
+// Example:
+//
+//	// Serve the parts of the API that are not implemented yet.
+//	openapi.Mock(app.Group("/api/v2"), doc)
+func Mock(r *zeno.RouteGroup, doc *Document, config ...MockConfig) {
+	var cfg MockConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	var middleware []zeno.Handler
+	if cfg.Validate {
+		middleware = append(middleware, Validator(doc, Config{BasePath: r.Prefix()}))
+	}
+	for _, rt := range doc.routes {
+		if _, err := r.AddRoute(rt.method, rt.path, append(middleware, mockHandler(rt.op))...); err != nil && !errors.Is(err, zeno.ErrRouteExists) {
+			panic(err)
+		}
+	}
+}
+
+// mockHandler returns the handler answering op with mock responses.
+func mockHandler(op *Operation) zeno.Handler {
+	return func(c *zeno.Context) error {
+		prefer := parsePrefer(c.GetHeader("Prefer"))
+		status, resp := mockResponse(op, prefer["code"])
+		if resp == nil {
+			if prefer["code"] != "" {
+				return zeno.NewHTTPError(zeno.StatusBadRequest, "no response documented for status "+prefer["code"])
+			}
+			return c.SendStatusCode(status)
+		}
+		if len(resp.Content) == 0 {
+			return c.SendStatusCode(status)
+		}
+		c.Status(status)
+
+		types := mediaTypes(resp.Content)
+		sort.SliceStable(types, func(i, j int) bool { return isJSON(types[i]) && !isJSON(types[j]) })
+		ct := types[0]
+		if c.GetHeader(zeno.HeaderAccept) != "" {
+			if ct = c.Accepts(types...); ct == "" {
+				return zeno.ErrNotAcceptable
+			}
+		}
+		v, ok := mockExample(resp.Content[ct], prefer["example"])
+		if !ok {
+			return zeno.NewHTTPError(zeno.StatusBadRequest, "no example named "+prefer["example"])
+		}
+		if s, isString := v.(string); isString && !isJSON(ct) {
+			c.SetContentType(ct)
+			return c.SendString(s)
+		}
+		return c.SendJSON(v, ct)
+	}
+}
+
+// parsePrefer returns the preferences of a Prefer header (RFC 7240), e.g.
+// "code=404, example=notFound".
+func parsePrefer(header string) map[string]string {
+	prefs := make(map[string]string)
+	for _, part := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+		k, v, _ := strings.Cut(part, "=")
+		prefs[strings.ToLower(strings.TrimSpace(k))] = strings.Trim(strings.TrimSpace(v), `"`)
+	}
+	return prefs
+}
+
+// mockResponse returns the status and response to mock for op: the one
+// documented for code if given, otherwise the lowest documented 2xx
+// status, a 2XX range or the default response. Without any of these it
+// returns 204 No Content and a nil Response.
+func mockResponse(op *Operation, code string) (int, *Response) {
+	if code != "" {
+		status, err := strconv.Atoi(code)
+		if err != nil {
+			return 0, nil
+		}
+		return status, lookupResponse(op.Responses, status)
+	}
+	codes := make([]string, 0, len(op.Responses))
+	for k := range op.Responses {
+		codes = append(codes, k)
+	}
+	sort.Strings(codes)
+	for _, k := range codes {
+		if status, err := strconv.Atoi(k); err == nil && status >= 200 && status < 300 {
+			return status, op.Responses[k]
+		}
+	}
+	for _, k := range []string{"2XX", "2xx", "default"} {
+		if resp := op.Responses[k]; resp != nil {
+			return zeno.StatusOK, resp
+		}
+	}
+	return zeno.StatusNoContent, nil
+}
+
+// mockExample returns the example of mt named name, or, without a name,
+// its example, its first named example or a value generated from its
+// schema.
+func mockExample(mt *MediaType, name string) (any, bool) {
+	if mt == nil {
+		return nil, name == ""
+	}
+	if name != "" {
+		e := mt.Examples[name]
+		if e == nil {
+			return nil, false
+		}
+		return e.Value, true
+	}
+	if mt.Example != nil {
+		return mt.Example, true
+	}
+	names := make([]string, 0, len(mt.Examples))
+	for k := range mt.Examples {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		if e := mt.Examples[k]; e != nil {
+			return e.Value, true
+		}
+	}
+	return mt.Schema.Generate(), true
+}
+
+// isJSON reports whether the media type ct is JSON.
+func isJSON(ct string) bool {
+	return ct == "application/json" || strings.HasSuffix(ct, "+json")
+}
+
+// maxGenerateDepth bounds the nesting of generated values, so recursive
+// schemas terminate.
+const maxGenerateDepth = 8
+
+// Generate returns an example value for s: its example, default or first
+// enum value if given, otherwise a placeholder of its type, with every
+// property of objects and the minimum number of items, but at least one,
+// in arrays.
+//
+// Example:
+//
+//	v := doc.Components.Schemas["User"].Generate()
+//	// map[string]any{"id": 0.0, "email": "user@example.com", "tags": []any{"string"}}
+func (s *Schema) Generate() any {
+	return s.generate(0)
+}
+
+// generate returns an example value for s at the given nesting depth.
+func (s *Schema) generate(depth int) any {
+	switch {
+	case s == nil || s.never:
+		return nil
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.OneOf) > 0:
+		return s.OneOf[0].generate(depth)
+	case len(s.AnyOf) > 0:
+		return s.AnyOf[0].generate(depth)
+	}
+
+	typ := ""
+	for _, t := range s.Type {
+		if t != "null" {
+			typ = t
+			break
+		}
+	}
+	switch {
+	case typ == "" && (len(s.Properties) > 0 || len(s.AllOf) > 0):
+		typ = "object"
+	case typ == "" && s.Items != nil:
+		typ = "array"
+	}
+
+	switch typ {
+	case "string":
+		return generateString(s)
+	case "integer", "number":
+		switch {
+		case s.Minimum != nil:
+			return *s.Minimum
+		case s.Maximum != nil && *s.Maximum < 0:
+			return *s.Maximum
+		}
+		return 0.0
+	case "boolean":
+		return true
+	case "array":
+		n := 1
+		if s.MinItems != nil && *s.MinItems > n {
+			n = *s.MinItems
+		}
+		if (s.MaxItems != nil && *s.MaxItems == 0) || depth >= maxGenerateDepth {
+			n = 0
+		}
+		items := make([]any, n)
+		for i := range items {
+			items[i] = s.Items.generate(depth + 1)
+		}
+		return items
+	case "object":
+		obj := make(map[string]any)
+		if depth >= maxGenerateDepth {
+			return obj
+		}
+		for _, sub := range s.AllOf {
+			if m, ok := sub.generate(depth).(map[string]any); ok {
+				for k, v := range m {
+					obj[k] = v
+				}
+			}
+		}
+		for name, prop := range s.Properties {
+			obj[name] = prop.generate(depth + 1)
+		}
+		return obj
+	}
+	return nil
+}
+
+// generateString returns a placeholder string valid for the format and
+// length bounds of s.
+func generateString(s *Schema) string {
+	switch s.Format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-4000-8000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	}
+	v := "string"
+	if s.MinLength != nil && len(v) < *s.MinLength {
+		v += strings.Repeat("x", *s.MinLength-len(v))
+	}
+	if s.MaxLength != nil && len(v) > *s.MaxLength {
+		v = v[:*s.MaxLength]
+	}
+	return v
+}
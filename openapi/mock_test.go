@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/Abhishek2010dev/zeno/zenotest"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockSpec = `
+openapi: 3.1.0
+info: {title: Pets, version: "1"}
+paths:
+  /pets:
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, maximum: 50}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: "#/components/schemas/Pet"}
+    post:
+      responses:
+        "201": {description: created}
+  /pets/{id}:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              examples:
+                rex: {value: {id: 1, name: Rex, tag: dog}}
+                tom: {$ref: "#/components/examples/Tom"}
+            text/plain:
+              example: Rex
+        "404":
+          description: not found
+          content:
+            application/problem+json:
+              example: {title: Not Found}
+components:
+  examples:
+    Tom: {value: {id: 2, name: Tom, tag: cat}}
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id: {type: integer, minimum: 1}
+        name: {type: string, minLength: 8}
+        tag: {type: [string, "null"], enum: [dog, cat]}
+        born: {type: string, format: date}
+        owner: {$ref: "#/components/schemas/Owner"}
+    Owner:
+      type: object
+      properties:
+        name: {type: string, default: Ann}
+        pets:
+          type: array
+          items: {$ref: "#/components/schemas/Pet"}
+`
+
+func TestMock(t *testing.T) {
+	doc, err := Load([]byte(mockSpec))
+	assert.NoError(t, err)
+	tc := zenotest.New(NewMock(doc, MockConfig{Validate: true}))
+	defer tc.Close()
+
+	var pets []map[string]any
+	resp := tc.Get("/pets").Do().ExpectStatus(t, zeno.StatusOK)
+	assert.NoError(t, resp.DecodeJSON(&pets))
+	if assert.Len(t, pets, 1) {
+		assert.Equal(t, 1.0, pets[0]["id"])
+		assert.Equal(t, "stringxx", pets[0]["name"])
+		assert.Equal(t, "dog", pets[0]["tag"])
+		assert.Equal(t, "2024-01-01", pets[0]["born"])
+		assert.Equal(t, "Ann", pets[0]["owner"].(map[string]any)["name"])
+	}
+	var generated []any
+	assert.NoError(t, resp.DecodeJSON(&generated))
+	assert.Empty(t, doc.Paths["/pets"].Get.Responses["200"].Content["application/json"].Schema.Validate(generated))
+
+	tc.Get("/pets").Query("limit", "100").Do().ExpectStatus(t, zeno.StatusBadRequest)
+	tc.Post("/pets").Do().ExpectStatus(t, zeno.StatusCreated)
+
+	tc.Get("/pets/1").Do().
+		ExpectStatus(t, zeno.StatusOK).
+		ExpectJSON(t, map[string]any{"id": 1, "name": "Rex", "tag": "dog"})
+	tc.Get("/pets/1").Header("Prefer", "example=tom").Do().
+		ExpectJSON(t, map[string]any{"id": 2, "name": "Tom", "tag": "cat"})
+	tc.Get("/pets/1").Header(zeno.HeaderAccept, "text/plain").Do().
+		ExpectHeader(t, zeno.HeaderContentType, "text/plain").
+		ExpectBody(t, "Rex")
+	tc.Get("/pets/1").Header("Prefer", "code=404").Do().
+		ExpectStatus(t, zeno.StatusNotFound).
+		ExpectHeader(t, zeno.HeaderContentType, "application/problem+json").
+		ExpectJSON(t, map[string]any{"title": "Not Found"})
+
+	tc.Get("/pets/1").Header("Prefer", "code=500").Do().ExpectStatus(t, zeno.StatusBadRequest)
+	tc.Get("/pets/1").Header("Prefer", "example=missing").Do().ExpectStatus(t, zeno.StatusBadRequest)
+	tc.Get("/pets/1").Header(zeno.HeaderAccept, "image/png").Do().ExpectStatus(t, zeno.StatusNotAcceptable)
+}
+
+func TestMock_Group(t *testing.T) {
+	doc, err := Load([]byte(mockSpec))
+	assert.NoError(t, err)
+	z := zeno.New()
+	z.Get("/health", func(c *zeno.Context) error { return c.SendString("ok") })
+	z.Post("/api/pets", func(c *zeno.Context) error { return c.SendString("created") })
+	Mock(z.Group("/api"), doc, MockConfig{Validate: true})
+	tc := zenotest.New(z)
+	defer tc.Close()
+
+	tc.Get("/health").Do().ExpectBody(t, "ok")
+	tc.Get("/api/pets").Do().ExpectStatus(t, zeno.StatusOK)
+	tc.Get("/api/pets").Query("limit", "100").Do().ExpectStatus(t, zeno.StatusBadRequest)
+	tc.Get("/pets").Do().ExpectStatus(t, zeno.StatusNotFound)
+	tc.Post("/api/pets").Do().ExpectBody(t, "created")
+}
@@ -0,0 +1,849 @@
+// Package openapi validates requests, and optionally responses, against an
+// OpenAPI 3 document.
+//
+// A Document is loaded from the JSON or YAML form of a specification, and
+// the Validator middleware matches each request to the operation it
+// documents, checking path, query, header and cookie parameters, the
+// Content-Type and the body schema. Requests that do not conform are
+// answered with a structured 400 Bad Request listing every violation.
+// NewMock and Mock serve a document's operations from its examples before
+// the real handlers exist.
+//
+// Only the parts of OpenAPI needed for validation are modelled. Schemas
+// support the common JSON Schema keywords (type, enum, format, length,
+// pattern and range bounds, items, properties, required,
+// additionalProperties, allOf, anyOf and oneOf), and references are
+// resolved within the document's components.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Abhishek2010dev/zeno"
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a loaded OpenAPI document.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	routes []*route // operations, most specific path first
+}
+
+// Info holds the document's metadata.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem holds the operations of a path template such as
+// "/users/{id}".
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters,omitempty"`
+	Get        *Operation   `json:"get,omitempty"`
+	Put        *Operation   `json:"put,omitempty"`
+	Post       *Operation   `json:"post,omitempty"`
+	Delete     *Operation   `json:"delete,omitempty"`
+	Options    *Operation   `json:"options,omitempty"`
+	Head       *Operation   `json:"head,omitempty"`
+	Patch      *Operation   `json:"patch,omitempty"`
+	Trace      *Operation   `json:"trace,omitempty"`
+}
+
+// operations returns the operations of p by HTTP method.
+func (p *PathItem) operations() map[string]*Operation {
+	ops := map[string]*Operation{
+		zeno.MethodGet: p.Get, zeno.MethodPut: p.Put, zeno.MethodPost: p.Post,
+		zeno.MethodDelete: p.Delete, zeno.MethodOptions: p.Options, zeno.MethodHead: p.Head,
+		zeno.MethodPatch: p.Patch, zeno.MethodTrace: p.Trace,
+	}
+	if ops[zeno.MethodHead] == nil {
+		ops[zeno.MethodHead] = p.Get
+	}
+	return ops
+}
+
+// Operation is a single API operation on a path.
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses,omitempty"`
+
+	params []*Parameter // path item and operation parameters, merged
+}
+
+// Parameter is a path, query, header or cookie parameter.
+type Parameter struct {
+	Ref      string  `json:"$ref,omitempty"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Explode  *bool   `json:"explode,omitempty"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody describes the bodies an operation accepts, by media type.
+type RequestBody struct {
+	Ref      string                `json:"$ref,omitempty"`
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Response describes the bodies of a response, by media type.
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema and examples of a body in one media type.
+type MediaType struct {
+	Schema   *Schema             `json:"schema,omitempty"`
+	Example  any                 `json:"example,omitempty"`
+	Examples map[string]*Example `json:"examples,omitempty"`
+}
+
+// Example is a named example of a body.
+type Example struct {
+	Ref     string `json:"$ref,omitempty"`
+	Summary string `json:"summary,omitempty"`
+	Value   any    `json:"value,omitempty"`
+}
+
+// Components holds the reusable objects that references point to, such
+// as "#/components/schemas/User".
+type Components struct {
+	Schemas       map[string]*Schema      `json:"schemas,omitempty"`
+	Parameters    map[string]*Parameter   `json:"parameters,omitempty"`
+	RequestBodies map[string]*RequestBody `json:"requestBodies,omitempty"`
+	Responses     map[string]*Response    `json:"responses,omitempty"`
+	Examples      map[string]*Example     `json:"examples,omitempty"`
+}
+
+// Load parses an OpenAPI 3 document in JSON or YAML form and resolves its
+// references. It returns an error if the document cannot be parsed, a
+// reference cannot be resolved or a schema pattern does not compile.
+//
+// Example:
+//
+//	doc, err := openapi.Load(spec) // e.g. an embedded openapi.yaml
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	app.Use(openapi.Validator(doc))
+func Load(data []byte) (*Document, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		var v any
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("openapi: invalid document: %w", err)
+		}
+		b, err := json.Marshal(jsonValue(v))
+		if err != nil {
+			return nil, fmt.Errorf("openapi: invalid document: %w", err)
+		}
+		data = b
+	}
+	doc := &Document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("openapi: invalid document: %w", err)
+	}
+	if err := doc.init(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// LoadFile reads and parses the OpenAPI document in the named file, as
+// Load does.
+func LoadFile(name string) (*Document, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Load(data)
+}
+
+// jsonValue converts a value decoded from YAML into one encoding/json can
+// marshal: mapping keys, such as unquoted status codes, become strings.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			v[k] = jsonValue(child)
+		}
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, child := range v {
+			m[fmt.Sprint(k)] = jsonValue(child)
+		}
+		return m
+	case []any:
+		for i, child := range v {
+			v[i] = jsonValue(child)
+		}
+	}
+	return v
+}
+
+// route is an operation with its compiled path template.
+type route struct {
+	method string
+	path   string
+	re     *regexp.Regexp
+	names  []string // path parameter names, in order
+	op     *Operation
+}
+
+// init resolves the document's references and compiles its paths.
+func (d *Document) init() error {
+	r := &resolver{doc: d, seen: make(map[*Schema]bool)}
+	for name, s := range d.Components.Schemas {
+		r.schema(&s)
+		d.Components.Schemas[name] = s
+	}
+	for _, p := range d.Components.Parameters {
+		r.schema(&p.Schema)
+	}
+	for _, b := range d.Components.RequestBodies {
+		r.content(b.Content)
+	}
+	for _, resp := range d.Components.Responses {
+		r.content(resp.Content)
+	}
+
+	done := make(map[*Operation]bool) // HEAD may share the GET operation
+	for path, item := range d.Paths {
+		re, names, err := compilePath(path)
+		if err != nil {
+			return err
+		}
+		r.parameters(item.Parameters)
+		for method, op := range item.operations() {
+			if op == nil {
+				continue
+			}
+			if !done[op] {
+				done[op] = true
+				r.parameters(op.Parameters)
+				op.params = mergeParameters(item.Parameters, op.Parameters)
+				r.requestBody(&op.RequestBody)
+				for code, resp := range op.Responses {
+					r.response(&resp)
+					op.Responses[code] = resp
+				}
+			}
+			d.routes = append(d.routes, &route{method: method, path: path, re: re, names: names, op: op})
+		}
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	// Concrete paths take precedence over templated ones, as in
+	// "/users/me" over "/users/{id}".
+	sort.Slice(d.routes, func(i, j int) bool {
+		a, b := d.routes[i], d.routes[j]
+		if len(a.names) != len(b.names) {
+			return len(a.names) < len(b.names)
+		}
+		if len(a.path) != len(b.path) {
+			return len(a.path) > len(b.path)
+		}
+		return a.path < b.path
+	})
+	return nil
+}
+
+// compilePath compiles a path template into a regular expression with one
+// group per parameter.
+func compilePath(path string) (*regexp.Regexp, []string, error) {
+	var b strings.Builder
+	var names []string
+	b.WriteByte('^')
+	for rest := path; rest != ""; {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			b.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, nil, fmt.Errorf("openapi: unclosed parameter in path %q", path)
+		}
+		b.WriteString(regexp.QuoteMeta(rest[:open]))
+		b.WriteString("([^/]+)")
+		names = append(names, rest[open+1:open+end])
+		rest = rest[open+end+1:]
+	}
+	b.WriteByte('$')
+	return regexp.MustCompile(b.String()), names, nil
+}
+
+// mergeParameters returns the path item parameters not overridden by an
+// operation parameter with the same name and location, followed by the
+// operation parameters.
+func mergeParameters(item, op []*Parameter) []*Parameter {
+	var params []*Parameter
+	for _, p := range item {
+		if !slices.ContainsFunc(op, func(o *Parameter) bool { return o.Name == p.Name && o.In == p.In }) {
+			params = append(params, p)
+		}
+	}
+	return append(params, op...)
+}
+
+// resolver replaces references by the components they point to, recording
+// the first failure.
+type resolver struct {
+	doc  *Document
+	seen map[*Schema]bool
+	err  error
+}
+
+// lookup returns the name of the component in section that ref points to.
+func (r *resolver) lookup(ref, section string) (string, bool) {
+	name, ok := strings.CutPrefix(ref, "#/components/"+section+"/")
+	if !ok && r.err == nil {
+		r.err = fmt.Errorf("openapi: unsupported reference %q", ref)
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~"), ok
+}
+
+// missing records a reference to a component that does not exist.
+func (r *resolver) missing(ref string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("openapi: unresolved reference %q", ref)
+	}
+}
+
+// schema resolves *p and the schemas it contains.
+func (r *resolver) schema(p **Schema) {
+	s := *p
+	for i := 0; s != nil && s.Ref != ""; i++ {
+		name, ok := r.lookup(s.Ref, "schemas")
+		target := r.doc.Components.Schemas[name]
+		if !ok || target == nil || i > len(r.doc.Components.Schemas) {
+			r.missing(s.Ref)
+			*p = nil
+			return
+		}
+		s = target
+	}
+	*p = s
+	if s == nil || r.seen[s] {
+		return
+	}
+	r.seen[s] = true
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil && r.err == nil {
+			r.err = fmt.Errorf("openapi: invalid schema pattern %q: %w", s.Pattern, err)
+		}
+		s.re = re
+	}
+	r.schema(&s.Items)
+	r.schema(&s.AdditionalProperties)
+	for name, prop := range s.Properties {
+		r.schema(&prop)
+		s.Properties[name] = prop
+	}
+	for _, list := range [][]*Schema{s.AllOf, s.AnyOf, s.OneOf} {
+		for i := range list {
+			r.schema(&list[i])
+		}
+	}
+}
+
+// parameters resolves the parameters in params.
+func (r *resolver) parameters(params []*Parameter) {
+	for i, p := range params {
+		if p.Ref != "" {
+			name, ok := r.lookup(p.Ref, "parameters")
+			target := r.doc.Components.Parameters[name]
+			if !ok || target == nil {
+				r.missing(p.Ref)
+				params[i] = &Parameter{}
+				continue
+			}
+			params[i] = target
+		}
+		r.schema(&params[i].Schema)
+	}
+}
+
+// requestBody resolves *p.
+func (r *resolver) requestBody(p **RequestBody) {
+	if b := *p; b != nil && b.Ref != "" {
+		name, ok := r.lookup(b.Ref, "requestBodies")
+		if *p = r.doc.Components.RequestBodies[name]; !ok || *p == nil {
+			r.missing(b.Ref)
+		}
+		return
+	}
+	if *p != nil {
+		r.content((*p).Content)
+	}
+}
+
+// response resolves *p.
+func (r *resolver) response(p **Response) {
+	if resp := *p; resp != nil && resp.Ref != "" {
+		name, ok := r.lookup(resp.Ref, "responses")
+		if *p = r.doc.Components.Responses[name]; !ok || *p == nil {
+			r.missing(resp.Ref)
+		}
+		return
+	}
+	if *p != nil {
+		r.content((*p).Content)
+	}
+}
+
+// content resolves the schemas and examples of content.
+func (r *resolver) content(content map[string]*MediaType) {
+	for _, mt := range content {
+		if mt == nil {
+			continue
+		}
+		r.schema(&mt.Schema)
+		for name, e := range mt.Examples {
+			if e == nil || e.Ref == "" {
+				continue
+			}
+			key, ok := r.lookup(e.Ref, "examples")
+			if mt.Examples[name] = r.doc.Components.Examples[key]; !ok || mt.Examples[name] == nil {
+				r.missing(e.Ref)
+			}
+		}
+	}
+}
+
+// Find returns the operation documented for method on path, a request
+// path relative to the API's base path, and the values of its path
+// parameters. It returns a nil Operation if none matches.
+//
+// Example:
+//
+//	op, params := doc.Find("GET", "/users/42") // op.OperationID == "getUser", params["id"] == "42"
+func (d *Document) Find(method, path string) (*Operation, map[string]string) {
+	r, _ := d.find(method, path)
+	if r == nil {
+		return nil, nil
+	}
+	return r.op, r.params(path)
+}
+
+// find returns the route for method on path, and whether any route
+// matches path regardless of the method.
+func (d *Document) find(method, path string) (*route, bool) {
+	pathFound := false
+	for _, r := range d.routes {
+		if !r.re.MatchString(path) {
+			continue
+		}
+		if r.method == method {
+			return r, true
+		}
+		pathFound = true
+	}
+	return nil, pathFound
+}
+
+// params returns the path parameter values of path.
+func (r *route) params(path string) map[string]string {
+	m := r.re.FindStringSubmatch(path)
+	params := make(map[string]string, len(r.names))
+	for i, name := range r.names {
+		params[name] = m[i+1]
+	}
+	return params
+}
+
+// FieldError is one violation of the specification.
+type FieldError struct {
+	// In is where the violation was found: "path", "query", "header" or
+	// "cookie" for parameters, "body" for the request body and "response"
+	// for the response.
+	In string `json:"in"`
+
+	// Name is the name of the offending parameter or header.
+	Name string `json:"name,omitempty"`
+
+	// Pointer is the JSON pointer of the offending value within a body,
+	// e.g. "/items/0/price".
+	Pointer string `json:"pointer,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// String returns a description of the violation, e.g.
+// `query parameter "limit": must be at most 100`.
+func (e FieldError) String() string {
+	where := e.In
+	if e.Name != "" {
+		where += " parameter " + strconv.Quote(e.Name)
+	}
+	if e.Pointer != "" {
+		where += " " + e.Pointer
+	}
+	return strings.TrimSpace(where) + ": " + e.Message
+}
+
+// ValidationError reports the violations of a request or response. It is
+// a zeno.HTTPError and marshals to the JSON sent to clients:
+//
+//	{"status": 400, "message": "request does not match the API specification",
+//	 "errors": [{"in": "query", "name": "limit", "message": "must be at most 100"}]}
+type ValidationError struct {
+	Status  int          `json:"status"`
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors"`
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		parts[i] = fe.String()
+	}
+	return e.Message + ": " + strings.Join(parts, "; ")
+}
+
+// StatusCode implements zeno.HTTPError.
+func (e *ValidationError) StatusCode() int { return e.Status }
+
+// Config configures the Validator middleware.
+type Config struct {
+	// BasePath is removed from request paths before they are matched
+	// against the document's paths, e.g. "/api/v1" when the API is served
+	// under that prefix. Requests outside it are not validated.
+	BasePath string
+
+	// RejectUnknown answers requests for operations the document does not
+	// describe with 404 Not Found or 405 Method Not Allowed. By default
+	// they pass through unvalidated.
+	RejectUnknown bool
+
+	// ValidateResponses also checks that the status code, Content-Type and
+	// body of each response are documented for the operation. Streamed
+	// response bodies are not checked.
+	ValidateResponses bool
+
+	// ErrorHandler answers requests that fail validation. By default err
+	// is sent as JSON with its status, 400 Bad Request, or 415 Unsupported
+	// Media Type for an undocumented Content-Type.
+	ErrorHandler func(c *zeno.Context, err *ValidationError) error
+
+	// OnResponseError is called with the violations of a response, e.g. to
+	// log them in production while the response is sent unchanged. By
+	// default the error, whose status is 500, is returned to the error
+	// handler, replacing the response.
+	OnResponseError func(c *zeno.Context, err *ValidationError) error
+
+	// Skipper, when set, lets requests for which it returns true bypass
+	// validation.
+	Skipper zeno.Skipper
+}
+
+// Validator returns a middleware that validates requests against doc:
+// required parameters must be present and every parameter must match its
+// schema, the Content-Type must be one the operation accepts and JSON and
+// URL-encoded form bodies must match the schema of their media type. The
+// violations are sent as a ValidationError.
+//
+// Example:
+//
+//	doc, err := openapi.LoadFile("openapi.yaml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	api := app.Group("/api/v1", openapi.Validator(doc, openapi.Config{
+//	    BasePath:          "/api/v1",
+//	    ValidateResponses: true,
+//	}))
+func Validator(doc *Document, config ...Config) zeno.Handler {
+	var cfg Config
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = func(c *zeno.Context, err *ValidationError) error {
+			return c.Status(err.Status).SendJSON(err)
+		}
+	}
+	return func(c *zeno.Context) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		path, ok := strings.CutPrefix(c.Path(), strings.TrimSuffix(cfg.BasePath, "/"))
+		if !ok {
+			return c.Next()
+		}
+		if path == "" {
+			path = "/"
+		}
+		r, pathFound := doc.find(c.Method(), path)
+		if r == nil {
+			switch {
+			case !cfg.RejectUnknown:
+				return c.Next()
+			case pathFound:
+				return zeno.ErrMethodNotAllowed
+			}
+			return zeno.ErrNotFound
+		}
+
+		if err := validateRequest(c, r.op, r.params(path)); err != nil {
+			c.Abort()
+			return cfg.ErrorHandler(c, err)
+		}
+		if err := c.Next(); err != nil || !cfg.ValidateResponses {
+			return err
+		}
+		if err := validateResponse(c, r.op); err != nil {
+			if cfg.OnResponseError != nil {
+				return cfg.OnResponseError(c, err)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// validateRequest checks the parameters and body of the request.
+func validateRequest(c *zeno.Context, op *Operation, pathParams map[string]string) *ValidationError {
+	var errs []FieldError
+	for _, p := range op.params {
+		errs = append(errs, p.check(c, pathParams)...)
+	}
+
+	status := zeno.StatusBadRequest
+	if b := op.RequestBody; b != nil {
+		body := c.Body()
+		ct, _ := c.ContentType()
+		switch mt, ok := lookupMediaType(b.Content, ct); {
+		case len(body) == 0:
+			if b.Required {
+				errs = append(errs, FieldError{In: "body", Message: "is required"})
+			}
+		case !ok:
+			status = zeno.StatusUnsupportedMediaType
+			errs = append(errs, FieldError{In: "header", Name: zeno.HeaderContentType,
+				Message: "must be one of " + strings.Join(mediaTypes(b.Content), ", ")})
+		case mt != nil && mt.Schema != nil:
+			errs = append(errs, checkBody("body", ct, body, mt.Schema)...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Status: status, Message: "request does not match the API specification", Errors: errs}
+}
+
+// validateResponse checks the status, Content-Type and body of the
+// response.
+func validateResponse(c *zeno.Context, op *Operation) *ValidationError {
+	status := c.StatusCode()
+	resp := lookupResponse(op.Responses, status)
+	var errs []FieldError
+	if resp == nil {
+		errs = append(errs, FieldError{In: "response", Message: "status " + strconv.Itoa(status) + " is not documented"})
+	} else if body := c.ResponseBody(); len(body) > 0 && len(resp.Content) > 0 {
+		ct, _, _ := strings.Cut(c.ResponseHeader(zeno.HeaderContentType), ";")
+		ct = strings.ToLower(strings.TrimSpace(ct))
+		switch mt, ok := lookupMediaType(resp.Content, ct); {
+		case !ok:
+			errs = append(errs, FieldError{In: "response", Name: zeno.HeaderContentType,
+				Message: "must be one of " + strings.Join(mediaTypes(resp.Content), ", ")})
+		case mt != nil && mt.Schema != nil:
+			errs = append(errs, checkBody("response", ct, body, mt.Schema)...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Status: zeno.StatusInternalServerError, Message: "response does not match the API specification", Errors: errs}
+}
+
+// lookupResponse returns the response documented for status: an exact
+// match, then a range such as "2XX", then "default".
+func lookupResponse(responses map[string]*Response, status int) *Response {
+	code := strconv.Itoa(status)
+	if r := responses[code]; r != nil {
+		return r
+	}
+	for k, r := range responses {
+		if len(k) == 3 && k[0] == code[0] && strings.EqualFold(k[1:], "XX") {
+			return r
+		}
+	}
+	return responses["default"]
+}
+
+// lookupMediaType returns the entry of content for the media type ct,
+// trying an exact match, then ranges such as "image/*" and "*/*".
+func lookupMediaType(content map[string]*MediaType, ct string) (*MediaType, bool) {
+	if ct == "" {
+		return nil, false
+	}
+	major, _, _ := strings.Cut(ct, "/")
+	var wildcard, all *MediaType
+	wildcardFound, allFound := false, false
+	for k, mt := range content {
+		k, _, _ = strings.Cut(k, ";")
+		switch k = strings.ToLower(strings.TrimSpace(k)); k {
+		case ct:
+			return mt, true
+		case major + "/*":
+			wildcard, wildcardFound = mt, true
+		case "*/*":
+			all, allFound = mt, true
+		}
+	}
+	if wildcardFound {
+		return wildcard, true
+	}
+	return all, allFound
+}
+
+// mediaTypes returns the sorted media types of content.
+func mediaTypes(content map[string]*MediaType) []string {
+	types := make([]string, 0, len(content))
+	for k := range content {
+		types = append(types, k)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// checkBody validates a JSON or URL-encoded form body against schema.
+// Bodies in other media types are not inspected.
+func checkBody(in, ct string, body []byte, schema *Schema) []FieldError {
+	var v any
+	switch {
+	case ct == "application/json" || strings.HasSuffix(ct, "+json"):
+		if err := json.Unmarshal(body, &v); err != nil {
+			return []FieldError{{In: in, Message: "is not valid JSON: " + err.Error()}}
+		}
+	case ct == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return []FieldError{{In: in, Message: "is not a valid form: " + err.Error()}}
+		}
+		v = formObject(values, schema)
+	default:
+		return nil
+	}
+	errs := schema.Validate(v)
+	for i := range errs {
+		errs[i].In = in
+	}
+	return errs
+}
+
+// formObject converts form values into an object, converting each field
+// according to its property schema.
+func formObject(values url.Values, schema *Schema) map[string]any {
+	obj := make(map[string]any, len(values))
+	for k, vs := range values {
+		var prop *Schema
+		if schema != nil {
+			prop = schema.Properties[k]
+		}
+		obj[k] = parseValue(vs, prop)
+	}
+	return obj
+}
+
+// check validates the parameter p of the request.
+func (p *Parameter) check(c *zeno.Context, pathParams map[string]string) []FieldError {
+	var raw []string
+	switch p.In {
+	case "path":
+		if v, ok := pathParams[p.Name]; ok {
+			raw = []string{v}
+		}
+	case "query":
+		for _, v := range c.RequestCtx().QueryArgs().PeekMulti(p.Name) {
+			raw = append(raw, string(v))
+		}
+	case "header":
+		if v := c.Request().Header.Peek(p.Name); v != nil {
+			raw = []string{string(v)}
+		}
+	case "cookie":
+		if v := c.Request().Header.Cookie(p.Name); v != nil {
+			raw = []string{string(v)}
+		}
+	}
+	if raw == nil {
+		if p.Required || p.In == "path" {
+			return []FieldError{{In: p.In, Name: p.Name, Message: "is required"}}
+		}
+		return nil
+	}
+
+	// Query and cookie parameters default to the exploded form style,
+	// repeating the name for each array item; otherwise items are
+	// separated by commas.
+	explode := p.In == "query" || p.In == "cookie"
+	if p.Explode != nil {
+		explode = *p.Explode
+	}
+	if len(raw) == 1 && p.Schema != nil && slices.Contains(p.Schema.Type, "array") &&
+		(!explode || p.In == "path" || p.In == "header") {
+		raw = strings.Split(raw[0], ",")
+	}
+
+	errs := p.Schema.Validate(parseValue(raw, p.Schema))
+	for i := range errs {
+		errs[i].In, errs[i].Name = p.In, p.Name
+	}
+	return errs
+}
+
+// parseValue converts the textual values of a parameter or form field
+// into the JSON value its schema describes: an array of all values for
+// array schemas, otherwise the first value, as a number or boolean when
+// the schema allows one and the value parses as such. Values that do not
+// parse stay strings, so validation reports the type mismatch.
+func parseValue(raw []string, schema *Schema) any {
+	if schema == nil {
+		return raw[0]
+	}
+	if slices.Contains(schema.Type, "array") {
+		items := make([]any, len(raw))
+		for i, s := range raw {
+			items[i] = parseValue([]string{s}, schema.Items)
+		}
+		return items
+	}
+	s := raw[0]
+	for _, t := range schema.Type {
+		switch t {
+		case "integer", "number":
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b
+			}
+		}
+	}
+	return s
+}
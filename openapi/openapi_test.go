@@ -0,0 +1,305 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/Abhishek2010dev/zeno/zenotest"
+	"github.com/stretchr/testify/assert"
+)
+
+const spec = `
+openapi: 3.0.3
+info:
+  title: Users
+  version: "1.0"
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      parameters:
+        - $ref: "#/components/parameters/Limit"
+        - name: tag
+          in: query
+          schema:
+            type: array
+            items: {type: string, enum: [admin, staff]}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: "#/components/schemas/User"}
+    post:
+      operationId: createUser
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/User"}
+          application/x-www-form-urlencoded:
+            schema: {$ref: "#/components/schemas/User"}
+      responses:
+        201:
+          description: created
+  /users/me:
+    get:
+      operationId: me
+      responses:
+        default: {description: ok}
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: integer, minimum: 1}
+    get:
+      operationId: getUser
+      parameters:
+        - name: X-Tenant
+          in: header
+          required: true
+          schema: {type: string, format: uuid}
+      responses:
+        2XX: {description: ok}
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema: {type: integer, minimum: 1, maximum: 100}
+  schemas:
+    User:
+      type: object
+      required: [name, email]
+      additionalProperties: false
+      properties:
+        name: {type: string, minLength: 1, maxLength: 20}
+        email: {type: string, format: email}
+        age: {type: integer, nullable: true, minimum: 0}
+        roles:
+          type: array
+          maxItems: 2
+          items: {type: string, pattern: "^[a-z]+$"}
+`
+
+func loadSpec(t *testing.T) *Document {
+	t.Helper()
+	doc, err := Load([]byte(spec))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return doc
+}
+
+func newApp(doc *Document, config ...Config) *zeno.Zeno {
+	z := zeno.New()
+	z.Use(Validator(doc, config...))
+	z.Get("/users", func(c *zeno.Context) error {
+		return c.SendJSON([]map[string]any{{"name": "ann", "email": "ann@example.com"}})
+	})
+	z.Post("/users", func(c *zeno.Context) error { return c.Status(zeno.StatusCreated).SendString("created") })
+	z.Get("/users/me", func(c *zeno.Context) error { return c.SendString("me") })
+	z.Get("/users/{id}", func(c *zeno.Context) error { return c.SendString("user " + c.Param("id")) })
+	z.Get("/other", func(c *zeno.Context) error { return c.SendString("other") })
+	return z
+}
+
+func decodeErrors(t *testing.T, body []byte) []string {
+	t.Helper()
+	var verr ValidationError
+	assert.NoError(t, json.Unmarshal(body, &verr))
+	var out []string
+	for _, e := range verr.Errors {
+		out = append(out, e.String())
+	}
+	return out
+}
+
+func TestLoad(t *testing.T) {
+	doc := loadSpec(t)
+	assert.Equal(t, "Users", doc.Info.Title)
+
+	op, params := doc.Find(zeno.MethodGet, "/users/42")
+	assert.Equal(t, "getUser", op.OperationID)
+	assert.Equal(t, map[string]string{"id": "42"}, params)
+
+	op, _ = doc.Find(zeno.MethodGet, "/users/me")
+	assert.Equal(t, "me", op.OperationID, "concrete paths take precedence")
+
+	op, _ = doc.Find(zeno.MethodHead, "/users")
+	assert.Equal(t, "listUsers", op.OperationID, "HEAD falls back to GET")
+
+	op, _ = doc.Find(zeno.MethodDelete, "/users")
+	assert.Nil(t, op)
+
+	jsonDoc, err := Load([]byte(`{"openapi":"3.1.0","paths":{"/a":{"get":{"responses":{}}}}}`))
+	assert.NoError(t, err)
+	op, _ = jsonDoc.Find(zeno.MethodGet, "/a")
+	assert.NotNil(t, op)
+
+	_, err = Load([]byte(`{"paths":{"/a":{"get":{"requestBody":{"$ref":"#/components/requestBodies/Missing"}}}}}`))
+	assert.ErrorContains(t, err, "unresolved reference")
+	_, err = Load([]byte(`{"components":{"schemas":{"A":{"type":"string","pattern":"("}}}}`))
+	assert.ErrorContains(t, err, "invalid schema pattern")
+	_, err = Load([]byte(`{"paths":{"/a/{id":{}}}`))
+	assert.ErrorContains(t, err, "unclosed parameter")
+}
+
+func TestSchema_Validate(t *testing.T) {
+	doc := loadSpec(t)
+	user := doc.Components.Schemas["User"]
+
+	var v any
+	assert.NoError(t, json.Unmarshal([]byte(`{"name":"ann","email":"ann@example.com","age":null,"roles":["dev"]}`), &v))
+	assert.Empty(t, user.Validate(v))
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"name":"","age":1.5,"roles":["a","B","c"],"extra":1}`), &v))
+	var got []string
+	for _, e := range user.Validate(v) {
+		got = append(got, e.String())
+	}
+	assert.ElementsMatch(t, []string{
+		"/email: is required",
+		"/name: must be at least 1 characters long",
+		"/age: must be of type integer",
+		"/roles: must have at most 2 items",
+		"/roles/1: must match the pattern ^[a-z]+$",
+		"/extra: is not allowed",
+	}, got)
+
+	var s Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":["string","null"],"anyOf":[{"type":"null"},{"minLength":2}]}`), &s))
+	assert.Empty(t, s.Validate(nil))
+	assert.Empty(t, s.Validate("ab"))
+	assert.Equal(t, "does not match any of the allowed schemas", s.Validate("a")[0].Message)
+	assert.Equal(t, "must be of type string or null", s.Validate(true)[0].Message)
+
+	var one Schema
+	assert.NoError(t, json.Unmarshal([]byte(`{"oneOf":[{"maxLength":3},{"minLength":2}]}`), &one))
+	assert.Empty(t, one.Validate("a"))
+	assert.Equal(t, "must match exactly one schema, matches 2", one.Validate("ab")[0].Message)
+	assert.Equal(t, "is not allowed", (&Schema{never: true}).Validate(1.0)[0].Message)
+}
+
+func TestValidator_Parameters(t *testing.T) {
+	tc := zenotest.New(newApp(loadSpec(t)))
+	defer tc.Close()
+
+	tc.Get("/users").Query("limit", "10").Query("tag", "admin").Do().ExpectStatus(t, zeno.StatusOK)
+
+	resp := tc.Get("/users").Query("limit", "500").Query("tag", "guest").Do().
+		ExpectStatus(t, zeno.StatusBadRequest).
+		ExpectHeader(t, zeno.HeaderContentType, "application/json; charset=utf-8")
+	assert.Equal(t, []string{
+		`query parameter "limit": must be at most 100`,
+		`query parameter "tag" /0: must be one of "admin", "staff"`,
+	}, decodeErrors(t, resp.Body))
+
+	resp = tc.Get("/users").Query("limit", "ten").Do().ExpectStatus(t, zeno.StatusBadRequest)
+	assert.Equal(t, []string{`query parameter "limit": must be of type integer`}, decodeErrors(t, resp.Body))
+
+	tc.Get("/users/7").Header("X-Tenant", "6f1c2b1e-55a8-4b0e-9d43-3f1b8a3c2d10").Do().
+		ExpectStatus(t, zeno.StatusOK).ExpectBody(t, "user 7")
+	resp = tc.Get("/users/0").Do().ExpectStatus(t, zeno.StatusBadRequest)
+	assert.Equal(t, []string{
+		`path parameter "id": must be at least 1`,
+		`header parameter "X-Tenant": is required`,
+	}, decodeErrors(t, resp.Body))
+
+	tc.Get("/users/me").Do().ExpectStatus(t, zeno.StatusOK).ExpectBody(t, "me")
+	tc.Get("/other").Do().ExpectStatus(t, zeno.StatusOK)
+}
+
+func TestValidator_Body(t *testing.T) {
+	tc := zenotest.New(newApp(loadSpec(t)))
+	defer tc.Close()
+
+	tc.Post("/users").JSON(map[string]any{"name": "ann", "email": "ann@example.com"}).Do().
+		ExpectStatus(t, zeno.StatusCreated)
+	tc.Post("/users").Body("application/x-www-form-urlencoded", []byte("name=ann&email=ann%40example.com&age=30")).Do().
+		ExpectStatus(t, zeno.StatusCreated)
+
+	resp := tc.Post("/users").JSON(map[string]any{"name": "ann", "email": "not an email"}).Do().
+		ExpectStatus(t, zeno.StatusBadRequest)
+	assert.Equal(t, []string{"body /email: must be a valid email"}, decodeErrors(t, resp.Body))
+
+	resp = tc.Post("/users").Body("application/json", []byte(`{"name":`)).Do().ExpectStatus(t, zeno.StatusBadRequest)
+	assert.True(t, strings.HasPrefix(decodeErrors(t, resp.Body)[0], "body: is not valid JSON"))
+
+	resp = tc.Post("/users").Do().ExpectStatus(t, zeno.StatusBadRequest)
+	assert.Equal(t, []string{"body: is required"}, decodeErrors(t, resp.Body))
+
+	resp = tc.Post("/users").Body("text/plain", []byte("ann")).Do().ExpectStatus(t, zeno.StatusUnsupportedMediaType)
+	assert.Equal(t, []string{`header parameter "Content-Type": must be one of application/json, application/x-www-form-urlencoded`},
+		decodeErrors(t, resp.Body))
+}
+
+func TestValidator_Config(t *testing.T) {
+	doc := loadSpec(t)
+
+	z := zeno.New()
+	z.Use(Validator(doc, Config{
+		BasePath:      "/api",
+		RejectUnknown: true,
+		ErrorHandler: func(c *zeno.Context, err *ValidationError) error {
+			return c.Status(zeno.StatusUnprocessableEntity).SendString(err.Error())
+		},
+	}))
+	z.Get("/api/users", func(c *zeno.Context) error { return c.SendString("users") })
+	z.Get("/api/unknown", func(c *zeno.Context) error { return c.SendString("unknown") })
+	z.Get("/outside", func(c *zeno.Context) error { return c.SendString("outside") })
+	tc := zenotest.New(z)
+	defer tc.Close()
+
+	tc.Get("/api/users").Do().ExpectStatus(t, zeno.StatusOK)
+	tc.Get("/api/users").Query("limit", "0").Do().
+		ExpectStatus(t, zeno.StatusUnprocessableEntity).
+		ExpectBody(t, `request does not match the API specification: query parameter "limit": must be at least 1`)
+	tc.Get("/api/unknown").Do().ExpectStatus(t, zeno.StatusNotFound)
+	tc.Request(zeno.MethodDelete, "/api/users").Do().ExpectStatus(t, zeno.StatusMethodNotAllowed)
+	tc.Get("/outside").Do().ExpectStatus(t, zeno.StatusOK)
+}
+
+func TestValidator_Responses(t *testing.T) {
+	doc := loadSpec(t)
+
+	z := zeno.New()
+	z.Use(Validator(doc, Config{ValidateResponses: true}))
+	z.Get("/users", func(c *zeno.Context) error {
+		if c.Query("bad") != "" {
+			return c.SendJSON([]map[string]any{{"name": "ann"}})
+		}
+		return c.SendJSON([]map[string]any{{"name": "ann", "email": "ann@example.com"}})
+	})
+	z.Post("/users", func(c *zeno.Context) error { return c.Status(zeno.StatusTeapot).SendString("teapot") })
+	tc := zenotest.New(z)
+	defer tc.Close()
+
+	tc.Get("/users").Do().ExpectStatus(t, zeno.StatusOK)
+	tc.Get("/users").Query("bad", "1").Do().
+		ExpectStatus(t, zeno.StatusInternalServerError).
+		ExpectBody(t, "response does not match the API specification: response /0/email: is required")
+	tc.Post("/users").JSON(map[string]any{"name": "ann", "email": "ann@example.com"}).Do().
+		ExpectStatus(t, zeno.StatusInternalServerError).
+		ExpectBody(t, "response does not match the API specification: response: status 418 is not documented")
+
+	var reported *ValidationError
+	z2 := zeno.New()
+	z2.Use(Validator(doc, Config{
+		ValidateResponses: true,
+		OnResponseError:   func(c *zeno.Context, err *ValidationError) error { reported = err; return nil },
+	}))
+	z2.Post("/users", func(c *zeno.Context) error { return c.Status(zeno.StatusTeapot).SendString("teapot") })
+	tc2 := zenotest.New(z2)
+	defer tc2.Close()
+	tc2.Post("/users").JSON(map[string]any{"name": "ann", "email": "ann@example.com"}).Do().
+		ExpectStatus(t, zeno.StatusTeapot).ExpectBody(t, "teapot")
+	if assert.NotNil(t, reported) {
+		assert.Equal(t, zeno.StatusInternalServerError, reported.StatusCode())
+	}
+}
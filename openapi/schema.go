@@ -0,0 +1,272 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Schema is the subset of JSON Schema used by OpenAPI that the validator
+// understands. Keywords not listed here are accepted in documents and
+// ignored.
+type Schema struct {
+	Ref      string `json:"$ref,omitempty"`
+	Type     Types  `json:"type,omitempty"`
+	Format   string `json:"format,omitempty"`
+	Nullable bool   `json:"nullable,omitempty"` // OpenAPI 3.0; 3.1 lists "null" in Type
+	Enum     []any  `json:"enum,omitempty"`
+	Default  any    `json:"default,omitempty"`
+	Example  any    `json:"example,omitempty"`
+
+	// Strings.
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+
+	// Numbers.
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	// Arrays.
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	// Objects. AdditionalProperties may be given as false in documents to
+	// reject properties not listed in Properties.
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	// Composition.
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+
+	never bool           // the schema false, matching no value
+	re    *regexp.Regexp // compiled Pattern
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting the boolean
+// schemas true, which matches any value, and false, which matches none.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{never: true}
+		return nil
+	}
+	type plain Schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// Types is the type keyword of a schema, given in documents either as a
+// single type name or, as in OpenAPI 3.1, a list of them.
+type Types []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = Types{name}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// Validate checks v, a value decoded from JSON with encoding/json, against
+// s and returns one FieldError per violation, with Pointer set to the JSON
+// pointer of the offending value. A nil Schema accepts every value.
+//
+// Example:
+//
+//	var v any
+//	_ = json.Unmarshal(body, &v)
+//	for _, e := range doc.Components.Schemas["User"].Validate(v) {
+//	    log.Printf("%s: %s", e.Pointer, e.Message)
+//	}
+func (s *Schema) Validate(v any) []FieldError {
+	var errs []FieldError
+	s.validate(v, "", &errs)
+	return errs
+}
+
+// validate appends the violations of v found at pointer to errs.
+func (s *Schema) validate(v any, pointer string, errs *[]FieldError) {
+	if s == nil {
+		return
+	}
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, FieldError{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+	if s.never {
+		fail("is not allowed")
+		return
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(v, pointer, errs)
+	}
+	if len(s.AnyOf) > 0 && s.matching(s.AnyOf, v) == 0 {
+		fail("does not match any of the allowed schemas")
+	}
+	if len(s.OneOf) > 0 {
+		if n := s.matching(s.OneOf, v); n != 1 {
+			fail("must match exactly one schema, matches %d", n)
+		}
+	}
+
+	if v == nil {
+		if len(s.Type) > 0 && !s.Nullable && !slices.Contains(s.Type, "null") {
+			fail("must not be null")
+		}
+		return
+	}
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return hasType(v, t) }) {
+		fail("must be of type %s", strings.Join(s.Type, " or "))
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+		fail("must be one of %s", enumList(s.Enum))
+	}
+
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("must be at least %d characters long", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("must be at most %d characters long", *s.MaxLength)
+		}
+		if s.re != nil && !s.re.MatchString(v) {
+			fail("must match the pattern %s", s.Pattern)
+		}
+		if !validFormat(s.Format, v) {
+			fail("must be a valid %s", s.Format)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("must be at least %s", formatNumber(*s.Minimum))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("must be at most %s", formatNumber(*s.Maximum))
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		for i, item := range v {
+			s.Items.validate(item, pointer+"/"+strconv.Itoa(i), errs)
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, FieldError{Pointer: pointer + "/" + escapePointer(name), Message: "is required"})
+			}
+		}
+		for name, value := range v {
+			child := pointer + "/" + escapePointer(name)
+			if p, ok := s.Properties[name]; ok {
+				p.validate(value, child, errs)
+			} else {
+				s.AdditionalProperties.validate(value, child, errs)
+			}
+		}
+	}
+}
+
+// matching returns the number of schemas v is valid against.
+func (s *Schema) matching(schemas []*Schema, v any) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(sub.Validate(v)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// hasType reports whether the decoded JSON value v is of the JSON Schema
+// type t.
+func hasType(v any, t string) bool {
+	switch t {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f) && !math.IsInf(f, 0)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "null":
+		return v == nil
+	}
+	return false
+}
+
+// validFormat reports whether s is valid in the given format. Unknown
+// formats are not checked, as JSON Schema treats them as annotations.
+func validFormat(format, s string) bool {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, s)
+	case "date":
+		_, err = time.Parse(time.DateOnly, s)
+	case "email":
+		var addr *mail.Address
+		if addr, err = mail.ParseAddress(s); err == nil && addr.Address != s {
+			return false
+		}
+	case "uuid":
+		return uuidPattern.MatchString(s)
+	}
+	return err == nil
+}
+
+// uuidPattern matches a UUID in its canonical textual form.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// enumList formats the values of an enum for error messages.
+func enumList(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		b, _ := json.Marshal(v)
+		parts[i] = string(b)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatNumber formats a schema bound without a spurious fraction.
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// escapePointer escapes a property name for use in a JSON pointer.
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,60 @@
+package zeno
+
+import (
+	"slices"
+	"strings"
+)
+
+// MethodOverrideConfig configures the MethodOverride middleware.
+type MethodOverrideConfig struct {
+	// Methods lists the methods a POST request may be overridden to.
+	// Defaults to PUT, PATCH and DELETE; other methods, such as CONNECT
+	// or TRACE, are ignored and the request is handled as a POST.
+	Methods []string
+}
+
+// MethodOverride returns a middleware that lets clients limited to GET and
+// POST, such as HTML forms, issue other methods. For POST requests the
+// method is taken from the X-HTTP-Method-Override header or, failing that,
+// the "_method" form field, upper-cased, and when it is allowed by the
+// config the request is re-dispatched to the route registered for it (see
+// Context.ReRoute).
+//
+// Because routing happens before middleware runs, MethodOverride must be
+// registered with Zeno.Use so that it also wraps the not-found chain.
+//
+// Example:
+//
+//	app.Use(zeno.MethodOverride())
+//
+//	// <form method="POST" action="/posts/1">
+//	//   <input type="hidden" name="_method" value="DELETE">
+//	app.Delete("/posts/{id}", deletePost)
+func MethodOverride(config ...MethodOverrideConfig) Handler {
+	var cfg MethodOverrideConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	allowed := []string{MethodPut, MethodPatch, MethodDelete}
+	if cfg.Methods != nil {
+		allowed = make([]string, len(cfg.Methods))
+		for i, m := range cfg.Methods {
+			allowed[i] = strings.ToUpper(m)
+		}
+	}
+
+	return func(c *Context) error {
+		if c.Method() != MethodPost {
+			return c.Next()
+		}
+		method := c.GetHeader(HeaderXHTTPMethodOverride)
+		if method == "" {
+			method = c.FormValue("_method")
+		}
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if !slices.Contains(allowed, method) {
+			return c.Next()
+		}
+		return c.ReRoute(method, strings.Clone(c.Path()))
+	}
+}
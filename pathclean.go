@@ -0,0 +1,146 @@
+package zeno
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/valyala/fasthttp"
+)
+
+// PathCleaningConfig configures how request paths are cleaned before
+// routing. By default routes are matched against fasthttp's normalized
+// path, which is percent-decoded with duplicate slashes collapsed and dot
+// segments resolved, and requests whose path contains an encoded NUL byte
+// are rejected with 400 Bad Request. Route parameter values are always
+// percent-decoded, so Param("name") for /users/J%C3%BCrgen returns
+// "Jürgen", unless EncodedParams is set.
+type PathCleaningConfig struct {
+	// Raw matches routes against the path exactly as sent by the client,
+	// still percent-encoded and with duplicate slashes and dot segments
+	// intact. Parameter values are decoded after matching. Context.Path
+	// keeps returning the normalized path.
+	Raw bool
+
+	// PreserveEncodedSlash keeps "%2F" from acting as a path separator, so
+	// "/files/a%2Fb" matches "/files/{name}" with name "a/b" instead of
+	// "/files/{dir}/{name}". The path is otherwise cleaned as by default.
+	PreserveEncodedSlash bool
+
+	// AllowNUL accepts paths containing an encoded NUL byte ("%00").
+	AllowNUL bool
+
+	// EncodedParams leaves route parameter values percent-encoded as they
+	// appear in the matched path. It only has an effect together with Raw
+	// or PreserveEncodedSlash, since the default path is already decoded.
+	EncodedParams bool
+}
+
+// errInvalidPath is returned for request paths rejected by path cleaning.
+var errInvalidPath = NewHTTPError(StatusBadRequest, "invalid request path")
+
+// invalidPathHandler ends the chain of requests with a rejected path.
+func invalidPathHandler(*Context) error {
+	return errInvalidPath
+}
+
+// routingPath returns the path ctx is routed by according to
+// z.PathCleaning, or false if the path must be rejected.
+func (z *Zeno) routingPath(ctx *fasthttp.RequestCtx) ([]byte, bool) {
+	cfg := z.PathCleaning
+	switch {
+	case cfg.Raw:
+		return ctx.URI().PathOriginal(), true
+	case cfg.PreserveEncodedSlash:
+		return cleanPath(ctx.URI().PathOriginal(), cfg.AllowNUL)
+	}
+	path := ctx.Path()
+	if !cfg.AllowNUL && bytes.IndexByte(path, 0) >= 0 {
+		return nil, false
+	}
+	return path, true
+}
+
+// cleanPath percent-decodes raw, collapses runs of slashes and resolves
+// "." and ".." segments. Encoded slashes and percent signs stay encoded so
+// they neither split segments nor get decoded twice. It reports false for
+// malformed escapes and, unless allowNUL is set, encoded NUL bytes.
+func cleanPath(raw []byte, allowNUL bool) ([]byte, bool) {
+	out := make([]byte, 0, len(raw)+1)
+	trailing := len(raw) > 0 && raw[len(raw)-1] == '/'
+	dot := false
+	for len(raw) > 0 {
+		var seg []byte
+		if i := bytes.IndexByte(raw, '/'); i >= 0 {
+			seg, raw = raw[:i], raw[i+1:]
+		} else {
+			seg, raw = raw, nil
+		}
+		if len(seg) == 0 {
+			continue
+		}
+		start := len(out)
+		out = append(out, '/')
+		for i := 0; i < len(seg); i++ {
+			b := seg[i]
+			if b == '%' {
+				if i+2 >= len(seg) || !ishex(seg[i+1]) || !ishex(seg[i+2]) {
+					return nil, false
+				}
+				b = unhex(seg[i+1])<<4 | unhex(seg[i+2])
+				switch {
+				case b == '/' || b == '%':
+					out = append(out, seg[i:i+3]...)
+					i += 2
+					continue
+				case b == 0 && !allowNUL:
+					return nil, false
+				}
+				i += 2
+			}
+			out = append(out, b)
+		}
+		switch dot = true; string(out[start+1:]) {
+		case ".":
+			out = out[:start]
+		case "..":
+			out = out[:start]
+			if i := bytes.LastIndexByte(out, '/'); i >= 0 {
+				out = out[:i]
+			}
+		default:
+			dot = false
+		}
+	}
+	if len(out) == 0 || trailing || dot {
+		out = append(out, '/')
+	}
+	return out, true
+}
+
+// unescapeParams percent-decodes the matched parameter values, which
+// still hold the escapes of a raw path or those cleanPath preserved.
+func (c *Context) unescapeParams() {
+	for i := range c.pnames {
+		if v := c.pvalues[i]; strings.IndexByte(v, '%') >= 0 {
+			if u, err := url.PathUnescape(v); err == nil && utf8.ValidString(u) {
+				c.pvalues[i] = u
+			}
+		}
+	}
+}
+
+func ishex(b byte) bool {
+	return '0' <= b && b <= '9' || 'a' <= b && b <= 'f' || 'A' <= b && b <= 'F'
+}
+
+func unhex(b byte) byte {
+	switch {
+	case b >= 'a':
+		return b - 'a' + 10
+	case b >= 'A':
+		return b - 'A' + 10
+	}
+	return b - '0'
+}
@@ -0,0 +1,77 @@
+package zeno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanPath(t *testing.T) {
+	tests := []struct {
+		raw, want string
+		ok        bool
+	}{
+		{"/", "/", true},
+		{"", "/", true},
+		{"/a//b/", "/a/b/", true},
+		{"/a/./b/../c", "/a/c", true},
+		{"/a/b/..", "/a/", true},
+		{"/../../etc", "/etc", true},
+		{"/a/%2e%2e/b", "/b", true},
+		{"/files/a%2Fb", "/files/a%2Fb", true},
+		{"/caf%C3%A9", "/café", true},
+		{"/100%25", "/100%25", true},
+		{"/x%00y", "", false},
+		{"/bad%zz", "", false},
+		{"/bad%4", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := cleanPath([]byte(tt.raw), false)
+		assert.Equal(t, tt.ok, ok, tt.raw)
+		if ok {
+			assert.Equal(t, tt.want, string(got), tt.raw)
+		}
+	}
+}
+
+func TestZeno_PathCleaning(t *testing.T) {
+	z := New()
+	z.Get("/files/{name}", func(c *Context) error { return c.SendString("file " + c.Param("name")) })
+	z.Get("/files/{dir}/{name}", func(c *Context) error { return c.SendString("dir " + c.Param("dir")) })
+
+	ctx := performRequest(z, "GET", "/files/a%2Fb", nil, nil)
+	assert.Equal(t, "dir a", string(ctx.Response.Body()))
+	ctx = performRequest(z, "GET", "/files/x%00y", nil, nil)
+	assert.Equal(t, StatusBadRequest, ctx.Response.StatusCode())
+
+	z.PathCleaning.PreserveEncodedSlash = true
+	ctx = performRequest(z, "GET", "/files/a%2Fb", nil, nil)
+	assert.Equal(t, "file a/b", string(ctx.Response.Body()))
+	ctx = performRequest(z, "GET", "/files//./x/../a%2Fb", nil, nil)
+	assert.Equal(t, "file a/b", string(ctx.Response.Body()))
+
+	z.PathCleaning = PathCleaningConfig{Raw: true}
+	ctx = performRequest(z, "GET", "/files/a%20b", nil, nil)
+	assert.Equal(t, "file a b", string(ctx.Response.Body()))
+	ctx = performRequest(z, "GET", "/files/../files/a", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+}
+
+func TestZeno_ParamDecoding(t *testing.T) {
+	z := New()
+	z.Get("/users/{name}", func(c *Context) error { return c.SendString(c.Param("name")) })
+
+	for _, cfg := range []PathCleaningConfig{{}, {Raw: true}, {PreserveEncodedSlash: true}} {
+		z.PathCleaning = cfg
+		ctx := performRequest(z, "GET", "/users/J%C3%BCrgen", nil, nil)
+		assert.Equal(t, "Jürgen", string(ctx.Response.Body()))
+	}
+
+	z.PathCleaning = PathCleaningConfig{Raw: true, EncodedParams: true}
+	ctx := performRequest(z, "GET", "/users/J%C3%BCrgen", nil, nil)
+	assert.Equal(t, "J%C3%BCrgen", string(ctx.Response.Body()))
+
+	z.PathCleaning = PathCleaningConfig{Raw: true}
+	ctx = performRequest(z, "GET", "/users/%FF", nil, nil)
+	assert.Equal(t, "%FF", string(ctx.Response.Body()))
+}
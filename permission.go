@@ -0,0 +1,324 @@
+package zeno
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Subject is the authenticated caller whose permissions are checked by
+// the Permission middleware.
+type Subject struct {
+	// ID identifies the caller, e.g. a user ID or a certificate's SAN.
+	ID string `json:"id"`
+
+	// Roles lists the roles granted to the caller.
+	Roles []string `json:"roles,omitempty"`
+
+	// Attributes holds further facts for attribute-based policies, such
+	// as a tenant or department.
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// PermissionRequest is a question put to a Policy: may Subject perform
+// Action on Resource?
+type PermissionRequest struct {
+	Subject *Subject `json:"subject"`
+
+	// Resource is the route's "resource" annotation (see Route.Meta), or
+	// its template, e.g. "/users/{id}".
+	Resource string `json:"resource"`
+
+	// Action is the route's "action" annotation, or the request method.
+	Action string `json:"action"`
+
+	// Tags are the tags of the route (see Route.Tags).
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Policy decides permission requests. Allow is called from request
+// goroutines concurrently; an error fails the request with 500 Internal
+// Server Error rather than granting access.
+type Policy interface {
+	Allow(c *Context, req *PermissionRequest) (bool, error)
+}
+
+// PolicyFunc adapts a function to a Policy.
+type PolicyFunc func(c *Context, req *PermissionRequest) (bool, error)
+
+// Allow calls f(c, req).
+func (f PolicyFunc) Allow(c *Context, req *PermissionRequest) (bool, error) { return f(c, req) }
+
+// PermissionError is the structured error of a denied request. It is an
+// HTTPError, so a custom PermissionConfig.OnDenied may return it to the
+// ErrorHandler.
+type PermissionError struct {
+	Status   int    `json:"status"`
+	Message  string `json:"message"`
+	Subject  string `json:"subject,omitempty"`
+	Action   string `json:"action,omitempty"`
+	Resource string `json:"resource,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *PermissionError) Error() string { return e.Message }
+
+// StatusCode implements HTTPError.
+func (e *PermissionError) StatusCode() int { return e.Status }
+
+// PermissionConfig configures the Permission middleware.
+type PermissionConfig struct {
+	// Policy decides the requests. Required.
+	Policy Policy
+
+	// Subject returns the caller of a request, typically from values set
+	// by an authentication middleware, or nil when it is anonymous.
+	// Anonymous requests are answered with 401 Unauthorized. Required.
+	Subject func(c *Context) *Subject
+
+	// OnDenied, when set, answers denied requests; the chain is already
+	// aborted when it is called. By default the PermissionError is sent
+	// as JSON.
+	OnDenied func(c *Context, err *PermissionError) error
+
+	// Skipper, when set, bypasses the middleware for requests for which
+	// it returns true.
+	Skipper Skipper
+}
+
+// Permission returns a middleware authorizing requests with a policy.
+// Each matched route is described as a resource and an action, by its
+// "resource" and "action" annotations or else its template and the
+// request method, and the policy decides whether the caller may perform
+// it. Denied requests are answered with 403 Forbidden and a JSON body
+// naming the subject, action and resource. Requests matching no route
+// pass through to the not-found handlers; when such a request is
+// re-dispatched to a route, for example by MethodOverride, the middleware
+// runs again and checks the new route.
+//
+// Example:
+//
+//	policy := zeno.NewRolePolicy().
+//	    Grant("admin", "*", "*").
+//	    Grant("editor", "GET", "/articles/*").
+//	    Grant("editor", "publish", "articles")
+//
+//	app.Use(zeno.Permission(zeno.PermissionConfig{
+//	    Policy: policy,
+//	    Subject: func(c *zeno.Context) *zeno.Subject {
+//	        if u := currentUser(c); u != nil {
+//	            return &zeno.Subject{ID: u.ID, Roles: u.Roles}
+//	        }
+//	        return nil
+//	    },
+//	}))
+//	app.Post("/articles/{id}/publish", publish).
+//	    Meta("resource", "articles").Meta("action", "publish")
+func Permission(config PermissionConfig) Handler {
+	if config.Policy == nil {
+		panic("zeno: Permission requires a Policy")
+	}
+	if config.Subject == nil {
+		panic("zeno: Permission requires a Subject")
+	}
+	if config.OnDenied == nil {
+		config.OnDenied = func(c *Context, err *PermissionError) error {
+			c.Status(err.Status)
+			return c.SendJSON(err)
+		}
+	}
+	return func(c *Context) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return c.Next()
+		}
+		r := c.Route()
+		if r == nil {
+			return c.Next()
+		}
+		req := &PermissionRequest{
+			Subject:  config.Subject(c),
+			Resource: r.template,
+			Action:   c.Method(),
+			Tags:     r.TagList(),
+		}
+		if v, ok := r.MetaValue("resource"); ok {
+			req.Resource = fmt.Sprint(v)
+		}
+		if v, ok := r.MetaValue("action"); ok {
+			req.Action = fmt.Sprint(v)
+		}
+		if req.Subject == nil {
+			c.Abort()
+			return config.OnDenied(c, &PermissionError{
+				Status:   StatusUnauthorized,
+				Message:  "authentication required",
+				Action:   req.Action,
+				Resource: req.Resource,
+			})
+		}
+		allowed, err := config.Policy.Allow(c, req)
+		if err != nil {
+			return NewHTTPError(StatusInternalServerError, "permission: "+err.Error())
+		}
+		if !allowed {
+			c.Abort()
+			return config.OnDenied(c, &PermissionError{
+				Status:   StatusForbidden,
+				Message:  "permission denied",
+				Subject:  req.Subject.ID,
+				Action:   req.Action,
+				Resource: req.Resource,
+			})
+		}
+		return c.Next()
+	}
+}
+
+// RolePolicy is a Policy granting actions on resources to roles.
+type RolePolicy struct {
+	mu     sync.RWMutex
+	grants map[string][]grant // by role
+}
+
+// grant is an action pattern and a resource pattern.
+type grant struct {
+	action, resource string
+}
+
+// NewRolePolicy returns a RolePolicy without grants, denying everything.
+func NewRolePolicy() *RolePolicy {
+	return &RolePolicy{grants: make(map[string][]grant)}
+}
+
+// Grant allows role to perform action on resource. The action matches
+// case-insensitively and "*" matches any action. The resource is a
+// pattern in the syntax of path.Match, e.g. "/users/*", where "*" alone
+// matches any resource.
+func (p *RolePolicy) Grant(role, action, resource string) *RolePolicy {
+	if _, err := path.Match(resource, ""); err != nil {
+		panic(fmt.Sprintf("zeno: invalid resource pattern %q", resource))
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.grants[role] = append(p.grants[role], grant{action: action, resource: resource})
+	return p
+}
+
+// Allow implements Policy, allowing requests when any role of the
+// subject has a matching grant.
+func (p *RolePolicy) Allow(_ *Context, req *PermissionRequest) (bool, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, role := range req.Subject.Roles {
+		if slices.ContainsFunc(p.grants[role], func(g grant) bool {
+			return (g.action == "*" || strings.EqualFold(g.action, req.Action)) && matchResource(g.resource, req.Resource)
+		}) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchResource reports whether resource matches pattern.
+func matchResource(pattern, resource string) bool {
+	if pattern == "*" {
+		return true
+	}
+	ok, _ := path.Match(pattern, resource)
+	return ok
+}
+
+// CasbinEnforcer is the method of a Casbin enforcer used by CasbinPolicy;
+// *casbin.Enforcer, *casbin.CachedEnforcer and *casbin.SyncedEnforcer
+// implement it.
+type CasbinEnforcer interface {
+	Enforce(rvals ...any) (bool, error)
+}
+
+// CasbinPolicy returns a Policy enforcing a Casbin model, with the
+// subject ID, resource and action as the request (sub, obj, act). Roles
+// are resolved by the model's role definitions.
+//
+// Example:
+//
+//	e, err := casbin.NewEnforcer("rbac_model.conf", "rbac_policy.csv")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	app.Use(zeno.Permission(zeno.PermissionConfig{
+//	    Policy:  zeno.CasbinPolicy(e),
+//	    Subject: subjectOf,
+//	}))
+func CasbinPolicy(e CasbinEnforcer) Policy {
+	return PolicyFunc(func(_ *Context, req *PermissionRequest) (bool, error) {
+		return e.Enforce(req.Subject.ID, req.Resource, req.Action)
+	})
+}
+
+// OPAPolicy returns a Policy querying an Open Policy Agent server
+// through its Data API. url names the decision, e.g.
+// "http://localhost:8181/v1/data/http/authz/allow", and receives the
+// PermissionRequest as input, extended with the request's path. The
+// decision must be a boolean, or an object with a boolean "allow" field.
+// client defaults to one with a 2 second timeout.
+//
+// Example:
+//
+//	app.Use(zeno.Permission(zeno.PermissionConfig{
+//	    Policy:  zeno.OPAPolicy("http://localhost:8181/v1/data/http/authz/allow", nil),
+//	    Subject: subjectOf,
+//	}))
+func OPAPolicy(url string, client *http.Client) Policy {
+	if client == nil {
+		client = &http.Client{Timeout: 2 * time.Second}
+	}
+	return PolicyFunc(func(c *Context, req *PermissionRequest) (bool, error) {
+		type input struct {
+			*PermissionRequest
+			Path string `json:"path"`
+		}
+		body, err := json.Marshal(map[string]any{"input": input{req, c.Path()}})
+		if err != nil {
+			return false, err
+		}
+		httpReq, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return false, err
+		}
+		httpReq.Header.Set(HeaderContentType, "application/json")
+		resp, err := client.Do(httpReq)
+		if err != nil {
+			return false, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return false, errors.New("opa: " + resp.Status)
+		}
+		var decision struct {
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+			return false, fmt.Errorf("opa: %w", err)
+		}
+		// An undefined decision has no result and denies.
+		var allow bool
+		if len(decision.Result) == 0 || json.Unmarshal(decision.Result, &allow) == nil {
+			return allow, nil
+		}
+		var object struct {
+			Allow bool `json:"allow"`
+		}
+		if err := json.Unmarshal(decision.Result, &object); err != nil {
+			return false, fmt.Errorf("opa: unexpected result %s", decision.Result)
+		}
+		return object.Allow, nil
+	})
+}
@@ -0,0 +1,207 @@
+package zeno
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// subjectFromHeader reads the caller from test headers.
+func subjectFromHeader(c *Context) *Subject {
+	id := c.GetHeader("X-User")
+	if id == "" {
+		return nil
+	}
+	var roles []string
+	if r := c.GetHeader("X-Roles"); r != "" {
+		roles = strings.Split(r, ",")
+	}
+	return &Subject{ID: id, Roles: roles}
+}
+
+func TestPermission_RolePolicy(t *testing.T) {
+	policy := NewRolePolicy().
+		Grant("admin", "*", "*").
+		Grant("editor", "get", "/articles/*").
+		Grant("editor", "publish", "articles")
+	z := New()
+	z.Use(Permission(PermissionConfig{Policy: policy, Subject: subjectFromHeader}))
+	ok := func(c *Context) error { return c.SendString("ok") }
+	z.Get("/articles/{id}", ok)
+	z.Delete("/articles/{id}", ok)
+	z.Post("/articles/{id}/publish", ok).Meta("resource", "articles").Meta("action", "publish")
+
+	for _, tt := range []struct {
+		method, uri, roles string
+		status             int
+	}{
+		{"GET", "/articles/1", "editor", StatusOK},
+		{"DELETE", "/articles/1", "editor", StatusForbidden},
+		{"DELETE", "/articles/1", "viewer,admin", StatusOK},
+		{"POST", "/articles/1/publish", "editor", StatusOK},
+		{"POST", "/articles/1/publish", "", StatusForbidden},
+		{"GET", "/missing", "", StatusNotFound},
+	} {
+		ctx := performRequest(z, tt.method, tt.uri, map[string]string{"X-User": "ann", "X-Roles": tt.roles}, nil)
+		assert.Equal(t, tt.status, ctx.Response.StatusCode(), tt.method+" "+tt.uri+" as "+tt.roles)
+	}
+
+	ctx := performRequest(z, "DELETE", "/articles/1", map[string]string{"X-User": "ann", "X-Roles": "editor"}, nil)
+	var perr PermissionError
+	assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &perr))
+	assert.Equal(t, PermissionError{Status: StatusForbidden, Message: "permission denied", Subject: "ann", Action: "DELETE", Resource: "/articles/{id}"}, perr)
+
+	ctx = performRequest(z, "GET", "/articles/1", nil, nil)
+	assert.Equal(t, StatusUnauthorized, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"status":401,"message":"authentication required","action":"GET","resource":"/articles/{id}"}`, string(ctx.Response.Body()))
+}
+
+func TestPermission_Policy(t *testing.T) {
+	var got *PermissionRequest
+	z := New()
+	z.Use(Permission(PermissionConfig{
+		Policy: PolicyFunc(func(c *Context, req *PermissionRequest) (bool, error) {
+			got = req
+			if req.Subject.ID == "broken" {
+				return false, errors.New("store down")
+			}
+			return !slices.Contains(req.Tags, "internal"), nil
+		}),
+		Subject:  subjectFromHeader,
+		OnDenied: func(c *Context, err *PermissionError) error { return err },
+	}))
+	z.Get("/stats", func(c *Context) error { return c.SendString("ok") }).Tags("internal")
+
+	ctx := performRequest(z, "GET", "/stats", map[string]string{"X-User": "bob"}, nil)
+	assert.Equal(t, StatusForbidden, ctx.Response.StatusCode())
+	assert.Equal(t, "permission denied", string(ctx.Response.Body()))
+	assert.Equal(t, []string{"internal"}, got.Tags)
+
+	ctx = performRequest(z, "GET", "/stats", map[string]string{"X-User": "broken"}, nil)
+	assert.Equal(t, StatusInternalServerError, ctx.Response.StatusCode())
+}
+
+func TestPermission_OnDeniedAborts(t *testing.T) {
+	ran := false
+	z := New()
+	z.Use(Permission(PermissionConfig{
+		Policy:  NewRolePolicy(),
+		Subject: subjectFromHeader,
+		OnDenied: func(c *Context, err *PermissionError) error {
+			c.Status(err.Status)
+			return c.SendString("nope")
+		},
+	}))
+	z.Get("/admin", func(c *Context) error {
+		ran = true
+		return c.SendString("secret")
+	})
+
+	ctx := performRequest(z, "GET", "/admin", map[string]string{"X-User": "ann"}, nil)
+	assert.Equal(t, StatusForbidden, ctx.Response.StatusCode())
+	assert.Equal(t, "nope", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/admin", nil, nil)
+	assert.Equal(t, StatusUnauthorized, ctx.Response.StatusCode())
+	assert.Equal(t, "nope", string(ctx.Response.Body()))
+	assert.False(t, ran, "handler ran after denial")
+}
+
+func TestPermission_MethodOverride(t *testing.T) {
+	deleted := false
+	z := New()
+	z.Use(Permission(PermissionConfig{
+		Policy:  NewRolePolicy().Grant("reader", "GET", "*"),
+		Subject: subjectFromHeader,
+	}), MethodOverride())
+	z.Get("/articles/{id}", func(c *Context) error { return c.SendString("article") })
+	z.Delete("/articles/{id}", func(c *Context) error {
+		deleted = true
+		return c.SendString("deleted")
+	})
+	headers := map[string]string{"X-User": "ann", "X-Roles": "reader"}
+
+	ctx := performRequest(z, "DELETE", "/articles/1", headers, nil)
+	assert.Equal(t, StatusForbidden, ctx.Response.StatusCode())
+
+	headers[HeaderXHTTPMethodOverride] = "DELETE"
+	ctx = performRequest(z, "POST", "/articles/1", headers, nil)
+	assert.Equal(t, StatusForbidden, ctx.Response.StatusCode())
+	assert.False(t, deleted, "overridden request bypassed the policy")
+}
+
+// fakeEnforcer mimics a Casbin enforcer with a fixed policy.
+type fakeEnforcer map[[3]string]bool
+
+func (e fakeEnforcer) Enforce(rvals ...any) (bool, error) {
+	return e[[3]string{rvals[0].(string), rvals[1].(string), rvals[2].(string)}], nil
+}
+
+func TestCasbinPolicy(t *testing.T) {
+	z := New()
+	z.Use(Permission(PermissionConfig{
+		Policy:  CasbinPolicy(fakeEnforcer{{"ann", "/reports", "GET"}: true}),
+		Subject: subjectFromHeader,
+	}))
+	z.Get("/reports", func(c *Context) error { return c.SendString("ok") })
+
+	assert.Equal(t, StatusOK, performRequest(z, "GET", "/reports", map[string]string{"X-User": "ann"}, nil).Response.StatusCode())
+	assert.Equal(t, StatusForbidden, performRequest(z, "GET", "/reports", map[string]string{"X-User": "bob"}, nil).Response.StatusCode())
+}
+
+func TestOPAPolicy(t *testing.T) {
+	opa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input struct {
+				Subject  Subject `json:"subject"`
+				Action   string  `json:"action"`
+				Resource string  `json:"resource"`
+				Path     string  `json:"path"`
+			} `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		in := body.Input
+		switch {
+		case r.URL.Path == "/v1/data/authz/allow" && in.Subject.ID == "ann":
+			w.Write([]byte(`{"result":true}`))
+		case r.URL.Path == "/v1/data/authz/allow" && in.Path == "/docs/public":
+			w.Write([]byte(`{"result":{"allow":true,"reason":"public"}}`))
+		case r.URL.Path == "/v1/data/authz/allow" && in.Subject.ID == "bob":
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer opa.Close()
+
+	z := New()
+	z.Use(Permission(PermissionConfig{
+		Policy:  OPAPolicy(opa.URL+"/v1/data/authz/allow", nil),
+		Subject: subjectFromHeader,
+	}))
+	z.Get("/docs/{name}", func(c *Context) error { return c.SendString("ok") })
+
+	for _, tt := range []struct {
+		user, uri string
+		status    int
+	}{
+		{"ann", "/docs/secret", StatusOK},
+		{"carol", "/docs/public", StatusOK},
+		{"bob", "/docs/secret", StatusForbidden},
+		{"dave", "/docs/secret", StatusInternalServerError},
+	} {
+		ctx := performRequest(z, "GET", tt.uri, map[string]string{"X-User": tt.user}, nil)
+		assert.Equal(t, tt.status, ctx.Response.StatusCode(), tt.user)
+	}
+}
+
+func TestRolePolicy_InvalidPattern(t *testing.T) {
+	assert.Panics(t, func() { NewRolePolicy().Grant("admin", "*", "[") })
+	assert.Panics(t, func() { Permission(PermissionConfig{Subject: subjectFromHeader}) })
+}
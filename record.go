@@ -0,0 +1,202 @@
+package zeno
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// RecordedRequest is a request captured by the Record middleware.
+type RecordedRequest struct {
+	Time       time.Time   `json:"time"`
+	Method     string      `json:"method"`
+	URI        string      `json:"uri"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+	RemoteAddr string      `json:"remote_addr,omitempty"`
+
+	// Truncated is set when Body was cut to RecordConfig.MaxBodySize.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// RecordSink stores recorded requests. Record is called from request
+// goroutines concurrently.
+type RecordSink interface {
+	Record(req RecordedRequest) error
+}
+
+// RecordSinkFunc adapts a function to a RecordSink.
+type RecordSinkFunc func(req RecordedRequest) error
+
+// Record calls f(req).
+func (f RecordSinkFunc) Record(req RecordedRequest) error { return f(req) }
+
+// jsonRecordSink writes recordings as JSON lines.
+type jsonRecordSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONRecordSink returns a RecordSink writing each request to w as one
+// line of JSON, the format read by ReadRecordings.
+//
+// Example:
+//
+//	f, _ := os.OpenFile("requests.jsonl", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+//	app.Use(zeno.Record(zeno.RecordConfig{Sink: zeno.NewJSONRecordSink(f)}))
+func NewJSONRecordSink(w io.Writer) RecordSink {
+	return &jsonRecordSink{enc: json.NewEncoder(w)}
+}
+
+// Record implements RecordSink.
+func (s *jsonRecordSink) Record(req RecordedRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(req)
+}
+
+// RecordConfig configures the Record middleware.
+type RecordConfig struct {
+	// Sink receives the recorded requests. Required.
+	Sink RecordSink
+
+	// Skipper, when set, excludes requests for which it returns true.
+	Skipper Skipper
+
+	// Redactor masks credentials and personal data in the recorded URI,
+	// headers and body. Defaults to DefaultRedactor.
+	Redactor *Redactor
+
+	// RedactHeaders lists further headers whose values are replaced by
+	// "[REDACTED]".
+	//
+	// Deprecated: list the headers in the Fields of Redactor instead.
+	RedactHeaders []string
+
+	// MaxBodySize caps the number of body bytes recorded. Defaults to
+	// 1 MiB; negative values disable body recording.
+	MaxBodySize int
+
+	// OnError is called when the sink fails. Recording errors never
+	// affect the response.
+	OnError func(c *Context, err error)
+}
+
+// Record returns a middleware that captures every request passing through
+// it, with its method, URI, headers and body, masked by config.Redactor, to
+// config.Sink, so that production traffic can be inspected or fed back
+// through the engine with Replay, e.g. to reproduce a bug or build a
+// regression suite.
+//
+// Example:
+//
+//	app.Use(zeno.Record(zeno.RecordConfig{
+//	    Sink:    zeno.NewJSONRecordSink(f),
+//	    Skipper: zeno.PathPrefix("/health"),
+//	}))
+func Record(config RecordConfig) Handler {
+	if config.Sink == nil {
+		panic("zeno: Record requires a Sink")
+	}
+	if config.Redactor == nil {
+		config.Redactor = DefaultRedactor()
+	}
+	if config.MaxBodySize == 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	return func(c *Context) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return c.Next()
+		}
+		if err := config.Sink.Record(recordRequest(c, config)); err != nil && config.OnError != nil {
+			config.OnError(c, err)
+		}
+		return c.Next()
+	}
+}
+
+// recordRequest captures the current request of c.
+func recordRequest(c *Context, config RecordConfig) RecordedRequest {
+	req := &c.ctx.Request
+	rec := RecordedRequest{
+		Time:       time.Now(),
+		Method:     string(req.Header.Method()),
+		URI:        config.Redactor.URI(string(req.Header.RequestURI())),
+		Header:     http.Header{},
+		RemoteAddr: c.ctx.RemoteAddr().String(),
+	}
+	req.Header.VisitAll(func(key, value []byte) {
+		rec.Header.Add(string(key), string(value))
+	})
+	config.Redactor.Header(rec.Header)
+	for _, h := range config.RedactHeaders {
+		values := rec.Header.Values(h)
+		for i := range values {
+			values[i] = "[REDACTED]"
+		}
+	}
+	if config.MaxBodySize > 0 && len(c.PostBody()) > 0 {
+		// The body is masked whole, since truncated JSON or forms cannot
+		// be parsed and would be dropped.
+		body := []byte(config.Redactor.Body(string(req.Header.ContentType()), c.PostBody()))
+		if len(body) > config.MaxBodySize {
+			body, rec.Truncated = body[:config.MaxBodySize], true
+		}
+		if len(body) > 0 {
+			rec.Body = body
+		}
+	}
+	return rec
+}
+
+// ReadRecordings reads requests written by NewJSONRecordSink from r.
+func ReadRecordings(r io.Reader) ([]RecordedRequest, error) {
+	var recs []RecordedRequest
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var rec RecordedRequest
+		if err := dec.Decode(&rec); err == io.EOF {
+			return recs, nil
+		} else if err != nil {
+			return recs, err
+		}
+		recs = append(recs, rec)
+	}
+}
+
+// Replay feeds a recorded request through the engine, exactly as if it had
+// been received, and returns the response. The request does not pass
+// through a network connection, so RemoteAddr is not restored.
+//
+// Example:
+//
+//	recs, _ := zeno.ReadRecordings(f)
+//	for _, rec := range recs {
+//	    if resp := app.Replay(rec); resp.StatusCode() >= 500 {
+//	        log.Printf("%s %s: %d", rec.Method, rec.URI, resp.StatusCode())
+//	    }
+//	}
+func (z *Zeno) Replay(rec RecordedRequest) *fasthttp.Response {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(rec.Method)
+	ctx.Request.SetRequestURI(rec.URI)
+	for k, values := range rec.Header {
+		for _, v := range values {
+			ctx.Request.Header.Add(k, v)
+		}
+	}
+	if len(rec.Body) > 0 {
+		ctx.Request.SetBody(rec.Body)
+	}
+	z.HandleRequest(ctx)
+
+	resp := &fasthttp.Response{}
+	ctx.Response.Body() // read any body stream into memory
+	ctx.Response.CopyTo(resp)
+	return resp
+}
@@ -0,0 +1,61 @@
+package zeno
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord_Redacts(t *testing.T) {
+	var buf bytes.Buffer
+	z := New()
+	z.Use(Record(RecordConfig{Sink: NewJSONRecordSink(&buf), RedactHeaders: []string{"X-Internal"}}))
+	z.Post("/login", func(c *Context) error { return c.SendStatus(StatusNoContent) })
+
+	performRequest(z, "POST", "/login?token=abc&next=%2F", map[string]string{
+		HeaderContentType: "application/json",
+		HeaderCookie:      "session=1",
+		"X-Internal":      "x",
+	}, []byte(`{"email":"ann@example.com","password":"hunter2"}`))
+
+	recs, err := ReadRecordings(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, recs, 1)
+	assert.Equal(t, "/login?next=%2F&token=%5BREDACTED%5D", recs[0].URI)
+	assert.Equal(t, "[REDACTED]", recs[0].Header.Get(HeaderCookie))
+	assert.Equal(t, "[REDACTED]", recs[0].Header.Get("X-Internal"))
+	assert.JSONEq(t, `{"email":"[REDACTED]","password":"[REDACTED]"}`, string(recs[0].Body))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	var buf bytes.Buffer
+	z := New()
+	z.Use(Record(RecordConfig{Sink: NewJSONRecordSink(&buf), Skipper: PathIs("/health"), MaxBodySize: 4}))
+	z.Post("/items", func(c *Context) error {
+		return c.Status(StatusCreated).SendString(c.Query("tag") + ":" + string(c.PostBody()))
+	})
+	z.Get("/health", func(c *Context) error { return c.SendString("ok") })
+
+	performRequest(z, "POST", "/items?tag=a", map[string]string{HeaderAuthorization: "Bearer secret"}, []byte("abc"))
+	performRequest(z, "GET", "/health", nil, nil)
+	performRequest(z, "POST", "/items", nil, []byte("too long"))
+
+	recs, err := ReadRecordings(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, recs, 2)
+	assert.Equal(t, "POST", recs[0].Method)
+	assert.Equal(t, "/items?tag=a", recs[0].URI)
+	assert.Equal(t, "[REDACTED]", recs[0].Header.Get(HeaderAuthorization))
+	assert.Equal(t, []byte("abc"), recs[0].Body)
+	assert.True(t, recs[1].Truncated)
+	assert.Equal(t, []byte("too "), recs[1].Body)
+
+	z2 := New()
+	z2.Post("/items", func(c *Context) error {
+		return c.Status(StatusCreated).SendString(c.Query("tag") + ":" + string(c.PostBody()))
+	})
+	resp := z2.Replay(recs[0])
+	assert.Equal(t, StatusCreated, resp.StatusCode())
+	assert.Equal(t, "a:abc", string(resp.Body()))
+}
@@ -0,0 +1,261 @@
+package zeno
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RedactorConfig configures a Redactor.
+type RedactorConfig struct {
+	// Fields lists the names of headers, query and form parameters, route
+	// parameters and JSON fields whose values are masked, compared case
+	// insensitively. Defaults to common credential names such as
+	// password, token, api_key, Authorization and Cookie; set an empty,
+	// non-nil slice to mask no fields.
+	Fields []string
+
+	// Patterns are masked wherever they appear in free text and in the
+	// values of fields not listed in Fields. Defaults to email addresses,
+	// bearer tokens and JWTs; set an empty, non-nil slice to disable.
+	Patterns []*regexp.Regexp
+
+	// Replacement is substituted for masked values. Defaults to
+	// "[REDACTED]".
+	Replacement string
+}
+
+// Redactor masks personal data and credentials, such as passwords, tokens
+// and email addresses, before they are written to logs, audit trails or
+// error responses. The access log, audit middleware and default error
+// handler use one; a nil *Redactor leaves everything unchanged.
+//
+// A Redactor is safe for concurrent use.
+type Redactor struct {
+	fields      []string
+	patterns    []*regexp.Regexp
+	replacement string
+}
+
+// defaultRedactFields are the field names masked by default.
+var defaultRedactFields = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token",
+	"id_token", "api_key", "apikey", "client_secret",
+	HeaderAuthorization, HeaderProxyAuthorization, HeaderCookie, HeaderSetCookie,
+}
+
+// defaultRedactPatterns are the patterns masked by default.
+var defaultRedactPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`),
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+}
+
+// NewRedactor returns a Redactor with the given configuration, applying
+// the defaults documented on RedactorConfig.
+//
+// Example:
+//
+//	r := zeno.NewRedactor(zeno.RedactorConfig{
+//	    Fields:   append([]string{"ssn"}, zeno.DefaultRedactor().Fields()...),
+//	    Patterns: []*regexp.Regexp{regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+//	})
+//	app.Redactor = r
+func NewRedactor(config ...RedactorConfig) *Redactor {
+	var cfg RedactorConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Fields == nil {
+		cfg.Fields = defaultRedactFields
+	}
+	if cfg.Patterns == nil {
+		cfg.Patterns = defaultRedactPatterns
+	}
+	if cfg.Replacement == "" {
+		cfg.Replacement = "[REDACTED]"
+	}
+	return &Redactor{fields: cfg.Fields, patterns: cfg.Patterns, replacement: cfg.Replacement}
+}
+
+// defaultRedactor is returned by DefaultRedactor.
+var defaultRedactor = NewRedactor()
+
+// DefaultRedactor returns the Redactor with the default configuration.
+func DefaultRedactor() *Redactor {
+	return defaultRedactor
+}
+
+// Fields returns the field names r masks.
+func (r *Redactor) Fields() []string {
+	if r == nil {
+		return nil
+	}
+	return append([]string(nil), r.fields...)
+}
+
+// IsSensitive reports whether values of the field called name are masked.
+func (r *Redactor) IsSensitive(name string) bool {
+	if r == nil {
+		return false
+	}
+	for _, f := range r.fields {
+		if strings.EqualFold(f, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns s with every match of r's patterns masked.
+//
+// Example:
+//
+//	zeno.DefaultRedactor().String("mail alice@example.com") // "mail [REDACTED]"
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, p := range r.patterns {
+		s = p.ReplaceAllLiteralString(s, r.replacement)
+	}
+	return s
+}
+
+// value returns the masked form of the value of field name.
+func (r *Redactor) value(name, v string) string {
+	if r.IsSensitive(name) {
+		return r.replacement
+	}
+	return r.String(v)
+}
+
+// Header masks the values of h in place.
+func (r *Redactor) Header(h http.Header) {
+	if r == nil {
+		return
+	}
+	for k, values := range h {
+		for i, v := range values {
+			values[i] = r.value(k, v)
+		}
+	}
+}
+
+// Values masks the values of v, e.g. a parsed query string, in place.
+func (r *Redactor) Values(v url.Values) {
+	if r == nil {
+		return
+	}
+	for k, values := range v {
+		for i, s := range values {
+			values[i] = r.value(k, s)
+		}
+	}
+}
+
+// Params masks the values of m, e.g. route parameters, in place.
+func (r *Redactor) Params(m map[string]string) {
+	if r == nil {
+		return
+	}
+	for k, v := range m {
+		m[k] = r.value(k, v)
+	}
+}
+
+// Query returns the masked form of the raw query string q.
+func (r *Redactor) Query(q string) string {
+	if r == nil || q == "" {
+		return q
+	}
+	values, err := url.ParseQuery(q)
+	if err != nil {
+		return r.String(q)
+	}
+	r.Values(values)
+	return values.Encode()
+}
+
+// URI returns the masked form of a request URI: the path is masked by r's
+// patterns and the query string as by Query.
+func (r *Redactor) URI(uri string) string {
+	if r == nil {
+		return uri
+	}
+	path, query, ok := strings.Cut(uri, "?")
+	if !ok {
+		return r.String(path)
+	}
+	return r.String(path) + "?" + r.Query(query)
+}
+
+// Body returns the masked form of a request or response body with the
+// given Content-Type. JSON bodies have sensitive fields masked at any
+// depth, URL-encoded forms have sensitive parameters masked, and all text
+// is masked by r's patterns. JSON or form bodies that fail to parse, and
+// thus cannot be masked reliably, are dropped entirely.
+func (r *Redactor) Body(contentType string, body []byte) string {
+	if r == nil {
+		return string(body)
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	switch {
+	case isJSONMediaType(mediaType):
+		var v any
+		if err := json.Unmarshal(body, &v); err != nil {
+			return ""
+		}
+		b, err := json.Marshal(r.json(v))
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return ""
+		}
+		r.Values(values)
+		return values.Encode()
+	}
+	return r.String(string(body))
+}
+
+// json masks the decoded JSON value v.
+func (r *Redactor) json(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if r.IsSensitive(k) {
+				v[k] = r.replacement
+			} else {
+				v[k] = r.json(child)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = r.json(child)
+		}
+	case string:
+		return r.String(v)
+	}
+	return v
+}
+
+// Redact returns s masked by Zeno.Redactor, or s unchanged if none is set.
+// Custom error handlers and loggers use it to apply the application's
+// redaction rules.
+//
+// Example:
+//
+//	app.ErrorHandler = func(c *zeno.Context, err error) error {
+//	    log.Printf("%s %s: %s", c.Method(), c.Redact(c.Path()), c.Redact(err.Error()))
+//	    return c.SendStatusCode(zeno.StatusInternalServerError)
+//	}
+func (c *Context) Redact(s string) string {
+	return c.zeno.Redactor.String(s)
+}
@@ -0,0 +1,52 @@
+package zeno
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor(t *testing.T) {
+	r := DefaultRedactor()
+	assert.Equal(t, "mail [REDACTED] now", r.String("mail alice@example.com now"))
+	assert.Equal(t, "auth [REDACTED]", r.String("auth Bearer abc.def"))
+	assert.Equal(t, "jwt [REDACTED]", r.String("jwt eyJhbGciOi.eyJzdWIi.sig"))
+
+	h := http.Header{"Authorization": {"Basic xyz"}, "X-User": {"bob@example.org"}, "Accept": {"*/*"}}
+	r.Header(h)
+	assert.Equal(t, http.Header{"Authorization": {"[REDACTED]"}, "X-User": {"[REDACTED]"}, "Accept": {"*/*"}}, h)
+
+	assert.Equal(t, "/users/[REDACTED]?password=%5BREDACTED%5D&q=go", r.URI("/users/a@b.io?q=go&password=pw"))
+	assert.JSONEq(t,
+		`{"user":{"email":"[REDACTED]","Password":"[REDACTED]","tags":["x"]},"token":"[REDACTED]"}`,
+		r.Body("application/json; charset=utf-8", []byte(`{"user":{"email":"a@b.io","Password":{"old":"p"},"tags":["x"]},"token":42}`)))
+	assert.Equal(t, "", r.Body("application/json", []byte(`{"password":`)))
+
+	custom := NewRedactor(RedactorConfig{
+		Fields:      []string{"ssn"},
+		Patterns:    []*regexp.Regexp{regexp.MustCompile(`\d{3}-\d{2}-\d{4}`)},
+		Replacement: "***",
+	})
+	assert.Equal(t, "ssn=%2A%2A%2A&x=%2A%2A%2A", custom.Query("ssn=1&x=123-45-6789"))
+	assert.Equal(t, "a@b.io", custom.String("a@b.io"))
+
+	var none *Redactor
+	assert.Equal(t, "a@b.io", none.String("a@b.io"))
+}
+
+func TestZeno_RedactErrors(t *testing.T) {
+	z := New()
+	z.Get("/", func(c *Context) error {
+		return NewHTTPError(StatusBadRequest, "unknown user alice@example.com")
+	})
+
+	ctx := performRequest(z, "GET", "/", nil, nil)
+	assert.Equal(t, "unknown user alice@example.com", string(ctx.Response.Body()))
+
+	z.Redactor = DefaultRedactor()
+	ctx = performRequest(z, "GET", "/", nil, nil)
+	assert.Equal(t, StatusBadRequest, ctx.Response.StatusCode())
+	assert.Equal(t, "unknown user [REDACTED]", string(ctx.Response.Body()))
+}
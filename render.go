@@ -0,0 +1,107 @@
+package zeno
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+)
+
+// HeaderHXRequest is sent by htmx on every request it issues, allowing the
+// server to answer with a page fragment instead of a full document.
+const HeaderHXRequest = "HX-Request"
+
+// Renderer renders a named template with the given data into w.
+//
+// Implementations are expected to resolve both full pages and the blocks
+// (fragments) defined inside them by name, so the same renderer serves
+// regular page loads and partial updates.
+type Renderer interface {
+	Render(w io.Writer, name string, data any) error
+}
+
+// HTMLRenderer is a Renderer backed by a parsed html/template set.
+//
+// Every template and every {{block}} or {{define}} inside the set is
+// addressable by name, which makes it suitable for fragment rendering:
+//
+//	{{define "users.html"}}
+//	  <ul id="list">{{block "rows" .}}{{range .}}<li>{{.}}</li>{{end}}{{end}}</ul>
+//	{{end}}
+//
+// Rendering "users.html" produces the whole page, while "rows" produces only
+// the list items.
+type HTMLRenderer struct {
+	templates *template.Template
+}
+
+// NewHTMLRenderer returns a Renderer that executes templates from t.
+//
+// Example:
+//
+//	app.Renderer = zeno.NewHTMLRenderer(template.Must(template.ParseGlob("views/*.html")))
+func NewHTMLRenderer(t *template.Template) *HTMLRenderer {
+	return &HTMLRenderer{templates: t}
+}
+
+// Render executes the template or block called name with data.
+func (r *HTMLRenderer) Render(w io.Writer, name string, data any) error {
+	return r.templates.ExecuteTemplate(w, name, data)
+}
+
+// EscapeHTML returns s with the characters <, >, &, ' and " escaped so it
+// can be embedded in HTML text or attribute values.
+//
+// Example:
+//
+//	return c.SendHTML("<p>Hello, " + zeno.EscapeHTML(name) + "</p>")
+func EscapeHTML(s string) string {
+	return template.HTMLEscapeString(s)
+}
+
+// SendHTMLSafe renders tmpl, an html/template source string, with data and
+// writes the result as an HTML response. Values from data are escaped for
+// the context they appear in, so untrusted input cannot inject markup or
+// scripts the way it can when concatenated into SendHTML.
+//
+// Parsed templates are cached by source, so tmpl should be a constant
+// rather than built per request. A generic 500 Internal Server Error is
+// returned if tmpl fails to parse or execute, without the details, which
+// could reveal the template or data; nothing is written in that case.
+//
+// Example:
+//
+//	return c.SendHTMLSafe(`<h1>Hello, {{.name}}!</h1>`, map[string]any{
+//	    "name": c.Query("name"),
+//	})
+func (c *Context) SendHTMLSafe(tmpl string, data map[string]any) error {
+	t, err := c.zeno.htmlTemplate(tmpl)
+	if err != nil {
+		return ErrInternalServer
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return ErrInternalServer
+	}
+	c.setMediaType("text/html", nil)
+	return c.SendBytes(buf.Bytes())
+}
+
+// maxHTMLTemplates bounds the templates cached by SendHTMLSafe, in case
+// their sources are built per request.
+const maxHTMLTemplates = 256
+
+// htmlTemplate returns the parsed template for src, from the cache if it
+// has been parsed before.
+func (z *Zeno) htmlTemplate(src string) (*template.Template, error) {
+	if t, ok := z.htmlTemplates.Load(src); ok {
+		return t.(*template.Template), nil
+	}
+	t, err := template.New("").Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	if z.htmlTemplateCount.Add(1) <= maxHTMLTemplates {
+		z.htmlTemplates.Store(src, t)
+	}
+	return t, nil
+}
@@ -0,0 +1,278 @@
+package zeno
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp/reuseport"
+)
+
+// envListenAddrs names the environment variable through which Restart
+// tells the child which address each inherited file descriptor (starting
+// at 3) is listening on.
+const envListenAddrs = "ZENO_LISTEN_ADDRS"
+
+// envReadyFD names the environment variable through which Restart tells
+// the child the file descriptor of the pipe on which it reports that it
+// is ready to serve.
+const envReadyFD = "ZENO_READY_FD"
+
+// addrListener is a listener opened by the engine together with the
+// address it was requested for.
+type addrListener struct {
+	addr string
+	ln   net.Listener
+}
+
+var (
+	inheritedOnce sync.Once
+	inheritedMu   sync.Mutex
+	inherited     map[string]net.Listener
+	readyOnce     sync.Once
+)
+
+// takeInherited returns the listener passed down by the parent process for
+// addr, if any. Each inherited listener is handed out only once; once all
+// of them have been, the parent is told that this process is ready.
+func takeInherited(addr string) net.Listener {
+	loadInherited()
+	inheritedMu.Lock()
+	ln := inherited[addr]
+	delete(inherited, addr)
+	claimed := len(inherited) == 0
+	inheritedMu.Unlock()
+	if claimed {
+		signalReady()
+	}
+	return ln
+}
+
+// loadInherited opens the listeners passed down by the parent process, on
+// first use.
+func loadInherited() {
+	inheritedOnce.Do(func() {
+		inherited = make(map[string]net.Listener)
+		addrs := os.Getenv(envListenAddrs)
+		if addrs == "" {
+			return
+		}
+		os.Unsetenv(envListenAddrs)
+		for i, a := range strings.Split(addrs, ",") {
+			f := os.NewFile(uintptr(3+i), a)
+			ln, err := net.FileListener(f)
+			f.Close()
+			if err == nil {
+				inherited[a] = ln
+			}
+		}
+	})
+}
+
+// Ready tells the process that started this one with Restart that it is
+// serving, so the old process shuts down, and closes the inherited
+// sockets no Run, RunTLS or RunMany has claimed. This happens on its own
+// once every inherited socket has been claimed; call Ready when the new
+// version of the program no longer serves some of the old addresses,
+// since Restart otherwise times out, killing it. It does nothing if the
+// process was not started by Restart.
+//
+// Example:
+//
+//	// The new version dropped the plain HTTP listener.
+//	go func() {
+//	    time.Sleep(time.Second)
+//	    app.Ready()
+//	}()
+//	log.Fatal(app.RunTLS(":443", "cert.pem", "key.pem"))
+func (z *Zeno) Ready() {
+	loadInherited()
+	inheritedMu.Lock()
+	for addr, ln := range inherited {
+		ln.Close()
+		delete(inherited, addr)
+	}
+	inheritedMu.Unlock()
+	signalReady()
+}
+
+// signalReady tells the parent process that started this one with Restart
+// that it is serving, so the parent can shut down. It does nothing if the
+// process was not started by Restart.
+func signalReady() {
+	readyOnce.Do(func() {
+		v := os.Getenv(envReadyFD)
+		if v == "" {
+			return
+		}
+		os.Unsetenv(envReadyFD)
+		fd, err := strconv.Atoi(v)
+		if err != nil {
+			return
+		}
+		f := os.NewFile(uintptr(fd), "ready")
+		f.Write([]byte{1})
+		f.Close()
+	})
+}
+
+// listenNetwork maps a listen address to a network and address for
+// net.Listen. "unix:/path" selects a Unix domain socket, a bracketed IPv6
+// host such as "[::]:80" selects tcp6, and anything else tcp4.
+func listenNetwork(addr string) (network, address string) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		return "unix", path
+	}
+	if strings.HasPrefix(addr, "[") {
+		return "tcp6", addr
+	}
+	return "tcp4", addr
+}
+
+// listen returns a listener for addr (see listenNetwork), reusing one
+// inherited from the parent process when available, and records it for
+// Restart.
+func (z *Zeno) listen(addr string) (net.Listener, error) {
+	ln := takeInherited(addr)
+	if ln == nil {
+		network, address := listenNetwork(addr)
+		if network == "unix" {
+			if fi, err := os.Stat(address); err == nil && fi.Mode()&os.ModeSocket != 0 {
+				os.Remove(address)
+			}
+		}
+		var err error
+		if z.useReusePort && network != "unix" {
+			ln, err = reuseport.Listen(network, address)
+		} else {
+			ln, err = net.Listen(network, address)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	z.mu.Lock()
+	z.listeners = append(z.listeners, addrListener{addr: addr, ln: ln})
+	z.mu.Unlock()
+	return ln, nil
+}
+
+// closeListeners closes lns and forgets them, so Restart does not hand
+// them to a new process.
+func (z *Zeno) closeListeners(lns ...net.Listener) {
+	z.mu.Lock()
+	z.listeners = slices.DeleteFunc(z.listeners, func(l addrListener) bool {
+		return slices.Contains(lns, l.ln)
+	})
+	z.mu.Unlock()
+	for _, ln := range lns {
+		ln.Close()
+	}
+}
+
+// Restart performs a zero-downtime binary upgrade. It starts a new copy of
+// the current executable with the same arguments, handing it the open
+// listening sockets, and then gracefully shuts this process down (see
+// Shutdown) once the child reports that it is serving. The child's Run,
+// RunTLS and RunMany pick up the inherited sockets for the same addresses,
+// so no connection attempt is refused while the old process drains its
+// in-flight requests. The child reports once all of the sockets have been
+// picked up, or when it calls Ready.
+//
+// If the child exits before it is ready, or ctx is done first, the child
+// is killed and this process keeps serving; Restart then returns an error.
+//
+// Restart is typically triggered by a signal after the binary on disk has
+// been replaced. It is not supported on Windows.
+//
+// Example:
+//
+//	go func() {
+//	    sig := make(chan os.Signal, 1)
+//	    signal.Notify(sig, syscall.SIGHUP)
+//	    <-sig
+//	    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	    defer cancel()
+//	    if err := app.Restart(ctx); err != nil {
+//	        log.Println("restart:", err)
+//	    }
+//	}()
+//	log.Fatal(app.Run(":8080"))
+func (z *Zeno) Restart(ctx context.Context) error {
+	z.mu.RLock()
+	listeners := append([]addrListener(nil), z.listeners...)
+	z.mu.RUnlock()
+	if len(listeners) == 0 {
+		return errors.New("zeno: Restart requires a running server")
+	}
+
+	type filer interface{ File() (*os.File, error) }
+	files := make([]*os.File, 0, len(listeners)+1)
+	addrs := make([]string, 0, len(listeners))
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for _, l := range listeners {
+		fl, ok := l.ln.(filer)
+		if !ok {
+			return fmt.Errorf("zeno: listener for %s cannot be inherited", l.addr)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return fmt.Errorf("zeno: listener for %s: %w", l.addr, err)
+		}
+		files = append(files, f)
+		addrs = append(addrs, l.addr)
+	}
+	ready, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer ready.Close()
+	files = append(files, readyW)
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(),
+		envListenAddrs+"="+strings.Join(addrs, ","),
+		envReadyFD+"="+strconv.Itoa(3+len(addrs)))
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("zeno: starting new process: %w", err)
+	}
+	// Only the child holds the write end now, so the read fails with EOF
+	// if it exits without reporting.
+	readyW.Close()
+	files = files[:len(files)-1]
+
+	errc := make(chan error, 1)
+	go func() {
+		var b [1]byte
+		_, err := ready.Read(b[:])
+		errc <- err
+	}()
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		go cmd.Wait()
+		return fmt.Errorf("zeno: new process did not become ready: %w", err)
+	}
+	return z.Shutdown(ctx)
+}
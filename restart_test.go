@@ -0,0 +1,157 @@
+package zeno
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestTakeInherited(t *testing.T) {
+	if os.Getenv("ZENO_TEST_CHILD") == "1" {
+		ln := takeInherited("127.0.0.1:0")
+		if ln == nil {
+			os.Exit(3)
+		}
+		os.Stdout.WriteString(ln.Addr().String())
+		os.Exit(0)
+	}
+
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	f, err := ln.(*net.TCPListener).File()
+	assert.NoError(t, err)
+	defer f.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestTakeInherited$")
+	cmd.Env = append(os.Environ(), "ZENO_TEST_CHILD=1", envListenAddrs+"=127.0.0.1:0")
+	cmd.ExtraFiles = []*os.File{f}
+	out, err := cmd.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, ln.Addr().String(), string(out))
+}
+
+// TestZeno_Restart restarts into a copy of the test binary: one that exits
+// without serving, which leaves the parent in charge, and one that serves a
+// single request and exits.
+func TestZeno_Restart(t *testing.T) {
+	const addr = "127.0.0.1:0"
+	switch os.Getenv("ZENO_TEST_RESTART") {
+	case "fail":
+		os.Exit(1)
+	case "serve":
+		z := New()
+		z.Get("/", func(c *Context) error {
+			c.AfterResponse(func() { z.Shutdown(context.Background()) })
+			return c.SendString("child")
+		})
+		if err := z.Run(addr); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	args := os.Args
+	os.Args = []string{args[0], "-test.run=^TestZeno_Restart$"}
+	defer func() { os.Args = args }()
+
+	z := New()
+	z.Get("/", func(c *Context) error { return c.SendString("parent") })
+	errc := make(chan error, 1)
+	go func() { errc <- z.Run(addr) }()
+	var url string
+	assert.Eventually(t, func() bool {
+		z.mu.RLock()
+		defer z.mu.RUnlock()
+		if len(z.listeners) == 0 {
+			return false
+		}
+		url = "http://" + z.listeners[0].ln.Addr().String() + "/"
+		return true
+	}, time.Second, 10*time.Millisecond)
+	get := func() string {
+		_, body, err := fasthttp.Get(nil, url)
+		assert.NoError(t, err)
+		return string(body)
+	}
+	assert.Equal(t, "parent", get())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	t.Setenv("ZENO_TEST_RESTART", "fail")
+	assert.Error(t, z.Restart(ctx))
+	assert.False(t, z.IsShuttingDown())
+	assert.Equal(t, "parent", get())
+
+	t.Setenv("ZENO_TEST_RESTART", "serve")
+	assert.NoError(t, z.Restart(ctx))
+	assert.NoError(t, <-errc)
+	assert.Equal(t, "child", get())
+}
+
+// TestZeno_RestartTwoAddresses restarts into a child serving two addresses
+// with separate Run calls, the second started late, and checks that the
+// parent waits for both to be picked up.
+func TestZeno_RestartTwoAddresses(t *testing.T) {
+	addrs := []string{"127.0.0.1:0", "localhost:0"}
+	if os.Getenv("ZENO_TEST_RESTART_TWO") == "serve" {
+		z := New()
+		var served atomic.Int32
+		z.Get("/", func(c *Context) error {
+			if served.Add(1) == 2 {
+				c.AfterResponse(func() { z.Shutdown(context.Background()) })
+			}
+			return c.SendString("child")
+		})
+		go z.Run(addrs[0])
+		time.Sleep(200 * time.Millisecond)
+		if err := z.Run(addrs[1]); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	args := os.Args
+	os.Args = []string{args[0], "-test.run=^TestZeno_RestartTwoAddresses$"}
+	defer func() { os.Args = args }()
+
+	z := New()
+	z.Get("/", func(c *Context) error { return c.SendString("parent") })
+	errc := make(chan error, len(addrs))
+	for _, addr := range addrs {
+		go func() { errc <- z.Run(addr) }()
+	}
+	var urls []string
+	assert.Eventually(t, func() bool {
+		z.mu.RLock()
+		defer z.mu.RUnlock()
+		if len(z.listeners) < len(addrs) {
+			return false
+		}
+		urls = urls[:0]
+		for _, l := range z.listeners {
+			urls = append(urls, "http://"+l.ln.Addr().String()+"/")
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	t.Setenv("ZENO_TEST_RESTART_TWO", "serve")
+	assert.NoError(t, z.Restart(ctx))
+	for range addrs {
+		assert.NoError(t, <-errc)
+	}
+	for _, url := range urls {
+		_, body, err := fasthttp.Get(nil, url)
+		assert.NoError(t, err, url)
+		assert.Equal(t, "child", string(body), url)
+	}
+}
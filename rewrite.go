@@ -0,0 +1,101 @@
+package zeno
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// rewriteRule is a compiled Rewrite rule.
+type rewriteRule struct {
+	pattern *regexp.Regexp
+	target  string
+}
+
+// numberedRef matches "$$" and the numbered references "$1", "$2", ... in
+// a Rewrite target.
+var numberedRef = regexp.MustCompile(`\$(\$|[0-9]+)`)
+
+// compileRewriteRule turns a Rewrite pattern into an anchored regular
+// expression. Patterns starting with "^" are used as regular expressions
+// verbatim; otherwise every "*" matches any sequence of characters and
+// becomes a numbered capture group, with all other characters matched
+// literally.
+//
+// Numbered references in target are braced, so that "$1foo" expands
+// group 1 followed by "foo" rather than a group named "1foo".
+func compileRewriteRule(pattern, target string) rewriteRule {
+	target = numberedRef.ReplaceAllStringFunc(target, func(ref string) string {
+		if ref == "$$" {
+			return ref
+		}
+		return "${" + ref[1:] + "}"
+	})
+	if strings.HasPrefix(pattern, "^") {
+		return rewriteRule{pattern: regexp.MustCompile(pattern), target: target}
+	}
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return rewriteRule{
+		pattern: regexp.MustCompile("^" + strings.Join(parts, "(.*)") + "$"),
+		target:  target,
+	}
+}
+
+// Rewrite returns a middleware that rewrites request paths according to
+// rules before they are routed. Keys are patterns and values are the
+// replacement paths, in which $1, $2, ... refer to captured segments, also
+// when followed directly by letters, as in "/new/$1.html"; ${name} refers
+// to a named group of a regular expression and $$ is a literal "$".
+//
+// A pattern is either a path containing "*" wildcards, each of which
+// captures any sequence of characters, or a regular expression starting
+// with "^". Longer patterns are tried first; the first match wins and the
+// request is re-dispatched to the rewritten path (see Context.Rewrite),
+// where the rules apply again; rules rewriting back and forth end with
+// 508 Loop Detected. Requests that match no rule continue down the chain
+// unchanged.
+//
+// Because routing happens before middleware runs, Rewrite must be
+// registered with Zeno.Use so that it also wraps the not-found chain.
+//
+// Example:
+//
+//	app.Use(zeno.Rewrite(map[string]string{
+//	    "/old/*":              "/new/$1",
+//	    "^/u/([0-9]+)/posts$": "/users/$1/posts",
+//	}))
+func Rewrite(rules map[string]string) Handler {
+	patterns := make([]string, 0, len(rules))
+	for p := range rules {
+		patterns = append(patterns, p)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	compiled := make([]rewriteRule, len(patterns))
+	for i, p := range patterns {
+		compiled[i] = compileRewriteRule(p, rules[p])
+	}
+
+	return func(c *Context) error {
+		path := c.Path()
+		for _, rule := range compiled {
+			if !rule.pattern.MatchString(path) {
+				continue
+			}
+			target := rule.pattern.ReplaceAllString(path, rule.target)
+			if target == path {
+				break
+			}
+			return c.Rewrite(target)
+		}
+		return c.Next()
+	}
+}
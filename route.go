@@ -4,6 +4,7 @@ package zeno
 import (
 	"fmt"
 	"net/url"
+	"regexp"
 	"strings"
 )
 
@@ -14,19 +15,22 @@ type Route struct {
 	name     string
 	path     string
 	template string
+	meta     map[string]any
+	tags     []string
 }
 
 // newRoute creates a new Route instance associated with the given group and path.
 // It transforms wildcard patterns into regular expressions and builds a URL template.
+// It panics if the path is not a valid pattern, see ValidatePath.
 //
 // It also registers the route in the global Zeno routes map.
 func newRoute(path string, group *RouteGroup) *Route {
 	path = group.prefix + path
-	name := path
-
-	if strings.HasSuffix(path, "*") {
-		path = path[:len(path)-1] + "{:.*}"
+	if err := ValidatePath(path); err != nil {
+		panic(err)
 	}
+	name := path
+	path = routePattern(path)
 
 	route := &Route{
 		group:    group,
@@ -34,18 +38,21 @@ func newRoute(path string, group *RouteGroup) *Route {
 		path:     path,
 		template: buildURLTemplate(path),
 	}
-	route.group.zeno.routes[path] = route
+	route.group.zeno.registerRoute(path, route)
 	return route
 }
 
 // Name sets a custom name for the route and registers it using that name.
+// Renaming a route drops its previous custom name. It panics if name is
+// already used by another route.
 //
 // Example:
 //
 //	r := newRoute("/user/{id}", group).Name("user.show")
 func (r *Route) Name(name string) *Route {
-	r.name = name
-	r.group.zeno.routes[name] = r
+	if err := r.group.zeno.registerName(name, r); err != nil {
+		panic(err)
+	}
 	return r
 }
 
@@ -109,10 +116,23 @@ func (r *Route) To(methods string, handlers ...Handler) *Route {
 // add registers handlers for a single HTTP method and attaches route/middleware chain.
 func (r *Route) add(method string, handlers []Handler) *Route {
 	hh := combineHandlers(r.group.handlers, handlers)
-	r.group.zeno.add(method, r.path, hh)
+	r.group.zeno.add(method, r.path, hh, r)
 	return r
 }
 
+// Detach removes the route from the router for all of its methods and
+// unregisters its names, so subsequent requests no longer match it. It is
+// safe to call while the server is running.
+//
+// Example:
+//
+//	beta := app.Get("/beta", betaHandler)
+//	// later, when the feature flag is switched off:
+//	beta.Detach()
+func (r *Route) Detach() {
+	r.group.zeno.detach(r)
+}
+
 // buildURLTemplate creates a reusable path template by stripping regex
 // suffixes from route parameters.
 //
@@ -164,6 +184,131 @@ func (r *Route) URL(pairs ...interface{}) (s string) {
 	return
 }
 
+// URLE generates a URL path like URL, but reports an error when a required
+// parameter has no value or a value does not satisfy the parameter's
+// pattern, instead of leaving the {token} in place.
+//
+// Values are path-escaped, except that slashes in wildcard parameters
+// ({path*}, {*path} or a ".*" / ".+" pattern) are kept so the value can span
+// several segments. The unnamed wildcard created by a trailing "*" is
+// addressed as "*". Optional parameters ({name?}) may be omitted. If the
+// last argument is a url.Values it is encoded as the query string.
+//
+// Example:
+//
+//	r := app.Get("/files/{bucket}/{path*}", h)
+//	u, err := r.URLE("bucket", "docs", "path", "a/b c.txt", url.Values{"v": {"2"}})
+//	// u == "/files/docs/a/b%20c.txt?v=2"
+func (r *Route) URLE(pairs ...any) (string, error) {
+	var query url.Values
+	if len(pairs)%2 == 1 {
+		q, ok := pairs[len(pairs)-1].(url.Values)
+		if !ok {
+			return "", fmt.Errorf("zeno: route %q: odd number of URL arguments", r.name)
+		}
+		query = q
+		pairs = pairs[:len(pairs)-1]
+	}
+	values := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		values[fmt.Sprint(pairs[i])] = fmt.Sprint(pairs[i+1])
+	}
+
+	var b strings.Builder
+	path := r.path
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			b.WriteString(path)
+			break
+		}
+		end := tokenEnd(path, start)
+		if end < 0 {
+			return "", fmt.Errorf("zeno: route %q: unterminated parameter", r.name)
+		}
+		b.WriteString(path[:start])
+		p := parseRouteParam(path[start+1 : end])
+		key := p.name
+		if key == "" && p.wildcard {
+			key = "*"
+		}
+		value, ok := values[key]
+		if !ok && !p.optional {
+			return "", fmt.Errorf("zeno: route %q: missing value for parameter %q", r.name, key)
+		}
+		if p.pattern != nil && ok && !p.pattern.MatchString(value) {
+			return "", fmt.Errorf("zeno: route %q: value %q does not match parameter %q", r.name, value, key)
+		}
+		if p.wildcard {
+			segments := strings.Split(value, "/")
+			for i, s := range segments {
+				segments[i] = url.PathEscape(s)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+		path = path[end+1:]
+	}
+	if len(query) > 0 {
+		b.WriteByte('?')
+		b.WriteString(query.Encode())
+	}
+	return b.String(), nil
+}
+
+// routeParam describes a {token} in a route path.
+type routeParam struct {
+	name     string
+	optional bool
+	wildcard bool
+	pattern  *regexp.Regexp
+}
+
+// parseRouteParam parses the inside of a {token} using the same rules as
+// the router: {name}, {name:regex}, {name?}, {name*} and {*name}.
+func parseRouteParam(raw string) routeParam {
+	var p routeParam
+	name, pattern, hasPattern := strings.Cut(raw, ":")
+	if strings.HasPrefix(name, "*") && len(name) > 1 {
+		name = name[1:] + "*"
+	}
+	if strings.HasSuffix(name, "?") {
+		p.optional = true
+		name = name[:len(name)-1]
+	}
+	if strings.HasSuffix(name, "*") {
+		p.wildcard = true
+		name = name[:len(name)-1]
+	}
+	if hasPattern && pattern != "" {
+		if pattern == ".*" || pattern == ".+" {
+			p.wildcard = true
+		}
+		p.pattern = regexp.MustCompile("^(?:" + pattern + ")$")
+	}
+	p.name = name
+	return p
+}
+
+// tokenEnd returns the index of the '}' closing the token that starts at
+// path[start], allowing balanced braces inside regex patterns.
+func tokenEnd(path string, start int) int {
+	depth := 0
+	for i := start; i < len(path); i++ {
+		switch path[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
 // combineHandlers merges group-level handlers with route-level handlers.
 //
 // The result is a flat handler chain with group handlers executed first.
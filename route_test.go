@@ -0,0 +1,124 @@
+package zeno
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoute_URLE(t *testing.T) {
+	z := New()
+
+	r := z.Get("/users/{id:[0-9]+}/posts/{slug}", func(*Context) error { return nil })
+	u, err := r.URLE("id", 42, "slug", "hello world", url.Values{"page": {"2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/42/posts/hello%20world?page=2", u)
+
+	_, err = r.URLE("id", 42)
+	assert.ErrorContains(t, err, `missing value for parameter "slug"`)
+
+	_, err = r.URLE("id", "abc", "slug", "x")
+	assert.ErrorContains(t, err, "does not match")
+
+	files := z.Get("/files/{path*}", func(*Context) error { return nil })
+	u, err = files.URLE("path", "a/b c.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "/files/a/b%20c.txt", u)
+
+	static := z.Get("/static/*", func(*Context) error { return nil })
+	u, err = static.URLE("*", "css/site.css")
+	assert.NoError(t, err)
+	assert.Equal(t, "/static/css/site.css", u)
+
+	opt := z.Get("/list/{page?}", func(*Context) error { return nil })
+	u, err = opt.URLE()
+	assert.NoError(t, err)
+	assert.Equal(t, "/list/", u)
+}
+
+func TestZeno_URLAndAbsoluteURL(t *testing.T) {
+	c, _ := newTestContext("GET", "http://api.local/x", nil, nil)
+	z := c.zeno
+	z.Get("/users/{id}", func(*Context) error { return nil }).Name("user.show")
+
+	u, err := z.URL("user.show", "id", 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/7", u)
+
+	_, err = z.URL("missing")
+	assert.Error(t, err)
+
+	_, err = z.AbsoluteURL("user.show", "id", 7)
+	assert.Error(t, err)
+
+	u, err = c.AbsoluteURL("user.show", "id", 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://api.local/users/7", u)
+
+	z.BaseURL = "https://example.com/"
+	u, err = c.AbsoluteURL("user.show", "id", 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/users/7", u)
+}
+
+func TestRoute_NameRegistry(t *testing.T) {
+	z := New()
+	h := func(*Context) error { return nil }
+
+	a := z.Get("/a", h).Name("a")
+	assert.Same(t, a, z.MustGetRoute("a"))
+	assert.PanicsWithError(t, `zeno: route name "a" already registered for /a`, func() {
+		z.Get("/b", h).Name("a")
+	})
+
+	a.Name("alpha")
+	assert.Nil(t, z.GetRoute("a"))
+	assert.Same(t, a, z.GetRoute("alpha"))
+	assert.Same(t, a, z.GetRoute("/a"))
+
+	var names []string
+	for name := range z.Routes() {
+		names = append(names, name)
+	}
+	assert.Equal(t, []string{"/a", "/b", "alpha"}, names)
+
+	assert.True(t, z.UnregisterRoute("alpha"))
+	assert.False(t, z.UnregisterRoute("alpha"))
+	assert.Panics(t, func() { z.MustGetRoute("alpha") })
+}
+
+func TestRouteGroup_Nesting(t *testing.T) {
+	z := New()
+	auth := func(c *Context) error { return c.Next() }
+	var inner *RouteGroup
+	z.Route("/api", func(api *RouteGroup) {
+		api.Route("/v1", func(v1 *RouteGroup) {
+			inner = v1
+			v1.Get("/users", func(c *Context) error { return c.SendString("users") })
+		})
+	}, auth)
+	z.Group("/admin")
+
+	ctx := performRequest(z, "GET", "/api/v1/users", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "/api/v1", inner.Prefix())
+	assert.Equal(t, "/api", inner.Parent().Prefix())
+	assert.Same(t, &z.RouteGroup, inner.Parent().Parent())
+	assert.Len(t, inner.Middleware(), 1)
+
+	var walked []string
+	z.Walk(func(g *RouteGroup, depth int) bool {
+		walked = append(walked, fmt.Sprintf("%d:%s", depth, g.Prefix()))
+		return true
+	})
+	assert.Equal(t, []string{"0:", "1:/api", "2:/api/v1", "1:/admin"}, walked)
+
+	walked = nil
+	z.Walk(func(g *RouteGroup, depth int) bool {
+		walked = append(walked, g.Prefix())
+		return g.Prefix() != "/api"
+	})
+	assert.Equal(t, []string{"", "/api"}, walked)
+}
@@ -0,0 +1,107 @@
+package zeno
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"slices"
+)
+
+// EnvPrintRoutes names the environment variable that, when set, makes Run
+// and its variants of applications that called PrintRoutesOnRequest print
+// the route table as JSON to standard output instead of serving. The zeno
+// command's routes subcommand sets it to list the routes of a built
+// binary.
+const EnvPrintRoutes = "ZENO_PRINT_ROUTES"
+
+// ErrRoutesPrinted is returned by Run and its variants, without serving,
+// once they have printed the route table for the zeno command. See
+// PrintRoutesOnRequest.
+var ErrRoutesPrinted = errors.New("zeno: route table printed")
+
+// RouteTableEntry describes one registered route and method, as returned
+// by RouteTable.
+type RouteTableEntry struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Name is the route's name: its path pattern, or the custom name
+	// given with Route.Name.
+	Name string `json:"name"`
+
+	// Handlers lists the function names of the handler chain, group
+	// middleware included.
+	Handlers []string `json:"handlers"`
+
+	Tags []string `json:"tags,omitempty"`
+}
+
+// RouteTable returns every registered route and method, sorted by path
+// and then method.
+//
+// Example:
+//
+//	for _, r := range app.RouteTable() {
+//	    fmt.Printf("%-7s %s\n", r.Method, r.Path)
+//	}
+func (z *Zeno) RouteTable() []RouteTableEntry {
+	z.mu.RLock()
+	table := make([]RouteTableEntry, len(z.entries))
+	for i, e := range z.entries {
+		table[i] = RouteTableEntry{
+			Method:   e.method,
+			Path:     e.path,
+			Name:     e.route.name,
+			Handlers: handlerNames(e.handlers),
+			Tags:     e.route.TagList(),
+		}
+	}
+	z.mu.RUnlock()
+	slices.SortStableFunc(table, func(a, b RouteTableEntry) int {
+		return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Method, b.Method))
+	})
+	return table
+}
+
+// writeRouteTable writes the route table to w as indented JSON.
+func (z *Zeno) writeRouteTable(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(z.RouteTable())
+}
+
+// PrintRoutesOnRequest opts the application in to the zeno command's
+// routes subcommand: when EnvPrintRoutes is set, Run and its variants
+// print the route table as JSON to standard output and return
+// ErrRoutesPrinted instead of serving. Without this call the variable is
+// ignored.
+//
+// Example:
+//
+//	app.PrintRoutesOnRequest()
+//	if err := app.Run(":3000"); err != nil && !errors.Is(err, zeno.ErrRoutesPrinted) {
+//	    log.Fatal(err)
+//	}
+func (z *Zeno) PrintRoutesOnRequest() {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.printRoutes = true
+}
+
+// printRoutesIfRequested prints the route table if the application opted
+// in and EnvPrintRoutes is set, returning ErrRoutesPrinted or the write
+// error. It returns nil otherwise.
+func (z *Zeno) printRoutesIfRequested() error {
+	z.mu.RLock()
+	enabled := z.printRoutes
+	z.mu.RUnlock()
+	if !enabled || os.Getenv(EnvPrintRoutes) == "" {
+		return nil
+	}
+	if err := z.writeRouteTable(os.Stdout); err != nil {
+		return err
+	}
+	return ErrRoutesPrinted
+}
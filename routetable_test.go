@@ -0,0 +1,58 @@
+package zeno
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZeno_RouteTable(t *testing.T) {
+	z := New()
+	api := z.Group("/api")
+	api.Post("/users", listUsers)
+	api.Get("/users/{id}", listUsers).Name("user").Tags("users")
+	api.Get("/users", listUsers)
+
+	table := z.RouteTable()
+	assert.Len(t, table, 3)
+	assert.Equal(t, RouteTableEntry{Method: MethodGet, Path: "/api/users", Name: "/api/users",
+		Handlers: []string{"github.com/Abhishek2010dev/zeno.listUsers"}}, table[0])
+	assert.Equal(t, MethodPost, table[1].Method)
+	assert.Equal(t, "user", table[2].Name)
+	assert.Equal(t, []string{"users"}, table[2].Tags)
+}
+
+// TestZeno_PrintRoutes runs the test binary itself with EnvPrintRoutes set,
+// so Run prints the routes instead of serving.
+func TestZeno_PrintRoutes(t *testing.T) {
+	if os.Getenv(EnvPrintRoutes) != "" {
+		z := New()
+		z.PrintRoutesOnRequest()
+		z.Get("/ping", listUsers)
+		if err := z.Run("127.0.0.1:0"); !errors.Is(err, ErrRoutesPrinted) {
+			os.Exit(3)
+		}
+		os.Exit(0)
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestZeno_PrintRoutes$")
+	cmd.Env = append(os.Environ(), EnvPrintRoutes+"=1")
+	out, err := cmd.Output()
+	assert.NoError(t, err)
+
+	var table []RouteTableEntry
+	assert.NoError(t, json.Unmarshal(out, &table))
+	if assert.Len(t, table, 1) {
+		assert.Equal(t, "/ping", table[0].Path)
+	}
+}
+
+func TestZeno_PrintRoutesRequiresOptIn(t *testing.T) {
+	t.Setenv(EnvPrintRoutes, "1")
+	z := New()
+	z.Get("/ping", listUsers)
+	assert.NoError(t, z.start())
+}
@@ -0,0 +1,369 @@
+// Package rpc exposes unary RPC endpoints through zeno routes using the
+// Connect protocol and gRPC-Web, so a single server can answer REST and RPC
+// clients on the same port.
+//
+// Procedures are registered as ordinary POST routes named after the RPC,
+// e.g. "/greet.v1.GreetService/Greet". The wire protocol is detected from
+// the Content-Type of each request:
+//
+//	application/json, application/proto         Connect unary
+//	application/grpc-web[+proto|+json]          gRPC-Web
+//	application/grpc-web-text[+proto|+json]     gRPC-Web, base64 encoded
+//
+// Message encoding is pluggable through Codec. JSON is built in; register a
+// protobuf codec (named "proto") to serve binary clients.
+package rpc
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Abhishek2010dev/zeno"
+)
+
+// Codec marshals and unmarshals RPC messages. Name is the codec's suffix
+// in content types, e.g. "json" or "proto".
+type Codec interface {
+	Name() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSON is the built-in Codec using encoding/json.
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                       { return "json" }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// Code is a canonical RPC status code shared by gRPC and Connect.
+type Code int
+
+// The canonical RPC status codes.
+const (
+	CodeOK                 Code = 0
+	CodeCanceled           Code = 1
+	CodeUnknown            Code = 2
+	CodeInvalidArgument    Code = 3
+	CodeDeadlineExceeded   Code = 4
+	CodeNotFound           Code = 5
+	CodeAlreadyExists      Code = 6
+	CodePermissionDenied   Code = 7
+	CodeResourceExhausted  Code = 8
+	CodeFailedPrecondition Code = 9
+	CodeAborted            Code = 10
+	CodeOutOfRange         Code = 11
+	CodeUnimplemented      Code = 12
+	CodeInternal           Code = 13
+	CodeUnavailable        Code = 14
+	CodeDataLoss           Code = 15
+	CodeUnauthenticated    Code = 16
+)
+
+var codeNames = [...]string{
+	"ok", "canceled", "unknown", "invalid_argument", "deadline_exceeded",
+	"not_found", "already_exists", "permission_denied", "resource_exhausted",
+	"failed_precondition", "aborted", "out_of_range", "unimplemented",
+	"internal", "unavailable", "data_loss", "unauthenticated",
+}
+
+// String returns the Connect name of the code, e.g. "not_found".
+func (c Code) String() string {
+	if c >= 0 && int(c) < len(codeNames) {
+		return codeNames[c]
+	}
+	return "code_" + strconv.Itoa(int(c))
+}
+
+// httpStatus maps c to the HTTP status used by the Connect protocol.
+func (c Code) httpStatus() int {
+	switch c {
+	case CodeCanceled:
+		return 499
+	case CodeInvalidArgument, CodeFailedPrecondition, CodeOutOfRange:
+		return zeno.StatusBadRequest
+	case CodeDeadlineExceeded:
+		return zeno.StatusGatewayTimeout
+	case CodeNotFound:
+		return zeno.StatusNotFound
+	case CodeAlreadyExists, CodeAborted:
+		return zeno.StatusConflict
+	case CodePermissionDenied:
+		return zeno.StatusForbidden
+	case CodeResourceExhausted:
+		return zeno.StatusTooManyRequests
+	case CodeUnimplemented:
+		return zeno.StatusNotImplemented
+	case CodeUnavailable:
+		return zeno.StatusServiceUnavailable
+	case CodeUnauthenticated:
+		return zeno.StatusUnauthorized
+	default:
+		return zeno.StatusInternalServerError
+	}
+}
+
+// codeForStatus maps an HTTP status, e.g. from a zeno.HTTPError, to a code.
+func codeForStatus(status int) Code {
+	switch status {
+	case zeno.StatusBadRequest:
+		return CodeInvalidArgument
+	case zeno.StatusUnauthorized:
+		return CodeUnauthenticated
+	case zeno.StatusForbidden:
+		return CodePermissionDenied
+	case zeno.StatusNotFound:
+		return CodeNotFound
+	case zeno.StatusConflict:
+		return CodeAlreadyExists
+	case zeno.StatusPreconditionFailed:
+		return CodeFailedPrecondition
+	case zeno.StatusTooManyRequests:
+		return CodeResourceExhausted
+	case zeno.StatusNotImplemented:
+		return CodeUnimplemented
+	case zeno.StatusServiceUnavailable:
+		return CodeUnavailable
+	case zeno.StatusGatewayTimeout:
+		return CodeDeadlineExceeded
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeUnknown
+}
+
+// Error is an RPC error carrying a status code. Handlers return it to
+// control the code seen by clients; other errors are reported as unknown,
+// except zeno.HTTPErrors whose status is mapped to the closest code.
+type Error struct {
+	Code    Code
+	Message string
+}
+
+// NewError returns an *Error with the given code and message.
+func NewError(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Code.String() + ": " + e.Message
+}
+
+// asError converts any error into an *Error.
+func asError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	var he zeno.HTTPError
+	if errors.As(err, &he) {
+		return &Error{Code: codeForStatus(he.StatusCode()), Message: he.Error()}
+	}
+	return &Error{Code: CodeUnknown, Message: err.Error()}
+}
+
+// trailersKey is the Context key under which SetTrailer stores trailers.
+const trailersKey = "zeno.rpc.trailers"
+
+// SetTrailer adds a trailing metadata entry to the response of the current
+// RPC. Connect transports it as a "Trailer-" prefixed header, gRPC-Web in
+// the trailer frame.
+func SetTrailer(c *zeno.Context, key, value string) {
+	t, _ := c.Get(trailersKey).(map[string]string)
+	if t == nil {
+		t = map[string]string{}
+		c.Set(trailersKey, t)
+	}
+	t[strings.ToLower(key)] = value
+}
+
+// trailers returns the trailers set with SetTrailer in key order.
+func trailers(c *zeno.Context) [][2]string {
+	t, _ := c.Get(trailersKey).(map[string]string)
+	out := make([][2]string, 0, len(t))
+	for k, v := range t {
+		out = append(out, [2]string{k, v})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i][0] < out[j][0] })
+	return out
+}
+
+// Unary returns a zeno.Handler serving a unary RPC implemented by fn over
+// both Connect and gRPC-Web. codecs lists the accepted message encodings
+// and defaults to JSON. Requests with an unsupported Content-Type are
+// answered with 415 Unsupported Media Type.
+//
+// Example:
+//
+//	app.Post("/greet.v1.GreetService/Greet", rpc.Unary(
+//	    func(c *zeno.Context, req *GreetRequest) (*GreetResponse, error) {
+//	        if req.Name == "" {
+//	            return nil, rpc.NewError(rpc.CodeInvalidArgument, "name is required")
+//	        }
+//	        return &GreetResponse{Greeting: "Hello, " + req.Name}, nil
+//	    },
+//	))
+func Unary[Req, Resp any](fn func(c *zeno.Context, req *Req) (*Resp, error), codecs ...Codec) zeno.Handler {
+	if len(codecs) == 0 {
+		codecs = []Codec{JSON}
+	}
+	byName := make(map[string]Codec, len(codecs))
+	for _, codec := range codecs {
+		byName[codec.Name()] = codec
+	}
+
+	call := func(c *zeno.Context, codec Codec, payload []byte) ([]byte, *Error) {
+		var req Req
+		if err := codec.Unmarshal(payload, &req); err != nil {
+			return nil, NewError(CodeInvalidArgument, "unmarshal request: "+err.Error())
+		}
+		resp, err := fn(c, &req)
+		if err != nil {
+			return nil, asError(err)
+		}
+		if resp == nil {
+			resp = new(Resp)
+		}
+		out, err := codec.Marshal(resp)
+		if err != nil {
+			return nil, NewError(CodeInternal, "marshal response: "+err.Error())
+		}
+		return out, nil
+	}
+
+	return func(c *zeno.Context) error {
+		mt, _, err := mime.ParseMediaType(c.GetHeader(zeno.HeaderContentType))
+		if err != nil || !strings.HasPrefix(mt, "application/") {
+			return zeno.ErrUnsupportedMediaType
+		}
+		if sub, ok := strings.CutPrefix(mt, "application/grpc-web"); ok {
+			text := false
+			if rest, ok := strings.CutPrefix(sub, "-text"); ok {
+				text, sub = true, rest
+			}
+			name := "proto"
+			if sub != "" {
+				if !strings.HasPrefix(sub, "+") {
+					return zeno.ErrUnsupportedMediaType
+				}
+				name = sub[1:]
+			}
+			codec, ok := byName[name]
+			if !ok {
+				return zeno.ErrUnsupportedMediaType
+			}
+			return serveGRPCWeb(c, mt, text, codec, call)
+		}
+		codec, ok := byName[strings.TrimPrefix(mt, "application/")]
+		if !ok {
+			return zeno.ErrUnsupportedMediaType
+		}
+		return serveConnect(c, mt, codec, call)
+	}
+}
+
+// serveConnect answers a Connect unary request.
+func serveConnect(c *zeno.Context, mt string, codec Codec, call func(*zeno.Context, Codec, []byte) ([]byte, *Error)) error {
+	var out []byte
+	rerr := errUnsupportedEncoding(c.GetHeader(zeno.HeaderContentEncoding))
+	if rerr == nil {
+		out, rerr = call(c, codec, c.PostBody())
+	}
+	for _, t := range trailers(c) {
+		c.SetHeader("Trailer-"+t[0], t[1])
+	}
+	if rerr != nil {
+		body, _ := json.Marshal(struct {
+			Code    string `json:"code"`
+			Message string `json:"message,omitempty"`
+		}{rerr.Code.String(), rerr.Message})
+		c.Status(rerr.Code.httpStatus())
+		c.SetContentType("application/json")
+		return c.SendBytes(body)
+	}
+	c.Status(zeno.StatusOK)
+	c.SetContentType(mt)
+	return c.SendBytes(out)
+}
+
+// errUnsupportedEncoding rejects compressed request bodies, which are not
+// supported.
+func errUnsupportedEncoding(encoding string) *Error {
+	if encoding == "" || encoding == "identity" {
+		return nil
+	}
+	return NewError(CodeUnimplemented, fmt.Sprintf("unsupported compression %q", encoding))
+}
+
+// Envelope flags used by gRPC-Web framing.
+const (
+	flagCompressed = 0x01
+	flagTrailer    = 0x80
+)
+
+// serveGRPCWeb answers a gRPC-Web request. Responses always use status 200
+// and report the RPC status in the trailer frame.
+func serveGRPCWeb(c *zeno.Context, mt string, text bool, codec Codec, call func(*zeno.Context, Codec, []byte) ([]byte, *Error)) error {
+	body := c.PostBody()
+	var rerr *Error
+	if text {
+		decoded, err := base64.StdEncoding.DecodeString(string(body))
+		if err != nil {
+			rerr = NewError(CodeInvalidArgument, "invalid base64 body")
+		}
+		body = decoded
+	}
+
+	var out []byte
+	if rerr == nil {
+		switch {
+		case len(body) < 5 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5:
+			rerr = NewError(CodeInvalidArgument, "malformed message envelope")
+		case body[0]&flagCompressed != 0:
+			rerr = NewError(CodeUnimplemented, "compressed messages are not supported")
+		default:
+			out, rerr = call(c, codec, body[5:])
+		}
+	}
+
+	var frames []byte
+	if rerr == nil {
+		frames = appendFrame(frames, 0, out)
+	}
+	var tr strings.Builder
+	if rerr == nil {
+		tr.WriteString("grpc-status: 0\r\n")
+	} else {
+		fmt.Fprintf(&tr, "grpc-status: %d\r\ngrpc-message: %s\r\n", rerr.Code, url.PathEscape(rerr.Message))
+	}
+	for _, t := range trailers(c) {
+		fmt.Fprintf(&tr, "%s: %s\r\n", t[0], t[1])
+	}
+	frames = appendFrame(frames, flagTrailer, []byte(tr.String()))
+
+	if text {
+		frames = []byte(base64.StdEncoding.EncodeToString(frames))
+	}
+	c.Status(zeno.StatusOK)
+	c.SetContentType(mt)
+	return c.SendBytes(frames)
+}
+
+// appendFrame appends a gRPC-Web length-prefixed frame to b.
+func appendFrame(b []byte, flags byte, payload []byte) []byte {
+	b = append(b, flags)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(payload)))
+	return append(b, payload...)
+}
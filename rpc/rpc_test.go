@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+type greetResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+func newApp() *zeno.Zeno {
+	z := zeno.New()
+	z.Post("/greet.v1.GreetService/Greet", Unary(func(c *zeno.Context, req *greetRequest) (*greetResponse, error) {
+		if req.Name == "" {
+			return nil, NewError(CodeInvalidArgument, "name is required")
+		}
+		SetTrailer(c, "X-Served-By", "zeno")
+		return &greetResponse{Greeting: "Hello, " + req.Name}, nil
+	}))
+	return z
+}
+
+func do(z *zeno.Zeno, contentType string, body []byte) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.SetRequestURI("/greet.v1.GreetService/Greet")
+	ctx.Request.Header.SetContentType(contentType)
+	ctx.Request.SetBody(body)
+	z.HandleRequest(ctx)
+	return ctx
+}
+
+func TestUnary_Connect(t *testing.T) {
+	z := newApp()
+
+	ctx := do(z, "application/json", []byte(`{"name":"Ada"}`))
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"greeting":"Hello, Ada"}`, string(ctx.Response.Body()))
+	assert.Equal(t, "zeno", string(ctx.Response.Header.Peek("Trailer-x-served-by")))
+
+	ctx = do(z, "application/json", []byte(`{}`))
+	assert.Equal(t, 400, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"code":"invalid_argument","message":"name is required"}`, string(ctx.Response.Body()))
+
+	ctx = do(z, "application/proto", nil)
+	assert.Equal(t, zeno.StatusUnsupportedMediaType, ctx.Response.StatusCode())
+}
+
+func TestUnary_GRPCWeb(t *testing.T) {
+	z := newApp()
+
+	msg := appendFrame(nil, 0, []byte(`{"name":"Ada"}`))
+	ctx := do(z, "application/grpc-web+json", msg)
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	want := appendFrame(nil, 0, []byte(`{"greeting":"Hello, Ada"}`))
+	want = appendFrame(want, flagTrailer, []byte("grpc-status: 0\r\nx-served-by: zeno\r\n"))
+	assert.Equal(t, want, ctx.Response.Body())
+
+	msg = appendFrame(nil, 0, []byte(`{}`))
+	ctx = do(z, "application/grpc-web-text+json", []byte(base64.StdEncoding.EncodeToString(msg)))
+	body, err := base64.StdEncoding.DecodeString(string(ctx.Response.Body()))
+	assert.NoError(t, err)
+	want = appendFrame(nil, flagTrailer, []byte("grpc-status: 3\r\ngrpc-message: name%20is%20required\r\n"))
+	assert.Equal(t, want, body)
+}
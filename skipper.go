@@ -0,0 +1,66 @@
+package zeno
+
+import "strings"
+
+// Skipper reports whether a middleware should be bypassed for the current
+// request. Middleware configurations expose it as a Skipper field, and
+// Unless and Only apply one to any middleware.
+type Skipper func(c *Context) bool
+
+// Unless wraps middleware so that it is bypassed, continuing with the rest
+// of the chain, for requests where skip returns true.
+//
+// Example:
+//
+//	app.Use(zeno.Unless(zeno.PathIs("/health", "/metrics"), auth))
+func Unless(skip Skipper, middleware Handler) Handler {
+	return func(c *Context) error {
+		if skip(c) {
+			return c.Next()
+		}
+		return middleware(c)
+	}
+}
+
+// Only wraps middleware so that it runs solely for requests where match
+// returns true; all other requests continue with the rest of the chain.
+//
+// Example:
+//
+//	app.Use(zeno.Only(zeno.PathPrefix("/admin/"), requireAdmin))
+func Only(match Skipper, middleware Handler) Handler {
+	return func(c *Context) error {
+		if !match(c) {
+			return c.Next()
+		}
+		return middleware(c)
+	}
+}
+
+// PathIs returns a Skipper matching requests whose path equals one of
+// paths.
+func PathIs(paths ...string) Skipper {
+	return func(c *Context) bool {
+		p := c.Path()
+		for _, path := range paths {
+			if p == path {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// PathPrefix returns a Skipper matching requests whose path starts with
+// one of prefixes.
+func PathPrefix(prefixes ...string) Skipper {
+	return func(c *Context) bool {
+		p := c.Path()
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(p, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
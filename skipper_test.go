@@ -0,0 +1,27 @@
+package zeno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnlessOnly(t *testing.T) {
+	deny := func(c *Context) error { return ErrForbidden }
+	z := New()
+	z.Use(Unless(PathIs("/health"), deny))
+	z.Get("/health", func(c *Context) error { return c.SendString("ok") })
+	z.Get("/data", func(c *Context) error { return c.SendString("data") })
+
+	assert.Equal(t, StatusOK, performRequest(z, "GET", "/health", nil, nil).Response.StatusCode())
+	assert.Equal(t, StatusForbidden, performRequest(z, "GET", "/data", nil, nil).Response.StatusCode())
+
+	z = New()
+	z.Use(Only(PathPrefix("/admin/"), deny))
+	z.Get("/admin/users", func(c *Context) error { return c.SendString("users") })
+	z.Get("/public", func(c *Context) error { return c.SendString("public") })
+
+	assert.Equal(t, StatusForbidden, performRequest(z, "GET", "/admin/users", nil, nil).Response.StatusCode())
+	ctx := performRequest(z, "GET", "/public", nil, nil)
+	assert.Equal(t, "public", string(ctx.Response.Body()))
+}
@@ -0,0 +1,80 @@
+package zeno
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SPAConfig configures Zeno.SPA.
+type SPAConfig struct {
+	// Index is the file served for paths that do not match an asset.
+	// Defaults to "index.html".
+	Index string
+
+	// Exclude lists path prefixes, such as "/api/", that must never fall
+	// back to the index file. Unmatched requests below them get a 404.
+	Exclude []string
+}
+
+// SPA serves a single-page application from root under prefix.
+//
+// Requests for existing files (scripts, styles, images) are served as-is.
+// Any other GET or HEAD request below prefix receives the index file, so
+// client-side routes such as /settings/profile work on reload. Paths
+// listed in SPAConfig.Exclude keep returning 404 to avoid masking API
+// typos with HTML.
+//
+// Files are served with Context.SendFile, so when Zeno.FS is set root
+// names a directory in it rather than on disk.
+//
+// The files are served as the not-found chain of prefix, replacing one
+// set with RouteGroup.NotFound, so the application's routes take priority
+// wherever they are registered, and requests with other methods are
+// answered with 404 Not Found, or 405 Method Not Allowed for paths that
+// have routes, as without SPA.
+//
+// Example:
+//
+//	app.Get("/api/users", listUsers)
+//	app.SPA("/", "./dist", zeno.SPAConfig{Exclude: []string{"/api/"}})
+func (z *Zeno) SPA(prefix, root string, config ...SPAConfig) {
+	var cfg SPAConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Index == "" {
+		cfg.Index = "index.html"
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	index := path.Join(filepath.ToSlash(root), cfg.Index)
+
+	handler := func(c *Context) error {
+		if m := c.Method(); m != MethodGet && m != MethodHead {
+			return ErrNotFound
+		}
+		p := c.Path()
+		for _, ex := range cfg.Exclude {
+			if strings.HasPrefix(p, ex) {
+				return ErrNotFound
+			}
+		}
+		name := path.Join(filepath.ToSlash(root), path.Clean("/"+strings.TrimPrefix(p, prefix)))
+		if fi, err := z.statFile(name); err == nil && !fi.IsDir() {
+			return c.SendFile(name)
+		}
+		return c.SendFile(index)
+	}
+	z.Group(prefix).NotFound(handler)
+}
+
+// statFile stats the slash-separated name where SendFile would read it:
+// in z.FS if set, on disk otherwise.
+func (z *Zeno) statFile(name string) (fs.FileInfo, error) {
+	if z.FS != nil {
+		return fs.Stat(z.FS, strings.TrimPrefix(path.Clean("/"+name), "/"))
+	}
+	return os.Stat(filepath.FromSlash(name))
+}
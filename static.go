@@ -0,0 +1,390 @@
+package zeno
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// StaticConfig configures Static and StaticFS.
+type StaticConfig struct {
+	// Index is the file served for requests that resolve to a directory.
+	// Defaults to "index.html".
+	Index string
+
+	// MaxAge sets a "public, max-age" Cache-Control header on served
+	// files. No Cache-Control header is sent when zero.
+	MaxAge time.Duration
+
+	// Compress makes StaticFS store gzip and brotli encoded copies of
+	// compressible assets at registration time, served to clients that
+	// accept them. To precompress a directory on disk into memory, pass
+	// os.DirFS(root) to StaticFS.
+	Compress bool
+
+	// Precompressed makes Static look for ".br" and ".gz" sidecar files
+	// next to each requested file, as produced by build tools, and send
+	// them with the matching Content-Encoding to clients that accept it.
+	Precompressed bool
+
+	// Browse enables directory listings for directories without an index
+	// file. Entries can be sorted with the "sort" (name, size or modtime)
+	// and "order" (asc or desc) query parameters.
+	Browse bool
+
+	// ShowHidden includes entries whose name starts with a dot in
+	// directory listings.
+	ShowHidden bool
+
+	// Listing renders directory listings. It defaults to an HTML page, or
+	// JSON for clients that prefer application/json.
+	Listing func(c *Context, listing DirListing) error
+}
+
+// DirListing describes a directory for StaticConfig.Listing.
+type DirListing struct {
+	// Path is the request path of the directory, ending in a slash.
+	Path    string     `json:"path"`
+	Entries []DirEntry `json:"entries"`
+}
+
+// DirEntry is a single file or directory in a DirListing.
+type DirEntry struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	IsDir   bool      `json:"isDir"`
+}
+
+// sidecarEncodings lists the sidecar suffixes checked by Static, in order
+// of preference.
+var sidecarEncodings = []struct{ encoding, suffix string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// staticConfig returns a copy of the first element of config with defaults
+// applied.
+func staticConfig(config []StaticConfig) StaticConfig {
+	var cfg StaticConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Index == "" {
+		cfg.Index = "index.html"
+	}
+	return cfg
+}
+
+// cacheControl returns the Cache-Control value for cfg, or "".
+func (cfg StaticConfig) cacheControl() string {
+	if cfg.MaxAge <= 0 {
+		return ""
+	}
+	return "public, max-age=" + strconv.Itoa(int(cfg.MaxAge.Seconds()))
+}
+
+// Static serves the files below the directory root under prefix for GET
+// and HEAD requests. Requests for a directory serve its index file, and
+// paths that would escape root are rejected with 404. With
+// StaticConfig.Precompressed, ".br" and ".gz" sidecar files are preferred
+// over compressing on the fly.
+//
+// Example:
+//
+//	app.Static("/assets", "./public", zeno.StaticConfig{MaxAge: 24 * time.Hour})
+func (r *RouteGroup) Static(prefix, root string, config ...StaticConfig) *Route {
+	cfg := staticConfig(config)
+	cc := cfg.cacheControl()
+	handler := func(c *Context) error {
+		name := filepath.Join(root, filepath.FromSlash(path.Clean("/"+c.Param("path"))))
+		fi, err := os.Stat(name)
+		if err == nil && fi.IsDir() {
+			dir := name
+			name = filepath.Join(dir, cfg.Index)
+			fi, err = os.Stat(name)
+			if err != nil && cfg.Browse {
+				return c.serveListing(os.DirFS(dir), ".", cfg)
+			}
+		}
+		if err != nil || fi.IsDir() {
+			return ErrNotFound
+		}
+		if cc != "" {
+			c.SetHeader(HeaderCacheControl, cc)
+		}
+		if cfg.Precompressed {
+			c.Vary(HeaderAcceptEncoding)
+			for _, sc := range sidecarEncodings {
+				if !c.ctx.Request.Header.HasAcceptEncoding(sc.encoding) {
+					continue
+				}
+				if sfi, err := os.Stat(name + sc.suffix); err == nil && !sfi.IsDir() {
+					return c.sendSidecar(name, name+sc.suffix, sc.encoding)
+				}
+			}
+		}
+		c.sendOSFile(name)
+		return nil
+	}
+	return newRoute(strings.TrimSuffix(prefix, "/")+"/{path*}", r).Get(handler).Head(handler)
+}
+
+// sendSidecar sends the precompressed file sidecar in place of name, with
+// the Content-Type of name and the given Content-Encoding.
+func (c *Context) sendSidecar(name, sidecar, encoding string) error {
+	c.sendOSFile(sidecar)
+	ct := mime.TypeByExtension(filepath.Ext(name))
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	c.SetContentType(ct)
+	c.SetHeader(HeaderContentEncoding, encoding)
+	return nil
+}
+
+// serveListing renders the directory dir of fsys according to cfg.
+func (c *Context) serveListing(fsys fs.FS, dir string, cfg StaticConfig) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return ErrNotFound
+	}
+	listing := DirListing{Path: c.Path(), Entries: make([]DirEntry, 0, len(entries))}
+	if !strings.HasSuffix(listing.Path, "/") {
+		listing.Path += "/"
+	}
+	for _, e := range entries {
+		if !cfg.ShowHidden && strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		listing.Entries = append(listing.Entries, DirEntry{
+			Name:    e.Name(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			IsDir:   e.IsDir(),
+		})
+	}
+	sortDirEntries(listing.Entries, c.Query("sort"), c.Query("order") == "desc")
+
+	if cfg.Listing != nil {
+		return cfg.Listing(c, listing)
+	}
+	if c.Accepts("text/html", "application/json") == "application/json" {
+		return c.SendJSON(listing)
+	}
+	var buf bytes.Buffer
+	if err := listingTemplate.Execute(&buf, listing); err != nil {
+		return err
+	}
+	return c.SendHTML(buf.String())
+}
+
+// sortDirEntries orders entries by key ("name", "size" or "modtime"),
+// listing directories first.
+func sortDirEntries(entries []DirEntry, key string, desc bool) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		var less bool
+		switch key {
+		case "size":
+			less = a.Size < b.Size
+		case "modtime":
+			less = a.ModTime.Before(b.ModTime)
+		default:
+			less = a.Name < b.Name
+		}
+		if desc {
+			return !less
+		}
+		return less
+	})
+}
+
+// listingTemplate is the default HTML directory listing.
+var listingTemplate = template.Must(template.New("listing").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Index of {{.Path}}</title></head>
+<body>
+<h1>Index of {{.Path}}</h1>
+<table>
+<tr><th><a href="?sort=name">Name</a></th><th><a href="?sort=size">Size</a></th><th><a href="?sort=modtime">Modified</a></th></tr>
+{{if ne .Path "/"}}<tr><td><a href="../">../</a></td><td></td><td></td></tr>
+{{end}}{{range .Entries}}<tr><td><a href="{{.Name}}{{if .IsDir}}/{{end}}">{{.Name}}{{if .IsDir}}/{{end}}</a></td><td>{{if not .IsDir}}{{.Size}}{{end}}</td><td>{{.ModTime.Format "2006-01-02 15:04"}}</td></tr>
+{{end}}</table>
+</body>
+</html>`))
+
+// staticAsset is a file of a StaticFS tree held in memory.
+type staticAsset struct {
+	contentType string
+	etag        string
+	data        []byte
+	gzip        []byte
+	brotli      []byte
+}
+
+// StaticFS serves the files of fsys under prefix, which makes it possible
+// to ship a UI inside the binary with go:embed. Every file is read into
+// memory when StaticFS is called and given a strong ETag derived from its
+// content, so unchanged assets are answered with 304 Not Modified. With
+// StaticConfig.Compress, compressible assets are also stored gzip and
+// brotli encoded and sent according to Accept-Encoding, each encoding
+// under an ETag of its own.
+//
+// It panics if fsys cannot be read, so broken embeds surface at startup.
+//
+// Example:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	ui, _ := fs.Sub(dist, "dist")
+//	app.StaticFS("/", ui, zeno.StaticConfig{Compress: true})
+func (r *RouteGroup) StaticFS(prefix string, fsys fs.FS, config ...StaticConfig) *Route {
+	cfg := staticConfig(config)
+	assets := map[string]*staticAsset{}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		assets[name] = newStaticAsset(name, data, cfg.Compress)
+		return nil
+	})
+	if err != nil {
+		panic("zeno: cannot read static files: " + err.Error())
+	}
+
+	cc := cfg.cacheControl()
+	handler := func(c *Context) error {
+		name := strings.TrimPrefix(path.Clean("/"+c.Param("path")), "/")
+		a := assets[name]
+		if a == nil {
+			a = assets[path.Join(name, cfg.Index)]
+		}
+		if a == nil {
+			if name == "" {
+				name = "."
+			}
+			if fi, err := fs.Stat(fsys, name); cfg.Browse && err == nil && fi.IsDir() {
+				return c.serveListing(fsys, name, cfg)
+			}
+			return ErrNotFound
+		}
+		return a.serve(c, cc)
+	}
+	return newRoute(strings.TrimSuffix(prefix, "/")+"/{path*}", r).Get(handler).Head(handler)
+}
+
+// newStaticAsset prepares data for serving, optionally precompressing it.
+func newStaticAsset(name string, data []byte, compress bool) *staticAsset {
+	sum := sha256.Sum256(data)
+	a := &staticAsset{
+		contentType: mime.TypeByExtension(path.Ext(name)),
+		etag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+		data:        data,
+	}
+	if a.contentType == "" {
+		a.contentType = http.DetectContentType(data)
+	}
+	if compress && isCompressible(a.contentType) && len(data) >= 256 {
+		if gz := fasthttp.AppendGzipBytesLevel(nil, data, fasthttp.CompressBestCompression); len(gz) < len(data) {
+			a.gzip = gz
+		}
+		if br := fasthttp.AppendBrotliBytesLevel(nil, data, fasthttp.CompressBrotliBestCompression); len(br) < len(data) {
+			a.brotli = br
+		}
+	}
+	return a
+}
+
+// isCompressible reports whether content of type ct benefits from
+// compression.
+func isCompressible(ct string) bool {
+	ct, _, _ = strings.Cut(ct, ";")
+	switch {
+	case strings.HasPrefix(ct, "text/"),
+		strings.HasSuffix(ct, "+json"), strings.HasSuffix(ct, "+xml"):
+		return true
+	}
+	switch ct {
+	case "application/javascript", "application/json", "application/xml",
+		"application/wasm", "image/svg+xml", "application/manifest+json":
+		return true
+	}
+	return false
+}
+
+// serve writes a to the response, honouring conditional request headers
+// and Accept-Encoding. Each encoding gets its own ETag, suffixed with the
+// encoding, since the bodies differ byte for byte.
+func (a *staticAsset) serve(c *Context, cacheControl string) error {
+	body, etag, encoding := a.data, a.etag, ""
+	switch {
+	case a.brotli != nil && c.ctx.Request.Header.HasAcceptEncoding("br"):
+		body, encoding = a.brotli, "br"
+	case a.gzip != nil && c.ctx.Request.Header.HasAcceptEncoding("gzip"):
+		body, encoding = a.gzip, "gzip"
+	}
+	if encoding != "" {
+		etag = strings.TrimSuffix(a.etag, `"`) + "-" + encoding + `"`
+	}
+
+	c.SetHeader(HeaderETag, etag)
+	if cacheControl != "" {
+		c.SetHeader(HeaderCacheControl, cacheControl)
+	}
+	if a.gzip != nil || a.brotli != nil {
+		c.Vary(HeaderAcceptEncoding)
+	}
+	if c.checkPreconditions(etag, time.Time{}) {
+		return nil
+	}
+
+	if encoding != "" {
+		c.SetHeader(HeaderContentEncoding, encoding)
+	}
+	c.SetContentType(a.contentType)
+	return c.SendBytes(body)
+}
+
+// etagMatches reports whether the If-None-Match header value matches etag,
+// using the weak comparison required for If-None-Match.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
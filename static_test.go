@@ -0,0 +1,132 @@
+package zeno
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticFS(t *testing.T) {
+	js := strings.Repeat("console.log('zeno');\n", 100)
+	z := New()
+	z.StaticFS("/ui", fstest.MapFS{
+		"index.html": {Data: []byte("<h1>home</h1>")},
+		"app.js":     {Data: []byte(js)},
+	}, StaticConfig{Compress: true, MaxAge: time.Hour})
+
+	ctx := performRequest(z, "GET", "/ui/", nil, nil)
+	assert.Equal(t, "<h1>home</h1>", string(ctx.Response.Body()))
+	assert.Equal(t, "text/html; charset=utf-8", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, "public, max-age=3600", string(ctx.Response.Header.Peek(HeaderCacheControl)))
+
+	ctx = performRequest(z, "GET", "/ui/app.js", map[string]string{HeaderAcceptEncoding: "gzip, br"}, nil)
+	assert.Equal(t, "br", string(ctx.Response.Header.Peek(HeaderContentEncoding)))
+	assert.Less(t, len(ctx.Response.Body()), len(js))
+	assert.Equal(t, HeaderAcceptEncoding, string(ctx.Response.Header.Peek(HeaderVary)))
+	body, err := ctx.Response.BodyUnbrotli()
+	assert.NoError(t, err)
+	assert.Equal(t, js, string(body))
+
+	etag := string(ctx.Response.Header.Peek(HeaderETag))
+	assert.True(t, strings.HasSuffix(etag, `-br"`))
+	ctx = performRequest(z, "GET", "/ui/app.js", map[string]string{HeaderIfNoneMatch: etag, HeaderAcceptEncoding: "br"}, nil)
+	assert.Equal(t, StatusNotModified, ctx.Response.StatusCode())
+
+	ctx = performRequest(z, "GET", "/ui/app.js", map[string]string{HeaderIfNoneMatch: etag}, nil)
+	assert.Equal(t, js, string(ctx.Response.Body()))
+	identity := string(ctx.Response.Header.Peek(HeaderETag))
+	assert.Equal(t, strings.TrimSuffix(etag, `-br"`)+`"`, identity)
+
+	ctx = performRequest(z, "GET", "/ui/app.js", map[string]string{HeaderAcceptEncoding: "gzip"}, nil)
+	assert.Equal(t, "gzip", string(ctx.Response.Header.Peek(HeaderContentEncoding)))
+	assert.NotEqual(t, identity, string(ctx.Response.Header.Peek(HeaderETag)))
+	assert.NotEqual(t, etag, string(ctx.Response.Header.Peek(HeaderETag)))
+
+	ctx = performRequest(z, "GET", "/ui/missing.css", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+}
+
+func TestStatic(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("index"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644))
+
+	z := New()
+	z.Static("/files", dir)
+
+	ctx := performRequest(z, "GET", "/files/a.txt", nil, nil)
+	assert.Equal(t, "a", string(ctx.Response.Body()))
+	ctx = performRequest(z, "GET", "/files/", nil, nil)
+	assert.Equal(t, "index", string(ctx.Response.Body()))
+	ctx = performRequest(z, "GET", "/files/../static_test.go", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+}
+
+func TestStatic_Precompressed(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.js"), []byte("plain"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.js.gz"), []byte("gzipped"), 0o644))
+
+	z := New()
+	z.Static("/", dir, StaticConfig{Precompressed: true})
+
+	ctx := performRequest(z, "GET", "/app.js", map[string]string{HeaderAcceptEncoding: "br, gzip"}, nil)
+	assert.Equal(t, "gzip", string(ctx.Response.Header.Peek(HeaderContentEncoding)))
+	assert.Equal(t, "text/javascript; charset=utf-8", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, HeaderAcceptEncoding, string(ctx.Response.Header.Peek(HeaderVary)))
+	assert.Equal(t, "gzipped", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/app.js", nil, nil)
+	assert.Empty(t, ctx.Response.Header.Peek(HeaderContentEncoding))
+	assert.Equal(t, "plain", string(ctx.Response.Body()))
+}
+
+func TestStatic_Browse(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(root, "docs"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "b.txt"), []byte("bbb"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, ".secret"), []byte("x"), 0o644))
+
+	z := New()
+	z.Static("/files", root, StaticConfig{Browse: true})
+
+	ctx := performRequest(z, "GET", "/files/", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	body := string(ctx.Response.Body())
+	assert.Contains(t, body, `<a href="docs/">docs/</a>`)
+	assert.Contains(t, body, `<a href="a.txt">a.txt</a>`)
+	assert.NotContains(t, body, ".secret")
+
+	ctx = performRequest(z, "GET", "/files/?sort=size&order=desc", map[string]string{HeaderAccept: "application/json"}, nil)
+	assert.Contains(t, string(ctx.Response.Header.ContentType()), "application/json")
+	var listing DirListing
+	assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &listing))
+	assert.Equal(t, "/files/", listing.Path)
+	names := make([]string, len(listing.Entries))
+	for i, e := range listing.Entries {
+		names[i] = e.Name
+	}
+	assert.Equal(t, []string{"docs", "b.txt", "a.txt"}, names)
+
+	z = New()
+	z.StaticFS("/ui", fstest.MapFS{"css/site.css": {Data: []byte("body{}")}}, StaticConfig{
+		Browse: true,
+		Listing: func(c *Context, l DirListing) error {
+			return c.SendString(l.Path + " " + l.Entries[0].Name)
+		},
+	})
+	ctx = performRequest(z, "GET", "/ui/css", nil, nil)
+	assert.Equal(t, "/ui/css/ site.css", string(ctx.Response.Body()))
+
+	z = New()
+	z.Static("/files", root)
+	ctx = performRequest(z, "GET", "/files/", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+}
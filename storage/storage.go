@@ -0,0 +1,224 @@
+// Package storage serves files kept outside the local file system, such as
+// objects in S3, GCS or MinIO, with the same range, conditional request
+// and caching support zeno gives local files. A Backend abstracts the
+// store; FS adapts an fs.FS and HTTPBackend speaks to object stores over
+// HTTP. Send answers a single request with an object and Static mounts a
+// whole bucket under a route prefix.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+)
+
+// Object describes a stored object.
+type Object struct {
+	Key string
+
+	// Size is the length of the object in bytes.
+	Size int64
+
+	// ModTime is the last modification time, or the zero time if unknown.
+	ModTime time.Time
+
+	// ETag is the entity tag reported by the store, including its quotes,
+	// or "" to derive one from Size and ModTime.
+	ETag string
+
+	// ContentType is the stored media type, or "" to derive it from the
+	// key's extension.
+	ContentType string
+}
+
+// Backend gives access to the objects of a store. Errors for missing
+// objects must match fs.ErrNotExist with errors.Is.
+type Backend interface {
+	// Stat returns the object stored under key.
+	Stat(ctx context.Context, key string) (Object, error)
+
+	// Open returns the content of the object stored under key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// ReadRange returns length bytes of the object stored under key,
+	// starting at offset.
+	ReadRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+}
+
+// Signer is implemented by backends that can hand out temporary URLs
+// giving direct access to an object, such as S3 presigned URLs. SignURL
+// returns an error matching errors.ErrUnsupported when it cannot sign.
+type Signer interface {
+	SignURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// Config configures Send and Static.
+type Config struct {
+	// Attachment makes browsers download objects rather than display
+	// them, with Content-Disposition: attachment.
+	Attachment bool
+
+	// MaxAge sets a "public, max-age" Cache-Control header on served
+	// objects. No Cache-Control header is sent when zero.
+	MaxAge time.Duration
+
+	// Redirect, when positive and the backend implements Signer, makes
+	// clients download objects directly from the store: requests are
+	// redirected with 307 Temporary Redirect to a URL signed for this
+	// long instead of streaming the object through the application.
+	Redirect time.Duration
+}
+
+// Send answers the request with the object stored under key. Range
+// requests are forwarded to the store so only the requested bytes are
+// transferred, and conditional requests are evaluated against the
+// object's ETag and modification time. Missing objects yield 404 Not
+// Found.
+//
+// Example:
+//
+//	app.Get("/invoices/{id}", func(c *zeno.Context) error {
+//	    return storage.Send(c, bucket, "invoices/"+c.Param("id")+".pdf", storage.Config{
+//	        Attachment: true,
+//	        Redirect:   5 * time.Minute,
+//	    })
+//	})
+func Send(c *zeno.Context, b Backend, key string, config ...Config) error {
+	var cfg Config
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	// The object is streamed after the handler returns, so reads must not
+	// depend on the request's lifetime.
+	ctx := context.Background()
+	if s, ok := b.(Signer); ok && cfg.Redirect > 0 {
+		url, err := s.SignURL(ctx, key, cfg.Redirect)
+		if err == nil {
+			c.SetHeader(zeno.HeaderCacheControl, "no-store")
+			return c.Redirect(url, zeno.StatusTemporaryRedirect)
+		}
+		if !errors.Is(err, errors.ErrUnsupported) {
+			return zeno.NewHTTPError(zeno.StatusInternalServerError, "failed to sign URL: "+err.Error())
+		}
+	}
+
+	obj, err := b.Stat(ctx, key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return zeno.ErrNotFound
+	}
+	if err != nil {
+		return zeno.NewHTTPError(zeno.StatusBadGateway, "failed to stat object: "+err.Error())
+	}
+	if cfg.MaxAge > 0 {
+		c.SetHeader(zeno.HeaderCacheControl, "public, max-age="+strconv.Itoa(int(cfg.MaxAge.Seconds())))
+	}
+	r := &objectReader{ctx: ctx, b: b, key: key, size: obj.Size, start: -1}
+	if rng, err := c.Ranges(obj.Size); err == nil && len(rng.Ranges) == 1 {
+		r.start, r.end = rng.Ranges[0].Start, rng.Ranges[0].End+1
+	}
+	return c.SendContent(r, zeno.ContentInfo{
+		Name:        key,
+		ContentType: obj.ContentType,
+		ModTime:     obj.ModTime,
+		ETag:        obj.ETag,
+		Attachment:  cfg.Attachment,
+	})
+}
+
+// Static serves the objects of b under prefix for GET and HEAD requests,
+// using the request path below prefix as the key.
+//
+// Example:
+//
+//	storage.Static(&app.RouteGroup, "/media", bucket, storage.Config{MaxAge: time.Hour})
+func Static(r *zeno.RouteGroup, prefix string, b Backend, config ...Config) *zeno.Route {
+	handler := func(c *zeno.Context) error {
+		key := strings.TrimPrefix(path.Clean("/"+c.Param("path")), "/")
+		if key == "" {
+			return zeno.ErrNotFound
+		}
+		return Send(c, b, key, config...)
+	}
+	return r.Get(strings.TrimSuffix(prefix, "/")+"/{path*}", handler).Head(handler)
+}
+
+// objectReader is an io.ReadSeeker over an object. The object is only
+// read once data is requested, from the current offset, so seeking to a
+// range does not transfer the bytes before it. Reading from start only
+// fetches the bytes up to end, the range the client asked for; should
+// more be read, they are fetched with another request.
+type objectReader struct {
+	ctx        context.Context
+	b          Backend
+	key        string
+	size       int64
+	start, end int64
+	pos        int64
+	body       io.ReadCloser
+}
+
+// Read implements io.Reader.
+func (r *objectReader) Read(p []byte) (int, error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		end := r.size
+		if r.pos == r.start {
+			end = r.end
+		}
+		var err error
+		if r.pos == 0 && end == r.size {
+			r.body, err = r.b.Open(r.ctx, r.key)
+		} else {
+			r.body, err = r.b.ReadRange(r.ctx, r.key, r.pos, end-r.pos)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.body.Read(p)
+	r.pos += int64(n)
+	if err == io.EOF && r.pos < r.size {
+		// The requested range is done; the next Read fetches the rest.
+		r.body.Close()
+		r.body, err = nil, nil
+	}
+	return n, err
+}
+
+// Seek implements io.Seeker.
+func (r *objectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, errors.New("storage: negative position")
+	}
+	if offset != r.pos && r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+	r.pos = offset
+	return offset, nil
+}
+
+// Close implements io.Closer.
+func (r *objectReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func do(z *zeno.Zeno, method, uri string, headers map[string]string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	for k, v := range headers {
+		ctx.Request.Header.Set(k, v)
+	}
+	z.HandleRequest(ctx)
+	return ctx
+}
+
+func TestStatic_FS(t *testing.T) {
+	mod := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	z := zeno.New()
+	Static(&z.RouteGroup, "/media", FS(fstest.MapFS{
+		"img/logo.svg": {Data: []byte("<svg></svg>"), ModTime: mod},
+	}), Config{MaxAge: time.Hour})
+
+	ctx := do(z, "GET", "/media/img/logo.svg", nil)
+	assert.Equal(t, zeno.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "<svg></svg>", string(ctx.Response.Body()))
+	assert.Equal(t, "image/svg+xml", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, "public, max-age=3600", string(ctx.Response.Header.Peek(zeno.HeaderCacheControl)))
+	etag := string(ctx.Response.Header.Peek(zeno.HeaderETag))
+	assert.NotEmpty(t, etag)
+
+	ctx = do(z, "GET", "/media/img/logo.svg", map[string]string{zeno.HeaderRange: "bytes=1-3"})
+	assert.Equal(t, zeno.StatusPartialContent, ctx.Response.StatusCode())
+	assert.Equal(t, "svg", string(ctx.Response.Body()))
+
+	ctx = do(z, "GET", "/media/img/logo.svg", map[string]string{zeno.HeaderIfNoneMatch: etag})
+	assert.Equal(t, zeno.StatusNotModified, ctx.Response.StatusCode())
+
+	assert.Equal(t, zeno.StatusNotFound, do(z, "GET", "/media/img", nil).Response.StatusCode())
+	assert.Equal(t, zeno.StatusNotFound, do(z, "GET", "/media/missing.txt", nil).Response.StatusCode())
+}
+
+func TestSend_HTTPBackend(t *testing.T) {
+	mod := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var ranges []string
+	var signed atomic.Int32
+	bucket := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.EscapedPath() != "/bucket/reports/q%201.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			ranges = append(ranges, r.Header.Get("Range"))
+		}
+		w.Header().Set("ETag", `"abc"`)
+		http.ServeContent(w, r, "q 1.csv", mod, strings.NewReader("0123456789"))
+	}))
+	defer bucket.Close()
+
+	b := NewHTTPBackend(bucket.URL+"/bucket/", HTTPConfig{
+		Sign: func(req *http.Request) error {
+			signed.Add(1)
+			req.Header.Set("Authorization", "secret")
+			return nil
+		},
+	})
+	z := zeno.New()
+	z.Get("/reports/{name}", func(c *zeno.Context) error {
+		return Send(c, b, "reports/"+c.Param("name"), Config{Attachment: true, Redirect: time.Minute})
+	})
+
+	ctx := do(z, "GET", "/reports/q%201.csv", nil)
+	assert.Equal(t, zeno.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "0123456789", string(ctx.Response.Body()))
+	assert.Equal(t, `"abc"`, string(ctx.Response.Header.Peek(zeno.HeaderETag)))
+	assert.Equal(t, mod.Format(http.TimeFormat), string(ctx.Response.Header.Peek(zeno.HeaderLastModified)))
+	assert.Contains(t, string(ctx.Response.Header.Peek(zeno.HeaderContentDisposition)), `filename="q 1.csv"`)
+
+	// Only the requested bytes are fetched from the store.
+	ctx = do(z, "GET", "/reports/q%201.csv", map[string]string{zeno.HeaderRange: "bytes=2-4", zeno.HeaderIfRange: `"abc"`})
+	assert.Equal(t, zeno.StatusPartialContent, ctx.Response.StatusCode())
+	assert.Equal(t, "234", string(ctx.Response.Body()))
+
+	// A stale If-Range gets the full object.
+	ctx = do(z, "GET", "/reports/q%201.csv", map[string]string{zeno.HeaderRange: "bytes=2-4", zeno.HeaderIfRange: `"old"`})
+	assert.Equal(t, zeno.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "0123456789", string(ctx.Response.Body()))
+	assert.Equal(t, []string{"", "bytes=2-4", ""}, ranges)
+	assert.Positive(t, signed.Load())
+
+	assert.Equal(t, zeno.StatusNotFound, do(z, "GET", "/reports/other.csv", nil).Response.StatusCode())
+}
+
+func TestSend_Redirect(t *testing.T) {
+	b := NewHTTPBackend("https://bucket.example", HTTPConfig{
+		Presign: func(_ context.Context, key string, expires time.Duration) (string, error) {
+			return "https://bucket.example/" + key + "?expires=" + expires.String(), nil
+		},
+	})
+	z := zeno.New()
+	Static(&z.RouteGroup, "/files", b, Config{Redirect: time.Minute})
+
+	ctx := do(z, "GET", "/files/a/b.zip", nil)
+	assert.Equal(t, zeno.StatusTemporaryRedirect, ctx.Response.StatusCode())
+	assert.Equal(t, "https://bucket.example/a/b.zip?expires=1m0s", string(ctx.Response.Header.Peek(zeno.HeaderLocation)))
+}
+
+func TestObjectReader(t *testing.T) {
+	r := &objectReader{ctx: context.Background(), b: FS(fstest.MapFS{"k": {Data: []byte("0123456789")}}), key: "k", size: 10, start: 2, end: 4}
+	_, err := r.Seek(2, 0)
+	assert.NoError(t, err)
+	buf := make([]byte, 10)
+	var got []byte
+	for {
+		n, err := r.Read(buf)
+		got = append(got, buf[:n]...)
+		if err != nil {
+			break
+		}
+	}
+	assert.Equal(t, "23456789", string(got))
+	assert.NoError(t, r.Close())
+}
@@ -0,0 +1,282 @@
+package zeno
+
+import (
+	"sort"
+	"strings"
+)
+
+// routeEntry records a single route registration so that routing tables
+// can be rebuilt from scratch when routes change at runtime.
+type routeEntry struct {
+	method   string
+	path     string
+	handlers []Handler
+	route    *Route
+
+	// middleware is the group middleware the route was registered with,
+	// reused for 405 responses on its path.
+	middleware []Handler
+}
+
+// routingTable holds the routing tree for each HTTP method together with
+// the largest number of parameters used by any route. A table that has
+// been published to request handlers is never modified again; changes are
+// made to a fresh copy which then replaces it.
+type routingTable struct {
+	getTree     *tree
+	headTree    *tree
+	postTree    *tree
+	putTree     *tree
+	patchTree   *tree
+	deletedTree *tree
+	connectTree *tree
+	optionsTree *tree
+	traceTree   *tree
+
+	// Trees for methods without a dedicated field, such as the WebDAV
+	// methods PROPFIND and MKCOL
+	customTrees map[string]*tree
+
+	// Max number of parameters used across all routes
+	maxParams int
+
+	// static maps method and path of routes without parameters to their
+	// handlers, letting most lookups bypass the trees entirely
+	static map[string]map[string][]Handler
+
+	// anyTree holds every path pattern regardless of method. A request
+	// whose method tree misses but which matches here is answered with the
+	// pattern's allowChain instead of the not-found chain.
+	anyTree *tree
+
+	// allowed maps each pattern, and its insertion order in anyTree, to
+	// the 405 chain for it.
+	allowed        map[string]*allowEntry
+	allowedByOrder map[int]*allowEntry
+
+	// notAllowed is the chain set with Zeno.MethodNotAllowed
+	notAllowed []Handler
+
+	// routes maps the first handler of each route chain to its route, so
+	// Context.Route can tell which route a chain belongs to
+	routes map[*Handler]*Route
+
+	// fallbacks are the per-group not-found chains, error handlers and
+	// default headers, longest prefix first
+	fallbacks []*groupFallback
+
+	// Largest number of parameter values a fallback lookup writes
+	fallbackParams int
+}
+
+// allowEntry is the 405 chain of one path pattern, run with the group
+// middleware of the first route registered for it.
+type allowEntry struct {
+	chain []Handler
+}
+
+// handle sets the Allow header to the methods of every route matching
+// the request path. OPTIONS requests are answered with it right away;
+// other methods continue to the 405 chain.
+func (a *allowEntry) handle(c *Context) error {
+	path, _ := c.zeno.routingPath(c.ctx)
+	table := c.zeno.table.Load()
+	c.SetHeader(HeaderAllow, allowHeader(table.allowedMethods(path, make([]string, table.maxParams))))
+	if c.Method() == MethodOptions {
+		c.Abort()
+	}
+	return nil
+}
+
+// allowHeader returns the Allow header value listing methods, which
+// always includes OPTIONS.
+func allowHeader(methods map[string]bool) string {
+	ms := []string{MethodOptions}
+	for m := range methods {
+		if m != MethodOptions {
+			ms = append(ms, m)
+		}
+	}
+	sort.Strings(ms)
+	return strings.Join(ms, ", ")
+}
+
+// buildRoutingTable creates a new table containing entries, inserted in
+// order so route priorities are preserved. notAllowed is the chain run
+// after the Allow header is set when only the method does not match.
+func buildRoutingTable(entries []routeEntry, notAllowed []Handler, fallbacks []*groupFallback) *routingTable {
+	t := &routingTable{notAllowed: notAllowed}
+	for _, e := range entries {
+		t.add(e)
+	}
+	t.setFallbacks(fallbacks)
+	return t
+}
+
+// setFallbacks sets the group fallbacks of t.
+func (t *routingTable) setFallbacks(fallbacks []*groupFallback) {
+	t.fallbacks = fallbacks
+	t.fallbackParams = 0
+	for _, f := range fallbacks {
+		if f.pattern != nil && f.nparams+1 > t.fallbackParams {
+			t.fallbackParams = f.nparams + 1
+		}
+	}
+}
+
+// add inserts e into the tree for its method, creating the tree on first
+// use. It updates maxParams if the route uses more parameters than seen so far.
+func (t *routingTable) add(e routeEntry) {
+	tree := t.treeForMethod(e.method)
+	if tree == nil {
+		tree = newTree()
+		t.setTreeForMethod(e.method, tree)
+	}
+	if !strings.Contains(e.path, "{") {
+		t.addStatic(tree, e)
+	}
+	if len(e.handlers) > 0 {
+		if t.routes == nil {
+			t.routes = make(map[*Handler]*Route)
+		}
+		t.routes[&e.handlers[0]] = e.route
+	}
+	if n := tree.Add([]byte(e.path), e.handlers); n > t.maxParams {
+		t.maxParams = n
+	}
+
+	a := t.allowed[e.path]
+	if a == nil {
+		if t.anyTree == nil {
+			t.anyTree = newTree()
+			t.allowed = make(map[string]*allowEntry)
+			t.allowedByOrder = make(map[int]*allowEntry)
+		}
+		a = &allowEntry{}
+		a.chain = combineHandlers(e.middleware, append([]Handler{a.handle}, t.notAllowed...))
+		t.anyTree.Add([]byte(e.path), a.chain)
+		t.allowed[e.path] = a
+		t.allowedByOrder[t.anyTree.count] = a
+	}
+}
+
+// addStatic records the static route e in the fast path map, unless a
+// route registered earlier already matches its path and thus takes
+// precedence in tree.
+func (t *routingTable) addStatic(tree *tree, e routeEntry) {
+	if h, _ := tree.Get([]byte(e.path), make([]string, t.maxParams)); h != nil {
+		return
+	}
+	if t.static == nil {
+		t.static = make(map[string]map[string][]Handler)
+	}
+	m := t.static[e.method]
+	if m == nil {
+		m = make(map[string][]Handler)
+		t.static[e.method] = m
+	}
+	m[e.path] = e.handlers
+}
+
+// allowFor returns the 405 chain of the first pattern matching path, or
+// nil if no route matches it for any method.
+func (t *routingTable) allowFor(path []byte, pvalues []string) *allowEntry {
+	if t.anyTree == nil {
+		return nil
+	}
+	if h, _, order := t.anyTree.lookup(path, pvalues); h != nil {
+		return t.allowedByOrder[order]
+	}
+	return nil
+}
+
+// allowedMethods returns the methods of every route whose pattern
+// matches path, which may belong to several patterns: with GET
+// /users/{id} and DELETE /users/me, both are allowed for /users/me.
+func (t *routingTable) allowedMethods(path []byte, pvalues []string) map[string]bool {
+	methods := make(map[string]bool)
+	t.eachTree(func(method string, tr *tree) {
+		if h, _ := tr.Get(path, pvalues); h != nil {
+			methods[method] = true
+		}
+	})
+	return methods
+}
+
+// eachTree calls fn with every method that has a routing tree.
+func (t *routingTable) eachTree(fn func(method string, tr *tree)) {
+	for _, m := range []string{
+		MethodGet, MethodHead, MethodPost, MethodPut, MethodPatch,
+		MethodDelete, MethodConnect, MethodOptions, MethodTrace,
+	} {
+		if tr := t.treeForMethod(m); tr != nil {
+			fn(m, tr)
+		}
+	}
+	for m, tr := range t.customTrees {
+		fn(m, tr)
+	}
+}
+
+// routeFor returns the route whose handler chain is handlers, or nil.
+func (t *routingTable) routeFor(handlers []Handler) *Route {
+	if len(handlers) == 0 {
+		return nil
+	}
+	return t.routes[&handlers[0]]
+}
+
+// treeForMethod returns the routing tree corresponding to an HTTP method.
+func (t *routingTable) treeForMethod(method string) *tree {
+	switch method {
+	case MethodGet:
+		return t.getTree
+	case MethodHead:
+		return t.headTree
+	case MethodPost:
+		return t.postTree
+	case MethodPut:
+		return t.putTree
+	case MethodPatch:
+		return t.patchTree
+	case MethodDelete:
+		return t.deletedTree
+	case MethodConnect:
+		return t.connectTree
+	case MethodOptions:
+		return t.optionsTree
+	case MethodTrace:
+		return t.traceTree
+	default:
+		return t.customTrees[method]
+	}
+}
+
+// setTreeForMethod sets the routing tree for the given HTTP method.
+func (t *routingTable) setTreeForMethod(method string, tr *tree) {
+	switch method {
+	case MethodGet:
+		t.getTree = tr
+	case MethodHead:
+		t.headTree = tr
+	case MethodPost:
+		t.postTree = tr
+	case MethodPut:
+		t.putTree = tr
+	case MethodPatch:
+		t.patchTree = tr
+	case MethodDelete:
+		t.deletedTree = tr
+	case MethodConnect:
+		t.connectTree = tr
+	case MethodOptions:
+		t.optionsTree = tr
+	case MethodTrace:
+		t.traceTree = tr
+	default:
+		if t.customTrees == nil {
+			t.customTrees = make(map[string]*tree)
+		}
+		t.customTrees[method] = tr
+	}
+}
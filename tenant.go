@@ -0,0 +1,166 @@
+package zeno
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+// Tenant is the customer account a request of a multi-tenant application
+// belongs to.
+type Tenant struct {
+	// ID identifies the tenant, e.g. "acme" for acme.example.com.
+	ID string `json:"id"`
+
+	// Name is the display name of the tenant.
+	Name string `json:"name,omitempty"`
+
+	// Attributes holds application data about the tenant, such as its
+	// plan or database.
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// TenantResolver extracts the tenant ID from a request, returning "" when
+// the request names none.
+type TenantResolver func(c *Context) string
+
+// TenantFromSubdomain returns a TenantResolver reading the tenant from
+// the subdomain of domain the request is addressed to, e.g. "acme" for
+// "acme.example.com" with domain "example.com". The bare domain and
+// deeper subdomains name no tenant.
+func TenantFromSubdomain(domain string) TenantResolver {
+	suffix := "." + strings.ToLower(strings.Trim(domain, "."))
+	return func(c *Context) string {
+		host := c.Host()
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+		sub, ok := strings.CutSuffix(host, suffix)
+		if !ok || sub == "" || strings.Contains(sub, ".") {
+			return ""
+		}
+		return sub
+	}
+}
+
+// TenantFromHeader returns a TenantResolver reading the tenant from the
+// request header name, e.g. "X-Tenant-ID" set by a gateway. Clients can
+// send any header, so use it only behind a gateway that strips the header
+// from incoming requests before setting it; otherwise any client can act
+// as any tenant, including on requests the other resolvers find no
+// tenant for.
+func TenantFromHeader(name string) TenantResolver {
+	return func(c *Context) string {
+		return strings.TrimSpace(c.GetHeader(name))
+	}
+}
+
+// TenantFromPath returns a TenantResolver reading the tenant from the
+// route parameter param, for tenant routes grouped under a path prefix
+// such as "/{tenant}".
+func TenantFromPath(param string) TenantResolver {
+	return func(c *Context) string {
+		return c.Param(param)
+	}
+}
+
+// TenantConfig configures the ResolveTenant middleware.
+type TenantConfig struct {
+	// Resolvers extract the tenant ID; the first one returning an ID
+	// wins. Required.
+	Resolvers []TenantResolver
+
+	// Lookup, when set, loads the tenant with the given ID, returning nil
+	// for unknown tenants, which are answered with 404 Not Found. An
+	// error fails the request: HTTPErrors are returned as they are,
+	// others as 500 Internal Server Error. By default every ID is a
+	// tenant with just that ID.
+	Lookup func(c *Context, id string) (*Tenant, error)
+
+	// Skipper, when set, bypasses the middleware for requests for which
+	// it returns true.
+	Skipper Skipper
+}
+
+// tenantKey is the Context key of the resolved tenant.
+const tenantKey = "zeno.tenant"
+
+// ResolveTenant returns a middleware resolving the tenant of each request
+// and storing it in the context, where Context.Tenant returns it.
+// Requests naming no tenant continue without one; install RequireTenant
+// on the groups serving tenants to reject them.
+//
+// Routing happens before middleware runs, so tenants on a path prefix can
+// be read from a route parameter with TenantFromPath.
+//
+// Example:
+//
+//	app.Use(zeno.ResolveTenant(zeno.TenantConfig{
+//	    Resolvers: []zeno.TenantResolver{
+//	        zeno.TenantFromSubdomain("example.com"),
+//	    },
+//	    Lookup: func(c *zeno.Context, id string) (*zeno.Tenant, error) {
+//	        return tenants.Find(id)
+//	    },
+//	}))
+//
+//	api := app.Group("/api")
+//	api.Use(zeno.RequireTenant())
+//	api.Get("/orders", func(c *zeno.Context) error {
+//	    return c.SendJSON(orders.List(c.Tenant().ID))
+//	})
+func ResolveTenant(config TenantConfig) Handler {
+	if len(config.Resolvers) == 0 {
+		panic("zeno: ResolveTenant requires a Resolver")
+	}
+	return func(c *Context) error {
+		// A rewritten request passes through again with its tenant set.
+		if (config.Skipper != nil && config.Skipper(c)) || c.Tenant() != nil {
+			return c.Next()
+		}
+		var id string
+		for _, resolve := range config.Resolvers {
+			if id = resolve(c); id != "" {
+				break
+			}
+		}
+		if id == "" {
+			return c.Next()
+		}
+		tenant := &Tenant{ID: id}
+		if config.Lookup != nil {
+			var err error
+			if tenant, err = config.Lookup(c, id); err != nil {
+				var httpErr HTTPError
+				if errors.As(err, &httpErr) {
+					return err
+				}
+				return NewHTTPError(StatusInternalServerError, "tenant: "+err.Error())
+			}
+			if tenant == nil {
+				return NewHTTPError(StatusNotFound, "unknown tenant")
+			}
+		}
+		c.Set(tenantKey, tenant)
+		return c.Next()
+	}
+}
+
+// RequireTenant returns a middleware answering requests without a tenant
+// resolved by ResolveTenant with 400 Bad Request.
+func RequireTenant() Handler {
+	return func(c *Context) error {
+		if c.Tenant() == nil {
+			return NewHTTPError(StatusBadRequest, "tenant required")
+		}
+		return c.Next()
+	}
+}
+
+// Tenant returns the tenant resolved by the ResolveTenant middleware, or
+// nil if there is none.
+func (c *Context) Tenant() *Tenant {
+	t, _ := c.Get(tenantKey).(*Tenant)
+	return t
+}
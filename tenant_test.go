@@ -0,0 +1,79 @@
+package zeno
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTenant(t *testing.T) {
+	tenants := map[string]*Tenant{
+		"acme":   {ID: "acme", Name: "Acme Corp"},
+		"globex": {ID: "globex", Name: "Globex"},
+	}
+	z := New()
+	z.Use(ResolveTenant(TenantConfig{
+		Resolvers: []TenantResolver{
+			TenantFromSubdomain("example.com"),
+			TenantFromHeader("X-Tenant-ID"),
+			TenantFromPath("tenant"),
+		},
+		Lookup: func(c *Context, id string) (*Tenant, error) {
+			if id == "broken" {
+				return nil, errors.New("db down")
+			}
+			return tenants[id], nil
+		},
+	}))
+	name := func(c *Context) error {
+		if tn := c.Tenant(); tn != nil {
+			return c.SendString(tn.Name)
+		}
+		return c.SendString("none")
+	}
+	z.Get("/", name)
+	z.Get("/old", func(c *Context) error { return c.Rewrite("/") })
+	api := z.Group("/api")
+	api.Use(RequireTenant())
+	api.Get("/orders", name)
+	z.Get("/t/{tenant}/orders", name)
+
+	for _, tt := range []struct {
+		host, header, uri string
+		status            int
+		body              string
+	}{
+		{"acme.example.com", "", "/", StatusOK, "Acme Corp"},
+		{"ACME.example.com:8080", "", "/", StatusOK, "Acme Corp"},
+		{"example.com", "", "/", StatusOK, "none"},
+		{"a.b.example.com", "", "/", StatusOK, "none"},
+		{"acme.example.org", "", "/", StatusOK, "none"},
+		{"example.com", "globex", "/", StatusOK, "Globex"},
+		{"acme.example.com", "globex", "/", StatusOK, "Acme Corp"},
+		{"acme.example.com", "", "/old", StatusOK, "Acme Corp"},
+		{"example.com", "", "/t/globex/orders", StatusOK, "Globex"},
+		{"initech.example.com", "", "/", StatusNotFound, "unknown tenant"},
+		{"broken.example.com", "", "/", StatusInternalServerError, "tenant: db down"},
+		{"acme.example.com", "", "/api/orders", StatusOK, "Acme Corp"},
+		{"example.com", "", "/api/orders", StatusBadRequest, "tenant required"},
+	} {
+		headers := map[string]string{HeaderHost: tt.host}
+		if tt.header != "" {
+			headers["X-Tenant-ID"] = tt.header
+		}
+		ctx := performRequest(z, "GET", tt.uri, headers, nil)
+		assert.Equal(t, tt.status, ctx.Response.StatusCode(), tt.host+tt.uri)
+		assert.Equal(t, tt.body, string(ctx.Response.Body()), tt.host+tt.uri)
+	}
+}
+
+func TestResolveTenant_Default(t *testing.T) {
+	z := New()
+	z.Use(ResolveTenant(TenantConfig{Resolvers: []TenantResolver{TenantFromHeader("X-Tenant-ID")}}))
+	z.Get("/", RequireTenant(), func(c *Context) error { return c.SendString(c.Tenant().ID) })
+
+	ctx := performRequest(z, "GET", "/", map[string]string{"X-Tenant-ID": " acme "}, nil)
+	assert.Equal(t, "acme", string(ctx.Response.Body()))
+	assert.Panics(t, func() { ResolveTenant(TenantConfig{}) })
+}
@@ -0,0 +1,87 @@
+package zeno
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// TimeParserConfig configures how time.Time values are parsed by Param,
+// Query, and struct binding.
+type TimeParserConfig struct {
+	// Layouts are tried in order for non-numeric values. Defaults to
+	// time.RFC3339Nano followed by the date-only layout "2006-01-02".
+	Layouts []string
+
+	// UnixUnit is the unit of purely numeric values, which are read as an
+	// offset from the Unix epoch. Use time.Millisecond for JavaScript-style
+	// timestamps. Defaults to time.Second.
+	UnixUnit time.Duration
+
+	// Location is used for layouts without a zone offset. Defaults to UTC.
+	Location *time.Location
+}
+
+// defaultTimeLayouts are used when TimeParserConfig.Layouts is empty.
+var defaultTimeLayouts = []string{time.RFC3339Nano, time.DateOnly}
+
+// parseTime parses s as a Unix timestamp if it is an integer, or with the
+// configured layouts otherwise. An empty string yields the zero time.
+func (cfg TimeParserConfig) parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		unit := cfg.UnixUnit
+		if unit <= 0 {
+			unit = time.Second
+		}
+		d, err := scaleDuration(n, unit)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q: %w", s, err)
+		}
+		return time.Unix(0, 0).Add(d).UTC(), nil
+	}
+	layouts := cfg.Layouts
+	if len(layouts) == 0 {
+		layouts = defaultTimeLayouts
+	}
+	loc := cfg.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// parseDuration parses s with time.ParseDuration, treating a bare integer
+// as a number of seconds so "timeout=30" works as well as "timeout=30s".
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return scaleDuration(n, time.Second)
+	}
+	return time.ParseDuration(s)
+}
+
+// scaleDuration returns n units, or an error if the result does not fit in
+// a time.Duration.
+func scaleDuration(n int64, unit time.Duration) (time.Duration, error) {
+	if n > math.MaxInt64/int64(unit) || n < math.MinInt64/int64(unit) {
+		return 0, fmt.Errorf("%d × %s overflows time.Duration", n, unit)
+	}
+	return time.Duration(n) * unit, nil
+}
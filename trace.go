@@ -0,0 +1,76 @@
+package zeno
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HeaderRouteTrace carries the routing decisions for a request when
+// Zeno.TraceRouting is enabled, one step per header line.
+const HeaderRouteTrace = "X-Route-Trace"
+
+// routeTracer collects the steps of a traced route lookup.
+type routeTracer struct {
+	steps []string
+}
+
+// add records a step at the given nesting depth. Depth is marked with
+// "| " rather than spaces, which header parsers would trim.
+func (t *routeTracer) add(depth int, format string, args ...any) {
+	t.steps = append(t.steps, strings.Repeat("| ", depth)+fmt.Sprintf(format, args...))
+}
+
+// TraceRoute returns the node-by-node decisions taken when routing a
+// request with method and path: the static map lookup, every prefix
+// comparison and regular expression attempt, and which parameter children
+// were tried or skipped because a route registered earlier had already
+// matched. It helps find out why a request is routed to an unexpected
+// handler. MatchRoute includes the same trace.
+//
+// Example:
+//
+//	for _, step := range app.TraceRoute("GET", "/users/me") {
+//	    fmt.Println(step)
+//	}
+func (z *Zeno) TraceRoute(method, path string) []string {
+	table := z.table.Load()
+	tr := &routeTracer{}
+	if h := table.static[method][path]; h != nil {
+		tr.add(0, "static map: %s %q matches", method, path)
+		return tr.steps
+	}
+	tr.add(0, "static map: no route for %s %q", method, path)
+
+	offs := make([]int, 2*table.maxParams)
+	if t := table.treeForMethod(method); t != nil {
+		tr.add(0, "%s tree:", method)
+		if d, _, order := t.root.get([]byte(path), len(path), offs, tr, 1); d != nil {
+			tr.add(0, "result: route #%d", order)
+			return tr.steps
+		}
+	} else {
+		tr.add(0, "no %s routes", method)
+	}
+	if table.anyTree != nil {
+		tr.add(0, "tree of all methods:")
+		if d, _, _ := table.anyTree.root.get([]byte(path), len(path), offs, tr, 1); d != nil {
+			tr.add(0, "result: 405, allowed %s", allowHeader(table.allowedMethods([]byte(path), make([]string, table.maxParams))))
+			return tr.steps
+		}
+	}
+	tr.add(0, "result: not found")
+	return tr.steps
+}
+
+// emitRouteTrace reports the routing trace of the current request, to
+// OnRouteTrace if set or else in HeaderRouteTrace response headers.
+func (z *Zeno) emitRouteTrace(c *Context, method string, path []byte) {
+	steps := z.TraceRoute(method, string(path))
+	if z.OnRouteTrace != nil {
+		z.OnRouteTrace(c, steps)
+		return
+	}
+	for _, step := range steps {
+		c.ctx.Response.Header.Add(HeaderRouteTrace, step)
+	}
+}
@@ -0,0 +1,52 @@
+package zeno
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZeno_TraceRoute(t *testing.T) {
+	z := New()
+	z.Get("/users/{id:[0-9]+}", func(c *Context) error { return c.SendString("id") })
+	z.Get("/users/{name}", func(c *Context) error { return c.SendString("name") })
+	z.Get("/users/me", func(c *Context) error { return c.SendString("me") })
+	z.Post("/items/{id}", func(c *Context) error { return nil })
+
+	trace := strings.Join(z.TraceRoute(MethodGet, "/users/me"), "\n")
+	assert.Contains(t, trace, "regex ^[0-9]+ does not match \"me\"")
+	assert.Contains(t, trace, "route #2 matches")
+	assert.True(t, strings.HasSuffix(trace, "result: route #2"), trace)
+
+	trace = strings.Join(z.TraceRoute(MethodGet, "/users/42"), "\n")
+	assert.Contains(t, trace, "{name}: skipped")
+	assert.True(t, strings.HasSuffix(trace, "result: route #1"), trace)
+
+	trace = strings.Join(z.TraceRoute(MethodGet, "/items/1"), "\n")
+	assert.True(t, strings.HasSuffix(trace, "result: 405, allowed OPTIONS, POST"), trace)
+
+	trace = strings.Join(z.TraceRoute(MethodPut, "/nope"), "\n")
+	assert.Contains(t, trace, "no PUT routes")
+	assert.True(t, strings.HasSuffix(trace, "result: not found"), trace)
+}
+
+func TestZeno_TraceRouting(t *testing.T) {
+	z := New()
+	z.TraceRouting = true
+	z.Get("/users/{id}", func(c *Context) error { return c.SendString("ok") })
+
+	ctx := performRequest(z, "GET", "/users/7", nil, nil)
+	assert.Equal(t, "ok", string(ctx.Response.Body()))
+	var steps []string
+	for _, v := range ctx.Response.Header.PeekAll(HeaderRouteTrace) {
+		steps = append(steps, string(v))
+	}
+	assert.Equal(t, z.TraceRoute(MethodGet, "/users/7"), steps)
+
+	var logged []string
+	z.OnRouteTrace = func(c *Context, trace []string) { logged = trace }
+	ctx = performRequest(z, "GET", "/users/7", nil, nil)
+	assert.Empty(t, ctx.Response.Header.PeekAll(HeaderRouteTrace))
+	assert.Equal(t, steps, logged)
+}
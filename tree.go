@@ -21,7 +21,6 @@ func newTree() *tree {
 	return &tree{
 		root: &node{
 			static:    true,
-			children:  make([]*node, 256),
 			pchildren: []*node{},
 			pindex:    -1,
 			pnames:    []string{},
@@ -41,10 +40,38 @@ func (t *tree) Add(key []byte, handlers []Handler) int {
 // It fills the provided pvalues slice with extracted parameter values.
 // It returns the matched handler chain, ordered list of parameter names, and insertion order.
 func (t *tree) Get(path []byte, pvalues []string) ([]Handler, []string) {
-	d, names, _ := t.root.get(path, pvalues)
+	d, names, _ := t.lookup(path, pvalues)
 	return d, names
 }
 
+// maxStackParams is the number of parameters whose offsets lookup keeps
+// on the stack; routes with more fall back to a heap allocated buffer.
+const maxStackParams = 8
+
+// lookup is like Get but also returns the insertion order of the matched
+// route, i.e. the number of Add calls up to and including the one that
+// registered it.
+//
+// Matching only records the byte offsets of parameters. Their values are
+// materialized once a route has won, as substrings of a single copy of
+// path, so a match costs at most one allocation and none for routes
+// without parameters.
+func (t *tree) lookup(path []byte, pvalues []string) ([]Handler, []string, int) {
+	var buf [2 * maxStackParams]int
+	offs := buf[:]
+	if len(pvalues) > maxStackParams {
+		offs = make([]int, 2*len(pvalues))
+	}
+	d, names, order := t.root.get(path, len(path), offs, nil, 0)
+	if d != nil && len(names) > 0 {
+		s := string(path)
+		for i := range names {
+			pvalues[i] = s[offs[2*i]:offs[2*i+1]]
+		}
+	}
+	return d, names, order
+}
+
 // node represents a single node in the radix tree.
 // Nodes may represent static paths or parameterized segments like {id}, {slug:.*}, {file*}, or {name?}.
 type node struct {
@@ -58,7 +85,13 @@ type node struct {
 	order    int       // insertion order of the route
 	minOrder int       // minimum order of any handler in subtree (used for prioritization)
 
-	children  []*node // static children (indexed by byte)
+	// Static children, sparsely indexed by the first byte of their key:
+	// children[i] starts with indices[i], and mask has bit b set when a
+	// child starts with byte b.
+	indices  []byte
+	children []*node
+	mask     [4]uint64
+
 	pchildren []*node // parameterized children
 
 	pindex int      // index of the parameter in the pvalues slice
@@ -83,7 +116,7 @@ func (n *node) add(key []byte, handlers []Handler, order int) int {
 
 	if matched == len(n.key) {
 		childKey := key[matched:]
-		if lit := n.children[childKey[0]]; lit != nil {
+		if lit := n.child(childKey[0]); lit != nil {
 			if pn := lit.add(childKey, handlers, order); pn >= 0 {
 				return pn
 			}
@@ -108,7 +141,9 @@ func (n *node) add(key []byte, handlers []Handler, order int) int {
 		handlers:  n.handlers,
 		order:     n.order,
 		minOrder:  n.minOrder,
+		indices:   n.indices,
 		children:  n.children,
+		mask:      n.mask,
 		pchildren: n.pchildren,
 		pindex:    n.pindex,
 		pnames:    n.pnames,
@@ -119,9 +154,9 @@ func (n *node) add(key []byte, handlers []Handler, order int) int {
 
 	n.key = key[:matched]
 	n.handlers = nil
-	n.children = make([]*node, 256)
+	n.indices, n.children, n.mask = nil, nil, [4]uint64{}
 	n.pchildren = []*node{}
-	n.children[rest[0]] = n1
+	n.setChild(rest[0], n1)
 
 	return n.add(key, handlers, order)
 }
@@ -148,14 +183,13 @@ func (n *node) addChild(key []byte, handlers []Handler, order int) int {
 			static:    true,
 			key:       key,
 			minOrder:  order,
-			children:  make([]*node, 256),
 			pchildren: []*node{},
 			pindex:    n.pindex,
 			pnames:    n.pnames,
 			handlers:  handlers,
 			order:     order,
 		}
-		n.children[key[0]] = lit
+		n.setChild(key[0], lit)
 		return lit.pindex + 1
 	}
 
@@ -165,12 +199,11 @@ func (n *node) addChild(key []byte, handlers []Handler, order int) int {
 			static:    true,
 			key:       key[:p0],
 			minOrder:  order,
-			children:  make([]*node, 256),
 			pchildren: []*node{},
 			pindex:    n.pindex,
 			pnames:    n.pnames,
 		}
-		n.children[prefix.key[0]] = prefix
+		n.setChild(prefix.key[0], prefix)
 		return prefix.addChild(key[p0:], handlers, order)
 	}
 
@@ -180,7 +213,6 @@ func (n *node) addChild(key []byte, handlers []Handler, order int) int {
 		static:    false,
 		key:       token,
 		minOrder:  order,
-		children:  make([]*node, 256),
 		pchildren: []*node{},
 		pindex:    n.pindex,
 		pnames:    n.pnames,
@@ -231,9 +263,14 @@ func (n *node) addChild(key []byte, handlers []Handler, order int) int {
 }
 
 // get attempts to match a path against this node and its children recursively.
-// It fills pvalues with captured parameter values and returns the matched
-// handler chain, parameter names, and match insertion order.
-func (n *node) get(path []byte, pvalues []string) ([]Handler, []string, int) {
+// path is the unmatched remainder of a request path of length full. The
+// start and end offsets, within the full path, of the value captured for
+// parameter i are stored in offs[2*i] and offs[2*i+1]. It returns the
+// matched handler chain, parameter names, and match insertion order.
+//
+// When tr is not nil, each decision is recorded in it at the given depth
+// for TraceRoute; routing passes nil, so tracing costs it nothing.
+func (n *node) get(path []byte, full int, offs []int, tr *routeTracer, depth int) ([]Handler, []string, int) {
 	bestOrder := math.MaxInt32
 	var bestData []Handler
 	var bestNames []string
@@ -241,68 +278,108 @@ func (n *node) get(path []byte, pvalues []string) ([]Handler, []string, int) {
 repeat:
 	if n.static {
 		if !bytes.HasPrefix(path, n.key) {
+			if tr != nil {
+				tr.add(depth, "%q: no match for %q", n.key, path)
+			}
 			return nil, nil, bestOrder
 		}
 		path = path[len(n.key):]
+		if tr != nil {
+			tr.add(depth, "%q: prefix matches, rest %q", n.key, path)
+		}
 	} else if n.regex != nil {
 		if len(path) == 0 && n.optional {
-			pvalues[n.pindex] = ""
+			setOffsets(offs, n.pindex, full, 0)
+			if tr != nil {
+				tr.add(depth, "%s: optional, empty", n.key)
+			}
 		} else if m := n.regex.FindIndex(path); m != nil {
-			pvalues[n.pindex] = string(path[:m[1]])
+			setOffsets(offs, n.pindex, full-len(path), m[1])
+			if tr != nil {
+				tr.add(depth, "%s: regex %s matches %q", n.key, n.regex, path[:m[1]])
+			}
 			path = path[m[1]:]
 		} else {
+			if tr != nil {
+				tr.add(depth, "%s: regex %s does not match %q", n.key, n.regex, path)
+			}
 			return nil, nil, bestOrder
 		}
 	} else if n.wildcard {
-		pvalues[n.pindex] = string(path)
+		setOffsets(offs, n.pindex, full-len(path), len(path))
+		if tr != nil {
+			tr.add(depth, "%s: wildcard captures %q", n.key, path)
+		}
 		path = nil
 	} else {
 		if len(path) == 0 {
-			if n.optional {
-				pvalues[n.pindex] = ""
-			} else {
+			if !n.optional {
+				if tr != nil {
+					tr.add(depth, "%s: nothing left to capture", n.key)
+				}
 				return nil, nil, bestOrder
 			}
+			setOffsets(offs, n.pindex, full, 0)
+			if tr != nil {
+				tr.add(depth, "%s: optional, empty", n.key)
+			}
 		} else {
 			idx := 0
 			for idx < len(path) && path[idx] != '/' {
-				if n.children[path[idx]] != nil {
+				if n.hasChild(path[idx]) {
 					break
 				}
 				idx++
 			}
-			pvalues[n.pindex] = string(path[:idx])
+			setOffsets(offs, n.pindex, full-len(path), idx)
+			if tr != nil {
+				tr.add(depth, "%s: captures %q", n.key, path[:idx])
+			}
 			path = path[idx:]
 		}
 	}
 
 	if len(path) > 0 {
-		if lit := n.children[path[0]]; lit != nil {
+		if lit := n.child(path[0]); lit != nil {
 			if len(n.pchildren) == 0 {
 				n = lit
+				depth++
 				goto repeat
 			}
-			if d, names, o := lit.get(path, pvalues); d != nil && o < bestOrder {
+			if d, names, o := lit.get(path, full, offs, tr, depth+1); d != nil && o < bestOrder {
 				bestData, bestNames, bestOrder = d, names, o
 			}
+		} else if tr != nil && len(n.pchildren) == 0 {
+			tr.add(depth, "no child for %q", path)
 		}
 	} else if n.handlers != nil {
 		bestData, bestNames, bestOrder = n.handlers, n.pnames, n.order
+		if tr != nil {
+			tr.add(depth, "route #%d matches", n.order)
+		}
+	} else if tr != nil {
+		tr.add(depth, "no route ends here")
 	}
 
-	tmp := pvalues
+	tmp := offs
 	scratch := false
 	for _, pc := range n.pchildren {
 		if pc.minOrder >= bestOrder {
+			if tr != nil {
+				tr.add(depth+1, "%s: skipped, its routes (from #%d) come after route #%d", pc.key, pc.minOrder, bestOrder)
+			}
 			continue
 		}
 		if bestData != nil && !scratch {
-			tmp = make([]string, len(pvalues))
+			tmp = make([]int, len(offs))
 			scratch = true
 		}
-		if d, names, o := pc.get(path, tmp); d != nil && o < bestOrder {
+		if d, names, o := pc.get(path, full, tmp, tr, depth+1); d != nil && o < bestOrder {
 			if scratch {
-				copy(pvalues[pc.pindex:], tmp[pc.pindex:])
+				copy(offs[2*pc.pindex:], tmp[2*pc.pindex:])
+			}
+			if tr != nil && bestData != nil {
+				tr.add(depth+1, "route #%d, registered earlier, wins over #%d", o, bestOrder)
 			}
 			bestData, bestNames, bestOrder = d, names, o
 		}
@@ -310,3 +387,42 @@ repeat:
 
 	return bestData, bestNames, bestOrder
 }
+
+// setOffsets records that parameter i spans n bytes starting at start.
+func setOffsets(offs []int, i, start, n int) {
+	offs[2*i], offs[2*i+1] = start, start+n
+}
+
+// hasChild reports whether n has a static child starting with c.
+func (n *node) hasChild(c byte) bool {
+	return n.mask[c>>6]&(1<<(c&63)) != 0
+}
+
+// child returns the static child of n starting with c, or nil.
+func (n *node) child(c byte) *node {
+	if !n.hasChild(c) {
+		return nil
+	}
+	for i, b := range n.indices {
+		if b == c {
+			return n.children[i]
+		}
+	}
+	return nil
+}
+
+// setChild sets the static child of n starting with c, replacing any
+// existing one.
+func (n *node) setChild(c byte, child *node) {
+	if n.hasChild(c) {
+		for i, b := range n.indices {
+			if b == c {
+				n.children[i] = child
+				return
+			}
+		}
+	}
+	n.indices = append(n.indices, c)
+	n.children = append(n.children, child)
+	n.mask[c>>6] |= 1 << (c & 63)
+}
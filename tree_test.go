@@ -1,6 +1,7 @@
 package zeno
 
 import (
+	"strconv"
 	"testing"
 )
 
@@ -41,3 +42,87 @@ func TestTree_NegativeMatches(t *testing.T) {
 		}
 	}
 }
+
+func TestTree_Params(t *testing.T) {
+	tree := newTree()
+	tree.Add([]byte("/users/{id}/posts"), []Handler{testHandler()})
+	tree.Add([]byte("/users/{name}/{tab}"), []Handler{testHandler()})
+	tree.Add([]byte("/item/{slug:[a-z]+}-{n}"), []Handler{testHandler()})
+	tree.Add([]byte("/post/{id?}"), []Handler{testHandler()})
+	tree.Add([]byte("/files/{path*}"), []Handler{testHandler()})
+
+	tests := []struct {
+		path   string
+		values []string
+	}{
+		{"/users/42/posts", []string{"42"}},
+		{"/users/ann/likes", []string{"ann", "likes"}},
+		{"/item/shoe-7", []string{"shoe", "7"}},
+		{"/post/", []string{""}},
+		{"/files/css/site.css", []string{"css/site.css"}},
+	}
+	for _, tt := range tests {
+		pvalues := make([]string, 4)
+		handlers, names := tree.Get([]byte(tt.path), pvalues)
+		if handlers == nil {
+			t.Errorf("no match for %q", tt.path)
+			continue
+		}
+		for i, want := range tt.values {
+			if i >= len(names) || pvalues[i] != want {
+				t.Errorf("%q: param %d = %q, want %q", tt.path, i, pvalues[i], want)
+			}
+		}
+	}
+}
+
+func benchmarkTree() *tree {
+	tree := newTree()
+	for _, p := range []string{
+		"/",
+		"/users",
+		"/users/{id}",
+		"/users/{id}/posts/{post}",
+		"/users/{id}/posts/{post}/comments/{comment:[0-9]+}",
+		"/files/{path*}",
+		"/health",
+	} {
+		tree.Add([]byte(p), []Handler{testHandler()})
+	}
+	return tree
+}
+
+func BenchmarkTree_Get(b *testing.B) {
+	tree := benchmarkTree()
+	for _, bc := range []struct{ name, path string }{
+		{"Static", "/health"},
+		{"Param", "/users/42"},
+		{"ThreeParams", "/users/42/posts/7/comments/99"},
+		{"Wildcard", "/files/css/site.css"},
+		{"Miss", "/unknown/path"},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			path := []byte(bc.path)
+			pvalues := make([]string, 4)
+			b.ReportAllocs()
+			for b.Loop() {
+				tree.Get(path, pvalues)
+			}
+		})
+	}
+}
+
+func BenchmarkTree_Build(b *testing.B) {
+	paths := make([]string, 0, 1000)
+	for i := range 250 {
+		res := "/res" + strconv.Itoa(i)
+		paths = append(paths, res, res+"/{id}", res+"/{id}/edit", res+"/{id}/items/{item}")
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		tree := newTree()
+		for _, p := range paths {
+			tree.Add([]byte(p), []Handler{testHandler()})
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package tus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned by a Store for an unknown upload.
+	ErrNotFound = errors.New("tus: upload not found")
+
+	// ErrOffsetMismatch is returned by Store.Write when offset is not the
+	// upload's current offset.
+	ErrOffsetMismatch = errors.New("tus: offset does not match")
+)
+
+// Info describes an upload.
+type Info struct {
+	ID string `json:"id"`
+
+	// Size is the total length in bytes, or -1 while the client has
+	// deferred it.
+	Size int64 `json:"size"`
+
+	// Offset is the number of bytes received so far.
+	Offset int64 `json:"-"`
+
+	// Metadata holds the decoded Upload-Metadata pairs sent on creation,
+	// e.g. the file name and type.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Done reports whether every byte of the upload has been received.
+func (i Info) Done() bool {
+	return i.Size >= 0 && i.Offset == i.Size
+}
+
+// Store persists uploads. The handler serializes the calls for a single
+// upload, so implementations only need to be safe for concurrent use
+// across uploads.
+type Store interface {
+	// Create records a new, empty upload.
+	Create(ctx context.Context, info Info) error
+
+	// Info returns the upload with the given ID, or ErrNotFound.
+	Info(ctx context.Context, id string) (Info, error)
+
+	// Write appends the data read from r to the upload, which must
+	// currently hold offset bytes, and returns the number of bytes
+	// written. Bytes written before r fails are kept, so the client can
+	// resume from there.
+	Write(ctx context.Context, id string, offset int64, r io.Reader) (int64, error)
+
+	// SetSize sets the size of an upload created with a deferred length.
+	SetSize(ctx context.Context, id string, size int64) error
+
+	// Open returns the data received so far, typically once the upload is
+	// done.
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+
+	// Delete removes the upload and its data.
+	Delete(ctx context.Context, id string) error
+}
+
+// FileStore is a Store keeping each upload in two files of a directory:
+// the data in "<id>.bin" and its Info in "<id>.info".
+//
+// Example:
+//
+//	store, err := tus.NewFileStore("/var/lib/app/uploads")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore in dir, creating the directory if
+// needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Path returns the name of the file holding the upload's data, e.g. to
+// move it elsewhere once it is done.
+func (s *FileStore) Path(id string) string {
+	return filepath.Join(s.dir, id+".bin")
+}
+
+// infoPath returns the name of the file holding the upload's Info.
+func (s *FileStore) infoPath(id string) string {
+	return filepath.Join(s.dir, id+".info")
+}
+
+// Create implements Store.
+func (s *FileStore) Create(_ context.Context, info Info) error {
+	if !validID(info.ID) {
+		return errors.New("tus: invalid upload ID")
+	}
+	f, err := os.OpenFile(s.Path(info.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return s.writeInfo(info)
+}
+
+// Info implements Store. The offset is the size of the data file.
+func (s *FileStore) Info(_ context.Context, id string) (Info, error) {
+	if !validID(id) {
+		return Info{}, ErrNotFound
+	}
+	data, err := os.ReadFile(s.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Info{}, ErrNotFound
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return Info{}, err
+	}
+	fi, err := os.Stat(s.Path(id))
+	if err != nil {
+		return Info{}, err
+	}
+	info.Offset = fi.Size()
+	return info, nil
+}
+
+// Write implements Store.
+func (s *FileStore) Write(_ context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	if !validID(id) {
+		return 0, ErrNotFound
+	}
+	f, err := os.OpenFile(s.Path(id), os.O_WRONLY|os.O_APPEND, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if fi.Size() != offset {
+		return 0, ErrOffsetMismatch
+	}
+	n, err := io.Copy(f, r)
+	if err != nil {
+		return n, err
+	}
+	return n, f.Close()
+}
+
+// SetSize implements Store.
+func (s *FileStore) SetSize(ctx context.Context, id string, size int64) error {
+	info, err := s.Info(ctx, id)
+	if err != nil {
+		return err
+	}
+	info.Size = size
+	return s.writeInfo(info)
+}
+
+// Open implements Store.
+func (s *FileStore) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	f, err := os.Open(s.Path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete implements Store.
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	if !validID(id) {
+		return ErrNotFound
+	}
+	if err := os.Remove(s.infoPath(id)); errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	if err := os.Remove(s.Path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// writeInfo atomically replaces the upload's info file.
+func (s *FileStore) writeInfo(info Info) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	tmp := s.infoPath(info.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.infoPath(info.ID))
+}
+
+// validID reports whether id can safely be used in a file name.
+func validID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,402 @@
+// Package tus implements the server side of the tus resumable upload
+// protocol, version 1.0.0 (https://tus.io/protocols/resumable-upload), for
+// large uploads over unreliable connections. A client creates an upload
+// with POST, sends its data with one or more PATCH requests and, after an
+// interruption, asks for the offset to resume from with HEAD.
+//
+// Besides the core protocol, the creation, creation-with-upload,
+// creation-defer-length and termination extensions are supported.
+package tus
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abhishek2010dev/zeno"
+)
+
+const (
+	// Version is the protocol version implemented by this package.
+	Version = "1.0.0"
+
+	// Extensions lists the supported protocol extensions, as advertised
+	// in the Tus-Extension header.
+	Extensions = "creation,creation-with-upload,creation-defer-length,termination"
+
+	// ContentType is the media type of the data sent with PATCH.
+	ContentType = "application/offset+octet-stream"
+)
+
+// Protocol headers.
+const (
+	HeaderTusResumable      = "Tus-Resumable"
+	HeaderTusVersion        = "Tus-Version"
+	HeaderTusExtension      = "Tus-Extension"
+	HeaderTusMaxSize        = "Tus-Max-Size"
+	HeaderUploadOffset      = "Upload-Offset"
+	HeaderUploadLength      = "Upload-Length"
+	HeaderUploadDeferLength = "Upload-Defer-Length"
+	HeaderUploadMetadata    = "Upload-Metadata"
+)
+
+// Config configures an upload endpoint.
+type Config struct {
+	// Store persists the uploads. It is required.
+	Store Store
+
+	// MaxSize is the largest upload accepted, in bytes. Zero means no
+	// limit.
+	MaxSize int64
+
+	// OnComplete, when set, is called once the last byte of an upload has
+	// been received, before the response is sent. An error is returned to
+	// the client instead; the upload is kept either way.
+	OnComplete func(c *zeno.Context, info Info) error
+}
+
+// Mount registers an upload endpoint under prefix on r: uploads are
+// created by POST to prefix and addressed as prefix/{id}.
+//
+// Browser clients on another origin need the CORS middleware to allow the
+// protocol headers and expose Location and the Upload-* headers. Clients
+// that cannot send PATCH or DELETE can use X-HTTP-Method-Override with
+// zeno.MethodOverride.
+//
+// Example:
+//
+//	store, err := tus.NewFileStore("uploads")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	tus.Mount(&app.RouteGroup, "/files", tus.Config{
+//	    Store:   store,
+//	    MaxSize: 10 << 30,
+//	    OnComplete: func(c *zeno.Context, info tus.Info) error {
+//	        log.Printf("received %s (%d bytes)", info.Metadata["filename"], info.Size)
+//	        return nil
+//	    },
+//	})
+func Mount(r *zeno.RouteGroup, prefix string, config Config) {
+	if config.Store == nil {
+		panic("tus: Config requires a Store")
+	}
+	h := &handler{config: config, busy: make(map[string]struct{})}
+	prefix = strings.TrimSuffix(prefix, "/")
+	g := r.Group(prefix)
+	g.Use(h.protocol)
+	g.Options("", h.options)
+	g.Post("", h.create)
+	g.Options("/{id}", h.options)
+	g.Head("/{id}", h.head)
+	g.Patch("/{id}", h.patch)
+	g.Delete("/{id}", h.delete)
+}
+
+// handler implements the protocol.
+type handler struct {
+	config Config
+
+	mu   sync.Mutex
+	busy map[string]struct{} // uploads with a request in progress
+}
+
+// protocol sets Tus-Resumable on every response and rejects requests for
+// another protocol version. OPTIONS may be sent without a version, to
+// discover the supported ones.
+func (h *handler) protocol(c *zeno.Context) error {
+	c.SetHeader(HeaderTusResumable, Version)
+	if c.Method() != zeno.MethodOptions && c.GetHeader(HeaderTusResumable) != Version {
+		c.SetHeader(HeaderTusVersion, Version)
+		return zeno.NewHTTPError(zeno.StatusPreconditionFailed, "unsupported Tus-Resumable version")
+	}
+	return c.Next()
+}
+
+// options advertises the server's capabilities.
+func (h *handler) options(c *zeno.Context) error {
+	c.SetHeader(HeaderTusVersion, Version)
+	c.SetHeader(HeaderTusExtension, Extensions)
+	if h.config.MaxSize > 0 {
+		c.SetHeader(HeaderTusMaxSize, strconv.FormatInt(h.config.MaxSize, 10))
+	}
+	return c.NoContent()
+}
+
+// create starts a new upload, writing the request body to it if the
+// client sent one (creation-with-upload).
+func (h *handler) create(c *zeno.Context) error {
+	info := Info{Size: -1, CreatedAt: time.Now().UTC()}
+	length, deferred := c.GetHeader(HeaderUploadLength), c.GetHeader(HeaderUploadDeferLength)
+	switch {
+	case length != "" && deferred == "":
+		size, err := h.parseLength(length)
+		if err != nil {
+			return err
+		}
+		info.Size = size
+	case length == "" && deferred == "1":
+	default:
+		return zeno.NewHTTPError(zeno.StatusBadRequest, "exactly one of Upload-Length and Upload-Defer-Length: 1 is required")
+	}
+	metadata, err := parseMetadata(c.GetHeader(HeaderUploadMetadata))
+	if err != nil {
+		return zeno.NewHTTPError(zeno.StatusBadRequest, "invalid Upload-Metadata: "+err.Error())
+	}
+	info.Metadata = metadata
+	info.ID = newID()
+
+	if err := h.config.Store.Create(c.RequestCtx(), info); err != nil {
+		return err
+	}
+	c.SetHeader(zeno.HeaderLocation, c.Scheme()+"://"+c.Host()+c.Path()+"/"+info.ID)
+
+	// No other request can know the ID yet, so the upload is not locked.
+	if c.GetHeader(zeno.HeaderContentType) == ContentType {
+		if err := h.write(c, &info); err != nil {
+			return err
+		}
+	}
+	c.SetHeader(HeaderUploadOffset, strconv.FormatInt(info.Offset, 10))
+	if info.Done() && h.config.OnComplete != nil {
+		if err := h.config.OnComplete(c, info); err != nil {
+			return err
+		}
+	}
+	return c.SendStatus(zeno.StatusCreated)
+}
+
+// head reports the offset to resume the upload from.
+func (h *handler) head(c *zeno.Context) error {
+	info, err := h.info(c)
+	if err != nil {
+		return err
+	}
+	c.SetHeader(zeno.HeaderCacheControl, "no-store")
+	c.SetHeader(HeaderUploadOffset, strconv.FormatInt(info.Offset, 10))
+	if info.Size < 0 {
+		c.SetHeader(HeaderUploadDeferLength, "1")
+	} else {
+		c.SetHeader(HeaderUploadLength, strconv.FormatInt(info.Size, 10))
+	}
+	if len(info.Metadata) > 0 {
+		c.SetHeader(HeaderUploadMetadata, formatMetadata(info.Metadata))
+	}
+	c.Status(zeno.StatusOK)
+	return nil
+}
+
+// patch appends the request body to the upload at the offset the client
+// claims, which must be the current one.
+func (h *handler) patch(c *zeno.Context) error {
+	if c.GetHeader(zeno.HeaderContentType) != ContentType {
+		return zeno.NewHTTPError(zeno.StatusUnsupportedMediaType, "Content-Type must be "+ContentType)
+	}
+	offset, err := strconv.ParseInt(c.GetHeader(HeaderUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		return zeno.NewHTTPError(zeno.StatusBadRequest, "invalid Upload-Offset")
+	}
+	id := c.Param("id")
+	if !h.lock(id) {
+		return zeno.NewHTTPError(zeno.StatusLocked, "upload is locked by another request")
+	}
+	defer h.unlock(id)
+
+	info, err := h.info(c)
+	if err != nil {
+		return err
+	}
+	if offset != info.Offset {
+		return zeno.NewHTTPError(zeno.StatusConflict, "Upload-Offset does not match the current offset "+strconv.FormatInt(info.Offset, 10))
+	}
+	if length := c.GetHeader(HeaderUploadLength); length != "" && info.Size < 0 {
+		size, err := h.parseLength(length)
+		if err != nil {
+			return err
+		}
+		if size < info.Offset {
+			return zeno.NewHTTPError(zeno.StatusBadRequest, "Upload-Length is smaller than the current offset")
+		}
+		if err := h.config.Store.SetSize(c.RequestCtx(), id, size); err != nil {
+			return err
+		}
+		info.Size = size
+	}
+
+	wasDone := info.Done()
+	if err := h.write(c, &info); err != nil {
+		return err
+	}
+	c.SetHeader(HeaderUploadOffset, strconv.FormatInt(info.Offset, 10))
+	if !wasDone && info.Done() && h.config.OnComplete != nil {
+		if err := h.config.OnComplete(c, info); err != nil {
+			return err
+		}
+	}
+	return c.NoContent()
+}
+
+// delete terminates an upload, discarding its data.
+func (h *handler) delete(c *zeno.Context) error {
+	id := c.Param("id")
+	if !h.lock(id) {
+		return zeno.NewHTTPError(zeno.StatusLocked, "upload is locked by another request")
+	}
+	defer h.unlock(id)
+	if err := h.config.Store.Delete(c.RequestCtx(), id); errors.Is(err, ErrNotFound) {
+		return zeno.NewHTTPError(zeno.StatusNotFound)
+	} else if err != nil {
+		return err
+	}
+	return c.NoContent()
+}
+
+// info returns the upload addressed by the request.
+func (h *handler) info(c *zeno.Context) (Info, error) {
+	info, err := h.config.Store.Info(c.RequestCtx(), c.Param("id"))
+	if errors.Is(err, ErrNotFound) {
+		return Info{}, zeno.NewHTTPError(zeno.StatusNotFound)
+	}
+	return info, err
+}
+
+// write appends the request body to the upload and advances info.Offset
+// past the bytes stored, even if reading the body failed midway. A body
+// running past the upload's size, or past MaxSize while the size is
+// deferred, is rejected once the limit is reached.
+func (h *handler) write(c *zeno.Context, info *Info) error {
+	var body io.Reader = c.BodyReader()
+	limit := info.Size
+	if limit < 0 && h.config.MaxSize > 0 {
+		limit = h.config.MaxSize
+	}
+	if limit >= 0 {
+		remaining := limit - info.Offset
+		if n := c.RequestCtx().Request.Header.ContentLength(); n > 0 && int64(n) > remaining {
+			return zeno.NewHTTPError(zeno.StatusRequestEntityTooLarge, "body exceeds the remaining "+strconv.FormatInt(remaining, 10)+" bytes of the upload")
+		}
+		body = &limitReader{r: body, n: remaining}
+	}
+	n, err := h.config.Store.Write(c.RequestCtx(), info.ID, info.Offset, body)
+	info.Offset += n
+	switch {
+	case errors.Is(err, errTooLarge):
+		return zeno.NewHTTPError(zeno.StatusRequestEntityTooLarge, "body exceeds the size of the upload")
+	case errors.Is(err, ErrOffsetMismatch):
+		return zeno.NewHTTPError(zeno.StatusConflict, err.Error())
+	}
+	return err
+}
+
+// parseLength parses an Upload-Length header, enforcing MaxSize.
+func (h *handler) parseLength(s string) (int64, error) {
+	size, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || size < 0 {
+		return 0, zeno.NewHTTPError(zeno.StatusBadRequest, "invalid Upload-Length")
+	}
+	if h.config.MaxSize > 0 && size > h.config.MaxSize {
+		return 0, zeno.NewHTTPError(zeno.StatusRequestEntityTooLarge, "Upload-Length exceeds Tus-Max-Size")
+	}
+	return size, nil
+}
+
+// lock marks the upload as busy, reporting false if it already was.
+// Concurrent requests for one upload would corrupt its offset.
+func (h *handler) lock(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.busy[id]; ok {
+		return false
+	}
+	h.busy[id] = struct{}{}
+	return true
+}
+
+// unlock releases an upload locked by lock.
+func (h *handler) unlock(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.busy, id)
+}
+
+// errTooLarge is returned by limitReader when the body exceeds the limit.
+var errTooLarge = errors.New("tus: body exceeds the upload size")
+
+// limitReader reads at most n bytes from r and fails with errTooLarge if r
+// has more.
+type limitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		var b [1]byte
+		if n, _ := io.ReadFull(l.r, b[:]); n > 0 {
+			return 0, errTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// newID returns a random upload ID.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// parseMetadata decodes an Upload-Metadata header: comma-separated pairs
+// of a key and a base64-encoded value, which may be omitted.
+func parseMetadata(s string) (map[string]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	m := make(map[string]string)
+	for pair := range strings.SplitSeq(s, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("empty key")
+		}
+		if _, ok := m[key]; ok {
+			return nil, errors.New("duplicate key " + strconv.Quote(key))
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, errors.New("value of " + strconv.Quote(key) + " is not base64")
+		}
+		m[key] = string(decoded)
+	}
+	return m, nil
+}
+
+// formatMetadata encodes metadata for the Upload-Metadata header, with
+// the keys sorted.
+func formatMetadata(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k
+		if v := m[k]; v != "" {
+			pairs[i] += " " + base64.StdEncoding.EncodeToString([]byte(v))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,182 @@
+package tus
+
+import (
+	"io"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/Abhishek2010dev/zeno/zenotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newApp(t *testing.T, config Config) (*zenotest.Client, *FileStore) {
+	store, err := NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+	config.Store = store
+	z := zeno.New()
+	Mount(z.Group("/api"), "/files/", config)
+	tc := zenotest.New(z)
+	t.Cleanup(func() { tc.Close() })
+	return tc, store
+}
+
+func patch(tc *zenotest.Client, location, offset, body string) *zenotest.Response {
+	return tc.Patch(location).
+		Header(HeaderTusResumable, Version).
+		Header(HeaderUploadOffset, offset).
+		Body(ContentType, []byte(body)).
+		Do()
+}
+
+func TestUpload(t *testing.T) {
+	var completed []Info
+	tc, store := newApp(t, Config{OnComplete: func(c *zeno.Context, info Info) error {
+		completed = append(completed, info)
+		return nil
+	}})
+
+	res := tc.Request(zeno.MethodOptions, "/api/files").Do().
+		ExpectStatus(t, zeno.StatusNoContent).
+		ExpectHeader(t, HeaderTusVersion, Version).
+		ExpectHeader(t, HeaderTusExtension, Extensions)
+	assert.Empty(t, res.Header.Get(HeaderTusMaxSize))
+
+	res = tc.Post("/api/files").
+		Header(HeaderTusResumable, Version).
+		Header(HeaderUploadLength, "11").
+		Header(HeaderUploadMetadata, "filename aGVsbG8udHh0,is_confidential").
+		Do().
+		ExpectStatus(t, zeno.StatusCreated).
+		ExpectHeader(t, HeaderTusResumable, Version).
+		ExpectHeader(t, HeaderUploadOffset, "0")
+	location := res.Header.Get(zeno.HeaderLocation)
+	assert.True(t, strings.HasPrefix(location, "http://zeno.test/api/files/"), location)
+	location = strings.TrimPrefix(location, "http://zeno.test")
+	id := path.Base(location)
+
+	patch(tc, location, "0", "hello").
+		ExpectStatus(t, zeno.StatusNoContent).
+		ExpectHeader(t, HeaderUploadOffset, "5")
+
+	// A retried chunk the server already received is rejected.
+	patch(tc, location, "0", "hello").ExpectStatus(t, zeno.StatusConflict)
+
+	tc.Request(zeno.MethodHead, location).Header(HeaderTusResumable, Version).Do().
+		ExpectStatus(t, zeno.StatusOK).
+		ExpectHeader(t, HeaderUploadOffset, "5").
+		ExpectHeader(t, HeaderUploadLength, "11").
+		ExpectHeader(t, HeaderUploadMetadata, "filename aGVsbG8udHh0,is_confidential").
+		ExpectHeader(t, zeno.HeaderCacheControl, "no-store")
+	assert.Empty(t, completed)
+
+	patch(tc, location, "5", " world!").ExpectStatus(t, zeno.StatusRequestEntityTooLarge)
+	patch(tc, location, "5", " world").
+		ExpectStatus(t, zeno.StatusNoContent).
+		ExpectHeader(t, HeaderUploadOffset, "11")
+
+	if assert.Len(t, completed, 1) {
+		assert.Equal(t, id, completed[0].ID)
+		assert.Equal(t, map[string]string{"filename": "hello.txt", "is_confidential": ""}, completed[0].Metadata)
+	}
+	r, err := store.Open(t.Context(), id)
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "hello world", string(data))
+
+	tc.Delete(location).Header(HeaderTusResumable, Version).Do().ExpectStatus(t, zeno.StatusNoContent)
+	tc.Request(zeno.MethodHead, location).Header(HeaderTusResumable, Version).Do().ExpectStatus(t, zeno.StatusNotFound)
+}
+
+func TestUpload_DeferLengthAndCreationWithUpload(t *testing.T) {
+	tc, _ := newApp(t, Config{MaxSize: 10})
+
+	tc.Request(zeno.MethodOptions, "/api/files").Do().ExpectHeader(t, HeaderTusMaxSize, "10")
+	tc.Post("/api/files").Header(HeaderTusResumable, Version).Header(HeaderUploadLength, "11").Do().
+		ExpectStatus(t, zeno.StatusRequestEntityTooLarge)
+
+	res := tc.Post("/api/files").
+		Header(HeaderTusResumable, Version).
+		Header(HeaderUploadDeferLength, "1").
+		Body(ContentType, []byte("abc")).
+		Do().
+		ExpectStatus(t, zeno.StatusCreated).
+		ExpectHeader(t, HeaderUploadOffset, "3")
+	location := strings.TrimPrefix(res.Header.Get(zeno.HeaderLocation), "http://zeno.test")
+
+	tc.Request(zeno.MethodHead, location).Header(HeaderTusResumable, Version).Do().
+		ExpectHeader(t, HeaderUploadDeferLength, "1")
+
+	tc.Patch(location).
+		Header(HeaderTusResumable, Version).
+		Header(HeaderUploadOffset, "3").
+		Header(HeaderUploadLength, "5").
+		Body(ContentType, []byte("de")).
+		Do().
+		ExpectStatus(t, zeno.StatusNoContent).
+		ExpectHeader(t, HeaderUploadOffset, "5")
+
+	tc.Request(zeno.MethodHead, location).Header(HeaderTusResumable, Version).Do().
+		ExpectHeader(t, HeaderUploadLength, "5")
+}
+
+func TestUpload_DeferLengthMaxSize(t *testing.T) {
+	tc, _ := newApp(t, Config{MaxSize: 5})
+
+	tc.Post("/api/files").
+		Header(HeaderTusResumable, Version).
+		Header(HeaderUploadDeferLength, "1").
+		Body(ContentType, []byte("abcdef")).
+		Do().
+		ExpectStatus(t, zeno.StatusRequestEntityTooLarge)
+
+	res := tc.Post("/api/files").
+		Header(HeaderTusResumable, Version).
+		Header(HeaderUploadDeferLength, "1").
+		Do().
+		ExpectStatus(t, zeno.StatusCreated)
+	location := strings.TrimPrefix(res.Header.Get(zeno.HeaderLocation), "http://zeno.test")
+
+	patch(tc, location, "0", "abc").ExpectStatus(t, zeno.StatusNoContent)
+	patch(tc, location, "3", "def").ExpectStatus(t, zeno.StatusRequestEntityTooLarge)
+	patch(tc, location, "3", "de").
+		ExpectStatus(t, zeno.StatusNoContent).
+		ExpectHeader(t, HeaderUploadOffset, "5")
+}
+
+func TestUpload_Errors(t *testing.T) {
+	tc, _ := newApp(t, Config{})
+
+	tc.Post("/api/files").Header(HeaderUploadLength, "1").Do().
+		ExpectStatus(t, zeno.StatusPreconditionFailed).
+		ExpectHeader(t, HeaderTusVersion, Version)
+	tc.Post("/api/files").Header(HeaderTusResumable, Version).Do().
+		ExpectStatus(t, zeno.StatusBadRequest)
+	tc.Post("/api/files").Header(HeaderTusResumable, Version).Header(HeaderUploadLength, "1").
+		Header(HeaderUploadMetadata, "name !!").Do().
+		ExpectStatus(t, zeno.StatusBadRequest)
+
+	res := tc.Post("/api/files").Header(HeaderTusResumable, Version).Header(HeaderUploadLength, "1").Do()
+	location := strings.TrimPrefix(res.Header.Get(zeno.HeaderLocation), "http://zeno.test")
+	tc.Patch(location).Header(HeaderTusResumable, Version).Header(HeaderUploadOffset, "0").
+		Body("application/octet-stream", []byte("x")).Do().
+		ExpectStatus(t, zeno.StatusUnsupportedMediaType)
+	patch(tc, location, "", "x").ExpectStatus(t, zeno.StatusBadRequest)
+	patch(tc, "/api/files/0123456789abcdef", "0", "x").ExpectStatus(t, zeno.StatusNotFound)
+	patch(tc, "/api/files/..", "0", "x").ExpectStatus(t, zeno.StatusNotFound)
+}
+
+func TestMetadata(t *testing.T) {
+	m, err := parseMetadata("a YQ==, b ,c Yw==")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "a", "b": "", "c": "c"}, m)
+	assert.Equal(t, "a YQ==,b,c Yw==", formatMetadata(m))
+
+	_, err = parseMetadata("a YQ==,a YQ==")
+	assert.ErrorContains(t, err, "duplicate")
+	m, err = parseMetadata("")
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+}
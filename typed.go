@@ -0,0 +1,92 @@
+package zeno
+
+// Validatable is implemented by request types that can check themselves
+// after binding. H calls Validate and answers 400 Bad Request when it
+// returns an error that is not already an HTTPError.
+type Validatable interface {
+	Validate() error
+}
+
+// H adapts a typed function into a Handler. For every request it binds a
+// new Req from the route parameters (`param` tags), the query string
+// (`query` tags) and, when present, the body (decoded according to its
+// Content-Type, see BodyParsed), calls Validate if Req implements
+// Validatable, invokes fn and sends the returned Resp as JSON.
+//
+// Set the status before returning to override the default 200 OK. When
+// Resp is an interface type and fn returns nil, 204 No Content is sent.
+//
+// Example:
+//
+//	type GetUser struct {
+//	    ID     int  `param:"id"`
+//	    Expand bool `query:"expand"`
+//	}
+//
+//	app.Get("/users/{id}", zeno.H(func(c *zeno.Context, req GetUser) (User, error) {
+//	    return users.Find(req.ID, req.Expand)
+//	}))
+func H[Req, Resp any](fn func(c *Context, req Req) (Resp, error)) Handler {
+	return func(c *Context) error {
+		var req Req
+		if err := c.bindRequest(&req); err != nil {
+			return err
+		}
+		if v, ok := any(&req).(Validatable); ok {
+			if err := v.Validate(); err != nil {
+				if _, ok := err.(HTTPError); ok {
+					return err
+				}
+				return NewHTTPError(StatusBadRequest, err.Error())
+			}
+		}
+		resp, err := fn(c, req)
+		if err != nil {
+			return err
+		}
+		if any(resp) == nil {
+			c.Status(StatusNoContent)
+			return nil
+		}
+		return c.SendJSON(resp)
+	}
+}
+
+// Bind fills out from the route parameters (`param` tags), the query
+// string (`query` tags) and, when present, the body, decoded according to
+// its Content-Type as by BodyParsed. Untagged fields are filled from the
+// body only. Multipart forms fill both `form`
+// and `file` tagged fields, see BindForm.
+//
+// Example:
+//
+//	var req UploadRequest
+//	if err := c.Bind(&req); err != nil {
+//	    return err
+//	}
+func (c *Context) Bind(out any) error {
+	return c.bindRequest(out)
+}
+
+// bindRequest fills out from the query string, the request body and the
+// route parameters, in that order. The parameters are bound last so that
+// neither the query nor the body can override the resource the route
+// selected. Only fields tagged `query` or `param` are bound from the query
+// string and the parameters, so a query such as "?isadmin=true" cannot
+// fill a field meant for the body.
+func (c *Context) bindRequest(out any) error {
+	if c.ctx.QueryArgs().Len() > 0 {
+		if err := c.bindQuery(out, bindTaggedTree); err != nil {
+			return err
+		}
+	}
+	if len(c.PostBody()) > 0 {
+		if err := c.bindBody(out); err != nil {
+			return err
+		}
+	}
+	if len(c.pnames) > 0 {
+		return c.bindParams(out, bindTaggedTree)
+	}
+	return nil
+}
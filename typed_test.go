@@ -0,0 +1,80 @@
+package zeno
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type createPost struct {
+	UserID int    `param:"id" json:"-"`
+	Draft  bool   `query:"draft" json:"-"`
+	Title  string `json:"title"`
+}
+
+func (r *createPost) Validate() error {
+	if r.Title == "" {
+		return errors.New("title is required")
+	}
+	return nil
+}
+
+func TestH(t *testing.T) {
+	z := New()
+	z.Post("/users/{id}/posts", H(func(c *Context, req createPost) (createPost, error) {
+		c.Status(StatusCreated)
+		return req, nil
+	}))
+
+	ctx := performRequest(z, "POST", "/users/7/posts?draft=true", map[string]string{HeaderContentType: "application/json"}, []byte(`{"title":"hi"}`))
+	assert.Equal(t, StatusCreated, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"title":"hi"}`, string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "POST", "/users/7/posts", map[string]string{HeaderContentType: "application/json"}, []byte(`{}`))
+	assert.Equal(t, StatusBadRequest, ctx.Response.StatusCode())
+
+	ctx = performRequest(z, "POST", "/users/x/posts", nil, nil)
+	assert.Equal(t, StatusBadRequest, ctx.Response.StatusCode())
+}
+
+func TestH_Binding(t *testing.T) {
+	c, _ := newTestContext("POST", "/?draft=1", map[string]string{HeaderContentType: "application/json"}, []byte(`{"title":"t"}`))
+	c.pnames, c.pvalues = []string{"id"}, []string{"3"}
+	var req createPost
+	assert.NoError(t, c.bindRequest(&req))
+	assert.Equal(t, createPost{UserID: 3, Draft: true, Title: "t"}, req)
+}
+
+func TestH_UntaggedFieldsFromBodyOnly(t *testing.T) {
+	type updateProfile struct {
+		Name    string `json:"name"`
+		IsAdmin bool
+	}
+	z := New()
+	z.Put("/users/{isadmin}", H(func(c *Context, req updateProfile) (updateProfile, error) {
+		return req, nil
+	}))
+
+	ctx := performRequest(z, "PUT", "/users/true?isadmin=true&IsAdmin=true", map[string]string{HeaderContentType: "application/json"}, []byte(`{"name":"ann"}`))
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"name":"ann","IsAdmin":false}`, string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "PUT", "/users/x", map[string]string{HeaderContentType: "application/json"}, []byte(`{"name":"ann","isadmin":true}`))
+	assert.JSONEq(t, `{"name":"ann","IsAdmin":true}`, string(ctx.Response.Body()))
+}
+
+func TestH_ParamsWin(t *testing.T) {
+	type updateUser struct {
+		ID   int    `param:"id" query:"ID" json:"id"`
+		Name string `json:"name"`
+	}
+	z := New()
+	z.Put("/users/{id}", H(func(c *Context, req updateUser) (updateUser, error) {
+		return req, nil
+	}))
+
+	ctx := performRequest(z, "PUT", "/users/1?ID=777", map[string]string{HeaderContentType: "application/json"}, []byte(`{"id":999,"name":"ann"}`))
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"id":1,"name":"ann"}`, string(ctx.Response.Body()))
+}
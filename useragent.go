@@ -0,0 +1,244 @@
+package zeno
+
+import (
+	"slices"
+	"strings"
+)
+
+// UserAgent is the result of classifying a User-Agent header with
+// ParseUserAgent. The classification is heuristic and covers the common
+// browsers, operating systems, crawlers and HTTP tools; anything else is
+// left with an empty Name.
+type UserAgent struct {
+	// Raw is the header value.
+	Raw string
+
+	// Name and Version identify the browser, crawler or tool, e.g.
+	// "Firefox" and "126.0", or "Googlebot" and "2.1".
+	Name    string
+	Version string
+
+	// OS is the operating system, e.g. "Windows", "macOS", "iOS",
+	// "Android" or "Linux".
+	OS string
+
+	Bot    bool // a crawler, monitoring service or scripted HTTP client
+	Mobile bool // a phone
+	Tablet bool // a tablet
+}
+
+// IsBrowser reports whether the agent is a recognized browser.
+func (ua UserAgent) IsBrowser() bool {
+	return !ua.Bot && ua.Name != ""
+}
+
+// uaToken maps a product token found in a User-Agent to the name reported.
+type uaToken struct {
+	token, name string
+}
+
+// botTokens are matched case insensitively, before the generic bot words.
+var botTokens = []uaToken{
+	{"googlebot", "Googlebot"},
+	{"adsbot-google", "AdsBot-Google"},
+	{"bingbot", "Bingbot"},
+	{"yandexbot", "YandexBot"},
+	{"baiduspider", "Baiduspider"},
+	{"duckduckbot", "DuckDuckBot"},
+	{"slurp", "Yahoo! Slurp"},
+	{"applebot", "Applebot"},
+	{"facebookexternalhit", "facebookexternalhit"},
+	{"twitterbot", "Twitterbot"},
+	{"linkedinbot", "LinkedInBot"},
+	{"slackbot", "Slackbot"},
+	{"discordbot", "Discordbot"},
+	{"ahrefsbot", "AhrefsBot"},
+	{"semrushbot", "SemrushBot"},
+	{"mj12bot", "MJ12bot"},
+	{"petalbot", "PetalBot"},
+	{"gptbot", "GPTBot"},
+	{"headlesschrome", "HeadlessChrome"},
+	{"curl/", "curl"},
+	{"wget/", "Wget"},
+	{"python-requests", "python-requests"},
+	{"python-urllib", "Python-urllib"},
+	{"go-http-client", "Go-http-client"},
+	{"okhttp", "okhttp"},
+	{"java/", "Java"},
+	{"postmanruntime", "PostmanRuntime"},
+}
+
+// botWords mark an agent as a bot when no known token matched.
+var botWords = []string{"bot", "crawler", "spider", "crawl", "scraper", "monitor", "http-client"}
+
+// browserTokens are tried in order: browsers built on Chrome or Safari
+// also carry their tokens, so the more specific ones come first.
+var browserTokens = []uaToken{
+	{"Edg/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"YaBrowser/", "Yandex Browser"},
+	{"Vivaldi/", "Vivaldi"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Version/", "Safari"},
+	{"MSIE ", "Internet Explorer"},
+	{"rv:", "Internet Explorer"},
+}
+
+// ParseUserAgent classifies the User-Agent header value s.
+//
+// Example:
+//
+//	ua := zeno.ParseUserAgent("Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) ... Version/17.4 Mobile/15E148 Safari/604.1")
+//	// ua.Name == "Safari", ua.Version == "17.4", ua.OS == "iOS", ua.Mobile == true
+func ParseUserAgent(s string) UserAgent {
+	ua := UserAgent{Raw: s}
+	ua.OS = parseUAOS(s)
+
+	for _, t := range botTokens {
+		if i := indexFold(s, t.token); i >= 0 {
+			ua.Name, ua.Bot = t.name, true
+			ua.Version = uaVersion(strings.TrimPrefix(s[i+len(t.token):], "/"))
+			return ua
+		}
+	}
+	for _, w := range botWords {
+		if indexFold(s, w) >= 0 {
+			ua.Bot = true
+			return ua
+		}
+	}
+
+	for _, t := range browserTokens {
+		i := strings.Index(s, t.token)
+		if i < 0 {
+			continue
+		}
+		if t.name == "Safari" && !strings.Contains(s, "Safari/") {
+			continue
+		}
+		if t.token == "rv:" && !strings.Contains(s, "Trident/") {
+			continue
+		}
+		ua.Name, ua.Version = t.name, uaVersion(s[i+len(t.token):])
+		break
+	}
+
+	switch {
+	case strings.Contains(s, "iPad") || (ua.OS == "Android" && !strings.Contains(s, "Mobile")):
+		ua.Tablet = true
+	case strings.Contains(s, "Mobi") || strings.Contains(s, "iPhone"):
+		ua.Mobile = true
+	}
+	return ua
+}
+
+// parseUAOS returns the operating system named in s.
+func parseUAOS(s string) string {
+	switch {
+	case strings.Contains(s, "Windows"):
+		return "Windows"
+	case strings.Contains(s, "iPhone") || strings.Contains(s, "iPad") || strings.Contains(s, "iPod"):
+		return "iOS"
+	case strings.Contains(s, "Android"):
+		return "Android"
+	case strings.Contains(s, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(s, "Mac OS X") || strings.Contains(s, "Macintosh"):
+		return "macOS"
+	case strings.Contains(s, "Linux"):
+		return "Linux"
+	}
+	return ""
+}
+
+// uaVersion returns the version at the start of s, e.g. "126.0" from
+// "126.0 Safari/537.36".
+func uaVersion(s string) string {
+	end := 0
+	for end < len(s) && (s[end] == '.' || s[end] >= '0' && s[end] <= '9' || s[end] == '_') {
+		end++
+	}
+	return strings.TrimRight(s[:end], "._")
+}
+
+// userAgentKey is the Context key caching the parsed User-Agent.
+const userAgentKey = "zeno.useragent"
+
+// UserAgent returns the classified User-Agent header of the request. The
+// result is parsed once per request.
+//
+// Example:
+//
+//	if ua := c.UserAgent(); ua.Bot {
+//	    return c.SendString(prerendered)
+//	}
+func (c *Context) UserAgent() UserAgent {
+	if ua, ok := c.Get(userAgentKey).(UserAgent); ok {
+		return ua
+	}
+	ua := ParseUserAgent(c.GetHeader(HeaderUserAgent))
+	c.Set(userAgentKey, ua)
+	return ua
+}
+
+// BotConfig configures the Bots middleware.
+type BotConfig struct {
+	// Block rejects requests from bots, except those named in Allow.
+	Block bool
+
+	// Allow names the bots let through when blocking, e.g. "Googlebot",
+	// compared case insensitively.
+	Allow []string
+
+	// BlockEmpty also treats requests without a User-Agent as bots.
+	BlockEmpty bool
+
+	// Denied handles rejected requests. By default ErrForbidden is
+	// returned, answering 403 Forbidden.
+	Denied Handler
+
+	// Skipper, when set, lets requests for which it returns true bypass
+	// the middleware.
+	Skipper Skipper
+}
+
+// Bots returns a middleware that classifies the User-Agent of every
+// request, making it available through Context.UserAgent to later
+// handlers, e.g. for analytics or to rate limit crawlers separately, and
+// optionally blocks bots.
+//
+// Example:
+//
+//	app.Use(zeno.Bots(zeno.BotConfig{
+//	    Block: true,
+//	    Allow: []string{"Googlebot", "Bingbot"},
+//	}))
+func Bots(config ...BotConfig) Handler {
+	var cfg BotConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	return func(c *Context) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		ua := c.UserAgent()
+		bot := ua.Bot || (cfg.BlockEmpty && ua.Raw == "")
+		if !cfg.Block || !bot || (ua.Name != "" && slices.ContainsFunc(cfg.Allow, func(name string) bool {
+			return strings.EqualFold(name, ua.Name)
+		})) {
+			return c.Next()
+		}
+		if cfg.Denied != nil {
+			c.Abort()
+			return cfg.Denied(c)
+		}
+		return ErrForbidden
+	}
+}
@@ -0,0 +1,70 @@
+package zeno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		ua   string
+		want UserAgent
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36",
+			UserAgent{Name: "Chrome", Version: "125.0.0.0", OS: "Windows"},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36 Edg/125.0.2535.51",
+			UserAgent{Name: "Edge", Version: "125.0.2535.51", OS: "Windows"},
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 14.5; rv:126.0) Gecko/20100101 Firefox/126.0",
+			UserAgent{Name: "Firefox", Version: "126.0", OS: "macOS"},
+		},
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			UserAgent{Name: "Safari", Version: "17.4", OS: "iOS", Mobile: true},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 14; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			UserAgent{Name: "Chrome", Version: "124.0.0.0", OS: "Android", Tablet: true},
+		},
+		{
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			UserAgent{Name: "Googlebot", Version: "2.1", Bot: true},
+		},
+		{"curl/8.6.0", UserAgent{Name: "curl", Version: "8.6.0", Bot: true}},
+		{"SomeCrawler/1.0 (+https://example.com)", UserAgent{Bot: true}},
+		{"", UserAgent{}},
+		// Invalid UTF-8 changes length when lower-cased.
+		{"\xff\xff\xff\xff\xff\xff\xff\xff\xff\xffcurl/8.6.0", UserAgent{Name: "curl", Version: "8.6.0", Bot: true}},
+		{"\u0130K Wget/1.21", UserAgent{Name: "Wget", Version: "1.21", Bot: true}},
+	}
+	for _, tt := range tests {
+		tt.want.Raw = tt.ua
+		assert.Equal(t, tt.want, ParseUserAgent(tt.ua), tt.ua)
+	}
+	assert.True(t, ParseUserAgent(tests[0].ua).IsBrowser())
+	assert.False(t, ParseUserAgent("curl/8.6.0").IsBrowser())
+}
+
+func TestBots(t *testing.T) {
+	z := New()
+	z.Use(Bots(BotConfig{Block: true, Allow: []string{"googlebot"}, BlockEmpty: true}))
+	z.Get("/", func(c *Context) error { return c.SendString(c.UserAgent().Name) })
+
+	ua := func(v string) map[string]string { return map[string]string{HeaderUserAgent: v} }
+	ctx := performRequest(z, "GET", "/", ua("Mozilla/5.0 (X11; Linux x86_64; rv:126.0) Gecko/20100101 Firefox/126.0"), nil)
+	assert.Equal(t, "Firefox", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/", ua("Mozilla/5.0 (compatible; Googlebot/2.1)"), nil)
+	assert.Equal(t, "Googlebot", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/", ua("python-requests/2.31"), nil)
+	assert.Equal(t, StatusForbidden, ctx.Response.StatusCode())
+
+	ctx = performRequest(z, "GET", "/", nil, nil)
+	assert.Equal(t, StatusForbidden, ctx.Response.StatusCode())
+}
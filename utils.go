@@ -1,57 +1,208 @@
 package zeno
 
 import (
+	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// toType tries to convert a string to a primitive type T.
-// If conversion fails, it returns the zero value of T.
-func toType[T any](s string) T {
+// parseType converts a string to type T, reporting an error if s is not
+// a valid representation. Converters registered in conv take precedence
+// over the built-in primitive conversions. On failure the zero value of T
+// is returned.
+func parseType[T any](s string, conv converter) (T, error) {
 	var zero T
+	if fn := conv.custom[reflect.TypeFor[T]()]; fn != nil {
+		v, err := fn(s)
+		if err != nil {
+			return zero, err
+		}
+		return v.Interface().(T), nil
+	}
+	var v any
+	var err error
 	switch any(zero).(type) {
 	case int:
-		v, _ := strconv.Atoi(s)
-		return any(v).(T)
+		v, err = strconv.Atoi(s)
 	case int64:
-		v, _ := strconv.ParseInt(s, 10, 64)
-		return any(v).(T)
+		v, err = strconv.ParseInt(s, 10, 64)
 	case float64:
-		v, _ := strconv.ParseFloat(s, 64)
-		return any(v).(T)
-	case float32:
-		v, _ := strconv.ParseFloat(s, 32)
-		return any(float32(v)).(T)
+		v, err = strconv.ParseFloat(s, 64)
 	case bool:
-		v, _ := strconv.ParseBool(strings.ToLower(s))
-		return any(v).(T)
+		v, err = strconv.ParseBool(strings.ToLower(s))
 	case string:
-		return any(s).(T)
-	case uint:
-		v, _ := strconv.ParseUint(s, 10, 64)
-		return any(uint(v)).(T)
-	case uint64:
-		v, _ := strconv.ParseUint(s, 10, 64)
-		return any(v).(T)
-	case uint32:
-		v, _ := strconv.ParseUint(s, 10, 32)
-		return any(uint32(v)).(T)
-	case int32:
-		v, _ := strconv.ParseInt(s, 10, 32)
-		return any(int32(v)).(T)
-	case int16:
-		v, _ := strconv.ParseInt(s, 10, 16)
-		return any(int16(v)).(T)
-	case uint16:
-		v, _ := strconv.ParseUint(s, 10, 16)
-		return any(uint16(v)).(T)
-	case int8:
-		v, _ := strconv.ParseInt(s, 10, 8)
-		return any(int8(v)).(T)
-	case uint8:
-		v, _ := strconv.ParseUint(s, 10, 8)
-		return any(uint8(v)).(T)
+		return any(s).(T), nil
+	default:
+		var t T
+		if err := setValue(reflect.ValueOf(&t).Elem(), s, conv); err != nil {
+			return zero, err
+		}
+		return t, nil
+	}
+	if err != nil {
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+// setValue parses s and stores the result in v, which must be settable.
+// It supports the same primitive kinds as parseType, time.Time and
+// time.Duration, plus any type with a converter in conv, and reports
+// conversion failures so binding helpers can turn them into 400s.
+func setValue(v reflect.Value, s string, conv converter) error {
+	if ok, err := conv.set(v, s); ok {
+		return err
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+		if ok, err := conv.set(v, s); ok {
+			return err
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		if s == "" {
+			v.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(strings.ToLower(s))
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s == "" {
+			v.SetUint(0)
+			return nil
+		}
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// formatValue is the inverse of setValue: it renders a primitive value
+// as a string. Pointers are dereferenced and nil pointers render as "".
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch v.Type() {
+	case timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339Nano)
+	case durationType:
+		return v.Interface().(time.Duration).String()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
 	default:
-		return zero
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// isUUID reports whether s is a UUID in canonical 8-4-4-4-12 hex form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			c := s[i]
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// indexFold returns the index of the first instance of needle, which
+// must be lower-case ASCII, in s, ignoring the case of ASCII letters, or
+// -1. Unlike searching strings.ToLower(s), whose length differs from s
+// for invalid UTF-8 and some non-ASCII letters, the index is valid in s.
+func indexFold[T string | []byte](s T, needle string) int {
+	for i := 0; i+len(needle) <= len(s); i++ {
+		if hasPrefixFold(s[i:], needle) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastIndexFold is like indexFold but returns the index of the last
+// instance of needle in s.
+func lastIndexFold[T string | []byte](s T, needle string) int {
+	for i := len(s) - len(needle); i >= 0; i-- {
+		if hasPrefixFold(s[i:], needle) {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasPrefixFold reports whether s starts with needle, which must be
+// lower-case ASCII, ignoring the case of ASCII letters.
+func hasPrefixFold[T string | []byte](s T, needle string) bool {
+	if len(s) < len(needle) {
+		return false
+	}
+	for j := 0; j < len(needle); j++ {
+		b := s[j]
+		if 'A' <= b && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		if b != needle[j] {
+			return false
+		}
 	}
+	return true
 }
@@ -1,35 +1,125 @@
 package zeno
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestToType(t *testing.T) {
-	assert.Equal(t, 123, toType[int]("123"))
-	assert.Equal(t, int64(-456), toType[int64]("-456"))
-	assert.Equal(t, float64(3.14), toType[float64]("3.14"))
-	assert.Equal(t, float32(2.718), toType[float32]("2.718"))
-	assert.Equal(t, true, toType[bool]("true"))
-	assert.Equal(t, false, toType[bool]("false"))
-	assert.Equal(t, "hello", toType[string]("hello"))
-
-	assert.Equal(t, uint(42), toType[uint]("42"))
-	assert.Equal(t, uint8(255), toType[uint8]("255"))
-	assert.Equal(t, int8(-128), toType[int8]("-128"))
-	assert.Equal(t, int16(32767), toType[int16]("32767"))
-	assert.Equal(t, uint16(65535), toType[uint16]("65535"))
-	assert.Equal(t, int32(2147483647), toType[int32]("2147483647"))
-	assert.Equal(t, uint32(4294967295), toType[uint32]("4294967295"))
-	assert.Equal(t, int64(9223372036854775807), toType[int64]("9223372036854775807"))
-	assert.Equal(t, uint64(18446744073709551615), toType[uint64]("18446744073709551615"))
-
-	// Invalid inputs should return zero value
-	assert.Equal(t, 0, toType[int]("invalid"))
-	assert.Equal(t, float64(0), toType[float64]("invalid"))
-	assert.Equal(t, false, toType[bool]("invalid"))
-
-	// For string, it always returns the same string
-	assert.Equal(t, "invalid", toType[string]("invalid"))
+func TestParseType(t *testing.T) {
+	conv := converter{}
+	for in, want := range map[string]any{
+		"123":                  123,
+		"-456":                 int64(-456),
+		"3.14":                 float64(3.14),
+		"2.718":                float32(2.718),
+		"true":                 true,
+		"FALSE":                false,
+		"hello":                "hello",
+		"42":                   uint(42),
+		"255":                  uint8(255),
+		"-128":                 int8(-128),
+		"32767":                int16(32767),
+		"65535":                uint16(65535),
+		"2147483647":           int32(2147483647),
+		"4294967295":           uint32(4294967295),
+		"9223372036854775807":  int64(9223372036854775807),
+		"18446744073709551615": uint64(18446744073709551615),
+	} {
+		v := reflect.New(reflect.TypeOf(want)).Elem()
+		assert.NoError(t, setValue(v, in, conv), in)
+		assert.Equal(t, want, v.Interface(), in)
+	}
+
+	// Invalid inputs report an error and yield the zero value.
+	n, err := parseType[int]("invalid", conv)
+	assert.Error(t, err)
+	assert.Equal(t, 0, n)
+	f, err := parseType[float64]("invalid", conv)
+	assert.Error(t, err)
+	assert.Equal(t, float64(0), f)
+	b, err := parseType[bool]("invalid", conv)
+	assert.Error(t, err)
+	assert.False(t, b)
+
+	// Strings are returned unchanged.
+	s, err := parseType[string]("invalid", conv)
+	assert.NoError(t, err)
+	assert.Equal(t, "invalid", s)
+}
+
+type color struct{ R, G, B uint8 }
+
+func parseColor(s string) (color, error) {
+	var c color
+	_, err := fmt.Sscanf(s, "#%02x%02x%02x", &c.R, &c.G, &c.B)
+	return c, err
+}
+
+func TestRegisterConverter(t *testing.T) {
+	z := New()
+	z.RegisterConverter(parseColor)
+
+	v, err := parseType[color]("#ff8000", z.converter())
+	assert.NoError(t, err)
+	assert.Equal(t, color{255, 128, 0}, v)
+
+	var out struct {
+		Fg      color   `query:"fg"`
+		Palette []color `query:"p"`
+	}
+	tree := valueTree{}
+	tree.add("fg", "#010203", "")
+	tree.add("p", "#000000", "")
+	tree.add("p", "#ffffff", "")
+	assert.NoError(t, bindTree(&out, tree, "query", z.converter()))
+	assert.Equal(t, color{1, 2, 3}, out.Fg)
+	assert.Equal(t, []color{{0, 0, 0}, {255, 255, 255}}, out.Palette)
+
+	assert.Panics(t, func() { z.RegisterConverter(func(int) (color, error) { return color{}, nil }) })
+}
+
+func TestParseTimeAndDuration(t *testing.T) {
+	conv := converter{}
+
+	ts, err := parseType[time.Time]("2024-05-01T10:00:00Z", conv)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), ts)
+
+	ts, err = parseType[time.Time]("1714557600", conv)
+	assert.NoError(t, err)
+	assert.True(t, ts.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)))
+
+	conv.time.UnixUnit = time.Millisecond
+	ts, err = parseType[time.Time]("1714557600000", conv)
+	assert.NoError(t, err)
+	assert.True(t, ts.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)))
+
+	_, err = parseType[time.Time]("yesterday", conv)
+	assert.Error(t, err)
+
+	d, err := parseType[time.Duration]("1m30s", conv)
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Second, d)
+	d, err = parseType[time.Duration]("30", conv)
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, d)
+	_, err = parseType[time.Duration]("9223372036854775807", conv)
+	assert.Error(t, err, "seconds overflowing time.Duration")
+	_, err = parseType[time.Time]("-9223372036854775", conv)
+	assert.Error(t, err, "milliseconds overflowing time.Duration")
+
+	var out struct {
+		Since   *time.Time    `query:"since"`
+		Timeout time.Duration `query:"timeout"`
+	}
+	tree := valueTree{}
+	tree.add("since", "2024-05-01", "")
+	tree.add("timeout", "5s", "")
+	assert.NoError(t, bindTree(&out, tree, "query", converter{}))
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), *out.Since)
+	assert.Equal(t, 5*time.Second, out.Timeout)
 }
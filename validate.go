@@ -0,0 +1,93 @@
+package zeno
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// routePattern expands the shorthand a route path may end with, a bare
+// "*" matching the rest of the path, into the parameter syntax the tree
+// understands.
+func routePattern(path string) string {
+	if strings.HasSuffix(path, "*") {
+		return path[:len(path)-1] + "{:.*}"
+	}
+	return path
+}
+
+// ValidatePath reports whether path is a valid route pattern, returning a
+// descriptive error if it is not: a parameter is left unclosed, its
+// regular expression does not compile, or a wildcard parameter ({name*})
+// is followed by more of the pattern.
+//
+// Route registration helpers such as Get panic on these errors, which is
+// appropriate for routes fixed at compile time. Validate routes loaded at
+// runtime first, or register them with AddRoute.
+//
+// Example:
+//
+//	if err := zeno.ValidatePath("/files/{path*}/meta"); err != nil {
+//	    log.Print(err) // wildcard parameter must be terminal
+//	}
+func ValidatePath(path string) error {
+	pattern := routePattern(path)
+	for rest := pattern; ; {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			return nil
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return fmt.Errorf("zeno: unclosed parameter in route pattern %q", path)
+		}
+		end += open
+		// As in the tree, a parameter starts at the last '{' before its '}'.
+		open += strings.LastIndexByte(rest[open:end], '{')
+		raw := rest[open+1 : end]
+		rest = rest[end+1:]
+
+		if len(raw) > 1 && raw[0] == '*' {
+			raw = raw[1:] + "*"
+		}
+		name, re, hasRe := strings.Cut(raw, ":")
+		name = strings.TrimSuffix(name, "?")
+		if strings.HasSuffix(name, "*") && rest != "" {
+			return fmt.Errorf("zeno: wildcard parameter must be terminal in route pattern %q", path)
+		}
+		if hasRe && re != "" {
+			if _, err := regexp.Compile("^" + re); err != nil {
+				return fmt.Errorf("zeno: invalid regular expression in route pattern %q: %w", path, err)
+			}
+		}
+	}
+}
+
+// ErrRouteExists is returned by AddRoute when a route is already
+// registered for the method and path.
+var ErrRouteExists = errors.New("zeno: route is already registered")
+
+// AddRoute registers handlers for method and path like Handle, but returns
+// an error instead of panicking when path is not a valid pattern (see
+// ValidatePath) or a route is already registered for method and path, so
+// routes loaded at runtime, e.g. from configuration or a plugin, cannot
+// bring the server down. Nothing is registered on error.
+//
+// Example:
+//
+//	for _, def := range loadedRoutes {
+//	    if _, err := app.AddRoute(def.Method, def.Path, proxyTo(def.Upstream)); err != nil {
+//	        log.Printf("skipping route: %v", err)
+//	    }
+//	}
+func (r *RouteGroup) AddRoute(method, path string, handlers ...Handler) (*Route, error) {
+	full := r.prefix + path
+	if err := ValidatePath(full); err != nil {
+		return nil, err
+	}
+	if r.zeno.hasRoute(method, routePattern(full)) {
+		return nil, fmt.Errorf("%w: %s %s", ErrRouteExists, method, full)
+	}
+	return r.Handle(method, path, handlers...), nil
+}
@@ -0,0 +1,52 @@
+package zeno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePath(t *testing.T) {
+	for _, p := range []string{"/", "/users/{id}", "/users/{id:[0-9]+}/{slug?}", "/files/{path*}", "/static/*", "/{*rest}", "/x{a}.{b}"} {
+		assert.NoError(t, ValidatePath(p), p)
+	}
+	for p, msg := range map[string]string{
+		"/users/{id":          "unclosed parameter",
+		"/files/{path*}/meta": "wildcard parameter must be terminal",
+		"/{*rest}/x":          "wildcard parameter must be terminal",
+		"/items/{id:[0-9}":    "invalid regular expression",
+	} {
+		assert.ErrorContains(t, ValidatePath(p), msg, p)
+	}
+}
+
+func TestRouteGroup_AddRoute(t *testing.T) {
+	z := New()
+	api := z.Group("/api")
+
+	r, err := api.AddRoute(MethodGet, "/items/{id:[0-9}", NotFoundHandler)
+	assert.Nil(t, r)
+	assert.ErrorContains(t, err, "invalid regular expression")
+	for name := range z.Routes() {
+		t.Errorf("route %q registered despite error", name)
+	}
+
+	r, err = api.AddRoute(MethodGet, "/items/{id}", func(c *Context) error { return c.SendString(c.Param("id")) })
+	assert.NoError(t, err)
+	assert.NotNil(t, r)
+	ctx := performRequest(z, "GET", "/api/items/7", nil, nil)
+	assert.Equal(t, "7", string(ctx.Response.Body()))
+
+	r, err = api.AddRoute(MethodGet, "/items/{id}", NotFoundHandler)
+	assert.Nil(t, r)
+	assert.ErrorIs(t, err, ErrRouteExists)
+	assert.EqualError(t, err, "zeno: route is already registered: GET /api/items/{id}")
+	ctx = performRequest(z, "GET", "/api/items/7", nil, nil)
+	assert.Equal(t, "7", string(ctx.Response.Body()))
+	_, err = api.AddRoute(MethodPost, "/items/{id}", NotFoundHandler)
+	assert.NoError(t, err)
+
+	assert.PanicsWithError(t, `zeno: wildcard parameter must be terminal in route pattern "/{p*}/x"`, func() {
+		z.Get("/{p*}/x", NotFoundHandler)
+	})
+}
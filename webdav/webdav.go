@@ -0,0 +1,170 @@
+// Package webdav serves WebDAV file shares (RFC 4918) through zeno routes.
+//
+// It wires the WebDAV methods (PROPFIND, PROPPATCH, MKCOL, COPY, MOVE,
+// LOCK, UNLOCK, plus GET, HEAD, PUT, DELETE and OPTIONS) to the protocol
+// implementation of golang.org/x/net/webdav. Content can come from any
+// fs.FS, which is served read-only, or from a writable FileSystem such as
+// Dir.
+package webdav
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"golang.org/x/net/webdav"
+)
+
+// Methods lists the HTTP methods handled by a WebDAV share.
+const Methods = "OPTIONS,GET,HEAD,PUT,DELETE,PROPFIND,PROPPATCH,MKCOL,COPY,MOVE,LOCK,UNLOCK"
+
+// FileSystem is a writable WebDAV backend. Dir provides one backed by a
+// directory on disk.
+type FileSystem = webdav.FileSystem
+
+// Dir is a FileSystem rooted at a directory on disk.
+type Dir = webdav.Dir
+
+// Config configures a WebDAV share.
+type Config struct {
+	// FS is served read-only: methods that modify the share are rejected
+	// with a 4xx status. It is ignored when FileSystem is set.
+	FS fs.FS
+
+	// FileSystem is a writable backend, e.g. webdav.Dir("/srv/share").
+	FileSystem FileSystem
+
+	// LockSystem manages LOCK and UNLOCK. Defaults to an in-memory lock
+	// system, which is sufficient for a single process.
+	LockSystem webdav.LockSystem
+}
+
+// Mount registers a WebDAV share under prefix on r for every method in
+// Methods.
+//
+// Example:
+//
+//	webdav.Mount(&app.RouteGroup, "/dav", webdav.Config{
+//	    FileSystem: webdav.Dir("/srv/share"),
+//	})
+func Mount(r *zeno.RouteGroup, prefix string, config Config) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	h := Handler(prefix, config)
+	r.To(Methods, prefix+"/*", h)
+	if prefix != "" {
+		r.To(Methods, prefix, h)
+	}
+}
+
+// Handler returns a zeno.Handler serving a WebDAV share whose resources are
+// addressed relative to prefix. Use it to register the share manually,
+// e.g. behind route-specific middleware; Mount is the usual entry point.
+func Handler(prefix string, config Config) zeno.Handler {
+	filesystem := config.FileSystem
+	if filesystem == nil {
+		if config.FS == nil {
+			panic("webdav: Config requires FS or FileSystem")
+		}
+		filesystem = readOnlyFS{config.FS}
+	}
+	locks := config.LockSystem
+	if locks == nil {
+		locks = webdav.NewMemLS()
+	}
+	serve := fasthttpadaptor.NewFastHTTPHandler(&webdav.Handler{
+		Prefix:     prefix,
+		FileSystem: filesystem,
+		LockSystem: locks,
+	})
+	return func(c *zeno.Context) error {
+		serve(c.RequestCtx())
+		return nil
+	}
+}
+
+// readOnlyFS adapts an fs.FS to the FileSystem interface, rejecting all
+// modifications.
+type readOnlyFS struct {
+	fsys fs.FS
+}
+
+// name converts a WebDAV path ("/a/b") to an fs.FS path ("a/b").
+func (r readOnlyFS) name(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+func (r readOnlyFS) Mkdir(context.Context, string, os.FileMode) error { return fs.ErrPermission }
+func (r readOnlyFS) RemoveAll(context.Context, string) error          { return fs.ErrPermission }
+func (r readOnlyFS) Rename(context.Context, string, string) error     { return fs.ErrPermission }
+
+func (r readOnlyFS) Stat(_ context.Context, name string) (os.FileInfo, error) {
+	return fs.Stat(r.fsys, r.name(name))
+}
+
+func (r readOnlyFS) OpenFile(_ context.Context, name string, flag int, _ os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return nil, fs.ErrPermission
+	}
+	name = r.name(name)
+	f, err := r.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyFile{File: f, fsys: r.fsys, name: name}, nil
+}
+
+// readOnlyFile adapts an fs.File to webdav.File.
+type readOnlyFile struct {
+	fs.File
+	fsys    fs.FS
+	name    string
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (f *readOnlyFile) Write([]byte) (int, error) { return 0, fs.ErrPermission }
+
+func (f *readOnlyFile) Seek(offset int64, whence int) (int64, error) {
+	if s, ok := f.File.(io.Seeker); ok {
+		return s.Seek(offset, whence)
+	}
+	return 0, errors.New("webdav: file does not support seeking")
+}
+
+// Readdir implements http.File on top of fs.ReadDir.
+func (f *readOnlyFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.read {
+		entries, err := fs.ReadDir(f.fsys, f.name)
+		if err != nil {
+			return nil, err
+		}
+		f.entries, f.read = entries, true
+	}
+	n := len(f.entries)
+	if count > 0 && count < n {
+		n = count
+	}
+	if count > 0 && n == 0 {
+		return nil, io.EOF
+	}
+	infos := make([]os.FileInfo, 0, n)
+	for _, e := range f.entries[:n] {
+		info, err := e.Info()
+		if err != nil {
+			return infos, err
+		}
+		infos = append(infos, info)
+	}
+	f.entries = f.entries[n:]
+	return infos, nil
+}
@@ -0,0 +1,56 @@
+package webdav
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func do(z *zeno.Zeno, method, uri string, headers map[string]string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	for k, v := range headers {
+		ctx.Request.Header.Set(k, v)
+	}
+	z.HandleRequest(ctx)
+	return ctx
+}
+
+func TestMount_ReadOnlyFS(t *testing.T) {
+	z := zeno.New()
+	Mount(&z.RouteGroup, "/dav", Config{FS: fstest.MapFS{
+		"docs/readme.txt": {Data: []byte("hello")},
+	}})
+
+	ctx := do(z, "PROPFIND", "/dav/docs/", map[string]string{"Depth": "1"})
+	assert.Equal(t, 207, ctx.Response.StatusCode())
+	assert.Contains(t, string(ctx.Response.Body()), "/dav/docs/readme.txt")
+
+	ctx = do(z, "GET", "/dav/docs/readme.txt", nil)
+	assert.Equal(t, "hello", string(ctx.Response.Body()))
+
+	ctx = do(z, "MKCOL", "/dav/new", nil)
+	assert.GreaterOrEqual(t, ctx.Response.StatusCode(), 400)
+}
+
+func TestMount_Dir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644))
+
+	z := zeno.New()
+	Mount(&z.RouteGroup, "/dav", Config{FileSystem: Dir(dir)})
+
+	ctx := do(z, "MKCOL", "/dav/sub", nil)
+	assert.Equal(t, zeno.StatusCreated, ctx.Response.StatusCode())
+
+	ctx = do(z, "MOVE", "http://example.com/dav/a.txt", map[string]string{"Destination": "http://example.com/dav/sub/b.txt"})
+	assert.Equal(t, zeno.StatusCreated, ctx.Response.StatusCode())
+	_, err := os.Stat(filepath.Join(dir, "sub", "b.txt"))
+	assert.NoError(t, err)
+}
@@ -1,18 +1,30 @@
 package zeno
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"maps"
+	"net"
 	"net/http"
-	"sort"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"github.com/bytedance/sonic"
 	"github.com/fxamacker/cbor/v2"
 	"github.com/pelletier/go-toml/v2"
 	"github.com/valyala/fasthttp"
-	"github.com/valyala/fasthttp/reuseport"
+	"golang.org/x/net/http2"
 	"gopkg.in/yaml.v3"
 )
 
@@ -26,27 +38,71 @@ type Map map[string]any
 type Zeno struct {
 	RouteGroup // Root group for registering routes directly
 
-	// Routing trees for each HTTP method
-	getTree     *tree
-	headTree    *tree
-	postTree    *tree
-	putTree     *tree
-	patchTree   *tree
-	deletedTree *tree
-	connectTree *tree
-	optionsTree *tree
-	traceTree   *tree
+	// Routing trees for each HTTP method. The table is replaced
+	// atomically when routes change while the server is running.
+	table atomic.Pointer[routingTable]
+
+	// Registered routes in insertion order, used to rebuild the table
+	entries []routeEntry
+
+	// Serializes route registration and guards the routes map
+	mu sync.RWMutex
+
+	// Set, under mu, once a server is started or the first request is
+	// handled; from then on route changes are applied copy-on-write
+	serving atomic.Bool
 
 	// Request context pooling for performance
 	pool sync.Pool
 
-	// Max number of parameters used across all routes
-	maxParams int
+	// Server used by Run and friends, created lazily by Server
+	server *fasthttp.Server
+
+	// Servers started by Run and friends, stopped by Shutdown
+	servers []*fasthttp.Server
+
+	// Listeners opened by Run and friends, handed to the child by Restart
+	listeners []addrListener
+
+	// Set once Shutdown has been called
+	draining atomic.Bool
+
+	// Lifecycle hooks, see OnStart, OnShutdown and OnRouteRegistered
+	onStart    []func() error
+	onShutdown []func(context.Context) error
+	onRoute    []func(RouteInfo)
+	startOnce  sync.Once
+	startErr   error
+
+	// Set by PrintRoutesOnRequest
+	printRoutes bool
+
+	// Names of the global middleware, parallel to RouteGroup.handlers;
+	// anonymous middleware has an empty name. See UseNamed.
+	middlewareNames []string
 
 	// Handlers executed when no route matches
 	notFound         []Handler
 	notFoundHandlers []Handler
 
+	// Paths answered from memory by global middleware such as Favicon,
+	// which skip routing; reset when the global middleware changes
+	assetPaths atomic.Pointer[map[string]bool]
+
+	// Handlers executed when a route matches the path but not the method
+	notAllowed []Handler
+
+	// Templates parsed by SendHTMLSafe, by source, and their number
+	htmlTemplates     sync.Map
+	htmlTemplateCount atomic.Int64
+
+	// Per-group not-found chains, error handlers and default headers,
+	// longest prefix first; published in the routing table
+	fallbacks []*groupFallback
+
+	// Whether any group has set default response headers
+	defaultHeaders atomic.Bool
+
 	// Named route registry
 	routes map[string]*Route
 
@@ -58,21 +114,154 @@ type Zeno struct {
 	// Custom error handler
 	ErrorHandler func(*Context, error) error
 
+	// Redactor, when set, masks credentials and personal data in the
+	// error messages the default ErrorHandler sends, which may echo
+	// request input. See Context.Redact.
+	Redactor *Redactor
+
 	// Use SO_REUSEPORT for multiple listeners on same port
 	useReusePort bool
 
+	// ALPN protocol handlers registered via NextProto
+	nextProtos map[string]fasthttp.ServeHandler
+
+	// HTTP/2 implementation registered for "h2" when HTTP2 is set; h2Base
+	// carries its graceful shutdown
+	h2     *http2.Server
+	h2Base *http.Server
+
+	// Custom string converters registered via RegisterConverter
+	converters converterRegistry
+
+	// InputFilters are applied, in order, to the values returned by Param,
+	// Query, QueryArray and FormValue and to values bound by BindQuery and
+	// BindParams. Struct fields can pick their own chain with a `filter`
+	// tag, e.g. `filter:"trim,escapehtml"`, or opt out with `filter:"-"`.
+	// See RegisterInputFilter.
+	InputFilters []InputFilter
+
+	// Filters registered with RegisterInputFilter
+	namedFilters map[string]InputFilter
+
+	// PathCleaning configures how request paths are cleaned before they
+	// are matched against routes.
+	PathCleaning PathCleaningConfig
+
+	// QueryParser configures how query strings are parsed by QueryNested
+	// and BindQuery.
+	QueryParser QueryParserConfig
+
+	// TimeParser configures how time.Time values are parsed from path
+	// parameters, query strings, and bound request data.
+	TimeParser TimeParserConfig
+
+	// BaseURL is the scheme and host (e.g. "https://example.com") that
+	// AbsoluteURL prefixes to generated paths. When empty, Context's
+	// AbsoluteURL falls back to the scheme and host of the current request.
+	BaseURL string
+
+	// AutoTLS configures certificate management for RunAutoTLS.
+	AutoTLS AutoTLSConfig
+
+	// ClientCAs, when set, makes RunTLS and RunAutoTLS request client
+	// certificates in the TLS handshake and verify the ones presented
+	// against the pool. Clients without certificates are still accepted;
+	// the ClientCert middleware requires them where needed.
+	ClientCAs *x509.CertPool
+
+	// Flags evaluates the feature flags queried with Context.Feature and
+	// RequireFeature. Without it every flag is disabled.
+	Flags FlagProvider
+
+	// FlagUser, when set, returns the ID of the user making a request, to
+	// which Flags can target flags and percentage rollouts.
+	FlagUser func(c *Context) string
+
+	// Debug enables development-time safety checks. Contexts are poisoned
+	// when their request completes and are not returned to the pool, so a
+	// handler or goroutine that keeps using one panics with a clear message
+	// instead of silently reading another request's data. It costs an
+	// allocation per request and should be disabled in production.
+	Debug bool
+
+	// TraceRouting records the node-by-node routing decisions for every
+	// request (see TraceRoute) and reports them to OnRouteTrace or, when
+	// that is nil, in X-Route-Trace response headers. Routing each request
+	// twice is slow and the headers reveal the route layout, so enable it
+	// only while troubleshooting.
+	TraceRouting bool
+
+	// OnRouteTrace receives the routing trace of each request when
+	// TraceRouting is enabled, e.g. to log it.
+	OnRouteTrace func(c *Context, trace []string)
+
+	// DisableStatusBody stops SendStatus and SendStatusCode from writing
+	// the status text as the body of otherwise empty responses.
+	DisableStatusBody bool
+
+	// StreamRequestBody enables fasthttp's request body streaming. When
+	// set, large bodies are not read into memory before the handler runs;
+	// use Context.BodyReader to consume them incrementally. Must be set
+	// before Run.
+	StreamRequestBody bool
+
+	// ShutdownDelay is how long Shutdown keeps serving after it marks the
+	// engine as draining and before it stops accepting connections, so
+	// that load balancers polling the readiness probe (see HealthCheck)
+	// stop routing new requests first. It is cut short when the context
+	// passed to Shutdown is done. Zero stops accepting immediately.
+	ShutdownDelay time.Duration
+
+	// HTTP2 serves HTTP/2 to TLS clients that negotiate "h2" via ALPN in
+	// RunTLS and RunAutoTLS, using golang.org/x/net/http2. Each stream is
+	// handled by HandleRequest with a fully read request body, so
+	// StreamRequestBody and Hijack do not apply to HTTP/2 requests. A
+	// handler registered for "h2" with NextProto takes precedence. Must
+	// be set before Run.
+	HTTP2 bool
+
+	// BufferResponses makes the response body owned by the response while
+	// the chain runs, and committed only once it completes: Send helpers
+	// copy the bytes they are given, so middleware can inspect and replace
+	// the body after Next (see ResponseBody and SetResponseBody), and when
+	// the chain returns an error, the status, body, Content-Type and the
+	// headers and cookies set since the handler started are discarded
+	// before the error handler runs, so clients never receive half of a
+	// success response glued to an error. Headers set by middleware before
+	// calling Next are kept.
+	BufferResponses bool
+
 	// JsonDecoder is the default function used to decode a JSON payload
 	// from the request body. It should unmarshal the byte slice into
 	// the target Go value. A typical implementation uses json.Unmarshal
 	// or a high-performance alternative such as sonic or jsoniter.
 	JsonDecoder DecoderFunc
 
+	// JsonStrictDecoder decodes JSON like JsonDecoder but fails on object
+	// keys that match no field of the destination. BindJSON uses it when
+	// JSONBindingConfig.DisallowUnknownFields is set.
+	JsonStrictDecoder DecoderFunc
+
+	// JSONBinding holds the default BindJSON validation settings.
+	JSONBinding JSONBindingConfig
+
+	// Body codecs registered via RegisterDecoder and RegisterEncoder,
+	// keyed by media type; encoderTypes keeps registration order.
+	decoders     map[string]DecoderFunc
+	encoders     map[string]EncoderFunc
+	encoderTypes []string
+
 	// JsonEncoder is the default function used to encode a Go value into
 	// JSON format. It should return the marshaled byte slice that can be
 	// directly written to the response. Set the "Content-Type" to
 	// "application/json" before sending the bytes.
 	JsonEncoder EncoderFunc
 
+	// JsonStreamEncoder is used by SendJSONStream to encode a Go value
+	// straight into the response body stream. It defaults to sonic's
+	// streaming encoder, which is also used when it is set to nil.
+	JsonStreamEncoder StreamEncoderFunc
+
 	// JsonIndent is an optional function used to pretty-print JSON output.
 	// It takes a Go value, prefix, and indent string to format the output
 	// for better readability. Typically wraps json.MarshalIndent or similar.
@@ -83,6 +272,13 @@ type Zeno struct {
 	// If set, all JSON responses will begin with this prefix.
 	SecureJSONPrefix string
 
+	// DefaultCharset is appended as a charset parameter to the Content-Type
+	// of textual responses (JSON, XML, YAML, TOML, CSV, HTML and plain text)
+	// whose type is not given explicitly. It only labels the body, which the
+	// built-in encoders always produce as UTF-8. Defaults to "utf-8"; set it
+	// to "" to send bare media types.
+	DefaultCharset string
+
 	// XmlDecoder is the default function used to decode an XML payload
 	// from the request body. It should unmarshal the byte slice into
 	// the target Go value. Typically wraps encoding/xml.Unmarshal or
@@ -133,31 +329,46 @@ type Zeno struct {
 	// written directly to the response. You should set the "Content-Type"
 	// to "application/cbor" before writing the response.
 	CborEncoder EncoderFunc
+
+	// Renderer is used by Context.Render, RenderPartial and SendTemplate to
+	// produce HTML output. It is nil by default; assign one such as
+	// NewHTMLRenderer before using the template helpers.
+	Renderer Renderer
+
+	// FS, when set, is the file system Context.SendFile reads from instead
+	// of the OS file system, e.g. an embed.FS or fstest.MapFS. Paths passed
+	// to SendFile are then interpreted relative to its root.
+	FS fs.FS
 }
 
 // New creates and returns a new Zeno instance with default settings,
 // initializes route trees, not found handlers, and context pooling.
 func New() *Zeno {
 	z := &Zeno{
-		routes:           make(map[string]*Route),
-		JsonDecoder:      sonic.Unmarshal,
-		JsonEncoder:      sonic.Marshal,
-		JsonIndent:       sonic.MarshalIndent,
-		XmlEncoder:       xml.Marshal,
-		XmlDecoder:       xml.Unmarshal,
-		XmlIndent:        xml.MarshalIndent,
-		YamlDecoder:      yaml.Unmarshal,
-		YamlEncoder:      yaml.Marshal,
-		TomlDecoder:      toml.Unmarshal,
-		TomlEncoder:      toml.Marshal,
-		CborDecoder:      cbor.Unmarshal,
-		CborEncoder:      cbor.Marshal,
-		SecureJSONPrefix: "while(1);",
+		routes:            make(map[string]*Route),
+		JsonDecoder:       sonic.Unmarshal,
+		JsonStrictDecoder: sonic.Config{DisallowUnknownFields: true}.Froze().Unmarshal,
+		JsonEncoder:       sonic.Marshal,
+		JsonIndent:        sonic.MarshalIndent,
+		JsonStreamEncoder: sonicStreamEncoder,
+		XmlEncoder:        xml.Marshal,
+		XmlDecoder:        xml.Unmarshal,
+		XmlIndent:         xml.MarshalIndent,
+		YamlDecoder:       yaml.Unmarshal,
+		YamlEncoder:       yaml.Marshal,
+		TomlDecoder:       toml.Unmarshal,
+		TomlEncoder:       toml.Marshal,
+		CborDecoder:       cbor.Unmarshal,
+		CborEncoder:       cbor.Marshal,
+		SecureJSONPrefix:  "while(1);",
+		DefaultCharset:    "utf-8",
 	}
 	z.RouteGroup = *NewRouteGroup("", z, nil)
+	z.notAllowed = []Handler{methodNotAllowedHandler}
+	z.table.Store(&routingTable{notAllowed: z.notAllowed})
 	z.pool.New = func() interface{} {
 		return &Context{
-			pvalues: make([]string, z.maxParams),
+			pvalues: make([]string, z.table.Load().maxParams),
 			zeno:    z,
 		}
 	}
@@ -167,27 +378,148 @@ func New() *Zeno {
 	z.toBytes = func(v string) []byte {
 		return unsafe.Slice(unsafe.StringData(v), len(v))
 	}
-	z.NotFound(MethodNotAllowedHandler, NotFoundHandler)
+	z.NotFound(NotFoundHandler)
 	z.ErrorHandler = func(c *Context, err error) error {
 		if httpErr, ok := err.(HTTPError); ok {
-			return c.Status(httpErr.StatusCode()).SendString(httpErr.Error())
+			return c.Status(httpErr.StatusCode()).SendString(c.Redact(httpErr.Error()))
 		}
 		return c.Status(StatusInternalServerError).SendString("Internal Server Error")
 	}
 	return z
 }
 
+// sonicStreamEncoder is the default JsonStreamEncoder. It encodes v with
+// sonic's streaming encoder directly into w.
+func sonicStreamEncoder(w io.Writer, v any) error {
+	return sonic.ConfigDefault.NewEncoder(w).Encode(v)
+}
+
 // Use appends the specified handlers to the router and shares them with all routes.
 func (r *Zeno) Use(handlers ...Handler) {
+	r.middlewareNames = append(r.syncMiddlewareNames(), make([]string, len(handlers))...)
 	r.RouteGroup.Use(handlers...)
 	r.notFoundHandlers = combineHandlers(r.handlers, r.notFound)
+	r.assetPaths.Store(nil)
 }
 
 // Route returns a named route by name.
 func (z *Zeno) GetRoute(name string) *Route {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
 	return z.routes[name]
 }
 
+// URL builds the path for the named route using Route.URLE, so it can be
+// used outside of request handling, e.g. in background jobs. It returns an
+// error if the route does not exist or a parameter is missing.
+//
+// Example:
+//
+//	u, err := app.URL("user.show", "id", 42) // "/users/42"
+func (z *Zeno) URL(name string, pairs ...any) (string, error) {
+	r := z.GetRoute(name)
+	if r == nil {
+		return "", fmt.Errorf("zeno: route %q not found", name)
+	}
+	return r.URLE(pairs...)
+}
+
+// AbsoluteURL is like URL but prefixes the result with BaseURL, producing
+// a URL suitable for emails, webhooks and Location headers. It returns an
+// error if BaseURL is not set.
+//
+// Example:
+//
+//	app.BaseURL = "https://example.com"
+//	u, _ := app.AbsoluteURL("user.show", "id", 42) // "https://example.com/users/42"
+func (z *Zeno) AbsoluteURL(name string, pairs ...any) (string, error) {
+	if z.BaseURL == "" {
+		return "", errors.New("zeno: AbsoluteURL requires BaseURL to be set")
+	}
+	path, err := z.URL(name, pairs...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(z.BaseURL, "/") + path, nil
+}
+
+// MustGetRoute is like GetRoute but panics if no route is registered
+// under name. It is intended for wiring code that runs at startup.
+func (z *Zeno) MustGetRoute(name string) *Route {
+	r := z.GetRoute(name)
+	if r == nil {
+		panic(fmt.Sprintf("zeno: route %q not found", name))
+	}
+	return r
+}
+
+// Routes returns an iterator over the route registry in name order. Every
+// route appears under its path and, if given one, under its custom name.
+// The registry is snapshotted when iteration starts.
+//
+// Example:
+//
+//	for name, r := range app.Routes() {
+//	    fmt.Println(name, r.URL())
+//	}
+func (z *Zeno) Routes() iter.Seq2[string, *Route] {
+	return func(yield func(string, *Route) bool) {
+		z.mu.RLock()
+		names := slices.Sorted(maps.Keys(z.routes))
+		routes := make([]*Route, len(names))
+		for i, name := range names {
+			routes[i] = z.routes[name]
+		}
+		z.mu.RUnlock()
+		for i, name := range names {
+			if !yield(name, routes[i]) {
+				return
+			}
+		}
+	}
+}
+
+// UnregisterRoute removes name from the route registry without affecting
+// request matching. It reports whether the name was registered.
+func (z *Zeno) UnregisterRoute(name string) bool {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	r, ok := z.routes[name]
+	if !ok {
+		return false
+	}
+	delete(z.routes, name)
+	if r.name == name {
+		r.name = r.path
+	}
+	return true
+}
+
+// registerRoute stores r in the named route registry under its path.
+// Routes sharing a path replace each other, since they are addressed by
+// the same URL.
+func (z *Zeno) registerRoute(name string, r *Route) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.routes[name] = r
+}
+
+// registerName stores r under a custom name, replacing the route's previous
+// custom name. It returns an error if name already refers to another route.
+func (z *Zeno) registerName(name string, r *Route) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if other, ok := z.routes[name]; ok && other != r {
+		return fmt.Errorf("zeno: route name %q already registered for %s", name, other.path)
+	}
+	if r.name != r.path && z.routes[r.name] == r {
+		delete(z.routes, r.name)
+	}
+	r.name = name
+	z.routes[name] = r
+	return nil
+}
+
 // NotFound sets the handler(s) to be used when no route is matched.
 // The final notFound handler chain includes global middleware.
 func (r *Zeno) NotFound(handlers ...Handler) {
@@ -195,43 +527,54 @@ func (r *Zeno) NotFound(handlers ...Handler) {
 	r.notFoundHandlers = combineHandlers(r.handlers, r.notFound)
 }
 
+// MethodNotAllowed sets the handler(s) run when a route matches the path
+// of a request but not its method, replacing the default that returns
+// ErrMethodNotAllowed. The Allow header has already been set when they run,
+// and the middleware of the group that registered the path runs first.
+// OPTIONS requests are answered with the Allow header alone and do not
+// reach these handlers.
+//
+// Example:
+//
+//	app.MethodNotAllowed(func(c *zeno.Context) error {
+//	    return c.Status(zeno.StatusMethodNotAllowed).SendJSON(zeno.Map{
+//	        "error": "method not allowed",
+//	        "allow": string(c.Response().Header.Peek(zeno.HeaderAllow)),
+//	    })
+//	})
+func (z *Zeno) MethodNotAllowed(handlers ...Handler) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.notAllowed = handlers
+	z.table.Store(buildRoutingTable(z.entries, z.notAllowed, z.fallbacks))
+}
+
 // find attempts to locate a handler chain for the given method and path.
-// If no match is found, the notFound handler is returned.
+// If the path matches a route registered for other methods, the 405 chain
+// of that route is returned; if nothing matches, the notFound chain.
 func (z *Zeno) find(method string, path []byte, pvalues []string) ([]Handler, []string) {
-	t := z.treeForMethod(method)
-	if t != nil {
+	table := z.table.Load()
+	if h := table.static[method][string(path)]; h != nil {
+		return h, nil
+	}
+	if t := table.treeForMethod(method); t != nil {
 		if h, pnames := t.Get(path, pvalues); h != nil {
 			return h, pnames
 		}
 	}
-	return z.notFoundHandlers, nil
+	if table.anyTree != nil {
+		if h, pnames := table.anyTree.Get(path, pvalues); h != nil {
+			return h, pnames
+		}
+	}
+	return z.notFoundFor(table, z.toString(path), pvalues)
 }
 
 // findAllowedMethods returns a set of allowed HTTP methods for a given path.
 // Useful for generating Allow headers when responding with 405 errors.
 func (z *Zeno) findAllowedMethods(path []byte) map[string]bool {
-	methods := make(map[string]bool)
-	pvalues := make([]string, z.maxParams)
-
-	check := func(method string, s *tree) {
-		if s != nil {
-			if h, _ := s.Get(path, pvalues); h != nil {
-				methods[method] = true
-			}
-		}
-	}
-
-	check(MethodGet, z.getTree)
-	check(MethodHead, z.headTree)
-	check(MethodPost, z.postTree)
-	check(MethodPut, z.putTree)
-	check(MethodPatch, z.patchTree)
-	check(MethodDelete, z.deletedTree)
-	check(MethodConnect, z.connectTree)
-	check(MethodOptions, z.optionsTree)
-	check(MethodTrace, z.traceTree)
-
-	return methods
+	table := z.table.Load()
+	return table.allowedMethods(path, make([]string, table.maxParams))
 }
 
 // HandleRequest is the main request entry point for fasthttp.
@@ -239,15 +582,41 @@ func (z *Zeno) findAllowedMethods(path []byte) map[string]bool {
 // executes the handler chain, and handles any returned errors.
 func (z *Zeno) HandleRequest(ctx *fasthttp.RequestCtx) {
 	c := z.pool.Get().(*Context)
-	defer z.pool.Put(c)
+	defer z.releaseContext(c)
+
+	if !z.serving.Load() {
+		z.markServing()
+	}
 
 	c.init(ctx)
-	c.handlers, c.pnames = z.find(z.toString(ctx.Method()), ctx.Path(), c.pvalues)
+	defer c.runAfterResponse()
+	table := z.table.Load()
+	n := table.maxParams
+	if table.fallbackParams > n {
+		n = table.fallbackParams
+	}
+	if len(c.pvalues) < n {
+		// Routes with more parameters were added after c was pooled.
+		c.pvalues = make([]string, n)
+	}
+	if len(c.fallbackValues) < table.fallbackParams {
+		c.fallbackValues = make([]string, table.fallbackParams)
+	}
+	if paths := z.assetPaths.Load(); paths != nil && (*paths)[c.Path()] {
+		// Served from memory by global middleware; skip the route lookup.
+		c.handlers, c.pnames = z.handlers, nil
+	} else {
+		z.match(c)
+	}
+	z.applyDefaultHeaders(c, c.Path())
 
 	if err := c.Next(); err != nil {
+		if z.BufferResponses {
+			c.discardResponse()
+		}
 		// Call error handler if set
-		if z.ErrorHandler != nil {
-			if handleErr := z.ErrorHandler(c, err); handleErr != nil {
+		if handler := z.errorHandlerFor(c); handler != nil {
+			if handleErr := handler(c, err); handleErr != nil {
 				c.SendStatusCode(StatusInternalServerError)
 			}
 		} else {
@@ -255,71 +624,142 @@ func (z *Zeno) HandleRequest(ctx *fasthttp.RequestCtx) {
 			c.SendStatusCode(StatusInternalServerError)
 		}
 	}
+	c.suppressBody()
 }
 
-// add registers a route in the routing tree for the given HTTP method.
-// It updates maxParams if the route uses more parameters than seen so far.
-func (z *Zeno) add(method, path string, handlers []Handler) {
-	tree := z.treeForMethod(method)
-	if tree == nil {
-		tree = newTree()
-		z.setTreeForMethod(method, tree)
-	}
-	if n := tree.Add([]byte(path), handlers); n > z.maxParams {
-		z.maxParams = n
-	}
-}
-
-// treeForMethod returns the routing tree corresponding to an HTTP method.
-func (z *Zeno) treeForMethod(method string) *tree {
-	switch method {
-	case MethodGet:
-		return z.getTree
-	case MethodHead:
-		return z.headTree
-	case MethodPost:
-		return z.postTree
-	case MethodPut:
-		return z.putTree
-	case MethodPatch:
-		return z.patchTree
-	case MethodDelete:
-		return z.deletedTree
-	case MethodConnect:
-		return z.connectTree
-	case MethodOptions:
-		return z.optionsTree
-	case MethodTrace:
-		return z.traceTree
-	default:
-		return nil
+// match routes c's request by its method and path, cleaned according to
+// z.PathCleaning, and sets c's handler chain and parameters.
+func (z *Zeno) match(c *Context) {
+	ctx := c.ctx
+	path, ok := z.routingPath(ctx)
+	if !ok {
+		c.handlers, c.pnames = combineHandlers(z.handlers, []Handler{invalidPathHandler}), nil
+		return
+	}
+	c.handlers, c.pnames = z.find(z.toString(ctx.Method()), path, c.pvalues)
+	if z.TraceRouting {
+		z.emitRouteTrace(c, z.toString(ctx.Method()), path)
+	}
+	if cfg := z.PathCleaning; (cfg.Raw || cfg.PreserveEncodedSlash) && !cfg.EncodedParams {
+		c.unescapeParams()
 	}
 }
 
-// setTreeForMethod sets the routing tree for the given HTTP method.
-func (z *Zeno) setTreeForMethod(method string, t *tree) {
-	switch method {
-	case MethodGet:
-		z.getTree = t
-	case MethodHead:
-		z.headTree = t
-	case MethodPost:
-		z.postTree = t
-	case MethodPut:
-		z.putTree = t
-	case MethodPatch:
-		z.patchTree = t
-	case MethodDelete:
-		z.deletedTree = t
-	case MethodConnect:
-		z.connectTree = t
-	case MethodOptions:
-		z.optionsTree = t
-	case MethodTrace:
-		z.traceTree = t
+// markServing switches route registration to copy-on-write. It takes mu so
+// that an in-place update by add either completes before the table is
+// first read or is not made at all.
+func (z *Zeno) markServing() {
+	z.mu.Lock()
+	z.serving.Store(true)
+	z.mu.Unlock()
+}
+
+// releaseContext hands c back after its request has completed. In debug
+// mode the context is poisoned and dropped instead of being pooled.
+func (z *Zeno) releaseContext(c *Context) {
+	if z.Debug {
+		c.release()
+		return
+	}
+	z.pool.Put(c)
+}
+
+// add registers a route in the routing tree for the given HTTP method. It
+// panics if a route is already registered for method and path, which
+// would otherwise be shadowed silently.
+//
+// Before a server is started or the first request is served the current
+// table is updated in place. Afterwards a new table is built from all registered routes and
+// swapped in atomically, so in-flight requests keep using a consistent
+// snapshot and routes can safely be added at runtime.
+func (z *Zeno) add(method, path string, handlers []Handler, route *Route) {
+	z.mu.Lock()
+	if slices.ContainsFunc(z.entries, func(e routeEntry) bool { return e.method == method && e.path == path }) {
+		z.mu.Unlock()
+		panic(fmt.Sprintf("zeno: route %s %s is already registered", method, path))
+	}
+	entry := routeEntry{method: method, path: path, handlers: handlers, route: route, middleware: route.group.handlers}
+	z.entries = append(z.entries, entry)
+	if z.serving.Load() {
+		z.table.Store(buildRoutingTable(z.entries, z.notAllowed, z.fallbacks))
+	} else {
+		z.table.Load().add(entry)
+	}
+	hooks := z.onRoute
+	z.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn(RouteInfo{Method: method, Path: path, Route: route})
 	}
 }
 
+// hasRoute reports whether a route is registered for method and the
+// pattern path.
+func (z *Zeno) hasRoute(method, path string) bool {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	return slices.ContainsFunc(z.entries, func(e routeEntry) bool { return e.method == method && e.path == path })
+}
+
+// Remove deletes the route registered for method and path, reporting
+// whether one was found. Once a route has no method left, its name no
+// longer resolves with URL and GetRoute. path is the full pattern as it was registered,
+// including any group prefix. The routing table is rebuilt and swapped in
+// atomically, so Remove is safe to call while the server is running.
+//
+// Example:
+//
+//	app.Remove(zeno.MethodGet, "/api/users/{id}")
+func (z *Zeno) Remove(method, path string) bool {
+	if strings.HasSuffix(path, "*") {
+		path = path[:len(path)-1] + "{:.*}"
+	}
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return z.removeEntries(func(e routeEntry) bool {
+		return e.method == method && e.path == path
+	})
+}
+
+// detach removes every registration belonging to r and its names.
+func (z *Zeno) detach(r *Route) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.removeEntries(func(e routeEntry) bool {
+		return e.route == r
+	})
+}
+
+// removeEntries drops the entries matched by match, together with the
+// names of routes left without any entry, and publishes a rebuilt routing
+// table. It must be called with z.mu held.
+func (z *Zeno) removeEntries(match func(routeEntry) bool) bool {
+	var removed []*Route
+	z.entries = slices.DeleteFunc(z.entries, func(e routeEntry) bool {
+		if match(e) {
+			removed = append(removed, e.route)
+			return true
+		}
+		return false
+	})
+	if len(removed) == 0 {
+		return false
+	}
+	for _, r := range removed {
+		if slices.ContainsFunc(z.entries, func(e routeEntry) bool { return e.route == r }) {
+			continue
+		}
+		for name, route := range z.routes {
+			if route == r {
+				delete(z.routes, name)
+			}
+		}
+	}
+	z.table.Store(buildRoutingTable(z.entries, z.notAllowed, z.fallbacks))
+	return true
+}
+
 // NotFoundHandler is the default fallback handler that returns 404.
 func NotFoundHandler(*Context) error {
 	return ErrNotFound
@@ -327,35 +767,252 @@ func NotFoundHandler(*Context) error {
 
 // MethodNotAllowedHandler builds and sets the "Allow" header when
 // a route exists for the path but not for the method. If the request
-// method is not OPTIONS, it returns 405 Method Not Allowed.
+// method is not OPTIONS, it responds with 405 Method Not Allowed. When no
+// route matches the path it does nothing, so it can lead a not-found
+// chain:
+//
+//	app.NotFound(zeno.MethodNotAllowedHandler, zeno.NotFoundHandler)
 func MethodNotAllowedHandler(c *Context) error {
 	methods := c.Zeno().findAllowedMethods(c.ctx.Path())
 	if len(methods) == 0 {
 		return nil
 	}
-	methods["OPTIONS"] = true
-	ms := make([]string, 0, len(methods))
-	for m := range methods {
-		ms = append(ms, m)
-	}
-	sort.Strings(ms)
-	c.ctx.Response.Header.Set("Allow", strings.Join(ms, ", "))
-	if c.Method() != "OPTIONS" {
-		c.ctx.Response.SetStatusCode(http.StatusMethodNotAllowed)
+	c.SetHeader(HeaderAllow, allowHeader(methods))
+	if c.Method() != MethodOptions {
+		c.ctx.Response.SetStatusCode(StatusMethodNotAllowed)
 	}
 	c.Abort()
 	return nil
 }
 
+// methodNotAllowedHandler is the default 405 chain set with
+// Zeno.MethodNotAllowed. It returns ErrMethodNotAllowed for the
+// ErrorHandler to answer.
+func methodNotAllowedHandler(*Context) error {
+	return ErrMethodNotAllowed
+}
+
 // Run starts the HTTP server on the given address using fasthttp.
 // If useReusePort is true, it uses SO_REUSEPORT for load balancing across processes.
+// When the process was started by Restart, the listener inherited from the
+// parent for addr is reused instead of opening a new one.
 func (z *Zeno) Run(addr string) error {
-	if z.useReusePort {
-		ln, err := reuseport.Listen("tcp4", addr)
+	if err := z.start(); err != nil {
+		return err
+	}
+	ln, err := z.listen(addr)
+	if err != nil {
+		return err
+	}
+	return z.newServer().Serve(ln)
+}
+
+// Serve serves the application on ln, which is closed when Serve returns,
+// like Run does on the listener it opens. It suits listeners created
+// elsewhere, such as those from socket activation or the in-memory
+// listener of package zenotest.
+//
+// Example:
+//
+//	ln, err := net.Listen("tcp", "127.0.0.1:0")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	log.Fatal(app.Serve(ln))
+func (z *Zeno) Serve(ln net.Listener) error {
+	if err := z.start(); err != nil {
+		ln.Close()
+		return err
+	}
+	return z.newServer().Serve(ln)
+}
+
+// RunMany serves the application on several addresses at once through a
+// single server, so Shutdown stops all of them together. Addresses are
+// TCP host:port pairs (IPv6 hosts in brackets, e.g. "[::]:8080") or Unix
+// domain sockets written as "unix:/path/to/socket". It returns when any
+// listener fails, after shutting the others down.
+//
+// Example:
+//
+//	log.Fatal(app.RunMany(":8080", "[::]:8080", "unix:/run/app.sock"))
+func (z *Zeno) RunMany(addrs ...string) error {
+	if len(addrs) == 0 {
+		return errors.New("zeno: RunMany requires at least one address")
+	}
+	if err := z.start(); err != nil {
+		return err
+	}
+	listeners := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		ln, err := z.listen(addr)
 		if err != nil {
+			z.closeListeners(listeners...)
 			return err
 		}
-		return fasthttp.Serve(ln, z.HandleRequest)
+		listeners = append(listeners, ln)
+	}
+
+	s := z.newServer()
+	errc := make(chan error, len(listeners))
+	for _, ln := range listeners {
+		go func() { errc <- s.Serve(ln) }()
+	}
+	err := <-errc
+	s.Shutdown()
+	if err != nil {
+		z.closeListeners(listeners...)
+	}
+	return err
+}
+
+// RunTLS starts an HTTPS server on the given address using the certificate
+// and key files. Protocols registered with NextProto are offered via ALPN,
+// and so is HTTP/2 when Zeno.HTTP2 is set.
+func (z *Zeno) RunTLS(addr, certFile, keyFile string) error {
+	if err := z.start(); err != nil {
+		return err
+	}
+	ln, err := z.listen(addr)
+	if err != nil {
+		return err
+	}
+	s := z.newServer()
+	if z.ClientCAs != nil {
+		if s.TLSConfig == nil {
+			s.TLSConfig = &tls.Config{}
+		}
+		z.configureClientAuth(s.TLSConfig)
+	}
+	return s.ServeTLS(ln, certFile, keyFile)
+}
+
+// NextProto registers handler to serve TLS connections for which the
+// client negotiated proto via ALPN (for example "h2"). It plugs custom
+// protocol implementations into RunTLS and RunAutoTLS, including an
+// HTTP/2 implementation other than the one enabled by Zeno.HTTP2; the
+// handler owns the connection and is responsible for dispatching each
+// stream to HandleRequest. It must be called before RunTLS.
+//
+// Example:
+//
+//	app.NextProto("h2", h2server.ServeConn)
+func (z *Zeno) NextProto(proto string, handler fasthttp.ServeHandler) {
+	if z.nextProtos == nil {
+		z.nextProtos = make(map[string]fasthttp.ServeHandler)
+	}
+	z.nextProtos[proto] = handler
+}
+
+// protoNames returns the protocols registered with NextProto, sorted.
+func (z *Zeno) protoNames() []string {
+	return slices.Sorted(maps.Keys(z.nextProtos))
+}
+
+// Server returns the fasthttp.Server used by Run, RunTLS, RunMany and
+// RunAutoTLS, creating it on first use. It gives access to settings zeno
+// does not model itself, such as buffer sizes, timeouts and keep-alive
+// behaviour. Changes must be made before the server is started; Handler
+// is set to HandleRequest if left nil.
+//
+// Example:
+//
+//	srv := app.Server()
+//	srv.ReadBufferSize = 16 << 10
+//	srv.ReadTimeout = 10 * time.Second
+//	srv.TCPKeepalive = true
+//	log.Fatal(app.Run(":8080"))
+func (z *Zeno) Server() *fasthttp.Server {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if z.server == nil {
+		z.server = &fasthttp.Server{Handler: z.HandleRequest}
+	}
+	return z.server
+}
+
+// newServer prepares the fasthttp.Server returned by Server with the
+// engine's configuration and registers it for Shutdown. It is called once
+// the listeners are open, so it also reports readiness to a parent
+// process that started this one with Restart.
+func (z *Zeno) newServer() *fasthttp.Server {
+	s := z.Server()
+	if s.Handler == nil {
+		s.Handler = z.HandleRequest
+	}
+	if z.StreamRequestBody {
+		s.StreamRequestBody = true
 	}
-	return fasthttp.ListenAndServe(addr, z.HandleRequest)
+	if z.HTTP2 {
+		z.enableHTTP2()
+	}
+	// The server is shared by every Run, so protocols it already offers
+	// are not registered again.
+	for _, proto := range z.protoNames() {
+		if s.TLSConfig == nil || !slices.Contains(s.TLSConfig.NextProtos, proto) {
+			s.NextProto(proto, z.nextProtos[proto])
+		}
+	}
+	z.markServing()
+	z.trackServer(s)
+	return s
+}
+
+// trackServer records s so that Shutdown can stop it.
+func (z *Zeno) trackServer(s *fasthttp.Server) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if !slices.Contains(z.servers, s) {
+		z.servers = append(z.servers, s)
+	}
+}
+
+// Shutdown gracefully stops all servers started by Run, RunTLS, and
+// RunAutoTLS. It marks the engine as draining (see IsShuttingDown), waits
+// for ShutdownDelay, stops accepting new connections, and waits for
+// in-flight requests to finish or for ctx to be done, whichever comes
+// first. OnShutdown hooks run afterwards.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	_ = app.Shutdown(ctx)
+func (z *Zeno) Shutdown(ctx context.Context) error {
+	z.draining.Store(true)
+	if z.ShutdownDelay > 0 {
+		t := time.NewTimer(z.ShutdownDelay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}
+
+	z.mu.RLock()
+	servers := slices.Clone(z.servers)
+	h2 := z.h2Base
+	z.mu.RUnlock()
+
+	var errs []error
+	if h2 != nil {
+		// Sends GOAWAY on open HTTP/2 connections so they drain.
+		if err := h2.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, s := range servers {
+		if err := s.ShutdownWithContext(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, z.runShutdownHooks(ctx))
+	return errors.Join(errs...)
+}
+
+// IsShuttingDown reports whether Shutdown has been called. Readiness
+// probes use it to report failure while connections drain so load
+// balancers stop sending new traffic.
+func (z *Zeno) IsShuttingDown() bool {
+	return z.draining.Load()
 }
@@ -0,0 +1,654 @@
+package zeno
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// performRequest dispatches a synthetic request through z.HandleRequest and
+// returns the native context so the response can be inspected.
+func performRequest(z *Zeno, method, uri string, headers map[string]string, body []byte) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	for k, v := range headers {
+		ctx.Request.Header.Set(k, v)
+	}
+	if body != nil {
+		ctx.Request.SetBody(body)
+	}
+	z.HandleRequest(ctx)
+	return ctx
+}
+
+func TestZeno_AfterResponse(t *testing.T) {
+	z := New()
+	done := make(chan string, 1)
+	z.Get("/users/{id}", func(c *Context) error {
+		id := c.Param("id")
+		c.AfterResponse(func() { done <- id })
+		return c.SendString("ok")
+	})
+
+	ctx := performRequest(z, "GET", "/users/42", nil, nil)
+	assert.Equal(t, "ok", string(ctx.Response.Body()))
+
+	select {
+	case id := <-done:
+		assert.Equal(t, "42", id)
+	case <-time.After(time.Second):
+		t.Fatal("AfterResponse function was not called")
+	}
+}
+
+// logRecorder is a fasthttp.Logger keeping the messages it is given.
+type logRecorder struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (l *logRecorder) Printf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+}
+
+func TestZeno_AfterResponsePanic(t *testing.T) {
+	z := New()
+	logger := &logRecorder{}
+	z.Server().Logger = logger
+	done := make(chan struct{})
+	z.Get("/", func(c *Context) error {
+		c.AfterResponse(func() { panic("webhook down") })
+		c.AfterResponse(func() { close(done) })
+		return c.SendString("ok")
+	})
+
+	performRequest(z, "GET", "/", nil, nil)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("AfterResponse function after a panic was not called")
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	assert.Len(t, logger.logs, 1)
+	assert.Contains(t, logger.logs[0], "webhook down")
+}
+
+func TestZeno_DebugReleasedContext(t *testing.T) {
+	z := New()
+	z.Debug = true
+	var leaked *Context
+	z.Get("/", func(c *Context) error {
+		leaked = c
+		return c.SendString("ok")
+	})
+
+	performRequest(z, "GET", "/", nil, nil)
+	assert.PanicsWithValue(t,
+		"zeno: Context used after its request completed; use Context.Copy to pass request data to goroutines",
+		func() { _ = leaked.Path() })
+}
+
+func TestZeno_AddRouteAtRuntime(t *testing.T) {
+	z := New()
+	z.Get("/a", func(c *Context) error { return c.SendString("a") })
+
+	ctx := performRequest(z, "GET", "/b/1-2", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+
+	// The server is now serving; new routes are swapped in atomically.
+	z.Get("/b/{x}-{y}", func(c *Context) error { return c.SendString(c.Param("x") + c.Param("y")) })
+
+	ctx = performRequest(z, "GET", "/b/1-2", nil, nil)
+	assert.Equal(t, "12", string(ctx.Response.Body()))
+	ctx = performRequest(z, "GET", "/a", nil, nil)
+	assert.Equal(t, "a", string(ctx.Response.Body()))
+}
+
+func TestZeno_AddRouteWhileServing(t *testing.T) {
+	z := New()
+	z.Get("/a", func(c *Context) error { return c.SendString("a") })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 50 {
+			performRequest(z, "GET", "/r/"+strconv.Itoa(i), nil, nil)
+		}
+	}()
+	for i := range 50 {
+		z.Get("/r/"+strconv.Itoa(i), func(c *Context) error { return c.SendString("r") })
+	}
+	<-done
+
+	ctx := performRequest(z, "GET", "/r/49", nil, nil)
+	assert.Equal(t, "r", string(ctx.Response.Body()))
+}
+
+func TestZeno_RemoveRoute(t *testing.T) {
+	z := New()
+	z.Get("/users/{id}", func(c *Context) error { return c.SendString("get") })
+	z.Post("/users/{id}", func(c *Context) error { return c.SendString("post") })
+	beta := z.Get("/beta", func(c *Context) error { return c.SendString("beta") }).Name("beta")
+
+	z.Get("/old", func(c *Context) error { return nil }).Name("old")
+	assert.True(t, z.Remove(MethodGet, "/users/{id}"))
+	assert.False(t, z.Remove(MethodGet, "/users/{id}"))
+	assert.True(t, z.Remove(MethodGet, "/old"))
+	assert.Nil(t, z.GetRoute("old"))
+	_, err := z.URL("old")
+	assert.Error(t, err)
+
+	ctx := performRequest(z, "GET", "/users/1", nil, nil)
+	assert.Equal(t, StatusMethodNotAllowed, ctx.Response.StatusCode())
+	ctx = performRequest(z, "POST", "/users/1", nil, nil)
+	assert.Equal(t, "post", string(ctx.Response.Body()))
+
+	beta.Detach()
+	ctx = performRequest(z, "GET", "/beta", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+	assert.Nil(t, z.GetRoute("beta"))
+}
+
+func TestRewrite(t *testing.T) {
+	z := New()
+	z.Use(Rewrite(map[string]string{
+		"/old/*":          "/new/$1",
+		"^/u/([0-9]+)/?$": "/users/$1",
+	}))
+	z.Get("/new/{rest*}", func(c *Context) error { return c.SendString("new:" + c.Param("rest")) })
+	z.Get("/users/{id}", func(c *Context) error { return c.SendString("user:" + c.Param("id")) })
+
+	ctx := performRequest(z, "GET", "/old/a/b?x=1", nil, nil)
+	assert.Equal(t, "new:a/b", string(ctx.Response.Body()))
+	assert.Equal(t, "1", string(ctx.QueryArgs().Peek("x")))
+
+	ctx = performRequest(z, "GET", "/u/7", nil, nil)
+	assert.Equal(t, "user:7", string(ctx.Response.Body()))
+
+	z.Get("/loop", func(c *Context) error { return c.Rewrite("/loop2") })
+	z.Get("/loop2", func(c *Context) error { return c.Rewrite("/loop") })
+	ctx = performRequest(z, "GET", "/loop", nil, nil)
+	assert.Equal(t, StatusLoopDetected, ctx.Response.StatusCode())
+}
+
+func TestRewrite_Target(t *testing.T) {
+	for _, tt := range []struct{ pattern, target, path, want string }{
+		{"/docs/*", "/pages/$1v2", "/docs/intro", "/pages/introv2"},
+		{"/docs/*", "/pages/$1.html", "/docs/intro", "/pages/intro.html"},
+		{"/a/*/b/*", "/$2x$1", "/a/1/b/2", "/2x1"},
+		{"^/p/(?P<slug>[a-z]+)$", "/posts/${slug}", "/p/hi", "/posts/hi"},
+		{"/cost/*", "/price/$$$1", "/cost/5", "/price/$5"},
+	} {
+		rule := compileRewriteRule(tt.pattern, tt.target)
+		assert.Equal(t, tt.want, rule.pattern.ReplaceAllString(tt.path, rule.target), tt.target)
+	}
+}
+
+func TestRewrite_RerunsChain(t *testing.T) {
+	z := New()
+	var calls []string
+	z.Use(func(c *Context) error {
+		calls = append(calls, "global")
+		return c.Next()
+	})
+	z.Use(MaxConcurrent(1, 0, 0))
+	z.Use(Rewrite(map[string]string{"/old/*": "/files/$1"}))
+	z.Get("/files/{name}", func(c *Context) error { return c.SendString(c.Param("name")) })
+	admin := z.Group("/admin", func(c *Context) error {
+		calls = append(calls, "admin")
+		return c.Next()
+	})
+	admin.Get("/panel", func(c *Context) error { return c.SendString("panel") })
+	z.Get("/go-admin", func(c *Context) error { return c.Rewrite("/admin/panel") })
+
+	// The rewritten request runs the global middleware again, and the
+	// concurrency limit does not count it twice.
+	ctx := performRequest(z, "GET", "/old/a", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "a", string(ctx.Response.Body()))
+	assert.Equal(t, []string{"global", "global"}, calls)
+
+	// A group given its own middleware does not inherit the global one.
+	calls = nil
+	ctx = performRequest(z, "GET", "/go-admin", nil, nil)
+	assert.Equal(t, "panel", string(ctx.Response.Body()))
+	assert.Equal(t, []string{"global", "admin"}, calls)
+
+	// Rewritten paths are cleaned like request paths.
+	z.Get("/nul", func(c *Context) error { return c.Rewrite("/files/a\x00b") })
+	ctx = performRequest(z, "GET", "/nul", nil, nil)
+	assert.Equal(t, StatusBadRequest, ctx.Response.StatusCode())
+}
+
+func TestContext_ReRoute(t *testing.T) {
+	z := New()
+	z.Get("/errors/{code}", func(c *Context) error {
+		return c.Status(StatusNotFound).SendString("error page " + c.Param("code") + " for " + c.Get("from").(string))
+	})
+	z.Post("/missing", func(c *Context) error {
+		c.Set("from", strings.Clone(c.Method()))
+		return c.ReRoute(MethodGet, "/errors/404")
+	})
+
+	ctx := performRequest(z, "POST", "/missing", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+	assert.Equal(t, "error page 404 for POST", string(ctx.Response.Body()))
+}
+
+func TestZeno_SPA(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<app>"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.js"), []byte("js"), 0o644))
+
+	z := New()
+	z.Get("/api/ping", func(c *Context) error { return c.SendString("pong") })
+	z.SPA("/", dir, SPAConfig{Exclude: []string{"/api/"}})
+
+	assert.Equal(t, "pong", string(performRequest(z, "GET", "/api/ping", nil, nil).Response.Body()))
+	assert.Equal(t, "js", string(performRequest(z, "GET", "/app.js", nil, nil).Response.Body()))
+	ctx := performRequest(z, "GET", "/settings/profile", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "<app>", string(ctx.Response.Body()))
+	assert.Equal(t, "<app>", string(performRequest(z, "GET", "/../../etc/passwd", nil, nil).Response.Body()))
+	assert.Equal(t, StatusNotFound, performRequest(z, "GET", "/api/missing", nil, nil).Response.StatusCode())
+
+	// Other methods are answered as without the SPA.
+	assert.Equal(t, StatusNotFound, performRequest(z, "POST", "/settings/profile", nil, nil).Response.StatusCode())
+	assert.Equal(t, StatusNotFound, performRequest(z, "DELETE", "/api/missing", nil, nil).Response.StatusCode())
+	ctx = performRequest(z, "POST", "/api/ping", nil, nil)
+	assert.Equal(t, StatusMethodNotAllowed, ctx.Response.StatusCode())
+	assert.Equal(t, "GET, OPTIONS", string(ctx.Response.Header.Peek(HeaderAllow)))
+
+	// Routes registered later still take priority.
+	z.Get("/about", func(c *Context) error { return c.SendString("about") })
+	assert.Equal(t, "about", string(performRequest(z, "GET", "/about", nil, nil).Response.Body()))
+}
+
+func TestZeno_SPA_FS(t *testing.T) {
+	z := New()
+	z.FS = fstest.MapFS{
+		"dist/index.html":     {Data: []byte("<app>")},
+		"dist/assets/app.js":  {Data: []byte("js")},
+		"dist/assets/app.css": {Data: []byte("css")},
+	}
+	z.SPA("/", "./dist")
+
+	ctx := performRequest(z, "GET", "/assets/app.js", nil, nil)
+	assert.Equal(t, "js", string(ctx.Response.Body()))
+	assert.Equal(t, "text/javascript; charset=utf-8", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, "css", string(performRequest(z, "GET", "/assets/app.css", nil, nil).Response.Body()))
+	assert.Equal(t, "<app>", string(performRequest(z, "GET", "/assets", nil, nil).Response.Body()))
+	assert.Equal(t, "<app>", string(performRequest(z, "GET", "/settings/profile", nil, nil).Response.Body()))
+}
+
+func TestFaviconAndRobots(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "favicon.ico")
+	assert.NoError(t, os.WriteFile(file, []byte("ico"), 0o644))
+
+	z := New()
+	var seen []string
+	z.Use(func(c *Context) error {
+		seen = append(seen, c.Path())
+		return c.Next()
+	})
+	favicon := Favicon(file)
+	z.Use(favicon, Robots("User-agent: *\n"))
+	assert.Nil(t, z.assetPaths.Load())
+
+	ctx := performRequest(z, "GET", "/favicon.ico", nil, nil)
+	assert.Equal(t, "ico", string(ctx.Response.Body()))
+	assert.Equal(t, staticCacheControl, string(ctx.Response.Header.Peek(HeaderCacheControl)))
+
+	ctx = performRequest(z, "GET", "/robots.txt", nil, nil)
+	assert.Equal(t, "User-agent: *\n", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "GET", "/other", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+
+	// Served paths skip the route lookup from then on; earlier global
+	// middleware still runs on them.
+	assert.Equal(t, map[string]bool{"/favicon.ico": true, "/robots.txt": true}, *z.assetPaths.Load())
+	ctx = performRequest(z, "GET", "/favicon.ico", nil, nil)
+	assert.Equal(t, "ico", string(ctx.Response.Body()))
+	assert.Equal(t, []string{"/favicon.ico", "/robots.txt", "/other", "/favicon.ico"}, seen)
+	ctx = performRequest(z, "POST", "/favicon.ico", nil, nil)
+	assert.Equal(t, StatusMethodNotAllowed, ctx.Response.StatusCode())
+
+	// The paths are kept per engine, and only for global middleware.
+	other := New()
+	other.Get("/static", favicon)
+	performRequest(other, "GET", "/favicon.ico", nil, nil)
+	assert.Nil(t, other.assetPaths.Load())
+	z.Use(func(c *Context) error { return c.Next() })
+	assert.Nil(t, z.assetPaths.Load())
+}
+
+func TestHealthCheck(t *testing.T) {
+	var dbErr error
+	z := New()
+	z.Use(HealthCheck(HealthCheckConfig{
+		Checkers: []HealthChecker{func(*Context) error { return dbErr }},
+		Detail:   true,
+	}))
+
+	ctx := performRequest(z, "GET", "/readyz", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"status":"ok"}`, string(ctx.Response.Body()))
+
+	dbErr = errors.New("db down")
+	ctx = performRequest(z, "GET", "/readyz", nil, nil)
+	assert.Equal(t, StatusServiceUnavailable, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"status":"unavailable","errors":["db down"]}`, string(ctx.Response.Body()))
+
+	dbErr = nil
+	assert.NoError(t, z.Shutdown(context.Background()))
+	ctx = performRequest(z, "GET", "/readyz", nil, nil)
+	assert.Equal(t, StatusServiceUnavailable, ctx.Response.StatusCode())
+	ctx = performRequest(z, "GET", "/livez", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+}
+
+func TestZeno_ShutdownDelay(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "app.sock")
+	z := New()
+	z.ShutdownDelay = 200 * time.Millisecond
+	z.Use(HealthCheck(HealthCheckConfig{}))
+	z.Get("/", func(c *Context) error { return c.SendString("ok") })
+	errc := make(chan error, 1)
+	go func() { errc <- z.Run("unix:" + sock) }()
+
+	client := &fasthttp.Client{Dial: func(string) (net.Conn, error) { return net.Dial("unix", sock) }}
+	assert.Eventually(t, func() bool {
+		_, _, err := client.Get(nil, "http://app/")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() { done <- z.Shutdown(context.Background()) }()
+	assert.Eventually(t, z.IsShuttingDown, time.Second, time.Millisecond)
+
+	// New connections are still accepted while the delay runs.
+	conn, err := net.Dial("unix", sock)
+	if assert.NoError(t, err) {
+		conn.Write([]byte("GET /readyz HTTP/1.1\r\nHost: app\r\nConnection: close\r\n\r\n"))
+		resp, _ := io.ReadAll(conn)
+		conn.Close()
+		assert.True(t, strings.HasPrefix(string(resp), "HTTP/1.1 503"), string(resp))
+	}
+	assert.NoError(t, <-done)
+	assert.NoError(t, <-errc)
+}
+
+func TestMaxConcurrent(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	mw := MaxConcurrent(1, 1, 50*time.Millisecond)
+	slow := func(c *Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+
+	run := func() error {
+		c, _ := newTestContext("GET", "/", nil, nil)
+		c.handlers = []Handler{mw, slow}
+		return c.Next()
+	}
+
+	done := make(chan error)
+	go func() { done <- run() }()
+	<-started
+
+	// The queued request times out while the slot is busy.
+	c, ctx := newTestContext("GET", "/", nil, nil)
+	c.handlers = []Handler{mw, slow}
+	err := c.Next()
+	assert.Equal(t, StatusServiceUnavailable, err.(HTTPError).StatusCode())
+	assert.Equal(t, "1", string(ctx.Response.Header.Peek(HeaderRetryAfter)))
+
+	close(release)
+	assert.NoError(t, <-done)
+	go func() { <-started }()
+	assert.NoError(t, run())
+}
+
+func TestZeno_RunMany(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.sock"), filepath.Join(dir, "b.sock")
+
+	z := New()
+	z.Get("/", func(c *Context) error { return c.SendString("ok") })
+	errc := make(chan error, 1)
+	go func() { errc <- z.RunMany("unix:"+a, "unix:"+b) }()
+
+	for _, sock := range []string{a, b} {
+		client := &fasthttp.Client{Dial: func(string) (net.Conn, error) { return net.Dial("unix", sock) }}
+		var status int
+		var body []byte
+		assert.Eventually(t, func() bool {
+			var err error
+			status, body, err = client.Get(nil, "http://app/")
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, StatusOK, status)
+		assert.Equal(t, "ok", string(body))
+	}
+
+	assert.NoError(t, z.Shutdown(context.Background()))
+	assert.NoError(t, <-errc)
+}
+
+func TestZeno_RunMany_BindError(t *testing.T) {
+	dir := t.TempDir()
+	busy, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer busy.Close()
+
+	z := New()
+	sock := filepath.Join(dir, "a.sock")
+	assert.Error(t, z.RunMany("unix:"+sock, busy.Addr().String()))
+	assert.Empty(t, z.listeners, "no listener is kept for Restart")
+	_, err = net.Dial("unix", sock)
+	assert.Error(t, err, "listeners opened before the failure are closed")
+}
+
+func TestZeno_Server(t *testing.T) {
+	z := New()
+	s := z.Server()
+	assert.Same(t, s, z.Server())
+	s.ReadBufferSize = 16 << 10
+	z.StreamRequestBody = true
+
+	assert.Same(t, s, z.newServer())
+	assert.True(t, s.StreamRequestBody)
+	assert.Equal(t, 16<<10, s.ReadBufferSize)
+	assert.Len(t, z.servers, 1)
+}
+
+func TestZeno_LifecycleHooks(t *testing.T) {
+	z := New()
+	var events []string
+	z.OnRouteRegistered(func(r RouteInfo) { events = append(events, r.Method+" "+r.Path) })
+	z.OnStart(func() error { events = append(events, "start"); return nil })
+	z.OnStart(func() error { return errors.New("no db") })
+	z.OnShutdown(func(context.Context) error { events = append(events, "close db"); return nil })
+	z.OnShutdown(func(context.Context) error { events = append(events, "deregister"); return nil })
+
+	z.Get("/users", func(*Context) error { return nil })
+	assert.EqualError(t, z.Run("127.0.0.1:0"), "no db")
+	assert.EqualError(t, z.Run("127.0.0.1:0"), "no db")
+	assert.NoError(t, z.Shutdown(context.Background()))
+
+	assert.Equal(t, []string{"GET /users", "start", "deregister", "close db"}, events)
+}
+
+func TestZeno_CustomMethods(t *testing.T) {
+	z := New()
+	z.Use(MethodOverride())
+	z.Handle("PROPFIND", "/files/{path*}", func(c *Context) error {
+		return c.SendString("props:" + c.Param("path"))
+	})
+	z.Delete("/posts/{id}", func(c *Context) error { return c.SendString("deleted " + c.Param("id")) })
+	z.Get("/posts/{id}", func(c *Context) error { return nil })
+
+	ctx := performRequest(z, "PROPFIND", "/files/a/b", nil, nil)
+	assert.Equal(t, "props:a/b", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "POST", "/posts/3", map[string]string{HeaderXHTTPMethodOverride: "delete"}, nil)
+	assert.Equal(t, "deleted 3", string(ctx.Response.Body()))
+
+	ctx = performRequest(z, "POST", "/posts/4", map[string]string{HeaderContentType: "application/x-www-form-urlencoded"}, []byte("_method=DELETE"))
+	assert.Equal(t, "deleted 4", string(ctx.Response.Body()))
+
+	// Only PUT, PATCH and DELETE may be requested by default.
+	for _, m := range []string{"PROPFIND", "TRACE", "CONNECT", "X-ANYTHING"} {
+		ctx = performRequest(z, "POST", "/files/a", map[string]string{HeaderXHTTPMethodOverride: m}, nil)
+		assert.Equal(t, StatusMethodNotAllowed, ctx.Response.StatusCode(), m)
+	}
+	z2 := New()
+	calls := 0
+	z2.Use(func(c *Context) error {
+		calls++
+		return c.Next()
+	})
+	z2.Use(MethodOverride(MethodOverrideConfig{Methods: []string{"propfind"}}))
+	z2.Handle("PROPFIND", "/files/{path*}", func(c *Context) error { return c.SendString("props") })
+	z2.Delete("/files/{path*}", func(c *Context) error { return c.SendString("deleted") })
+	ctx = performRequest(z2, "POST", "/files/a", map[string]string{HeaderXHTTPMethodOverride: "PROPFIND"}, nil)
+	assert.Equal(t, "props", string(ctx.Response.Body()))
+	assert.Equal(t, 2, calls, "global middleware runs again for the overridden method")
+	ctx = performRequest(z2, "POST", "/files/a", map[string]string{HeaderXHTTPMethodOverride: "DELETE"}, nil)
+	assert.Equal(t, StatusMethodNotAllowed, ctx.Response.StatusCode())
+
+	methods := z.findAllowedMethods([]byte("/files/x"))
+	assert.True(t, methods["PROPFIND"])
+}
+
+func TestZeno_MethodNotAllowed(t *testing.T) {
+	z := New()
+	cors := func(c *Context) error {
+		c.SetHeader(HeaderAccessControlAllowOrigin, "*")
+		return c.Next()
+	}
+	api := z.Group("/api", cors)
+	api.Get("/users/{id}", func(c *Context) error { return nil })
+	api.Put("/users/{id}", func(c *Context) error { return nil })
+
+	ctx := performRequest(z, "OPTIONS", "/api/users/1", nil, nil)
+	assert.Equal(t, StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, "GET, OPTIONS, PUT", string(ctx.Response.Header.Peek(HeaderAllow)))
+	assert.Equal(t, "*", string(ctx.Response.Header.Peek(HeaderAccessControlAllowOrigin)))
+
+	ctx = performRequest(z, "DELETE", "/api/users/1", nil, nil)
+	assert.Equal(t, StatusMethodNotAllowed, ctx.Response.StatusCode())
+	assert.Equal(t, "GET, OPTIONS, PUT", string(ctx.Response.Header.Peek(HeaderAllow)))
+
+	z.MethodNotAllowed(func(c *Context) error {
+		return c.Status(StatusMethodNotAllowed).SendString("use " + string(c.Response().Header.Peek(HeaderAllow)))
+	})
+	ctx = performRequest(z, "DELETE", "/api/users/1", nil, nil)
+	assert.Equal(t, "use GET, OPTIONS, PUT", string(ctx.Response.Body()))
+	assert.Equal(t, "*", string(ctx.Response.Header.Peek(HeaderAccessControlAllowOrigin)))
+
+	ctx = performRequest(z, "DELETE", "/api/other", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+}
+
+func TestZeno_AllowUnionsPatterns(t *testing.T) {
+	z := New()
+	ok := func(c *Context) error { return nil }
+	z.Get("/users/{id}", ok)
+	z.Delete("/users/me", ok)
+
+	ctx := performRequest(z, "PUT", "/users/me", nil, nil)
+	assert.Equal(t, StatusMethodNotAllowed, ctx.Response.StatusCode())
+	assert.Equal(t, "DELETE, GET, OPTIONS", string(ctx.Response.Header.Peek(HeaderAllow)))
+
+	ctx = performRequest(z, "OPTIONS", "/users/me", nil, nil)
+	assert.Equal(t, "DELETE, GET, OPTIONS", string(ctx.Response.Header.Peek(HeaderAllow)))
+
+	ctx = performRequest(z, "PUT", "/users/7", nil, nil)
+	assert.Equal(t, "GET, OPTIONS", string(ctx.Response.Header.Peek(HeaderAllow)))
+	assert.Equal(t, "DELETE, GET, OPTIONS", z.MatchRoute("PUT", "/users/me").Allow)
+}
+
+func TestMethodNotAllowedHandler_NotFoundChain(t *testing.T) {
+	z := New()
+	z.NotFound(MethodNotAllowedHandler, NotFoundHandler)
+	z.Get("/users/{id}", func(c *Context) error { return nil })
+
+	ctx := performRequest(z, "GET", "/missing", nil, nil)
+	assert.Equal(t, StatusNotFound, ctx.Response.StatusCode())
+
+	// Routed to the not-found chain, the handler answers 405 itself.
+	c := z.pool.Get().(*Context)
+	defer z.pool.Put(c)
+	var rctx fasthttp.RequestCtx
+	rctx.Request.Header.SetMethod(MethodPost)
+	rctx.Request.SetRequestURI("/users/1")
+	c.init(&rctx)
+	assert.NoError(t, MethodNotAllowedHandler(c))
+	assert.Equal(t, StatusMethodNotAllowed, rctx.Response.StatusCode())
+	assert.Equal(t, "GET, OPTIONS", string(rctx.Response.Header.Peek(HeaderAllow)))
+}
+
+func TestZeno_StaticFastPath(t *testing.T) {
+	z := New()
+	z.Get("/users/{id}", func(c *Context) error { return c.SendString("user " + c.Param("id")) })
+	z.Get("/users/new", func(c *Context) error { return c.SendString("new") })
+	z.Get("/about", func(c *Context) error { return c.SendString("about") })
+	assert.PanicsWithValue(t, "zeno: route GET /about is already registered", func() {
+		z.Get("/about", func(c *Context) error { return c.SendString("shadowed") })
+	})
+	z.Group("/api").Get("/x", func(c *Context) error { return nil })
+	assert.Panics(t, func() { z.Get("/api/x", func(c *Context) error { return nil }) }, "groups collide too")
+
+	table := z.table.Load()
+	assert.NotContains(t, table.static[MethodGet], "/users/new")
+	assert.Contains(t, table.static[MethodGet], "/about")
+
+	// Earlier routes keep precedence over later static ones.
+	ctx := performRequest(z, "GET", "/users/new", nil, nil)
+	assert.Equal(t, "user new", string(ctx.Response.Body()))
+	ctx = performRequest(z, "GET", "/about", nil, nil)
+	assert.Equal(t, "about", string(ctx.Response.Body()))
+}
+
+func BenchmarkZeno_Find(b *testing.B) {
+	z := New()
+	h := func(c *Context) error { return nil }
+	for i := range 100 {
+		z.Get("/static/page"+strconv.Itoa(i), h)
+		z.Get("/items"+strconv.Itoa(i)+"/{id}", h)
+	}
+	for _, bc := range []struct{ name, path string }{
+		{"Static", "/static/page57"},
+		{"Param", "/items57/42"},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			path := []byte(bc.path)
+			pvalues := make([]string, 1)
+			b.ReportAllocs()
+			for b.Loop() {
+				z.find(MethodGet, path, pvalues)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+// Package zenotest sends requests to a zeno engine over an in-memory
+// connection, so routes can be tested end to end, through the real HTTP
+// parser and writer, without opening ports:
+//
+//	func TestGetUser(t *testing.T) {
+//	    c := zenotest.New(app)
+//	    defer c.Close()
+//
+//	    c.Get("/users/1").Do().
+//	        ExpectStatus(t, zeno.StatusOK).
+//	        ExpectJSON(t, map[string]any{"id": 1, "name": "Alice"})
+//	}
+package zenotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/Abhishek2010dev/zeno"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// Client sends requests to an engine over an in-memory connection.
+type Client struct {
+	z      *zeno.Zeno
+	ln     *fasthttputil.InmemoryListener
+	client *fasthttp.Client
+	once   sync.Once
+}
+
+// New returns a Client for z. The engine is served on the first request,
+// with its configured server (see Zeno.Server) as by Zeno.Serve, so
+// OnStart hooks and settings such as StreamRequestBody apply; call Close
+// when done.
+func New(z *zeno.Zeno) *Client {
+	c := &Client{z: z, ln: fasthttputil.NewInmemoryListener()}
+	c.client = &fasthttp.Client{
+		Dial: func(string) (net.Conn, error) { return c.ln.Dial() },
+	}
+	return c
+}
+
+// start serves the engine on the in-memory listener.
+func (c *Client) start() {
+	c.once.Do(func() {
+		go c.z.Serve(c.ln)
+	})
+}
+
+// Close stops the in-memory server.
+func (c *Client) Close() error {
+	return c.ln.Close()
+}
+
+// Request starts building a request with the given method and path.
+func (c *Client) Request(method, path string) *Request {
+	return &Request{c: c, method: method, path: path}
+}
+
+// Get starts building a GET request.
+func (c *Client) Get(path string) *Request { return c.Request(zeno.MethodGet, path) }
+
+// Post starts building a POST request.
+func (c *Client) Post(path string) *Request { return c.Request(zeno.MethodPost, path) }
+
+// Put starts building a PUT request.
+func (c *Client) Put(path string) *Request { return c.Request(zeno.MethodPut, path) }
+
+// Patch starts building a PATCH request.
+func (c *Client) Patch(path string) *Request { return c.Request(zeno.MethodPatch, path) }
+
+// Delete starts building a DELETE request.
+func (c *Client) Delete(path string) *Request { return c.Request(zeno.MethodDelete, path) }
+
+// Request is a request being built by a Client.
+type Request struct {
+	c           *Client
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        []byte
+	contentType string
+}
+
+// Query adds a query string parameter.
+func (r *Request) Query(key, value string) *Request {
+	if r.query == nil {
+		r.query = url.Values{}
+	}
+	r.query.Add(key, value)
+	return r
+}
+
+// Header sets a request header.
+func (r *Request) Header(key, value string) *Request {
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+	r.header.Set(key, value)
+	return r
+}
+
+// Body sets the request body and its Content-Type.
+func (r *Request) Body(contentType string, body []byte) *Request {
+	r.contentType, r.body = contentType, body
+	return r
+}
+
+// JSON sets the request body to v encoded as JSON. It panics if v cannot
+// be encoded.
+func (r *Request) JSON(v any) *Request {
+	b, err := r.c.z.JsonEncoder(v)
+	if err != nil {
+		panic("zenotest: Request.JSON: " + err.Error())
+	}
+	return r.Body("application/json", b)
+}
+
+// Do sends the request and returns the response. Transport failures, which
+// indicate a broken test setup rather than a failing route, panic.
+func (r *Request) Do() *Response {
+	r.c.start()
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	uri := "http://zeno.test" + r.path
+	if len(r.query) > 0 {
+		uri += "?" + r.query.Encode()
+	}
+	req.SetRequestURI(uri)
+	req.Header.SetMethod(r.method)
+	for k, values := range r.header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	if r.body != nil {
+		req.Header.SetContentType(r.contentType)
+		req.SetBody(r.body)
+	}
+	if err := r.c.client.Do(req, resp); err != nil {
+		panic("zenotest: Request.Do: " + err.Error())
+	}
+
+	out := &Response{
+		StatusCode: resp.StatusCode(),
+		Header:     http.Header{},
+		Body:       append([]byte(nil), resp.Body()...),
+	}
+	resp.Header.VisitAll(func(key, value []byte) {
+		out.Header.Add(string(key), string(value))
+	})
+	return out
+}
+
+// Response is a response received by a Client. Its Expect methods
+// report mismatches through t and return the response, so they can be
+// chained.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// ExpectStatus checks the status code.
+func (r *Response) ExpectStatus(t testing.TB, code int) *Response {
+	t.Helper()
+	if r.StatusCode != code {
+		t.Errorf("status = %d, want %d; body: %s", r.StatusCode, code, r.Body)
+	}
+	return r
+}
+
+// ExpectHeader checks the value of a response header.
+func (r *Response) ExpectHeader(t testing.TB, key, value string) *Response {
+	t.Helper()
+	if got := r.Header.Get(key); got != value {
+		t.Errorf("header %s = %q, want %q", key, got, value)
+	}
+	return r
+}
+
+// ExpectBody checks that the body equals body.
+func (r *Response) ExpectBody(t testing.TB, body string) *Response {
+	t.Helper()
+	if string(r.Body) != body {
+		t.Errorf("body = %q, want %q", r.Body, body)
+	}
+	return r
+}
+
+// ExpectJSON checks that the body is JSON equal to want, which may be any
+// value encoding to the expected document, e.g. a struct, a map or a
+// json.RawMessage. Object key order and formatting are ignored.
+func (r *Response) ExpectJSON(t testing.TB, want any) *Response {
+	t.Helper()
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		t.Errorf("encoding expected JSON: %v", err)
+		return r
+	}
+	var got, exp any
+	if err := json.Unmarshal(r.Body, &got); err != nil {
+		t.Errorf("body is not JSON: %v; body: %s", err, r.Body)
+		return r
+	}
+	_ = json.Unmarshal(wantJSON, &exp)
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("JSON body = %s, want %s", bytes.TrimSpace(r.Body), wantJSON)
+	}
+	return r
+}
+
+// DecodeJSON decodes the JSON body into out.
+func (r *Response) DecodeJSON(out any) error {
+	return json.Unmarshal(r.Body, out)
+}
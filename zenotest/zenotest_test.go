@@ -0,0 +1,67 @@
+package zenotest
+
+import (
+	"testing"
+
+	"github.com/Abhishek2010dev/zeno"
+)
+
+// recordingTB captures assertion failures reported by Response.
+type recordingTB struct {
+	testing.TB
+	failures int
+}
+
+func (r *recordingTB) Helper()               {}
+func (r *recordingTB) Errorf(string, ...any) { r.failures++ }
+
+func TestClient(t *testing.T) {
+	z := zeno.New()
+	z.Get("/users/{id}", func(c *zeno.Context) error {
+		return c.SendJSON(zeno.Map{"id": zeno.Param[int](c, "id"), "q": c.Query("q")})
+	})
+	z.Post("/echo", func(c *zeno.Context) error {
+		c.SetHeader("X-Seen", c.GetHeader("X-Test"))
+		return c.SendBytes(c.PostBody())
+	})
+
+	c := New(z)
+	defer c.Close()
+
+	c.Get("/users/1").Query("q", "a b").Do().
+		ExpectStatus(t, zeno.StatusOK).
+		ExpectHeader(t, zeno.HeaderContentType, "application/json; charset=utf-8").
+		ExpectJSON(t, zeno.Map{"q": "a b", "id": 1})
+
+	c.Post("/echo").Header("X-Test", "yes").JSON([]int{1, 2}).Do().
+		ExpectStatus(t, zeno.StatusOK).
+		ExpectHeader(t, "X-Seen", "yes").
+		ExpectBody(t, "[1,2]")
+
+	rec := &recordingTB{TB: t}
+	c.Get("/nope").Do().
+		ExpectStatus(rec, zeno.StatusOK).
+		ExpectJSON(rec, zeno.Map{})
+	if rec.failures != 2 {
+		t.Fatalf("failures = %d, want 2", rec.failures)
+	}
+}
+
+func TestClient_ServesEngine(t *testing.T) {
+	z := zeno.New()
+	started := false
+	z.OnStart(func() error {
+		started = true
+		return nil
+	})
+	z.Server().Name = "test-server"
+	z.Get("/", func(c *zeno.Context) error { return c.SendString("ok") })
+
+	c := New(z)
+	defer c.Close()
+
+	c.Get("/").Do().ExpectBody(t, "ok").ExpectHeader(t, zeno.HeaderServer, "test-server")
+	if !started {
+		t.Error("OnStart hooks did not run")
+	}
+}